@@ -0,0 +1,34 @@
+package acp
+
+import "strings"
+
+// MessageAccumulator folds streamed agent message and thought chunks into their
+// full text. Feed it every SessionUpdate for a turn, in order, with Add. Updates
+// other than agent message and thought chunks, such as tool calls and plans, are
+// ignored, as are non-text content blocks.
+//
+// The zero value is ready to use. A MessageAccumulator is not safe for concurrent use.
+type MessageAccumulator struct {
+	text    strings.Builder
+	thought strings.Builder
+}
+
+// Add folds u into the accumulated text.
+func (m *MessageAccumulator) Add(u SessionUpdate) {
+	switch {
+	case u.AgentMessageChunk != nil:
+		if t := u.AgentMessageChunk.Content.Text; t != nil {
+			m.text.WriteString(t.Text)
+		}
+	case u.AgentThoughtChunk != nil:
+		if t := u.AgentThoughtChunk.Content.Text; t != nil {
+			m.thought.WriteString(t.Text)
+		}
+	}
+}
+
+// Text returns the agent message text accumulated so far.
+func (m *MessageAccumulator) Text() string { return m.text.String() }
+
+// Thought returns the agent thought text accumulated so far.
+func (m *MessageAccumulator) Thought() string { return m.thought.String() }
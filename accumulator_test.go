@@ -0,0 +1,24 @@
+package acp
+
+import "testing"
+
+func TestMessageAccumulator(t *testing.T) {
+	var acc MessageAccumulator
+	for _, u := range []SessionUpdate{
+		UpdateAgentThoughtText("Let me "),
+		UpdateAgentMessageText("Hello, "),
+		UpdateToolCall("call-1"),
+		UpdateAgentThoughtText("think."),
+		UpdateAgentMessage(ImageBlock("aGk=", "image/png")),
+		UpdateUserMessageText("ignored"),
+		UpdateAgentMessageText("world!"),
+	} {
+		acc.Add(u)
+	}
+	if got := acc.Text(); got != "Hello, world!" {
+		t.Fatalf("Text() = %q", got)
+	}
+	if got := acc.Thought(); got != "Let me think." {
+		t.Fatalf("Thought() = %q", got)
+	}
+}
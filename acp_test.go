@@ -5,9 +5,12 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"log/slog"
+	"reflect"
 	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -433,6 +436,37 @@ func TestAgentDispatch_AllowsPartialUnstableMethodImplementation(t *testing.T) {
 	}
 }
 
+func TestAgentDispatch_RejectsMissingParams(t *testing.T) {
+	agent := &forkOnlyUnstableAgent{}
+	conn := &AgentSideConnection{
+		agent:          agent,
+		sessionCancels: make(map[string]context.CancelFunc),
+	}
+
+	for _, params := range []json.RawMessage{nil, json.RawMessage("null")} {
+		_, reqErr := conn.handle(context.Background(), AgentMethodSessionFork, params)
+		if reqErr == nil || reqErr.Code != CodeInvalidParams {
+			t.Fatalf("params %q: expected invalid params error, got %+v", params, reqErr)
+		}
+		want := map[string]any{"error": "missing params for method " + AgentMethodSessionFork}
+		if !reflect.DeepEqual(reqErr.Data, want) {
+			t.Fatalf("params %q: error data = %#v, want %#v", params, reqErr.Data, want)
+		}
+	}
+	if agent.called {
+		t.Fatal("UnstableForkSession must not be invoked without params")
+	}
+
+	// Params that are present but invalid still report the decode error.
+	_, reqErr := conn.handle(context.Background(), AgentMethodSessionFork, json.RawMessage(`{"cwd":1}`))
+	if reqErr == nil || reqErr.Code != CodeInvalidParams {
+		t.Fatalf("expected invalid params error, got %+v", reqErr)
+	}
+	if data, _ := reqErr.Data.(map[string]any); strings.HasPrefix(fmt.Sprint(data["error"]), "missing params") {
+		t.Fatalf("invalid params reported as missing: %+v", reqErr)
+	}
+}
+
 // Test bidirectional error handling similar to typescript/acp.test.ts
 func TestConnectionHandlesErrorsBidirectional(t *testing.T) {
 	ctx := context.Background()
@@ -620,8 +654,8 @@ func TestConnectionHandlesMessageOrdering(t *testing.T) {
 		SessionId: "test-session",
 		ToolCall: ToolCallUpdate{
 			Title:      Ptr("Execute command"),
-			Kind:       ptr(ToolKindExecute),
-			Status:     ptr(ToolCallStatusPending),
+			Kind:       Ptr(ToolKindExecute),
+			Status:     Ptr(ToolCallStatusPending),
 			ToolCallId: "tool-123",
 			Content:    []ToolCallContent{ToolContent(TextBlock("ls -la"))},
 		},
@@ -732,6 +766,63 @@ func TestConnectionHandlesNotifications(t *testing.T) {
 	}
 }
 
+func TestConnectionPreservesNotificationOrder(t *testing.T) {
+	c2aR, c2aW := io.Pipe()
+	a2cR, a2cW := io.Pipe()
+	defer func() {
+		_ = c2aR.Close()
+		_ = c2aW.Close()
+		_ = a2cR.Close()
+		_ = a2cW.Close()
+	}()
+
+	const total = 200
+	var mu sync.Mutex
+	var chunks []string
+	_ = NewClientSideConnection(&clientFuncs{
+		SessionUpdateFunc: func(_ context.Context, n SessionNotification) error {
+			// Vary handler latency so that concurrent dispatch would reorder chunks.
+			if len(n.Update.AgentMessageChunk.Content.Text.Text)%2 == 0 {
+				time.Sleep(100 * time.Microsecond)
+			}
+			mu.Lock()
+			chunks = append(chunks, n.Update.AgentMessageChunk.Content.Text.Text)
+			mu.Unlock()
+			return nil
+		},
+	}, c2aW, a2cR)
+	agentSide := NewAgentSideConnection(agentFuncs{}, a2cW, c2aR)
+
+	want := make([]string, 0, total)
+	for i := 0; i < total; i++ {
+		text := strings.Repeat("x", i%3) + strconv.Itoa(i)
+		want = append(want, text)
+		if err := agentSide.SessionUpdate(context.Background(), SessionNotification{
+			SessionId: "test-session",
+			Update:    UpdateAgentMessageText(text),
+		}); err != nil {
+			t.Fatalf("sessionUpdate %d: %v", i, err)
+		}
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		n := len(chunks)
+		mu.Unlock()
+		if n == total {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !slices.Equal(chunks, want) {
+		t.Fatalf("chunks delivered out of order or incomplete: got %d chunks", len(chunks))
+	}
+}
+
 func TestConnectionDoesNotCancelInboundContextBeforeDrainingNotificationsOnDisconnect(t *testing.T) {
 	const n = 25
 
@@ -872,7 +963,7 @@ func TestConnectionFailsFastOnNotificationQueueOverflow(t *testing.T) {
 	}
 
 	cause := context.Cause(c.ctx)
-	if !errors.Is(cause, errNotificationQueueOverflow) {
+	if !errors.Is(cause, ErrNotificationQueueOverflow) {
 		t.Fatalf("expected overflow cancellation cause, got %v", cause)
 	}
 
@@ -949,10 +1040,6 @@ func TestConnectionHandlesInitialize(t *testing.T) {
 	}
 }
 
-func ptr[T any](t T) *T {
-	return &t
-}
-
 // Test that canceling the client's Prompt context sends a session/cancel
 // to the agent, and that the connection remains usable afterwards.
 func TestPromptCancellationSendsCancelAndAllowsNewSession(t *testing.T) {
@@ -1199,16 +1286,11 @@ func TestRequestHandlerCanMakeNestedRequest(t *testing.T) {
 			return AuthenticateResponse{}, nil
 		},
 		PromptFunc: func(ctx context.Context, p PromptRequest) (PromptResponse, error) {
-			_, err := ag.RequestPermission(ctx, RequestPermissionRequest{
-				SessionId: p.SessionId,
-				ToolCall: ToolCallUpdate{
-					ToolCallId: "call_1",
-					Title:      Ptr("Test permission"),
-				},
-				Options: []PermissionOption{
-					{Kind: PermissionOptionKindAllowOnce, Name: "Allow", OptionId: "allow"},
-				},
-			})
+			_, err := ag.RequestPermission(ctx, NewRequestPermissionRequest(
+				p.SessionId,
+				ToolCallUpdate{ToolCallId: "call_1", Title: Ptr("Test permission")},
+				[]PermissionOption{{Kind: PermissionOptionKindAllowOnce, Name: "Allow", OptionId: "allow"}},
+			))
 			if err != nil {
 				return PromptResponse{}, err
 			}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"io"
 	"log/slog"
+	"slices"
 	"sync"
 )
 
@@ -14,20 +15,50 @@ type AgentSideConnection struct {
 
 	mu             sync.Mutex
 	sessionCancels map[string]context.CancelFunc
+
+	peerExtensions peerExtensions
+	capabilities   negotiatedCapabilities
+	updateSeqs     sessionUpdateCounters
 }
 
 // NewAgentSideConnection creates a new agent-side connection bound to the
 // provided Agent implementation.
-func NewAgentSideConnection(agent Agent, peerInput io.Writer, peerOutput io.Reader) *AgentSideConnection {
+func NewAgentSideConnection(agent Agent, peerInput io.Writer, peerOutput io.Reader, opts ...ConnectionOption) *AgentSideConnection {
 	asc := &AgentSideConnection{}
 	asc.agent = agent
 	asc.sessionCancels = make(map[string]context.CancelFunc)
-	asc.conn = NewConnection(asc.handleWithExtensions, peerInput, peerOutput)
+	asc.conn = NewConnection(asc.handleWithExtensions, peerInput, peerOutput, append(slices.Clip(opts), withOutboundCapabilityCheck(asc.capabilities.checkClientCapability))...)
 	return asc
 }
 
 // Done exposes a channel that closes when the peer disconnects.
 func (c *AgentSideConnection) Done() <-chan struct{} { return c.conn.Done() }
 
+// Err reports why the connection shut down. See Connection.Err.
+func (c *AgentSideConnection) Err() error { return c.conn.Err() }
+
+// Wait blocks until the connection shuts down or ctx is done.
+// See Connection.Wait.
+func (c *AgentSideConnection) Wait(ctx context.Context) error { return c.conn.Wait(ctx) }
+
+// Close tears down the connection.
+// See Connection.Close.
+func (c *AgentSideConnection) Close() error { return c.conn.Close() }
+
+// Shutdown waits for running handlers to finish before closing the connection.
+// See Connection.Shutdown.
+func (c *AgentSideConnection) Shutdown(ctx context.Context) error { return c.conn.Shutdown(ctx) }
+
 // SetLogger directs connection diagnostics to the provided logger.
 func (c *AgentSideConnection) SetLogger(l *slog.Logger) { c.conn.SetLogger(l) }
+
+// SessionUpdates sends a session/update notification for each update, in order,
+// writing them to the client contiguously so that no other message is
+// interleaved between them. Nothing is sent if any update fails to encode.
+func (c *AgentSideConnection) SessionUpdates(ctx context.Context, sessionId SessionId, updates ...SessionUpdate) error {
+	params := make([]any, len(updates))
+	for i, u := range updates {
+		params[i] = SessionNotification{SessionId: sessionId, Update: u}
+	}
+	return c.conn.sendNotificationGroup(ctx, ClientMethodSessionUpdate, params)
+}
@@ -10,12 +10,15 @@ import (
 func (a *AgentSideConnection) handle(ctx context.Context, method string, params json.RawMessage) (any, *RequestError) {
 	switch method {
 	case AgentMethodAuthenticate:
+		if len(params) == 0 || string(params) == "null" {
+			return nil, NewInvalidParams(map[string]any{"error": "missing params for method authenticate"})
+		}
 		var p AuthenticateRequest
 		if err := json.Unmarshal(params, &p); err != nil {
 			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
 		}
 		if err := p.Validate(); err != nil {
-			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
+			return nil, invalidParamsFromValidation(err)
 		}
 		resp, err := a.agent.Authenticate(ctx, p)
 		if err != nil {
@@ -23,12 +26,15 @@ func (a *AgentSideConnection) handle(ctx context.Context, method string, params
 		}
 		return resp, nil
 	case AgentMethodDocumentDidChange:
+		if len(params) == 0 || string(params) == "null" {
+			return nil, NewInvalidParams(map[string]any{"error": "missing params for method document/didChange"})
+		}
 		var p UnstableDidChangeDocumentNotification
 		if err := json.Unmarshal(params, &p); err != nil {
 			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
 		}
 		if err := p.Validate(); err != nil {
-			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
+			return nil, invalidParamsFromValidation(err)
 		}
 		exp, ok := a.agent.(interface {
 			UnstableDidChangeDocument(context.Context, UnstableDidChangeDocumentNotification) error
@@ -41,12 +47,15 @@ func (a *AgentSideConnection) handle(ctx context.Context, method string, params
 		}
 		return nil, nil
 	case AgentMethodDocumentDidClose:
+		if len(params) == 0 || string(params) == "null" {
+			return nil, NewInvalidParams(map[string]any{"error": "missing params for method document/didClose"})
+		}
 		var p UnstableDidCloseDocumentNotification
 		if err := json.Unmarshal(params, &p); err != nil {
 			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
 		}
 		if err := p.Validate(); err != nil {
-			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
+			return nil, invalidParamsFromValidation(err)
 		}
 		exp, ok := a.agent.(interface {
 			UnstableDidCloseDocument(context.Context, UnstableDidCloseDocumentNotification) error
@@ -59,12 +68,15 @@ func (a *AgentSideConnection) handle(ctx context.Context, method string, params
 		}
 		return nil, nil
 	case AgentMethodDocumentDidFocus:
+		if len(params) == 0 || string(params) == "null" {
+			return nil, NewInvalidParams(map[string]any{"error": "missing params for method document/didFocus"})
+		}
 		var p UnstableDidFocusDocumentNotification
 		if err := json.Unmarshal(params, &p); err != nil {
 			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
 		}
 		if err := p.Validate(); err != nil {
-			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
+			return nil, invalidParamsFromValidation(err)
 		}
 		exp, ok := a.agent.(interface {
 			UnstableDidFocusDocument(context.Context, UnstableDidFocusDocumentNotification) error
@@ -77,12 +89,15 @@ func (a *AgentSideConnection) handle(ctx context.Context, method string, params
 		}
 		return nil, nil
 	case AgentMethodDocumentDidOpen:
+		if len(params) == 0 || string(params) == "null" {
+			return nil, NewInvalidParams(map[string]any{"error": "missing params for method document/didOpen"})
+		}
 		var p UnstableDidOpenDocumentNotification
 		if err := json.Unmarshal(params, &p); err != nil {
 			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
 		}
 		if err := p.Validate(); err != nil {
-			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
+			return nil, invalidParamsFromValidation(err)
 		}
 		exp, ok := a.agent.(interface {
 			UnstableDidOpenDocument(context.Context, UnstableDidOpenDocumentNotification) error
@@ -95,12 +110,15 @@ func (a *AgentSideConnection) handle(ctx context.Context, method string, params
 		}
 		return nil, nil
 	case AgentMethodDocumentDidSave:
+		if len(params) == 0 || string(params) == "null" {
+			return nil, NewInvalidParams(map[string]any{"error": "missing params for method document/didSave"})
+		}
 		var p UnstableDidSaveDocumentNotification
 		if err := json.Unmarshal(params, &p); err != nil {
 			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
 		}
 		if err := p.Validate(); err != nil {
-			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
+			return nil, invalidParamsFromValidation(err)
 		}
 		exp, ok := a.agent.(interface {
 			UnstableDidSaveDocument(context.Context, UnstableDidSaveDocumentNotification) error
@@ -113,17 +131,22 @@ func (a *AgentSideConnection) handle(ctx context.Context, method string, params
 		}
 		return nil, nil
 	case AgentMethodInitialize:
+		if len(params) == 0 || string(params) == "null" {
+			return nil, NewInvalidParams(map[string]any{"error": "missing params for method initialize"})
+		}
 		var p InitializeRequest
 		if err := json.Unmarshal(params, &p); err != nil {
 			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
 		}
 		if err := p.Validate(); err != nil {
-			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
+			return nil, invalidParamsFromValidation(err)
 		}
+		a.peerExtensions.record(p.Meta)
 		resp, err := a.agent.Initialize(ctx, p)
 		if err != nil {
 			return nil, toReqErr(err)
 		}
+		a.capabilities.record(p.ClientCapabilities, resp.AgentCapabilities)
 		return resp, nil
 	case AgentMethodLogout:
 		var p LogoutRequest
@@ -131,7 +154,7 @@ func (a *AgentSideConnection) handle(ctx context.Context, method string, params
 			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
 		}
 		if err := p.Validate(); err != nil {
-			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
+			return nil, invalidParamsFromValidation(err)
 		}
 		resp, err := a.agent.Logout(ctx, p)
 		if err != nil {
@@ -139,12 +162,15 @@ func (a *AgentSideConnection) handle(ctx context.Context, method string, params
 		}
 		return resp, nil
 	case AgentMethodNesAccept:
+		if len(params) == 0 || string(params) == "null" {
+			return nil, NewInvalidParams(map[string]any{"error": "missing params for method nes/accept"})
+		}
 		var p UnstableAcceptNesNotification
 		if err := json.Unmarshal(params, &p); err != nil {
 			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
 		}
 		if err := p.Validate(); err != nil {
-			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
+			return nil, invalidParamsFromValidation(err)
 		}
 		exp, ok := a.agent.(interface {
 			UnstableAcceptNes(context.Context, UnstableAcceptNesNotification) error
@@ -157,12 +183,15 @@ func (a *AgentSideConnection) handle(ctx context.Context, method string, params
 		}
 		return nil, nil
 	case AgentMethodNesClose:
+		if len(params) == 0 || string(params) == "null" {
+			return nil, NewInvalidParams(map[string]any{"error": "missing params for method nes/close"})
+		}
 		var p UnstableCloseNesRequest
 		if err := json.Unmarshal(params, &p); err != nil {
 			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
 		}
 		if err := p.Validate(); err != nil {
-			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
+			return nil, invalidParamsFromValidation(err)
 		}
 		exp, ok := a.agent.(interface {
 			UnstableCloseNes(context.Context, UnstableCloseNesRequest) (UnstableCloseNesResponse, error)
@@ -176,12 +205,15 @@ func (a *AgentSideConnection) handle(ctx context.Context, method string, params
 		}
 		return resp, nil
 	case AgentMethodNesReject:
+		if len(params) == 0 || string(params) == "null" {
+			return nil, NewInvalidParams(map[string]any{"error": "missing params for method nes/reject"})
+		}
 		var p UnstableRejectNesNotification
 		if err := json.Unmarshal(params, &p); err != nil {
 			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
 		}
 		if err := p.Validate(); err != nil {
-			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
+			return nil, invalidParamsFromValidation(err)
 		}
 		exp, ok := a.agent.(interface {
 			UnstableRejectNes(context.Context, UnstableRejectNesNotification) error
@@ -199,7 +231,7 @@ func (a *AgentSideConnection) handle(ctx context.Context, method string, params
 			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
 		}
 		if err := p.Validate(); err != nil {
-			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
+			return nil, invalidParamsFromValidation(err)
 		}
 		exp, ok := a.agent.(interface {
 			UnstableStartNes(context.Context, UnstableStartNesRequest) (UnstableStartNesResponse, error)
@@ -213,12 +245,15 @@ func (a *AgentSideConnection) handle(ctx context.Context, method string, params
 		}
 		return resp, nil
 	case AgentMethodNesSuggest:
+		if len(params) == 0 || string(params) == "null" {
+			return nil, NewInvalidParams(map[string]any{"error": "missing params for method nes/suggest"})
+		}
 		var p UnstableSuggestNesRequest
 		if err := json.Unmarshal(params, &p); err != nil {
 			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
 		}
 		if err := p.Validate(); err != nil {
-			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
+			return nil, invalidParamsFromValidation(err)
 		}
 		exp, ok := a.agent.(interface {
 			UnstableSuggestNes(context.Context, UnstableSuggestNesRequest) (UnstableSuggestNesResponse, error)
@@ -232,12 +267,15 @@ func (a *AgentSideConnection) handle(ctx context.Context, method string, params
 		}
 		return resp, nil
 	case AgentMethodProvidersDisable:
+		if len(params) == 0 || string(params) == "null" {
+			return nil, NewInvalidParams(map[string]any{"error": "missing params for method providers/disable"})
+		}
 		var p UnstableDisableProviderRequest
 		if err := json.Unmarshal(params, &p); err != nil {
 			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
 		}
 		if err := p.Validate(); err != nil {
-			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
+			return nil, invalidParamsFromValidation(err)
 		}
 		exp, ok := a.agent.(interface {
 			UnstableDisableProvider(context.Context, UnstableDisableProviderRequest) (UnstableDisableProviderResponse, error)
@@ -256,7 +294,7 @@ func (a *AgentSideConnection) handle(ctx context.Context, method string, params
 			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
 		}
 		if err := p.Validate(); err != nil {
-			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
+			return nil, invalidParamsFromValidation(err)
 		}
 		exp, ok := a.agent.(interface {
 			UnstableListProviders(context.Context, UnstableListProvidersRequest) (UnstableListProvidersResponse, error)
@@ -270,12 +308,15 @@ func (a *AgentSideConnection) handle(ctx context.Context, method string, params
 		}
 		return resp, nil
 	case AgentMethodProvidersSet:
+		if len(params) == 0 || string(params) == "null" {
+			return nil, NewInvalidParams(map[string]any{"error": "missing params for method providers/set"})
+		}
 		var p UnstableSetProviderRequest
 		if err := json.Unmarshal(params, &p); err != nil {
 			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
 		}
 		if err := p.Validate(); err != nil {
-			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
+			return nil, invalidParamsFromValidation(err)
 		}
 		exp, ok := a.agent.(interface {
 			UnstableSetProvider(context.Context, UnstableSetProviderRequest) (UnstableSetProviderResponse, error)
@@ -289,12 +330,15 @@ func (a *AgentSideConnection) handle(ctx context.Context, method string, params
 		}
 		return resp, nil
 	case AgentMethodSessionCancel:
+		if len(params) == 0 || string(params) == "null" {
+			return nil, NewInvalidParams(map[string]any{"error": "missing params for method session/cancel"})
+		}
 		var p CancelNotification
 		if err := json.Unmarshal(params, &p); err != nil {
 			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
 		}
 		if err := p.Validate(); err != nil {
-			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
+			return nil, invalidParamsFromValidation(err)
 		}
 		a.mu.Lock()
 		if cn, ok := a.sessionCancels[string(p.SessionId)]; ok {
@@ -307,12 +351,15 @@ func (a *AgentSideConnection) handle(ctx context.Context, method string, params
 		}
 		return nil, nil
 	case AgentMethodSessionClose:
+		if len(params) == 0 || string(params) == "null" {
+			return nil, NewInvalidParams(map[string]any{"error": "missing params for method session/close"})
+		}
 		var p CloseSessionRequest
 		if err := json.Unmarshal(params, &p); err != nil {
 			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
 		}
 		if err := p.Validate(); err != nil {
-			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
+			return nil, invalidParamsFromValidation(err)
 		}
 		resp, err := a.agent.CloseSession(ctx, p)
 		if err != nil {
@@ -320,12 +367,15 @@ func (a *AgentSideConnection) handle(ctx context.Context, method string, params
 		}
 		return resp, nil
 	case AgentMethodSessionDelete:
+		if len(params) == 0 || string(params) == "null" {
+			return nil, NewInvalidParams(map[string]any{"error": "missing params for method session/delete"})
+		}
 		var p UnstableDeleteSessionRequest
 		if err := json.Unmarshal(params, &p); err != nil {
 			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
 		}
 		if err := p.Validate(); err != nil {
-			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
+			return nil, invalidParamsFromValidation(err)
 		}
 		exp, ok := a.agent.(interface {
 			UnstableDeleteSession(context.Context, UnstableDeleteSessionRequest) (UnstableDeleteSessionResponse, error)
@@ -339,12 +389,15 @@ func (a *AgentSideConnection) handle(ctx context.Context, method string, params
 		}
 		return resp, nil
 	case AgentMethodSessionFork:
+		if len(params) == 0 || string(params) == "null" {
+			return nil, NewInvalidParams(map[string]any{"error": "missing params for method session/fork"})
+		}
 		var p UnstableForkSessionRequest
 		if err := json.Unmarshal(params, &p); err != nil {
 			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
 		}
 		if err := p.Validate(); err != nil {
-			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
+			return nil, invalidParamsFromValidation(err)
 		}
 		exp, ok := a.agent.(interface {
 			UnstableForkSession(context.Context, UnstableForkSessionRequest) (UnstableForkSessionResponse, error)
@@ -363,7 +416,7 @@ func (a *AgentSideConnection) handle(ctx context.Context, method string, params
 			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
 		}
 		if err := p.Validate(); err != nil {
-			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
+			return nil, invalidParamsFromValidation(err)
 		}
 		resp, err := a.agent.ListSessions(ctx, p)
 		if err != nil {
@@ -371,12 +424,15 @@ func (a *AgentSideConnection) handle(ctx context.Context, method string, params
 		}
 		return resp, nil
 	case AgentMethodSessionLoad:
+		if len(params) == 0 || string(params) == "null" {
+			return nil, NewInvalidParams(map[string]any{"error": "missing params for method session/load"})
+		}
 		var p LoadSessionRequest
 		if err := json.Unmarshal(params, &p); err != nil {
 			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
 		}
 		if err := p.Validate(); err != nil {
-			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
+			return nil, invalidParamsFromValidation(err)
 		}
 		loader, ok := a.agent.(AgentLoader)
 		if !ok {
@@ -388,12 +444,15 @@ func (a *AgentSideConnection) handle(ctx context.Context, method string, params
 		}
 		return resp, nil
 	case AgentMethodSessionNew:
+		if len(params) == 0 || string(params) == "null" {
+			return nil, NewInvalidParams(map[string]any{"error": "missing params for method session/new"})
+		}
 		var p NewSessionRequest
 		if err := json.Unmarshal(params, &p); err != nil {
 			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
 		}
 		if err := p.Validate(); err != nil {
-			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
+			return nil, invalidParamsFromValidation(err)
 		}
 		resp, err := a.agent.NewSession(ctx, p)
 		if err != nil {
@@ -401,12 +460,15 @@ func (a *AgentSideConnection) handle(ctx context.Context, method string, params
 		}
 		return resp, nil
 	case AgentMethodSessionPrompt:
+		if len(params) == 0 || string(params) == "null" {
+			return nil, NewInvalidParams(map[string]any{"error": "missing params for method session/prompt"})
+		}
 		var p PromptRequest
 		if err := json.Unmarshal(params, &p); err != nil {
 			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
 		}
 		if err := p.Validate(); err != nil {
-			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
+			return nil, invalidParamsFromValidation(err)
 		}
 		var reqCtx context.Context
 		var cancel context.CancelFunc
@@ -426,12 +488,15 @@ func (a *AgentSideConnection) handle(ctx context.Context, method string, params
 		}
 		return resp, nil
 	case AgentMethodSessionResume:
+		if len(params) == 0 || string(params) == "null" {
+			return nil, NewInvalidParams(map[string]any{"error": "missing params for method session/resume"})
+		}
 		var p ResumeSessionRequest
 		if err := json.Unmarshal(params, &p); err != nil {
 			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
 		}
 		if err := p.Validate(); err != nil {
-			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
+			return nil, invalidParamsFromValidation(err)
 		}
 		resp, err := a.agent.ResumeSession(ctx, p)
 		if err != nil {
@@ -439,12 +504,15 @@ func (a *AgentSideConnection) handle(ctx context.Context, method string, params
 		}
 		return resp, nil
 	case AgentMethodSessionSetConfigOption:
+		if len(params) == 0 || string(params) == "null" {
+			return nil, NewInvalidParams(map[string]any{"error": "missing params for method session/set_config_option"})
+		}
 		var p SetSessionConfigOptionRequest
 		if err := json.Unmarshal(params, &p); err != nil {
 			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
 		}
 		if err := p.Validate(); err != nil {
-			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
+			return nil, invalidParamsFromValidation(err)
 		}
 		resp, err := a.agent.SetSessionConfigOption(ctx, p)
 		if err != nil {
@@ -452,12 +520,15 @@ func (a *AgentSideConnection) handle(ctx context.Context, method string, params
 		}
 		return resp, nil
 	case AgentMethodSessionSetMode:
+		if len(params) == 0 || string(params) == "null" {
+			return nil, NewInvalidParams(map[string]any{"error": "missing params for method session/set_mode"})
+		}
 		var p SetSessionModeRequest
 		if err := json.Unmarshal(params, &p); err != nil {
 			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
 		}
 		if err := p.Validate(); err != nil {
-			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
+			return nil, invalidParamsFromValidation(err)
 		}
 		resp, err := a.agent.SetSessionMode(ctx, p)
 		if err != nil {
@@ -0,0 +1,100 @@
+package acp
+
+import (
+	"encoding/json"
+	"log/slog"
+	"sync"
+)
+
+// handleBatch processes a JSON-RPC 2.0 batch. Requests in the batch are dispatched
+// concurrently and their responses are written back as a single array once all of
+// them complete; notifications produce no response. It returns a non-nil error if
+// a notification could not be queued, in which case the caller must shut down.
+func (c *Connection) handleBatch(raw []byte) error {
+	var elems []json.RawMessage
+	if err := json.Unmarshal(raw, &elems); err != nil {
+		c.reportProtocolError(slog.LevelError, raw, err, "failed to parse incoming batch", "err", err, "raw", string(raw))
+		return nil
+	}
+	if len(elems) == 0 {
+		// Per JSON-RPC 2.0, an empty batch yields a single (non-array) error response.
+		_ = c.sendMessage(anyMessage{ID: nullJSONRPCID(), Error: NewInvalidRequest(map[string]any{"error": "empty batch"})})
+		return nil
+	}
+
+	responses := make([]*anyMessage, len(elems))
+	var wg sync.WaitGroup
+	for i, elem := range elems {
+		var msg anyMessage
+		if err := c.codec.Unmarshal(elem, &msg); err != nil || (msg.ID == nil && msg.Method == "") {
+			responses[i] = &anyMessage{ID: batchElementID(elem), Error: NewInvalidRequest(map[string]any{"error": "invalid batch element"})}
+			continue
+		}
+
+		switch {
+		case msg.ID == nil && msg.Method == "$/cancel_request":
+			c.observeNotification(msg.Method, true)
+			c.handleCancelRequest(&msg)
+		case msg.Method == "":
+			c.handleResponse(&msg, elem)
+		case msg.ID != nil:
+			m := msg
+			reqCtx, done := c.trackInboundRequest(&m)
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				defer done()
+				responses[i] = c.dispatchInbound(reqCtx, c.rpcLogger(m.ID, m.Method), &m)
+			}(i)
+		default:
+			m := msg
+			if err := c.enqueueNotification(&m); err != nil {
+				return err
+			}
+		}
+	}
+
+	go func() {
+		wg.Wait()
+		out := make([]anyMessage, 0, len(responses))
+		for _, res := range responses {
+			if res != nil {
+				res.JSONRPC = "2.0"
+				out = append(out, *res)
+			}
+		}
+		if len(out) == 0 {
+			return
+		}
+		b, err := c.codec.Marshal(out)
+		if err != nil {
+			c.loggerOrDefault().Error("failed to encode batch response", "err", err)
+			return
+		}
+		if err := c.writeFrame(b); err != nil {
+			c.closeOnResponseWriteError(c.loggerOrDefault(), err)
+		}
+	}()
+	return nil
+}
+
+// batchElementID recovers the id of a batch element that is not a valid message,
+// so that its error response can be matched to it. It returns a null id if the
+// element has no usable id.
+func batchElementID(elem json.RawMessage) *json.RawMessage {
+	var partial struct {
+		ID json.RawMessage `json:"id"`
+	}
+	if err := json.Unmarshal(elem, &partial); err != nil || partial.ID == nil {
+		return nullJSONRPCID()
+	}
+	if _, err := canonicalJSONRPCIDKey(partial.ID); err != nil {
+		return nullJSONRPCID()
+	}
+	return &partial.ID
+}
+
+func nullJSONRPCID() *json.RawMessage {
+	id := json.RawMessage("null")
+	return &id
+}
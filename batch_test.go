@@ -0,0 +1,160 @@
+package acp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"sort"
+	"testing"
+	"time"
+)
+
+// batchResponse decodes the id as raw JSON so that null ids remain observable.
+type batchResponse struct {
+	ID     json.RawMessage `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *RequestError   `json:"error"`
+}
+
+func newBatchTestConnection(t *testing.T, handler MethodHandler) (io.Writer, <-chan []byte) {
+	t.Helper()
+
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	t.Cleanup(func() {
+		_ = inW.Close()
+		_ = outW.Close()
+		_ = inR.Close()
+		_ = outR.Close()
+	})
+
+	_ = NewConnection(handler, outW, inR)
+
+	lines := make(chan []byte, 10)
+	go func() {
+		scanner := bufio.NewScanner(outR)
+		for scanner.Scan() {
+			lines <- append([]byte(nil), scanner.Bytes()...)
+		}
+		close(lines)
+	}()
+	return inW, lines
+}
+
+func TestConnectionBatch_RespondsWithArray(t *testing.T) {
+	notified := make(chan string, 1)
+	w, lines := newBatchTestConnection(t, func(ctx context.Context, method string, params json.RawMessage) (any, *RequestError) {
+		if method == "notify" {
+			notified <- method
+			return nil, nil
+		}
+		return map[string]string{"method": method}, nil
+	})
+
+	batch := `[{"jsonrpc":"2.0","id":1,"method":"a"},{"jsonrpc":"2.0","method":"notify"},{"jsonrpc":"2.0","id":2,"method":"b"},5]` + "\n"
+	if _, err := w.Write([]byte(batch)); err != nil {
+		t.Fatalf("write batch: %v", err)
+	}
+
+	var raw []byte
+	select {
+	case raw = <-lines:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for batch response")
+	}
+
+	var responses []batchResponse
+	if err := json.Unmarshal(raw, &responses); err != nil {
+		t.Fatalf("expected array response, got %s: %v", raw, err)
+	}
+	if len(responses) != 3 {
+		t.Fatalf("expected 3 responses, got %d: %s", len(responses), raw)
+	}
+
+	var ids []string
+	for _, res := range responses {
+		if res.ID == nil {
+			t.Fatalf("response missing id: %s", raw)
+		}
+		ids = append(ids, string(res.ID))
+		if string(res.ID) == "null" {
+			if res.Error == nil || res.Error.Code != -32600 {
+				t.Fatalf("expected -32600 for invalid element, got %s", raw)
+			}
+		} else if res.Error != nil {
+			t.Fatalf("unexpected error response: %s", raw)
+		}
+	}
+	sort.Strings(ids)
+	if ids[0] != "1" || ids[1] != "2" || ids[2] != "null" {
+		t.Fatalf("unexpected response ids: %v", ids)
+	}
+
+	select {
+	case <-notified:
+	case <-time.After(2 * time.Second):
+		t.Fatal("batch notification was not dispatched")
+	}
+}
+
+func TestConnectionBatch_EmptyBatchReturnsInvalidRequest(t *testing.T) {
+	w, lines := newBatchTestConnection(t, func(ctx context.Context, method string, params json.RawMessage) (any, *RequestError) {
+		return nil, nil
+	})
+
+	if _, err := w.Write([]byte("[]\n")); err != nil {
+		t.Fatalf("write batch: %v", err)
+	}
+
+	var raw []byte
+	select {
+	case raw = <-lines:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for response")
+	}
+
+	var res batchResponse
+	if err := json.Unmarshal(raw, &res); err != nil {
+		t.Fatalf("expected single error object, got %s: %v", raw, err)
+	}
+	if string(res.ID) != "null" {
+		t.Fatalf("expected null id, got %s", raw)
+	}
+	if res.Error == nil || res.Error.Code != -32600 {
+		t.Fatalf("expected -32600, got %s", raw)
+	}
+}
+
+func TestConnectionBatch_InvalidElementKeepsItsID(t *testing.T) {
+	w, lines := newBatchTestConnection(t, func(ctx context.Context, method string, params json.RawMessage) (any, *RequestError) {
+		return nil, nil
+	})
+
+	batch := `[{"jsonrpc":"2.0","id":3,"method":7},{"jsonrpc":"2.0","id":"x","method":{}},{"jsonrpc":"2.0","id":{},"method":7}]` + "\n"
+	if _, err := w.Write([]byte(batch)); err != nil {
+		t.Fatalf("write batch: %v", err)
+	}
+
+	var raw []byte
+	select {
+	case raw = <-lines:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for batch response")
+	}
+
+	var responses []batchResponse
+	if err := json.Unmarshal(raw, &responses); err != nil {
+		t.Fatalf("expected array response, got %s: %v", raw, err)
+	}
+	var ids []string
+	for _, res := range responses {
+		if res.Error == nil || res.Error.Code != -32600 {
+			t.Fatalf("expected -32600 for every element, got %s", raw)
+		}
+		ids = append(ids, string(res.ID))
+	}
+	if want := []string{"3", `"x"`, "null"}; len(ids) != len(want) || ids[0] != want[0] || ids[1] != want[1] || ids[2] != want[2] {
+		t.Fatalf("response ids = %v, want %v", ids, want)
+	}
+}
@@ -0,0 +1,135 @@
+package acp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+)
+
+// ErrCapabilityNotSupported indicates a call to a method that the peer did not
+// advertise support for during initialize; see WithCapabilityEnforcement.
+var ErrCapabilityNotSupported = errors.New("capability not advertised by peer")
+
+// negotiatedCapabilities records the capabilities exchanged during initialize.
+type negotiatedCapabilities struct {
+	mu          sync.Mutex
+	initialized bool
+	client      ClientCapabilities
+	agent       AgentCapabilities
+}
+
+func (n *negotiatedCapabilities) record(client ClientCapabilities, agent AgentCapabilities) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.initialized = true
+	n.client = client
+	n.agent = agent
+}
+
+func (n *negotiatedCapabilities) get() (client ClientCapabilities, agent AgentCapabilities, ok bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.client, n.agent, n.initialized
+}
+
+type capabilitiesContextKey struct{}
+
+func withNegotiatedCapabilities(ctx context.Context, n *negotiatedCapabilities) context.Context {
+	return context.WithValue(ctx, capabilitiesContextKey{}, n)
+}
+
+// ClientCapabilitiesFromContext returns the capabilities the client sent in its
+// initialize request, for use in the handlers of either side, e.g. to check
+// that the client supports fs/read_text_file before calling it. It reports
+// false outside a handler or before initialize has completed.
+func ClientCapabilitiesFromContext(ctx context.Context) (ClientCapabilities, bool) {
+	n, ok := ctx.Value(capabilitiesContextKey{}).(*negotiatedCapabilities)
+	if !ok {
+		return ClientCapabilities{}, false
+	}
+	client, _, ok := n.get()
+	return client, ok
+}
+
+// AgentCapabilitiesFromContext returns the capabilities the agent sent in its
+// initialize response. Like ClientCapabilitiesFromContext, it reports false
+// outside a handler or before initialize has completed.
+func AgentCapabilitiesFromContext(ctx context.Context) (AgentCapabilities, bool) {
+	n, ok := ctx.Value(capabilitiesContextKey{}).(*negotiatedCapabilities)
+	if !ok {
+		return AgentCapabilities{}, false
+	}
+	_, agent, ok := n.get()
+	return agent, ok
+}
+
+// WithCapabilityEnforcement makes calls to methods that the peer did not
+// advertise during initialize fail locally with an error wrapping
+// ErrCapabilityNotSupported, instead of being sent. It covers the capability
+// gated methods: fs/read_text_file, fs/write_text_file and terminal/* for agents
+// calling clients, and session/load for clients calling agents. Calls made
+// before initialize completes are not checked. It has no effect on a plain
+// Connection.
+func WithCapabilityEnforcement(enabled bool) ConnectionOption {
+	return func(c *Connection) {
+		c.capabilityEnforcement = enabled
+	}
+}
+
+// withOutboundCapabilityCheck installs the check WithCapabilityEnforcement uses.
+func withOutboundCapabilityCheck(check func(method string) error) ConnectionOption {
+	return func(c *Connection) {
+		c.outboundCapabilityCheck = check
+	}
+}
+
+// checkOutboundCapability applies the capability check to an outbound request,
+// if enforcement is enabled.
+func (c *Connection) checkOutboundCapability(method string) error {
+	if !c.capabilityEnforcement || c.outboundCapabilityCheck == nil {
+		return nil
+	}
+	return c.outboundCapabilityCheck(method)
+}
+
+// checkClientCapability reports whether the client advertised support for method.
+func (n *negotiatedCapabilities) checkClientCapability(method string) error {
+	client, _, ok := n.get()
+	if !ok {
+		return nil
+	}
+	var supported bool
+	switch {
+	case method == ClientMethodFsReadTextFile:
+		supported = client.Fs.ReadTextFile
+	case method == ClientMethodFsWriteTextFile:
+		supported = client.Fs.WriteTextFile
+	case strings.HasPrefix(method, "terminal/"):
+		supported = client.Terminal
+	default:
+		return nil
+	}
+	if supported {
+		return nil
+	}
+	return capabilityNotSupportedError("client", method)
+}
+
+// checkAgentCapability reports whether the agent advertised support for method.
+func (n *negotiatedCapabilities) checkAgentCapability(method string) error {
+	_, agent, ok := n.get()
+	if !ok || method != AgentMethodSessionLoad || agent.LoadSession {
+		return nil
+	}
+	return capabilityNotSupportedError("agent", method)
+}
+
+func capabilityNotSupportedError(side, method string) error {
+	return &RequestError{
+		Code:    CodeMethodNotFound,
+		Message: "Method not found",
+		Data:    map[string]any{"method": method, "error": side + " does not support " + method},
+		cause:   ErrCapabilityNotSupported,
+	}
+}
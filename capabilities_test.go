@@ -0,0 +1,134 @@
+package acp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestCapabilitiesFromContext(t *testing.T) {
+	type seen struct {
+		client ClientCapabilities
+		agent  AgentCapabilities
+		ok     bool
+	}
+	var duringInitialize, inAgent, inClient seen
+
+	agent := agentFuncs{
+		InitializeFunc: func(ctx context.Context, _ InitializeRequest) (InitializeResponse, error) {
+			_, duringInitialize.ok = ClientCapabilitiesFromContext(ctx)
+			return InitializeResponse{ProtocolVersion: ProtocolVersionNumber, AgentCapabilities: AgentCapabilities{LoadSession: true}}, nil
+		},
+		NewSessionFunc: func(ctx context.Context, _ NewSessionRequest) (NewSessionResponse, error) {
+			inAgent.client, inAgent.ok = ClientCapabilitiesFromContext(ctx)
+			inAgent.agent, _ = AgentCapabilitiesFromContext(ctx)
+			return NewSessionResponse{SessionId: "s1"}, nil
+		},
+	}
+	client := &clientFuncs{
+		ReadTextFileFunc: func(ctx context.Context, _ ReadTextFileRequest) (ReadTextFileResponse, error) {
+			inClient.client, inClient.ok = ClientCapabilitiesFromContext(ctx)
+			inClient.agent, _ = AgentCapabilitiesFromContext(ctx)
+			return ReadTextFileResponse{}, nil
+		},
+	}
+	clientConn, agentConn := newNotificationBarrierTestPair(t, client, agent)
+	ctx := context.Background()
+
+	if _, ok := ClientCapabilitiesFromContext(ctx); ok {
+		t.Fatal("ClientCapabilitiesFromContext reported capabilities outside a handler")
+	}
+	if _, err := clientConn.Initialize(ctx, InitializeRequest{
+		ProtocolVersion:    ProtocolVersionNumber,
+		ClientCapabilities: ClientCapabilities{Fs: FileSystemCapabilities{ReadTextFile: true}},
+	}); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	if duringInitialize.ok {
+		t.Fatal("capabilities were available before initialize completed")
+	}
+
+	if _, err := clientConn.NewSession(ctx, NewSessionRequest{Cwd: "/", McpServers: []McpServer{}}); err != nil {
+		t.Fatalf("NewSession: %v", err)
+	}
+	if _, err := agentConn.ReadTextFile(ctx, ReadTextFileRequest{SessionId: "s1", Path: "/f"}); err != nil {
+		t.Fatalf("ReadTextFile: %v", err)
+	}
+
+	for side, got := range map[string]seen{"agent": inAgent, "client": inClient} {
+		if !got.ok || !got.client.Fs.ReadTextFile || got.client.Fs.WriteTextFile || !got.agent.LoadSession {
+			t.Fatalf("%s handler saw %+v, want the negotiated capabilities", side, got)
+		}
+	}
+}
+
+func TestWithCapabilityEnforcement(t *testing.T) {
+	for _, enforce := range []bool{false, true} {
+		t.Run(fmt.Sprintf("enforce=%v", enforce), func(t *testing.T) {
+			var writes atomic.Int32
+			agent := agentFuncs{
+				InitializeFunc: func(context.Context, InitializeRequest) (InitializeResponse, error) {
+					return InitializeResponse{ProtocolVersion: ProtocolVersionNumber}, nil
+				},
+			}
+			client := &clientFuncs{
+				WriteTextFileFunc: func(context.Context, WriteTextFileRequest) (WriteTextFileResponse, error) {
+					writes.Add(1)
+					return WriteTextFileResponse{}, nil
+				},
+				ReadTextFileFunc: func(context.Context, ReadTextFileRequest) (ReadTextFileResponse, error) {
+					return ReadTextFileResponse{}, nil
+				},
+			}
+			agentConn, clientConn := NewInMemoryConnections(agent, client, WithCapabilityEnforcement(enforce))
+			t.Cleanup(func() { _ = clientConn.Close() })
+			ctx := context.Background()
+
+			// Before initialize nothing is known, so nothing is enforced.
+			if _, err := agentConn.WriteTextFile(ctx, WriteTextFileRequest{SessionId: "s", Path: "/f", Content: "x"}); err != nil {
+				t.Fatalf("WriteTextFile before initialize: %v", err)
+			}
+			if _, err := clientConn.Initialize(ctx, InitializeRequest{
+				ProtocolVersion:    ProtocolVersionNumber,
+				ClientCapabilities: ClientCapabilities{Fs: FileSystemCapabilities{ReadTextFile: true}},
+			}); err != nil {
+				t.Fatalf("Initialize: %v", err)
+			}
+
+			if _, err := agentConn.ReadTextFile(ctx, ReadTextFileRequest{SessionId: "s", Path: "/f"}); err != nil {
+				t.Fatalf("ReadTextFile: %v", err)
+			}
+			_, err := agentConn.WriteTextFile(ctx, WriteTextFileRequest{SessionId: "s", Path: "/f", Content: "x"})
+			if !enforce {
+				if err != nil || writes.Load() != 2 {
+					t.Fatalf("WriteTextFile without enforcement: err %v, %d writes", err, writes.Load())
+				}
+				return
+			}
+			if !errors.Is(err, ErrCapabilityNotSupported) || !strings.Contains(err.Error(), "client does not support fs/write_text_file") {
+				t.Fatalf("WriteTextFile: got %v, want a capability error", err)
+			}
+			if writes.Load() != 1 {
+				t.Fatalf("unsupported call reached the client")
+			}
+			_, err = clientConn.LoadSession(ctx, testLoadSessionRequest("s"))
+			if !errors.Is(err, ErrCapabilityNotSupported) || !strings.Contains(err.Error(), "agent does not support session/load") {
+				t.Fatalf("LoadSession: got %v, want a capability error", err)
+			}
+		})
+	}
+}
+
+func TestSideConnections_DoNotWriteIntoCallerOptions(t *testing.T) {
+	opts := make([]ConnectionOption, 0, 2)
+	a := NewAgentSideConnection(agentFuncs{}, io.Discard, strings.NewReader(""), opts...)
+	c := NewClientSideConnection(&clientFuncs{}, io.Discard, strings.NewReader(""), opts...)
+	_, _ = a.Close(), c.Close()
+	if opts[:cap(opts)][0] != nil {
+		t.Fatal("constructor appended into the caller's options slice")
+	}
+}
@@ -1,27 +1,53 @@
 package acp
 
 import (
+	"context"
 	"io"
 	"log/slog"
+	"slices"
+	"sync"
 )
 
 // ClientSideConnection provides the client's view of the connection and implements Agent calls.
 type ClientSideConnection struct {
 	conn   *Connection
 	client Client
+
+	streamsMu sync.Mutex
+	streams   map[SessionId]*PromptStream
+
+	peerExtensions peerExtensions
+	sessionModes   sessionModes
+	capabilities   negotiatedCapabilities
+	progress       progressHandlers
 }
 
 // NewClientSideConnection creates a new client-side connection bound to the
 // provided Client implementation.
-func NewClientSideConnection(client Client, peerInput io.Writer, peerOutput io.Reader) *ClientSideConnection {
+func NewClientSideConnection(client Client, peerInput io.Writer, peerOutput io.Reader, opts ...ConnectionOption) *ClientSideConnection {
 	csc := &ClientSideConnection{}
 	csc.client = client
-	csc.conn = NewConnection(csc.handleWithExtensions, peerInput, peerOutput)
+	csc.conn = NewConnection(csc.handleWithExtensions, peerInput, peerOutput, append(slices.Clip(opts), withOutboundCapabilityCheck(csc.capabilities.checkAgentCapability))...)
 	return csc
 }
 
 // Done exposes a channel that closes when the peer disconnects.
 func (c *ClientSideConnection) Done() <-chan struct{} { return c.conn.Done() }
 
+// Err reports why the connection shut down. See Connection.Err.
+func (c *ClientSideConnection) Err() error { return c.conn.Err() }
+
+// Wait blocks until the connection shuts down or ctx is done.
+// See Connection.Wait.
+func (c *ClientSideConnection) Wait(ctx context.Context) error { return c.conn.Wait(ctx) }
+
+// Close tears down the connection.
+// See Connection.Close.
+func (c *ClientSideConnection) Close() error { return c.conn.Close() }
+
+// Shutdown waits for running handlers to finish before closing the connection.
+// See Connection.Shutdown.
+func (c *ClientSideConnection) Shutdown(ctx context.Context) error { return c.conn.Shutdown(ctx) }
+
 // SetLogger directs connection diagnostics to the provided logger.
 func (c *ClientSideConnection) SetLogger(l *slog.Logger) { c.conn.SetLogger(l) }
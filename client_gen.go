@@ -10,12 +10,15 @@ import (
 func (c *ClientSideConnection) handle(ctx context.Context, method string, params json.RawMessage) (any, *RequestError) {
 	switch method {
 	case ClientMethodElicitationComplete:
+		if len(params) == 0 || string(params) == "null" {
+			return nil, NewInvalidParams(map[string]any{"error": "missing params for method elicitation/complete"})
+		}
 		var p UnstableCompleteElicitationNotification
 		if err := json.Unmarshal(params, &p); err != nil {
 			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
 		}
 		if err := p.Validate(); err != nil {
-			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
+			return nil, invalidParamsFromValidation(err)
 		}
 		exp, ok := c.client.(interface {
 			UnstableCompleteElicitation(context.Context, UnstableCompleteElicitationNotification) error
@@ -28,12 +31,15 @@ func (c *ClientSideConnection) handle(ctx context.Context, method string, params
 		}
 		return nil, nil
 	case ClientMethodElicitationCreate:
+		if len(params) == 0 || string(params) == "null" {
+			return nil, NewInvalidParams(map[string]any{"error": "missing params for method elicitation/create"})
+		}
 		var p UnstableCreateElicitationRequest
 		if err := json.Unmarshal(params, &p); err != nil {
 			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
 		}
 		if err := p.Validate(); err != nil {
-			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
+			return nil, invalidParamsFromValidation(err)
 		}
 		exp, ok := c.client.(interface {
 			UnstableCreateElicitation(context.Context, UnstableCreateElicitationRequest) (UnstableCreateElicitationResponse, error)
@@ -47,12 +53,15 @@ func (c *ClientSideConnection) handle(ctx context.Context, method string, params
 		}
 		return resp, nil
 	case ClientMethodFsReadTextFile:
+		if len(params) == 0 || string(params) == "null" {
+			return nil, NewInvalidParams(map[string]any{"error": "missing params for method fs/read_text_file"})
+		}
 		var p ReadTextFileRequest
 		if err := json.Unmarshal(params, &p); err != nil {
 			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
 		}
 		if err := p.Validate(); err != nil {
-			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
+			return nil, invalidParamsFromValidation(err)
 		}
 		resp, err := c.client.ReadTextFile(ctx, p)
 		if err != nil {
@@ -60,12 +69,15 @@ func (c *ClientSideConnection) handle(ctx context.Context, method string, params
 		}
 		return resp, nil
 	case ClientMethodFsWriteTextFile:
+		if len(params) == 0 || string(params) == "null" {
+			return nil, NewInvalidParams(map[string]any{"error": "missing params for method fs/write_text_file"})
+		}
 		var p WriteTextFileRequest
 		if err := json.Unmarshal(params, &p); err != nil {
 			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
 		}
 		if err := p.Validate(); err != nil {
-			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
+			return nil, invalidParamsFromValidation(err)
 		}
 		resp, err := c.client.WriteTextFile(ctx, p)
 		if err != nil {
@@ -73,12 +85,15 @@ func (c *ClientSideConnection) handle(ctx context.Context, method string, params
 		}
 		return resp, nil
 	case ClientMethodMcpConnect:
+		if len(params) == 0 || string(params) == "null" {
+			return nil, NewInvalidParams(map[string]any{"error": "missing params for method mcp/connect"})
+		}
 		var p UnstableConnectMcpRequest
 		if err := json.Unmarshal(params, &p); err != nil {
 			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
 		}
 		if err := p.Validate(); err != nil {
-			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
+			return nil, invalidParamsFromValidation(err)
 		}
 		exp, ok := c.client.(interface {
 			UnstableConnectMcp(context.Context, UnstableConnectMcpRequest) (UnstableConnectMcpResponse, error)
@@ -92,12 +107,15 @@ func (c *ClientSideConnection) handle(ctx context.Context, method string, params
 		}
 		return resp, nil
 	case ClientMethodMcpDisconnect:
+		if len(params) == 0 || string(params) == "null" {
+			return nil, NewInvalidParams(map[string]any{"error": "missing params for method mcp/disconnect"})
+		}
 		var p UnstableDisconnectMcpRequest
 		if err := json.Unmarshal(params, &p); err != nil {
 			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
 		}
 		if err := p.Validate(); err != nil {
-			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
+			return nil, invalidParamsFromValidation(err)
 		}
 		exp, ok := c.client.(interface {
 			UnstableDisconnectMcp(context.Context, UnstableDisconnectMcpRequest) (UnstableDisconnectMcpResponse, error)
@@ -111,12 +129,15 @@ func (c *ClientSideConnection) handle(ctx context.Context, method string, params
 		}
 		return resp, nil
 	case ClientMethodSessionRequestPermission:
+		if len(params) == 0 || string(params) == "null" {
+			return nil, NewInvalidParams(map[string]any{"error": "missing params for method session/request_permission"})
+		}
 		var p RequestPermissionRequest
 		if err := json.Unmarshal(params, &p); err != nil {
 			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
 		}
 		if err := p.Validate(); err != nil {
-			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
+			return nil, invalidParamsFromValidation(err)
 		}
 		resp, err := c.client.RequestPermission(ctx, p)
 		if err != nil {
@@ -124,24 +145,30 @@ func (c *ClientSideConnection) handle(ctx context.Context, method string, params
 		}
 		return resp, nil
 	case ClientMethodSessionUpdate:
+		if len(params) == 0 || string(params) == "null" {
+			return nil, NewInvalidParams(map[string]any{"error": "missing params for method session/update"})
+		}
 		var p SessionNotification
 		if err := json.Unmarshal(params, &p); err != nil {
 			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
 		}
 		if err := p.Validate(); err != nil {
-			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
+			return nil, invalidParamsFromValidation(err)
 		}
 		if err := c.client.SessionUpdate(ctx, p); err != nil {
 			return nil, toReqErr(err)
 		}
 		return nil, nil
 	case ClientMethodTerminalCreate:
+		if len(params) == 0 || string(params) == "null" {
+			return nil, NewInvalidParams(map[string]any{"error": "missing params for method terminal/create"})
+		}
 		var p CreateTerminalRequest
 		if err := json.Unmarshal(params, &p); err != nil {
 			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
 		}
 		if err := p.Validate(); err != nil {
-			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
+			return nil, invalidParamsFromValidation(err)
 		}
 		resp, err := c.client.CreateTerminal(ctx, p)
 		if err != nil {
@@ -149,12 +176,15 @@ func (c *ClientSideConnection) handle(ctx context.Context, method string, params
 		}
 		return resp, nil
 	case ClientMethodTerminalKill:
+		if len(params) == 0 || string(params) == "null" {
+			return nil, NewInvalidParams(map[string]any{"error": "missing params for method terminal/kill"})
+		}
 		var p KillTerminalRequest
 		if err := json.Unmarshal(params, &p); err != nil {
 			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
 		}
 		if err := p.Validate(); err != nil {
-			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
+			return nil, invalidParamsFromValidation(err)
 		}
 		resp, err := c.client.KillTerminal(ctx, p)
 		if err != nil {
@@ -162,12 +192,15 @@ func (c *ClientSideConnection) handle(ctx context.Context, method string, params
 		}
 		return resp, nil
 	case ClientMethodTerminalOutput:
+		if len(params) == 0 || string(params) == "null" {
+			return nil, NewInvalidParams(map[string]any{"error": "missing params for method terminal/output"})
+		}
 		var p TerminalOutputRequest
 		if err := json.Unmarshal(params, &p); err != nil {
 			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
 		}
 		if err := p.Validate(); err != nil {
-			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
+			return nil, invalidParamsFromValidation(err)
 		}
 		resp, err := c.client.TerminalOutput(ctx, p)
 		if err != nil {
@@ -175,12 +208,15 @@ func (c *ClientSideConnection) handle(ctx context.Context, method string, params
 		}
 		return resp, nil
 	case ClientMethodTerminalRelease:
+		if len(params) == 0 || string(params) == "null" {
+			return nil, NewInvalidParams(map[string]any{"error": "missing params for method terminal/release"})
+		}
 		var p ReleaseTerminalRequest
 		if err := json.Unmarshal(params, &p); err != nil {
 			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
 		}
 		if err := p.Validate(); err != nil {
-			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
+			return nil, invalidParamsFromValidation(err)
 		}
 		resp, err := c.client.ReleaseTerminal(ctx, p)
 		if err != nil {
@@ -188,12 +224,15 @@ func (c *ClientSideConnection) handle(ctx context.Context, method string, params
 		}
 		return resp, nil
 	case ClientMethodTerminalWaitForExit:
+		if len(params) == 0 || string(params) == "null" {
+			return nil, NewInvalidParams(map[string]any{"error": "missing params for method terminal/wait_for_exit"})
+		}
 		var p WaitForTerminalExitRequest
 		if err := json.Unmarshal(params, &p); err != nil {
 			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
 		}
 		if err := p.Validate(); err != nil {
-			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
+			return nil, invalidParamsFromValidation(err)
 		}
 		resp, err := c.client.WaitForTerminalExit(ctx, p)
 		if err != nil {
@@ -225,6 +264,10 @@ func (c *ClientSideConnection) UnstableDidSaveDocument(ctx context.Context, para
 }
 func (c *ClientSideConnection) Initialize(ctx context.Context, params InitializeRequest) (InitializeResponse, error) {
 	resp, err := SendRequest[InitializeResponse](c.conn, ctx, AgentMethodInitialize, params)
+	if err == nil {
+		c.peerExtensions.record(resp.Meta)
+		c.capabilities.record(params.ClientCapabilities, resp.AgentCapabilities)
+	}
 	return resp, err
 }
 func (c *ClientSideConnection) Logout(ctx context.Context, params LogoutRequest) (LogoutResponse, error) {
@@ -274,6 +317,9 @@ func (c *ClientSideConnection) UnstableDeleteSession(ctx context.Context, params
 }
 func (c *ClientSideConnection) UnstableForkSession(ctx context.Context, params UnstableForkSessionRequest) (UnstableForkSessionResponse, error) {
 	resp, err := SendRequest[UnstableForkSessionResponse](c.conn, ctx, AgentMethodSessionFork, params)
+	if err == nil {
+		c.sessionModes.record(resp.SessionId, resp.Modes)
+	}
 	return resp, err
 }
 func (c *ClientSideConnection) ListSessions(ctx context.Context, params ListSessionsRequest) (ListSessionsResponse, error) {
@@ -282,10 +328,16 @@ func (c *ClientSideConnection) ListSessions(ctx context.Context, params ListSess
 }
 func (c *ClientSideConnection) LoadSession(ctx context.Context, params LoadSessionRequest) (LoadSessionResponse, error) {
 	resp, err := SendRequest[LoadSessionResponse](c.conn, ctx, AgentMethodSessionLoad, params)
+	if err == nil {
+		c.sessionModes.record(params.SessionId, resp.Modes)
+	}
 	return resp, err
 }
 func (c *ClientSideConnection) NewSession(ctx context.Context, params NewSessionRequest) (NewSessionResponse, error) {
 	resp, err := SendRequest[NewSessionResponse](c.conn, ctx, AgentMethodSessionNew, params)
+	if err == nil {
+		c.sessionModes.record(resp.SessionId, resp.Modes)
+	}
 	return resp, err
 }
 func (c *ClientSideConnection) Prompt(ctx context.Context, params PromptRequest) (PromptResponse, error) {
@@ -299,6 +351,9 @@ func (c *ClientSideConnection) Prompt(ctx context.Context, params PromptRequest)
 }
 func (c *ClientSideConnection) ResumeSession(ctx context.Context, params ResumeSessionRequest) (ResumeSessionResponse, error) {
 	resp, err := SendRequest[ResumeSessionResponse](c.conn, ctx, AgentMethodSessionResume, params)
+	if err == nil {
+		c.sessionModes.record(params.SessionId, resp.Modes)
+	}
 	return resp, err
 }
 func (c *ClientSideConnection) SetSessionConfigOption(ctx context.Context, params SetSessionConfigOptionRequest) (SetSessionConfigOptionResponse, error) {
@@ -307,5 +362,8 @@ func (c *ClientSideConnection) SetSessionConfigOption(ctx context.Context, param
 }
 func (c *ClientSideConnection) SetSessionMode(ctx context.Context, params SetSessionModeRequest) (SetSessionModeResponse, error) {
 	resp, err := SendRequest[SetSessionModeResponse](c.conn, ctx, AgentMethodSessionSetMode, params)
+	if err == nil {
+		c.sessionModes.setCurrent(params.SessionId, params.ModeId)
+	}
 	return resp, err
 }
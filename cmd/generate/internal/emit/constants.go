@@ -17,6 +17,10 @@ func WriteConstantsJen(outDir string, meta *load.Meta) error {
 	f.HeaderComment("Code generated by acp-go-generator; DO NOT EDIT.")
 	f.Comment("ProtocolVersionNumber is the ACP protocol version supported by this SDK.")
 	f.Const().Id("ProtocolVersionNumber").Op("=").Lit(meta.Version)
+	if meta.SchemaVersion != "" {
+		f.Comment("SchemaVersion is the ACP schema release these bindings were generated from.")
+		f.Const().Id("SchemaVersion").Op("=").Lit(meta.SchemaVersion)
+	}
 
 	// Agent methods
 	amKeys := make([]string, 0, len(meta.AgentMethods))
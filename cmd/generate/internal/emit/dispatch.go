@@ -33,7 +33,7 @@ func WriteDispatchJen(outDir string, schema *load.Schema, meta *load.Meta) error
 		}
 		caseBody := []Code{}
 		if mi.Notif != "" {
-			caseBody = append(caseBody, jUnmarshalValidate(mi.Notif)...)
+			caseBody = append(caseBody, jUnmarshalValidate(schema, mi.Method, mi.Notif)...)
 			// Special-case: session/cancel should also cancel any in-flight prompt ctx for the session.
 			if mi.Method == "session/cancel" {
 				caseBody = append(
@@ -56,7 +56,7 @@ func WriteDispatchJen(outDir string, schema *load.Schema, meta *load.Meta) error
 		} else if mi.Req != "" {
 			respName := strings.TrimSuffix(mi.Req, "Request") + "Response"
 			nullResp := ir.IsNullResponse(schema.Defs[respName])
-			caseBody = append(caseBody, jUnmarshalValidate(mi.Req)...)
+			caseBody = append(caseBody, jUnmarshalValidate(schema, mi.Method, mi.Req)...)
 			methodName := strings.TrimSuffix(mi.Req, "Request")
 			pre, recv := jAgentAssert(mi.Binding, methodName, mi.Req, respName, !nullResp)
 			if pre != nil {
@@ -86,6 +86,16 @@ func WriteDispatchJen(outDir string, schema *load.Schema, meta *load.Meta) error
 				)
 			} else if nullResp {
 				caseBody = append(caseBody, jCallRequestNoResp(recv, methodName)...)
+			} else if mi.Method == "initialize" {
+				// Remember which extension methods the client advertised, and the
+				// capabilities both sides settled on.
+				caseBody = append(caseBody,
+					Id("a").Dot("peerExtensions").Dot("record").Call(Id("p").Dot("Meta")),
+					List(Id("resp"), Id("err")).Op(":=").Id(recv).Dot(methodName).Call(Id("ctx"), Id("p")),
+					If(Id("err").Op("!=").Nil()).Block(jRetToReqErr()),
+					Id("a").Dot("capabilities").Dot("record").Call(Id("p").Dot("ClientCapabilities"), Id("resp").Dot("AgentCapabilities")),
+					Return(Id("resp"), Nil()),
+				)
 			} else {
 				caseBody = append(caseBody, jCallRequestWithResp(recv, methodName)...)
 			}
@@ -177,7 +187,7 @@ func WriteDispatchJen(outDir string, schema *load.Schema, meta *load.Meta) error
 		}
 		body := []Code{}
 		if mi.Notif != "" {
-			body = append(body, jUnmarshalValidate(mi.Notif)...)
+			body = append(body, jUnmarshalValidate(schema, mi.Method, mi.Notif)...)
 			callName := ir.DispatchMethodNameForNotification(k, mi.Notif)
 			pre, recv := jClientAssert(mi.Binding, callName, mi.Notif, "", false)
 			if pre != nil {
@@ -187,7 +197,7 @@ func WriteDispatchJen(outDir string, schema *load.Schema, meta *load.Meta) error
 		} else if mi.Req != "" {
 			respName := strings.TrimSuffix(mi.Req, "Request") + "Response"
 			nullResp := ir.IsNullResponse(schema.Defs[respName])
-			body = append(body, jUnmarshalValidate(mi.Req)...)
+			body = append(body, jUnmarshalValidate(schema, mi.Method, mi.Req)...)
 			methodName := strings.TrimSuffix(mi.Req, "Request")
 			pre, recv := jClientAssert(mi.Binding, methodName, mi.Req, respName, !nullResp)
 			if pre != nil {
@@ -256,6 +266,45 @@ func WriteDispatchJen(outDir string, schema *load.Schema, meta *load.Meta) error
 							),
 							Return(Id("resp"), Id("err")),
 						)
+				} else if mi.Method == "initialize" {
+					// Remember which extension methods the agent advertised, and the
+					// capabilities both sides settled on.
+					fClient.Func().Params(Id("c").Op("*").Id("ClientSideConnection")).Id(strings.TrimSuffix(mi.Req, "Request")).
+						Params(Id("ctx").Qual("context", "Context"), Id("params").Id(mi.Req)).Params(Id(respName), Error()).
+						Block(
+							List(Id("resp"), Id("err")).Op(":=").Id("SendRequest").Types(Id(respName)).Call(Id("c").Dot("conn"), Id("ctx"), Id(constName), Id("params")),
+							If(Id("err").Op("==").Nil()).Block(
+								Id("c").Dot("peerExtensions").Dot("record").Call(Id("resp").Dot("Meta")),
+								Id("c").Dot("capabilities").Dot("record").Call(Id("params").Dot("ClientCapabilities"), Id("resp").Dot("AgentCapabilities")),
+							),
+							Return(Id("resp"), Id("err")),
+						)
+				} else if mi.Method == "session/set_mode" {
+					// Keep the recorded current mode in step with successful switches.
+					fClient.Func().Params(Id("c").Op("*").Id("ClientSideConnection")).Id(strings.TrimSuffix(mi.Req, "Request")).
+						Params(Id("ctx").Qual("context", "Context"), Id("params").Id(mi.Req)).Params(Id(respName), Error()).
+						Block(
+							List(Id("resp"), Id("err")).Op(":=").Id("SendRequest").Types(Id(respName)).Call(Id("c").Dot("conn"), Id("ctx"), Id(constName), Id("params")),
+							If(Id("err").Op("==").Nil()).Block(
+								Id("c").Dot("sessionModes").Dot("setCurrent").Call(Id("params").Dot("SessionId"), Id("params").Dot("ModeId")),
+							),
+							Return(Id("resp"), Id("err")),
+						)
+				} else if respDef := expandAllOf(schema, schema.Defs[respName]); respDef != nil && respDef.Properties["modes"] != nil {
+					// Remember the session's modes so SetMode can validate mode ids.
+					sessionID := Id("params").Dot("SessionId")
+					if respDef.Properties["sessionId"] != nil {
+						sessionID = Id("resp").Dot("SessionId")
+					}
+					fClient.Func().Params(Id("c").Op("*").Id("ClientSideConnection")).Id(strings.TrimSuffix(mi.Req, "Request")).
+						Params(Id("ctx").Qual("context", "Context"), Id("params").Id(mi.Req)).Params(Id(respName), Error()).
+						Block(
+							List(Id("resp"), Id("err")).Op(":=").Id("SendRequest").Types(Id(respName)).Call(Id("c").Dot("conn"), Id("ctx"), Id(constName), Id("params")),
+							If(Id("err").Op("==").Nil()).Block(
+								Id("c").Dot("sessionModes").Dot("record").Call(sessionID, Id("resp").Dot("Modes")),
+							),
+							Return(Id("resp"), Id("err")),
+						)
 				} else {
 					fClient.Func().Params(Id("c").Op("*").Id("ClientSideConnection")).Id(strings.TrimSuffix(mi.Req, "Request")).
 						Params(Id("ctx").Qual("context", "Context"), Id("params").Id(mi.Req)).Params(Id(respName), Error()).
@@ -2,6 +2,7 @@ package emit
 
 import (
 	"github.com/coder/acp-go-sdk/cmd/generate/internal/ir"
+	"github.com/coder/acp-go-sdk/cmd/generate/internal/load"
 )
 
 // invInvalid: return invalid params with compact json-like message
@@ -12,15 +13,31 @@ func jInvInvalid() Code {
 // retToReqErr: wrap error to JSON-RPC request error
 func jRetToReqErr() Code { return Return(Nil(), Id("toReqErr").Call(Id("err"))) }
 
-// jUnmarshalValidate emits var p T; json.Unmarshal; p.Validate
-func jUnmarshalValidate(typeName string) []Code {
-	return []Code{
+// jUnmarshalValidate emits var p T; json.Unmarshal; p.Validate. Validate failures
+// carry the offending field in the error data. If the schema requires any
+// property of T, absent or null params are rejected up front with a "missing
+// params" error instead of being decoded into a zero value.
+func jUnmarshalValidate(schema *load.Schema, wire, typeName string) []Code {
+	var out []Code
+	if hasRequiredProps(schema, typeName) {
+		out = append(out, If(Len(Id("params")).Op("==").Lit(0).Op("||").String().Call(Id("params")).Op("==").Lit("null")).Block(
+			Return(Nil(), Id("NewInvalidParams").Call(Map(String()).Any().Values(Dict{Lit("error"): Lit("missing params for method " + wire)}))),
+		))
+	}
+	return append(out,
 		Var().Id("p").Id(typeName),
 		If(List(Id("err")).Op(":=").Qual("encoding/json", "Unmarshal").Call(Id("params"), Op("&").Id("p")), Id("err").Op("!=").Nil()).
 			Block(jInvInvalid()),
 		If(List(Id("err")).Op(":=").Id("p").Dot("Validate").Call(), Id("err").Op("!=").Nil()).
-			Block(jInvInvalid()),
-	}
+			Block(Return(Nil(), Id("invalidParamsFromValidation").Call(Id("err")))),
+	)
+}
+
+// hasRequiredProps reports whether the definition named typeName requires any
+// property.
+func hasRequiredProps(schema *load.Schema, typeName string) bool {
+	def := expandAllOf(schema, schema.Defs[typeName])
+	return def != nil && len(def.Required) > 0
 }
 
 // jAgentAssert returns prelude for interface assertions and the receiver name.
@@ -0,0 +1,45 @@
+package emit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/coder/acp-go-sdk/cmd/generate/internal/load"
+)
+
+func TestWriteFuzzJen(t *testing.T) {
+	schema := &load.Schema{Defs: map[string]*load.Definition{
+		"Block": {OneOf: []*load.Definition{
+			{Type: "object", Properties: map[string]*load.Definition{"type": {Const: "text"}, "text": {Type: "string"}}},
+			{Ref: "#/$defs/Image"},
+		}},
+		"Image":  {Type: "object", Properties: map[string]*load.Definition{"type": {Const: "image"}}},
+		"Status": {Enum: []any{"on", "off"}},
+	}}
+	dir := t.TempDir()
+	if err := WriteFuzzJen(dir, schema, &load.Meta{Version: 1}); err != nil {
+		t.Fatalf("WriteFuzzJen: %v", err)
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "fuzz_gen_test.go"))
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	out := string(b)
+	for _, want := range []string{
+		"func FuzzBlockUnmarshal(f *testing.F) {",
+		`"{\"type\":\"text\"}", "{\"type\":\"image\"}"`,
+		"f.Fuzz(fuzzUnionRoundTrip[Block])",
+		"func fuzzUnionRoundTrip[T any](t *testing.T, data []byte) {",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	for _, notWant := range []string{"FuzzImageUnmarshal", "FuzzStatusUnmarshal"} {
+		if strings.Contains(out, notWant) {
+			t.Errorf("non-union type got a fuzz target %s", notWant)
+		}
+	}
+}
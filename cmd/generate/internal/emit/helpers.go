@@ -3,11 +3,13 @@ package emit
 import (
 	"bytes"
 	"fmt"
+	"go/token"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
 
+	"github.com/coder/acp-go-sdk/cmd/generate/internal/ir"
 	"github.com/coder/acp-go-sdk/cmd/generate/internal/load"
 	"github.com/coder/acp-go-sdk/cmd/generate/internal/util"
 )
@@ -68,8 +70,8 @@ func WriteHelpersJen(outDir string, schema *load.Schema, _ *load.Meta) error {
 					continue
 				}
 				expanded := expandAllOf(schema, v)
-				for k, pd := range expanded.Properties {
-					if pd != nil && pd.Const != nil {
+				for _, k := range sortedPropertyKeys(expanded.Properties) {
+					if pd := expanded.Properties[k]; pd != nil && pd.Const != nil {
 						discKey = k
 						break
 					}
@@ -181,9 +183,52 @@ func WriteHelpersJen(outDir string, schema *load.Schema, _ *load.Meta) error {
 		}
 	}
 
+	for _, name := range keys {
+		emitRequestConstructor(f, name, schema.Defs[name])
+	}
+
 	var buf bytes.Buffer
 	if err := f.Render(&buf); err != nil {
 		return err
 	}
 	return os.WriteFile(filepath.Join(outDir, "helpers_gen.go"), buf.Bytes(), 0o644)
 }
+
+// emitRequestConstructor emits New<Name>(required...) <Name> for method request
+// types, taking the schema-required fields positionally in schema order.
+// Requests with inline-object required fields are skipped since their nested
+// type names are assigned by the types emitter.
+func emitRequestConstructor(f *File, name string, def *load.Definition) {
+	if def == nil || def.XMethod == "" || !strings.HasSuffix(name, "Request") {
+		return
+	}
+	// Only plain structs; unions get per-variant helpers above.
+	if len(def.AnyOf) > 0 || len(def.OneOf) > 0 || ir.PrimaryType(def) != "object" || len(def.Properties) == 0 {
+		return
+	}
+	params := []Code{}
+	assigns := Dict{}
+	for _, rk := range def.Required {
+		pd := def.Properties[rk]
+		if pd == nil {
+			continue
+		}
+		if pd.Ref == "" && len(pd.Properties) > 0 {
+			return
+		}
+		pname := rk
+		if token.IsKeyword(pname) {
+			pname += "_"
+		}
+		params = append(params, Id(pname).Add(jenTypeForOptional(pd)))
+		assigns[Id(util.ToExportedField(rk))] = Id(pname)
+	}
+	if len(params) == 0 {
+		return
+	}
+	f.Comment(fmt.Sprintf("New%s constructs a %s with its required fields set.", name, name))
+	f.Func().Id("New" + name).Params(params...).Id(name).Block(
+		Return(Id(name).Values(assigns)),
+	)
+	f.Line()
+}
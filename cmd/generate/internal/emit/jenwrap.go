@@ -4,10 +4,11 @@ import jen "github.com/dave/jennifer/jen"
 
 // Local aliases to avoid dot-importing jennifer while keeping concise calls.
 type (
-	Code  = jen.Code
-	Dict  = jen.Dict
-	Group = jen.Group
-	File  = jen.File
+	Code      = jen.Code
+	Dict      = jen.Dict
+	Group     = jen.Group
+	File      = jen.File
+	Statement = jen.Statement
 )
 
 var (
@@ -29,6 +30,9 @@ var (
 	Index         = jen.Index
 	Qual          = jen.Qual
 	Error         = jen.Error
+	Err           = jen.Err
+	Len           = jen.Len
+	Continue      = jen.Continue
 	Case          = jen.Case
 	Default       = jen.Default
 	Switch        = jen.Switch
@@ -38,4 +42,6 @@ var (
 	Op            = jen.Op
 	InterfaceFunc = jen.InterfaceFunc
 	Comment       = jen.Comment
+	Add           = jen.Add
+	Values        = jen.Values
 )
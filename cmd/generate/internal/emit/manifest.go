@@ -0,0 +1,82 @@
+package emit
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/coder/acp-go-sdk/cmd/generate/internal/ir"
+	"github.com/coder/acp-go-sdk/cmd/generate/internal/load"
+)
+
+// WriteManifestJen emits methods_manifest_gen.go with MethodRouting, which maps
+// every wire method to the side that handles it and the Go interface method it
+// dispatches to.
+func WriteManifestJen(outDir string, schema *load.Schema, meta *load.Meta) error {
+	groups := ir.BuildMethodGroups(schema, meta)
+
+	f := NewFile("acp")
+	f.HeaderComment("Code generated by acp-go-generator; DO NOT EDIT.")
+
+	f.Comment("MethodInfo describes how a wire method is routed to Go code.")
+	f.Type().Id("MethodInfo").Struct(
+		Comment(`Side is "agent" for methods the agent handles and "client" for methods the client handles.`),
+		Id("Side").String(),
+		Comment(`Binding is the Go interface declaring the handler method, e.g. "Agent" or "ClientExperimental".`),
+		Id("Binding").String(),
+		Comment("GoMethod is the handler method's name on Binding."),
+		Id("GoMethod").String(),
+		Comment("Params is the Go type name of the request or notification params."),
+		Id("Params").String(),
+		Comment(`Result is the Go type name of the response, or "" for notifications and methods with a null result.`),
+		Id("Result").String(),
+		Comment("Notification reports whether the method is a notification, which has no response."),
+		Id("Notification").Bool(),
+	)
+	f.Line()
+
+	entries := Dict{}
+	seen := map[string]string{}
+	for _, side := range []struct {
+		name    string
+		methods map[string]string
+	}{{"agent", meta.AgentMethods}, {"client", meta.ClientMethods}} {
+		for _, k := range ir.SortedKeys(side.methods) {
+			wire := side.methods[k]
+			mi := groups[side.name+"|"+wire]
+			if mi == nil {
+				continue
+			}
+			fields := Dict{
+				Id("Side"):     Lit(side.name),
+				Id("Binding"):  Lit(mi.Binding.InterfaceName()),
+				Id("GoMethod"): Lit(ir.GoMethodName(k, mi)),
+			}
+			if mi.Notif != "" {
+				fields[Id("Params")] = Lit(mi.Notif)
+				fields[Id("Notification")] = Lit(true)
+			} else {
+				fields[Id("Params")] = Lit(mi.Req)
+				if respName := strings.TrimSuffix(mi.Req, "Request") + "Response"; !ir.IsNullResponse(schema.Defs[respName]) {
+					fields[Id("Result")] = Lit(respName)
+				}
+			}
+			if other, ok := seen[wire]; ok {
+				panic(fmt.Sprintf("BUG: wire method %q is handled by both the %s and %s side", wire, other, side.name))
+			}
+			seen[wire] = side.name
+			entries[Lit(wire)] = Values(fields)
+		}
+	}
+	f.Comment("MethodRouting maps each wire method name to its routing information. It is")
+	f.Comment("useful for validation, documentation and generic proxies. Do not modify it.")
+	f.Var().Id("MethodRouting").Op("=").Map(String()).Id("MethodInfo").Values(entries)
+
+	var buf bytes.Buffer
+	if err := f.Render(&buf); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(outDir, "methods_manifest_gen.go"), buf.Bytes(), 0o644)
+}
@@ -120,6 +120,49 @@ func emitDocComment(f *File, desc string) {
 	}
 }
 
+// allowsExtraProperties reports whether def declares properties and also
+// permits undeclared ones (additionalProperties true or a schema). Such structs
+// get an Extra catch-all so unknown keys survive a round trip.
+func allowsExtraProperties(def *load.Definition) bool {
+	if def == nil || def.AdditionalProperties == nil || len(def.Properties) == 0 {
+		return false
+	}
+	if _, ok := def.Properties["Extra"]; ok {
+		return false
+	}
+	if v, isBool := def.AdditionalProperties.BoolSchema(); isBool {
+		return v
+	}
+	return true
+}
+
+// sortedPropertyKeys returns the keys of props in sorted order. Emitters must
+// never range over schema maps directly, or regenerated output would churn.
+func sortedPropertyKeys(props map[string]*load.Definition) []string {
+	keys := make([]string, 0, len(props))
+	for k := range props {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// docText returns the doc comment text for def: its description followed by a
+// "Deprecated:" paragraph when the schema marks it x-deprecated.
+func docText(def *load.Definition) string {
+	if def == nil {
+		return ""
+	}
+	if def.Deprecated == "" {
+		return def.Description
+	}
+	dep := "Deprecated: " + def.Deprecated
+	if def.Description == "" {
+		return dep
+	}
+	return def.Description + "\n\n" + dep
+}
+
 // appendDocComments appends doc comment Code elements to a slice for struct field comments.
 // Used when building struct field definitions with proper multi-line comment support.
 func appendDocComments(codeSlice []Code, desc string) []Code {
@@ -164,42 +207,31 @@ func WriteTypesJen(outDir string, schema *load.Schema, meta *load.Meta) error {
 			}
 		}
 
-		if def.Description != "" {
-			emitDocComment(f, def.Description)
+		if doc := docText(def); doc != "" {
+			emitDocComment(f, doc)
 		}
 
 		switch {
 		case len(def.Enum) > 0:
-			f.Type().Id(name).String()
-			defs := []Code{}
+			values := []string{}
 			for _, v := range def.Enum {
-				s := fmt.Sprint(v)
-				defs = append(defs, Id(util.ToEnumConst(name, s)).Id(name).Op("=").Lit(s))
-			}
-			if len(defs) > 0 {
-				f.Const().Defs(defs...)
+				values = append(values, fmt.Sprint(v))
 			}
-			f.Line()
+			emitStringEnum(f, name, values, false)
 		case isStringConstUnion(def):
-			f.Type().Id(name).String()
-			defs := []Code{}
+			values := []string{}
 			for _, v := range def.OneOf {
 				if v != nil && v.Const != nil {
-					s := fmt.Sprint(v.Const)
-					defs = append(defs, Id(util.ToEnumConst(name, s)).Id(name).Op("=").Lit(s))
+					values = append(values, fmt.Sprint(v.Const))
 				}
 			}
-			if len(defs) > 0 {
-				f.Const().Defs(defs...)
-			}
-			f.Line()
+			emitStringEnum(f, name, values, false)
 		case len(def.AnyOf) > 0 && isOpenStringEnum(def):
 			// "Open" string enum: `anyOf` of string consts plus (typically) a
 			// free-form string catch-all. Emit as a named string type with
 			// constants for the known values. Unknown values are still
 			// representable as strings, matching the schema's extensibility.
-			f.Type().Id(name).String()
-			defs := []Code{}
+			values := []string{}
 			for _, v := range def.AnyOf {
 				if v == nil || v.Const == nil {
 					continue
@@ -208,12 +240,9 @@ func WriteTypesJen(outDir string, schema *load.Schema, meta *load.Meta) error {
 				if !ok {
 					continue
 				}
-				defs = append(defs, Id(util.ToEnumConst(name, s)).Id(name).Op("=").Lit(s))
-			}
-			if len(defs) > 0 {
-				f.Const().Defs(defs...)
+				values = append(values, s)
 			}
-			f.Line()
+			emitStringEnum(f, name, values, true)
 		case len(def.AnyOf) > 0:
 			emitUnion(f, name, schema, def, def.AnyOf, false, usedTypeNames)
 		case len(def.OneOf) > 0 && !isStringConstUnion(def):
@@ -248,8 +277,8 @@ func WriteTypesJen(outDir string, schema *load.Schema, meta *load.Meta) error {
 					usedTypeNames[nestedTypeName] = true
 
 					// Generate the nested struct type
-					if prop.Description != "" {
-						emitDocComment(f, prop.Description)
+					if doc := docText(prop); doc != "" {
+						emitDocComment(f, doc)
 					}
 					nestedFields := []Code{}
 					nestedReq := map[string]struct{}{}
@@ -265,8 +294,8 @@ func WriteTypesJen(outDir string, schema *load.Schema, meta *load.Meta) error {
 					for _, npk := range nestedPkeys {
 						nprop := prop.Properties[npk]
 						nfield := util.ToExportedField(npk)
-						if nprop.Description != "" {
-							nestedFields = appendDocComments(nestedFields, nprop.Description)
+						if doc := docText(nprop); doc != "" {
+							nestedFields = appendDocComments(nestedFields, doc)
 						}
 						ntag := npk
 						if _, ok := nestedReq[npk]; !ok {
@@ -294,14 +323,15 @@ func WriteTypesJen(outDir string, schema *load.Schema, meta *load.Meta) error {
 				kind        DefaultKind
 				allowNull   bool
 				nilable     bool // whether zero-value is nil (slice/map)
+				required    bool
 			}
 			defaults := []defaultProp{}
 
 			for _, pk := range pkeys {
 				prop := def.Properties[pk]
 				field := util.ToExportedField(pk)
-				if prop.Description != "" {
-					st = appendDocComments(st, prop.Description)
+				if doc := docText(prop); doc != "" {
+					st = appendDocComments(st, doc)
 				}
 				tag := pk
 				// Detect defaults generically
@@ -324,20 +354,18 @@ func WriteTypesJen(outDir string, schema *load.Schema, meta *load.Meta) error {
 						allowNull:   includesNull(prop),
 						nilable:     nilable,
 					}
+					_, dp.required = req[pk]
 					defaults = append(defaults, *dp)
 				}
 				if _, ok := req[pk]; !ok {
-					// Default: omit if empty for optional fields.
-					// Keep always-present behavior only for defaults where the zero value is nil (slice/map).
-					// For typed object defaults (non-nilable), still allow omission on the wire.
-					if dp == nil || (dp.kind != KindArray && dp.kind != KindObject) || (dp != nil && !dp.nilable) {
-						tag = pk + ",omitempty"
-					}
+					// Optional fields are omitted when empty, never sent as null or as an
+					// explicit default; receivers apply the default on decode.
+					tag = pk + ",omitempty"
 				}
 				// Emit an additional comment line indicating the default, if any.
 				if dp != nil && dp.defaultJSON != "null" {
 					// Insert an empty comment line before default comment (visual separator)
-					if prop.Description != "" {
+					if docText(prop) != "" {
 						st = append(st, Comment(""))
 					}
 					st = append(st, Comment(fmt.Sprintf("Defaults to %s if unset.", dp.defaultJSON)))
@@ -351,11 +379,29 @@ func WriteTypesJen(outDir string, schema *load.Schema, meta *load.Meta) error {
 				}
 				st = append(st, Id(field).Add(fieldType).Tag(map[string]string{"json": tag}))
 			}
+			hasExtra := allowsExtraProperties(def)
+			if hasExtra {
+				st = append(st,
+					Comment("Extra holds properties not declared by the schema, preserved across"),
+					Comment("unmarshal and marshal."),
+					Id("Extra").Map(String()).Qual("encoding/json", "RawMessage").Tag(map[string]string{"json": "-"}),
+				)
+			}
 			f.Type().Id(name).Struct(st...)
 			f.Line()
 
-			// If the struct has any fields with schema defaults, synthesize MarshalJSON and UnmarshalJSON
-			if len(defaults) > 0 {
+			// Required slice/map fields with defaults are filled on encode so a nil
+			// value is sent as the default rather than null.
+			fillOnMarshal := false
+			for _, dp := range defaults {
+				if dp.required && dp.nilable && (dp.kind == KindArray || dp.kind == KindObject) {
+					fillOnMarshal = true
+				}
+			}
+
+			// If the struct has any fields with schema defaults or keeps extra
+			// properties, synthesize MarshalJSON and UnmarshalJSON
+			if fillOnMarshal || hasExtra {
 				// MarshalJSON: coerce nil slices to empty slices before encoding
 				f.Func().Params(Id("v").Id(name)).Id("MarshalJSON").Params().Params(Index().Byte(), Error()).BlockFunc(func(g *Group) {
 					g.Type().Id("Alias").Id(name)
@@ -364,7 +410,7 @@ func WriteTypesJen(outDir string, schema *load.Schema, meta *load.Meta) error {
 					for _, dp := range defaults {
 						// For array/map defaults: if zero is nil, fill with default JSON when nil
 						if dp.kind == KindArray || dp.kind == KindObject {
-							if dp.nilable {
+							if dp.nilable && dp.required {
 								g.If(Id("a").Dot(dp.fieldName).Op("==").Nil()).Block(
 									Qual("encoding/json", "Unmarshal").Call(Index().Byte().Parens(Lit(dp.defaultJSON)), Op("&").Id("a").Dot(dp.fieldName)),
 								)
@@ -372,10 +418,25 @@ func WriteTypesJen(outDir string, schema *load.Schema, meta *load.Meta) error {
 						}
 						// For typed object defaults (non-nilable), we keep Option A: do not inject values on encode.
 					}
-					g.Return(Qual("encoding/json", "Marshal").Call(Id("a")))
+					if !hasExtra {
+						g.Return(Qual("encoding/json", "Marshal").Call(Id("a")))
+						return
+					}
+					// Merge extra properties; declared fields win on conflict.
+					g.List(Id("b"), Err()).Op(":=").Qual("encoding/json", "Marshal").Call(Id("a"))
+					g.If(Err().Op("!=").Nil().Op("||").Len(Id("v").Dot("Extra")).Op("==").Lit(0)).Block(Return(Id("b"), Err()))
+					g.Var().Id("m").Map(String()).Qual("encoding/json", "RawMessage")
+					g.If(Err().Op(":=").Qual("encoding/json", "Unmarshal").Call(Id("b"), Op("&").Id("m")), Err().Op("!=").Nil()).Block(Return(Nil(), Err()))
+					g.For(List(Id("k"), Id("raw")).Op(":=").Range().Id("v").Dot("Extra")).Block(
+						If(List(Id("_"), Id("ok")).Op(":=").Id("m").Index(Id("k")), Op("!").Id("ok")).Block(
+							Id("m").Index(Id("k")).Op("=").Id("raw"),
+						),
+					)
+					g.Return(Qual("encoding/json", "Marshal").Call(Id("m")))
 				})
 				f.Line()
-
+			}
+			if len(defaults) > 0 || hasExtra {
 				// UnmarshalJSON: apply defaults when field is missing or null (and schema doesn't include null)
 				f.Func().Params(Id("v").Op("*").Id(name)).Id("UnmarshalJSON").Params(Id("b").Index().Byte()).Error().BlockFunc(func(g *Group) {
 					g.Var().Id("m").Map(String()).Qual("encoding/json", "RawMessage")
@@ -398,6 +459,19 @@ func WriteTypesJen(outDir string, schema *load.Schema, meta *load.Meta) error {
 							}
 						})
 					}
+					if hasExtra {
+						g.For(List(Id("k"), Id("raw")).Op(":=").Range().Id("m")).BlockFunc(func(h *Group) {
+							keys := []Code{}
+							for _, pk := range pkeys {
+								keys = append(keys, Lit(pk))
+							}
+							h.Switch(Id("k")).Block(Case(keys...).Block(Continue()))
+							h.If(Id("a").Dot("Extra").Op("==").Nil()).Block(
+								Id("a").Dot("Extra").Op("=").Map(String()).Qual("encoding/json", "RawMessage").Values(),
+							)
+							h.Id("a").Dot("Extra").Index(Id("k")).Op("=").Id("raw")
+						})
+					}
 					g.Op("*").Id("v").Op("=").Id(name).Call(Id("a"))
 					g.Return(Nil())
 				})
@@ -422,7 +496,7 @@ func WriteTypesJen(outDir string, schema *load.Schema, meta *load.Meta) error {
 		// so skip emitValidateJen for types that already have a union Validate.
 		hasUnionValidate := len(def.OneOf) > 0 && !isStringConstUnion(def)
 		if !hasUnionValidate && (strings.HasSuffix(name, "Request") || strings.HasSuffix(name, "Response") || strings.HasSuffix(name, "Notification") || name == "ToolCallUpdate") {
-			emitValidateJen(f, name, def)
+			emitValidateJen(f, name, schema, def)
 		}
 	}
 
@@ -537,6 +611,197 @@ func isStringConstUnion(def *load.Definition) bool {
 	return true
 }
 
+// emitStringEnum emits a named string type with a constant per known value,
+// followed by String, IsValid and AllXxx helpers. For open enums IsValid still
+// only reports known values; callers must tolerate unknown ones on the wire.
+func emitStringEnum(f *File, name string, values []string, open bool) {
+	f.Type().Id(name).String()
+	if len(values) == 0 {
+		f.Line()
+		return
+	}
+	defs := []Code{}
+	consts := []Code{}
+	for _, s := range values {
+		c := util.ToEnumConst(name, s)
+		defs = append(defs, Id(c).Id(name).Op("=").Lit(s))
+		consts = append(consts, Id(c))
+	}
+	f.Const().Defs(defs...)
+	f.Line()
+
+	f.Comment("String returns the wire value of v.")
+	f.Func().Params(Id("v").Id(name)).Id("String").Params().String().Block(
+		Return(String().Call(Id("v"))),
+	)
+	f.Line()
+
+	if open {
+		f.Comment(fmt.Sprintf("IsValid reports whether v is one of the known %s values. The schema also", name))
+		f.Comment("permits other strings, so an invalid value is not necessarily an error.")
+	} else {
+		f.Comment(fmt.Sprintf("IsValid reports whether v is one of the known %s values.", name))
+	}
+	f.Func().Params(Id("v").Id(name)).Id("IsValid").Params().Bool().Block(
+		Switch(Id("v")).Block(
+			Case(consts...).Block(Return(Lit(true))),
+		),
+		Return(Lit(false)),
+	)
+	f.Line()
+
+	f.Comment(fmt.Sprintf("All%s returns the known %s values in schema order.", name, name))
+	f.Func().Id("All" + name).Params().Index().Id(name).Block(
+		Return(Index().Id(name).Values(consts...)),
+	)
+	f.Line()
+}
+
+// closedEnumField reports whether prop is emitted as a field of a closed
+// string enum type, returning the type name and whether the field is a pointer.
+// Open enums are skipped since the schema permits values outside the known set.
+func closedEnumField(schema *load.Schema, prop *load.Definition) (string, bool, bool) {
+	if prop == nil || schema == nil {
+		return "", false, false
+	}
+	ref, ptr := prop.Ref, false
+	if ref == "" && len(prop.AllOf) == 1 && prop.AllOf[0] != nil {
+		ref = prop.AllOf[0].Ref
+	}
+	if ref == "" {
+		list := prop.AnyOf
+		if len(list) == 0 {
+			list = prop.OneOf
+		}
+		if len(list) == 2 {
+			var nonNull string
+			hasNull := false
+			for _, e := range list {
+				switch {
+				case e == nil:
+				case e.Ref != "":
+					nonNull = e.Ref
+				case e.Type == "null":
+					hasNull = true
+				}
+			}
+			if hasNull {
+				ref, ptr = nonNull, true
+			}
+		}
+	}
+	if !strings.HasPrefix(ref, "#/$defs/") {
+		return "", false, false
+	}
+	name := ref[len("#/$defs/"):]
+	def := schema.Defs[name]
+	if def == nil || (len(def.Enum) == 0 && !isStringConstUnion(def)) {
+		return "", false, false
+	}
+	return name, ptr, true
+}
+
+// boundedField resolves the schema node carrying numeric or string bounds for
+// prop, following a $ref (optionally wrapped in allOf or unioned with null) to a primitive
+// definition. It reports the primitive type and whether the field is a pointer.
+func boundedField(schema *load.Schema, prop *load.Definition) (*load.Definition, string, bool) {
+	if prop == nil {
+		return nil, "", false
+	}
+	resolve := func(ref string) *load.Definition {
+		if schema == nil || !strings.HasPrefix(ref, "#/$defs/") {
+			return nil
+		}
+		return schema.Defs[ref[len("#/$defs/"):]]
+	}
+	if prop.Ref == "" && len(prop.AllOf) == 1 && prop.AllOf[0] != nil && prop.AllOf[0].Ref != "" {
+		prop = prop.AllOf[0]
+	}
+	if prop.Ref != "" {
+		def := resolve(prop.Ref)
+		return def, ir.PrimaryType(def), false
+	}
+	if arr, ok := prop.Type.([]any); ok && len(arr) == 2 {
+		return prop, ir.PrimaryType(prop), includesNull(prop)
+	}
+	if list := prop.AnyOf; len(list) == 2 {
+		var def *load.Definition
+		hasNull := false
+		for _, e := range list {
+			switch {
+			case e == nil:
+			case e.Ref != "":
+				def = resolve(e.Ref)
+			case e.Type == "null":
+				hasNull = true
+			}
+		}
+		if def != nil && hasNull {
+			return def, ir.PrimaryType(def), true
+		}
+		return nil, "", false
+	}
+	return prop, ir.PrimaryType(prop), false
+}
+
+// emitBoundsChecks appends minimum/maximum checks for numeric fields and
+// minLength/maxLength/pattern checks for string fields of v. Patterns are
+// compiled once into package-level variables emitted to f.
+func emitBoundsChecks(f *File, g *Group, schema *load.Schema, typeName, propName, field string, prop *load.Definition) {
+	def, typ, ptr := boundedField(schema, prop)
+	if def == nil {
+		return
+	}
+	val := Id("v").Dot(field)
+	if ptr {
+		val = Op("*").Id("v").Dot(field)
+	}
+	guard := func(cond *Statement) *Statement {
+		if ptr {
+			return Id("v").Dot(field).Op("!=").Nil().Op("&&").Add(cond)
+		}
+		return cond
+	}
+	bound := func(b float64) Code {
+		if typ == "integer" {
+			return Lit(int(b))
+		}
+		return Lit(b)
+	}
+	switch typ {
+	case "integer", "number":
+		if def.Minimum != nil {
+			g.If(guard(Add(val).Op("<").Add(bound(*def.Minimum)))).Block(
+				Return(jValidationErr(propName, "below minimum", fmt.Sprintf("%s must be >= %v, got %%v", propName, *def.Minimum), val)),
+			)
+		}
+		if def.Maximum != nil {
+			g.If(guard(Add(val).Op(">").Add(bound(*def.Maximum)))).Block(
+				Return(jValidationErr(propName, "above maximum", fmt.Sprintf("%s must be <= %v, got %%v", propName, *def.Maximum), val)),
+			)
+		}
+	case "string":
+		n := Qual("unicode/utf8", "RuneCountInString").Call(Qual("", "string").Call(val))
+		if def.MinLength != nil {
+			g.If(guard(n.Clone().Op("<").Lit(*def.MinLength))).Block(
+				Return(jValidationErr(propName, "too short", fmt.Sprintf("%s must be at least %d characters", propName, *def.MinLength))),
+			)
+		}
+		if def.MaxLength != nil {
+			g.If(guard(n.Clone().Op(">").Lit(*def.MaxLength))).Block(
+				Return(jValidationErr(propName, "too long", fmt.Sprintf("%s must be at most %d characters", propName, *def.MaxLength))),
+			)
+		}
+		if def.Pattern != "" {
+			re := "pattern" + typeName + field
+			f.Var().Id(re).Op("=").Qual("regexp", "MustCompile").Call(Lit(def.Pattern))
+			g.If(guard(Op("!").Id(re).Dot("MatchString").Call(Qual("", "string").Call(val)))).Block(
+				Return(jValidationErr(propName, "pattern mismatch", fmt.Sprintf("%s must match %%q", propName), Lit(def.Pattern))),
+			)
+		}
+	}
+}
+
 // isOpenStringEnum reports whether def is an "open" string enum: an `anyOf`
 // where every variant is a plain string schema (type: "string") and at least
 // one variant has a string `const`. Such schemas describe a string value with
@@ -574,13 +839,19 @@ func isOpenStringEnum(def *load.Definition) bool {
 	return sawConst
 }
 
+// jValidationErr emits a *ValidationError for field, whose message is format
+// expanded with args as by fmt.Sprintf.
+func jValidationErr(field, reason, format string, args ...Code) Code {
+	return Id("newValidationError").Call(append([]Code{Lit(field), Lit(reason), Lit(format)}, args...)...)
+}
+
 // emitValidateJen generates validators for selected types (logic unchanged).
 
-func emitValidateJen(f *File, name string, def *load.Definition) {
+func emitValidateJen(f *File, name string, schema *load.Schema, def *load.Definition) {
 	switch name {
 	case "ToolCallUpdate":
 		f.Func().Params(Id("t").Op("*").Id("ToolCallUpdate")).Id("Validate").Params().Params(Error()).Block(
-			If(Id("t").Dot("ToolCallId").Op("==").Lit("")).Block(Return(Qual("fmt", "Errorf").Call(Lit("toolCallId is required")))),
+			If(Id("t").Dot("ToolCallId").Op("==").Lit("")).Block(Return(jValidationErr("toolCallId", "required", "toolCallId is required"))),
 			Return(Nil()),
 		)
 		return
@@ -602,11 +873,24 @@ func emitValidateJen(f *File, name string, def *load.Definition) {
 				if required {
 					switch ir.PrimaryType(pDef) {
 					case "string":
-						g.If(Id("v").Dot(field).Op("==").Lit("")).Block(Return(Qual("fmt", "Errorf").Call(Lit(propName + " is required"))))
+						g.If(Id("v").Dot(field).Op("==").Lit("")).Block(Return(jValidationErr(propName, "required", propName+" is required")))
 					case "array":
-						g.If(Id("v").Dot(field).Op("==").Nil()).Block(Return(Qual("fmt", "Errorf").Call(Lit(propName + " is required"))))
+						g.If(Id("v").Dot(field).Op("==").Nil()).Block(Return(jValidationErr(propName, "required", propName+" is required")))
 					}
 				}
+				if _, ptr, ok := closedEnumField(schema, pDef); ok {
+					invalid := Return(jValidationErr(propName, "unknown enum", "invalid "+propName+": %q", Id("v").Dot(field)))
+					switch {
+					case ptr:
+						invalid = Return(jValidationErr(propName, "unknown enum", "invalid "+propName+": %q", Op("*").Id("v").Dot(field)))
+						g.If(Id("v").Dot(field).Op("!=").Nil().Op("&&").Op("!").Id("v").Dot(field).Dot("IsValid").Call()).Block(invalid)
+					case required:
+						g.If(Op("!").Id("v").Dot(field).Dot("IsValid").Call()).Block(invalid)
+					default:
+						g.If(Id("v").Dot(field).Op("!=").Lit("").Op("&&").Op("!").Id("v").Dot(field).Dot("IsValid").Call()).Block(invalid)
+					}
+				}
+				emitBoundsChecks(f, g, schema, name, propName, field, pDef)
 			}
 			g.Return(Nil())
 		})
@@ -623,8 +907,8 @@ func methodDescription(schema *load.Schema, mi *ir.MethodInfo) string {
 		if name == "" {
 			continue
 		}
-		if def := schema.Defs[name]; def != nil && def.Description != "" {
-			return def.Description
+		if def := schema.Defs[name]; def != nil && docText(def) != "" {
+			return docText(def)
 		}
 	}
 	return ""
@@ -814,8 +1098,10 @@ func jenTypeFor(d *load.Definition) Code {
 	case "array":
 		return Index().Add(jenTypeFor(d.Items))
 	case "object":
-		if len(d.Properties) == 0 {
-			return Map(String()).Any()
+		if ap := d.AdditionalProperties; ap != nil && len(d.Properties) == 0 {
+			if _, isBool := ap.BoolSchema(); !isBool {
+				return Map(String()).Add(jenTypeFor(ap))
+			}
 		}
 		return Map(String()).Any()
 	default:
@@ -896,6 +1182,55 @@ func jenTypeForOptional(d *load.Definition) Code {
 // emitAvailableCommandInputJen generates a concrete variant type for anyOf and a thin union wrapper
 // that supports JSON unmarshal by probing object shape. Currently the schema defines one variant
 // (title: UnstructuredCommandInput) with a required 'hint' field.
+// unionDiscriminatorKey returns the property that tells a union's variants
+// apart: the schema's explicit discriminator if present, otherwise the first
+// const-valued property found on a variant (for older schemas without
+// discriminator metadata). It returns "" for untagged unions.
+func unionDiscriminatorKey(schema *load.Schema, parentDef *load.Definition, defs []*load.Definition) string {
+	if parentDef != nil && parentDef.Discriminator != nil && parentDef.Discriminator.PropertyName != "" {
+		return parentDef.Discriminator.PropertyName
+	}
+	for _, v := range defs {
+		if v == nil {
+			continue
+		}
+		v = expandAllOf(schema, v)
+		for _, k := range sortedPropertyKeys(v.Properties) {
+			if pd := v.Properties[k]; pd != nil && pd.Const != nil {
+				return k
+			}
+		}
+	}
+	return ""
+}
+
+// requiredStringProps returns v's required string properties, excluding
+// consts such as discriminators, sorted with "name" first.
+func requiredStringProps(schema *load.Schema, v *load.Definition) []string {
+	var out []string
+	for _, key := range v.Required {
+		pd := v.Properties[key]
+		if pd == nil || pd.Const != nil {
+			continue
+		}
+		if pd.Ref != "" && strings.HasPrefix(pd.Ref, "#/$defs/") {
+			if d := schema.Defs[pd.Ref[len("#/$defs/"):]]; d != nil {
+				pd = d
+			}
+		}
+		if t, ok := expandAllOf(schema, pd).Type.(string); ok && t == "string" {
+			out = append(out, key)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if (out[i] == "name") != (out[j] == "name") {
+			return out[i] == "name"
+		}
+		return out[i] < out[j]
+	})
+	return out
+}
+
 func emitUnion(f *File, name string, schema *load.Schema, parentDef *load.Definition, defs []*load.Definition, exactlyOne bool, usedTypeNames map[string]bool) {
 	type variantInfo struct {
 		fieldName         string
@@ -908,32 +1243,12 @@ func emitUnion(f *File, name string, schema *load.Schema, parentDef *load.Defini
 		constPairs        [][2]string
 		isNull            bool
 		description       string
+		// requiredStrings lists the required string properties other than
+		// discriminators, with "name" first.
+		requiredStrings []string
 	}
 	variants := []variantInfo{}
-	discKey := ""
-	// Use schema's explicit discriminator if available
-	if parentDef != nil && parentDef.Discriminator != nil {
-		discKey = parentDef.Discriminator.PropertyName
-	}
-	// Fallback: discover discriminator key by scanning for const properties
-	// (for backward compatibility with older schemas without discriminator metadata)
-	if discKey == "" {
-		for _, v := range defs {
-			if v == nil {
-				continue
-			}
-			v = expandAllOf(schema, v)
-			for k, pd := range v.Properties {
-				if pd != nil && pd.Const != nil {
-					discKey = k
-					break
-				}
-			}
-			if discKey != "" {
-				break
-			}
-		}
-	}
+	discKey := unionDiscriminatorKey(schema, parentDef, defs)
 	sharedProps := map[string]*load.Definition{}
 	sharedRequired := map[string]struct{}{}
 	if parentDef != nil {
@@ -1055,8 +1370,8 @@ func emitUnion(f *File, name string, schema *load.Schema, parentDef *load.Defini
 		}
 		// collect const properties (e.g., type, outcome)
 		consts := [][2]string{}
-		for pk, pd := range v.Properties {
-			if pd != nil && pd.Const != nil {
+		for _, pk := range sortedPropertyKeys(v.Properties) {
+			if pd := v.Properties[pk]; pd != nil && pd.Const != nil {
 				if s, ok := pd.Const.(string); ok {
 					consts = append(consts, [2]string{pk, s})
 				}
@@ -1090,14 +1405,14 @@ func emitUnion(f *File, name string, schema *load.Schema, parentDef *load.Defini
 					pkeys = append(pkeys, pk)
 				}
 				sort.Strings(pkeys)
-				if v.Description != "" {
-					emitDocComment(f, v.Description)
+				if doc := docText(v); doc != "" {
+					emitDocComment(f, doc)
 				}
 				for _, pk := range pkeys {
 					pDef := mergedProps[pk]
 					field := util.ToExportedField(pk)
-					if pDef.Description != "" {
-						st = appendDocComments(st, pDef.Description)
+					if doc := docText(pDef); doc != "" {
+						st = appendDocComments(st, doc)
 					}
 					tag := pk
 					if _, ok := req[pk]; !ok {
@@ -1111,8 +1426,8 @@ func emitUnion(f *File, name string, schema *load.Schema, parentDef *load.Defini
 				isExtension := strings.Contains(strings.ToLower(v.Description), "extension") ||
 					strings.Contains(v.Title, "Ext")
 
-				if v.Description != "" {
-					emitDocComment(f, v.Description)
+				if doc := docText(v); doc != "" {
+					emitDocComment(f, doc)
 				}
 
 				if isExtension {
@@ -1145,7 +1460,8 @@ func emitUnion(f *File, name string, schema *load.Schema, parentDef *load.Defini
 			discValue:         dv,
 			constPairs:        consts,
 			isNull:            isNull,
-			description:       v.Description,
+			description:       docText(v),
+			requiredStrings:   requiredStringProps(schema, v),
 		})
 	}
 	// wrapper
@@ -1158,6 +1474,15 @@ func emitUnion(f *File, name string, schema *load.Schema, parentDef *load.Defini
 	}
 	f.Type().Id(name).Struct(st...)
 	f.Line()
+	// A union is strictly discriminated when every non-null variant carries a
+	// discriminator const. Such unions reject missing or unknown discriminator
+	// values instead of guessing a variant from the payload's shape.
+	strict := discKey != ""
+	for _, vi := range variants {
+		if !vi.isNull && vi.discValue == "" {
+			strict = false
+		}
+	}
 	// Unmarshal
 	f.Func().Params(Id("u").Op("*").Id(name)).Id("UnmarshalJSON").Params(Id("b").Index().Byte()).Error().BlockFunc(func(g *Group) {
 		// Handle literal null if a null-only variant exists
@@ -1198,10 +1523,19 @@ func emitUnion(f *File, name string, schema *load.Schema, parentDef *load.Defini
 							}
 						}
 					})
+					if strict {
+						h.If(Id("disc").Op("==").Lit("")).Block(
+							Return(Qual("fmt", "Errorf").Call(Lit(fmt.Sprintf("%s: missing %q discriminator", name, discKey)))),
+						)
+						h.Return(Qual("fmt", "Errorf").Call(Lit(fmt.Sprintf("%s: unknown %s %%q", name, discKey)), Id("disc")))
+					}
 				})
 			}
 			// required-key match
 			for _, vi := range variants {
+				if strict {
+					break
+				}
 				if vi.isObject && len(vi.required) > 0 {
 					obj.BlockFunc(func(h *Group) {
 						h.Var().Id("v").Id(vi.typeName)
@@ -1258,6 +1592,9 @@ func emitUnion(f *File, name string, schema *load.Schema, parentDef *load.Defini
 	})
 	// Marshal
 	f.Func().Params(Id("u").Id(name)).Id("MarshalJSON").Params().Params(Index().Byte(), Error()).BlockFunc(func(g *Group) {
+		if exactlyOne {
+			g.If(Err().Op(":=").Id("u").Dot("Validate").Call(), Err().Op("!=").Nil()).Block(Return(Nil(), Err()))
+		}
 		for _, vi := range variants {
 			g.If(Id("u").Dot(vi.fieldName).Op("!=").Nil()).BlockFunc(func(gg *Group) {
 				// Null-only variant encodes to JSON null
@@ -1351,7 +1688,7 @@ func emitUnion(f *File, name string, schema *load.Schema, parentDef *load.Defini
 				}
 			})
 		}
-		g.Return(Index().Byte().Values(), Nil())
+		g.Return(Nil(), Qual("errors", "New").Call(Lit(name+" has no variant set")))
 	})
 	f.Line()
 
@@ -1363,10 +1700,158 @@ func emitUnion(f *File, name string, schema *load.Schema, parentDef *load.Defini
 				g.If(Id("u").Dot(vi.fieldName).Op("!=").Nil()).Block(Id("count").Op("++"))
 			}
 			g.If(Id("count").Op("!=").Lit(1)).Block(
-				Return(Qual("errors", "New").Call(Lit(name + " must have exactly one variant set"))),
+				Return(Qual("fmt", "Errorf").Call(Lit(name+" must have exactly one variant set, got %d"), Id("count"))),
 			)
 			g.Return(Nil())
 		})
 		f.Line()
 	}
+
+	// anyOf unions get no generated Validate, but a variant check derived from
+	// the variants' required string properties lets hand-written validators
+	// compile against whichever variants the generated surface includes.
+	if !exactlyOne && slices.ContainsFunc(variants, func(vi variantInfo) bool { return len(vi.requiredStrings) > 0 }) {
+		f.Comment("validateVariant checks that exactly one variant is set and that the set")
+		f.Comment("variant's required string fields are non-empty.")
+		f.Func().Params(Id("u").Op("*").Id(name)).Id("validateVariant").Params().Params(Error()).BlockFunc(func(g *Group) {
+			g.Var().Id("count").Int()
+			for _, vi := range variants {
+				g.If(Id("u").Dot(vi.fieldName).Op("!=").Nil()).Block(Id("count").Op("++"))
+			}
+			g.If(Id("count").Op("!=").Lit(1)).Block(
+				Return(Qual("fmt", "Errorf").Call(Lit(name+" must have exactly one variant set, got %d"), Id("count"))),
+			)
+			g.Switch().BlockFunc(func(sw *Group) {
+				for _, vi := range variants {
+					if len(vi.requiredStrings) == 0 {
+						continue
+					}
+					label := vi.discValue
+					if label == "" {
+						label = strings.ToLower(vi.fieldName)
+					}
+					sw.Case(Id("u").Dot(vi.fieldName).Op("!=").Nil()).BlockFunc(func(c *Group) {
+						for _, key := range vi.requiredStrings {
+							field := Id("u").Dot(vi.fieldName).Dot(util.ToExportedField(key))
+							if key == "name" {
+								c.If(field.Op("==").Lit("")).Block(
+									Return(Qual("errors", "New").Call(Lit(label + ": name is required"))),
+								)
+								continue
+							}
+							// Once the name is known to be set, it identifies the variant.
+							format := label + ": " + key + " is required"
+							args := []Code{}
+							if slices.Contains(vi.requiredStrings, "name") {
+								format = label + " %q: " + key + " is required"
+								args = append(args, Id("u").Dot(vi.fieldName).Dot("Name"))
+							}
+							c.If(field.Op("==").Lit("")).Block(
+								Return(Qual("fmt", "Errorf").Call(append([]Code{Lit(format)}, args...)...)),
+							)
+						}
+					})
+				}
+			})
+			g.Return(Nil())
+		})
+		f.Line()
+	}
+
+	if name == "SessionUpdate" {
+		fields := make([][2]string, 0, len(variants))
+		for _, vi := range variants {
+			if !vi.isNull {
+				fields = append(fields, [2]string{vi.fieldName, vi.typeName})
+			}
+		}
+		emitUnionVisitor(f, name, fields)
+		kinds := make([][3]string, 0, len(variants))
+		for _, vi := range variants {
+			if !vi.isNull && vi.discValue != "" {
+				kinds = append(kinds, [3]string{vi.fieldName, vi.typeName, vi.discValue})
+			}
+		}
+		emitUnionKind(f, name, kinds)
+	}
+}
+
+// emitUnionKind emits a <Union>Kind enum of discriminator values, a Kind method
+// on the union, and a sealed <Union>Variant interface implemented by every
+// variant type so callers can type-switch on Variant(). Each variants entry is
+// a {fieldName, typeName, discValue} triple.
+func emitUnionKind(f *File, name string, variants [][3]string) {
+	kind := name + "Kind"
+	iface := name + "Variant"
+	seal := "is" + name + "Variant"
+	values := make([]string, 0, len(variants))
+	for _, v := range variants {
+		values = append(values, v[2])
+	}
+	f.Comment(fmt.Sprintf("%s identifies which variant of a %s is set.", kind, name))
+	emitStringEnum(f, kind, values, false)
+
+	f.Comment(fmt.Sprintf("Kind returns the discriminator of the variant that is set, or the empty %s", kind))
+	f.Comment("if none is.")
+	f.Func().Params(Id("u").Id(name)).Id("Kind").Params().Id(kind).Block(
+		Switch().BlockFunc(func(g *Group) {
+			for _, v := range variants {
+				g.Case(Id("u").Dot(v[0]).Op("!=").Nil()).Block(Return(Id(util.ToEnumConst(kind, v[2]))))
+			}
+		}),
+		Return(Lit("")),
+	)
+	f.Line()
+
+	f.Comment(fmt.Sprintf("%s is implemented by every %s variant type.", iface, name))
+	f.Comment("It is sealed, so a type switch over Variant() can cover every case.")
+	f.Type().Id(iface).Interface(
+		Id(kind).Params().Id(kind),
+		Id(seal).Params(),
+	)
+	f.Line()
+	for _, v := range variants {
+		f.Comment(fmt.Sprintf("%s returns %s.", kind, util.ToEnumConst(kind, v[2])))
+		f.Func().Params(Op("*").Id(v[1])).Id(kind).Params().Id(kind).Block(Return(Id(util.ToEnumConst(kind, v[2]))))
+		f.Func().Params(Op("*").Id(v[1])).Id(seal).Params().Block()
+		f.Line()
+	}
+
+	f.Comment("Variant returns the variant that is set, or nil if none is.")
+	f.Func().Params(Id("u").Id(name)).Id("Variant").Params().Id(iface).Block(
+		Switch().BlockFunc(func(g *Group) {
+			for _, v := range variants {
+				g.Case(Id("u").Dot(v[0]).Op("!=").Nil()).Block(Return(Id("u").Dot(v[0])))
+			}
+		}),
+		Return(Nil()),
+	)
+	f.Line()
+}
+
+// emitUnionVisitor emits a <Union>Visitor interface with one Visit method per
+// variant, and an Accept method on the union that dispatches to the variant that is
+// set. Each fields entry is a {fieldName, typeName} pair.
+func emitUnionVisitor(f *File, name string, fields [][2]string) {
+	iface := name + "Visitor"
+	f.Comment(fmt.Sprintf("%s handles each variant of a %s. See %s.Accept.", iface, name, name))
+	f.Type().Id(iface).InterfaceFunc(func(g *Group) {
+		for _, fd := range fields {
+			g.Id("Visit" + fd[0]).Params(Op("*").Id(fd[1])).Error()
+		}
+	})
+	f.Line()
+	f.Comment(fmt.Sprintf("Accept calls the %s method for the variant that is set and returns its", iface))
+	f.Comment("error. It returns an error if no variant is set.")
+	f.Func().Params(Id("u").Id(name)).Id("Accept").Params(Id("v").Id(iface)).Error().Block(
+		Switch().BlockFunc(func(g *Group) {
+			for _, fd := range fields {
+				g.Case(Id("u").Dot(fd[0]).Op("!=").Nil()).Block(
+					Return(Id("v").Dot("Visit" + fd[0]).Call(Id("u").Dot(fd[0]))),
+				)
+			}
+		}),
+		Return(Qual("errors", "New").Call(Lit(name+" has no variant set"))),
+	)
+	f.Line()
 }
@@ -0,0 +1,104 @@
+package emit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/coder/acp-go-sdk/cmd/generate/internal/load"
+)
+
+func writeTypes(t *testing.T, schema *load.Schema) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := WriteTypesJen(dir, schema, &load.Meta{Version: 1}); err != nil {
+		t.Fatalf("WriteTypesJen: %v", err)
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "types_gen.go"))
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	return string(b)
+}
+
+func TestWriteTypesJenDeprecated(t *testing.T) {
+	out := writeTypes(t, &load.Schema{Defs: map[string]*load.Definition{
+		"Widget": {
+			Type:        "object",
+			Description: "A widget.",
+			Deprecated:  "Use Gadget instead.",
+			Properties: map[string]*load.Definition{
+				"size": {Type: "integer", Deprecated: "Sizes are ignored."},
+			},
+		},
+	}})
+	for _, want := range []string{
+		"// A widget.\n//\n// Deprecated: Use Gadget instead.\ntype Widget struct",
+		"\t// Deprecated: Sizes are ignored.\n\tSize int",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteTypesJenAdditionalProperties(t *testing.T) {
+	out := writeTypes(t, &load.Schema{Defs: map[string]*load.Definition{
+		"Labels": {
+			Type: "object",
+			Properties: map[string]*load.Definition{
+				"tags": {Type: "object", AdditionalProperties: &load.Definition{Type: "string"}},
+			},
+		},
+		"Open": {
+			Type:                 "object",
+			Properties:           map[string]*load.Definition{"name": {Type: "string"}},
+			AdditionalProperties: &load.Definition{Type: "integer"},
+		},
+	}})
+	for _, want := range []string{
+		"Tags map[string]string `json:\"tags,omitempty\"`",
+		"Extra map[string]json.RawMessage `json:\"-\"`",
+		"func (v Open) MarshalJSON() ([]byte, error) {",
+		"func (v *Open) UnmarshalJSON(b []byte) error {",
+		"case \"name\":\n\t\t\tcontinue",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "func (v Labels) MarshalJSON") {
+		t.Errorf("Labels has no extra properties and needs no custom marshaling")
+	}
+}
+
+func TestWriteTypesJenOptionalDefaultsOmitted(t *testing.T) {
+	out := writeTypes(t, &load.Schema{Defs: map[string]*load.Definition{
+		"Listing": {
+			Type:     "object",
+			Required: []string{"items"},
+			Properties: map[string]*load.Definition{
+				"items": {Type: "array", Items: &load.Definition{Type: "string"}, Default: []any{}},
+			},
+		},
+		"Options": {
+			Type: "object",
+			Properties: map[string]*load.Definition{
+				"tags": {Type: "array", Items: &load.Definition{Type: "string"}, Default: []any{}},
+			},
+		},
+	}})
+	for _, want := range []string{
+		"Tags []string `json:\"tags,omitempty\"`",
+		"func (v *Options) UnmarshalJSON(b []byte) error {",
+		"func (v Listing) MarshalJSON() ([]byte, error) {",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "func (v Options) MarshalJSON") {
+		t.Errorf("optional defaults must be omitted on encode, not filled in")
+	}
+}
@@ -0,0 +1,71 @@
+package emit
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/coder/acp-go-sdk/cmd/generate/internal/ir"
+	"github.com/coder/acp-go-sdk/cmd/generate/internal/load"
+)
+
+// WriteUnimplementedJen emits unimplemented_gen.go with UnimplementedAgent and
+// UnimplementedClient, embeddable stubs covering every Agent and Client method.
+func WriteUnimplementedJen(outDir string, schema *load.Schema, meta *load.Meta) error {
+	groups := ir.BuildMethodGroups(schema, meta)
+
+	f := NewFile("acp")
+	f.HeaderComment("Code generated by acp-go-generator; DO NOT EDIT.")
+
+	f.Comment("UnimplementedAgent can be embedded in an Agent implementation so that only the")
+	f.Comment("methods of interest need to be written. Its requests fail with a method-not-found")
+	f.Comment("error and its notifications are ignored. Embedding it also keeps implementations")
+	f.Comment("compiling when methods are added to Agent or its optional interfaces.")
+	f.Type().Id("UnimplementedAgent").Struct()
+	f.Line()
+	for _, k := range ir.SortedKeys(meta.AgentMethods) {
+		if mi := groups["agent|"+meta.AgentMethods[k]]; mi != nil {
+			emitUnimplementedMethod(f, schema, "UnimplementedAgent", "AgentMethod"+toExportedConst(k), k, mi)
+		}
+	}
+
+	f.Comment("UnimplementedClient can be embedded in a Client implementation so that only the")
+	f.Comment("methods of interest need to be written. Its requests fail with a method-not-found")
+	f.Comment("error and its notifications are ignored. Embedding it also keeps implementations")
+	f.Comment("compiling when methods are added to Client or its optional interfaces.")
+	f.Type().Id("UnimplementedClient").Struct()
+	f.Line()
+	for _, k := range ir.SortedKeys(meta.ClientMethods) {
+		if mi := groups["client|"+meta.ClientMethods[k]]; mi != nil {
+			emitUnimplementedMethod(f, schema, "UnimplementedClient", "ClientMethod"+toExportedConst(k), k, mi)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := f.Render(&buf); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(outDir, "unimplemented_gen.go"), buf.Bytes(), 0o644)
+}
+
+func emitUnimplementedMethod(f *File, schema *load.Schema, recv, methodConst, key string, mi *ir.MethodInfo) {
+	ctxParam := Id("_").Qual("context", "Context")
+	switch {
+	case mi.Notif != "":
+		name := ir.DispatchMethodNameForNotification(key, mi.Notif)
+		f.Func().Params(Id(recv)).Id(name).Params(ctxParam, Id("_").Id(mi.Notif)).Error().Block(Return(Nil()))
+	case mi.Req != "":
+		respName := strings.TrimSuffix(mi.Req, "Request") + "Response"
+		methodName := strings.TrimSuffix(mi.Req, "Request")
+		notFound := Id("NewMethodNotFound").Call(Id(methodConst))
+		if ir.IsNullResponse(schema.Defs[respName]) {
+			f.Func().Params(Id(recv)).Id(methodName).Params(ctxParam, Id("_").Id(mi.Req)).Error().Block(Return(notFound))
+		} else {
+			f.Func().Params(Id(recv)).Id(methodName).Params(ctxParam, Id("_").Id(mi.Req)).Params(Id(respName), Error()).Block(Return(Id(respName).Values(), notFound))
+		}
+	default:
+		return
+	}
+	f.Line()
+}
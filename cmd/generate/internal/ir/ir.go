@@ -26,6 +26,27 @@ const (
 	BindClientTerminal
 )
 
+// InterfaceName returns the Go interface a binding routes to, or "" for
+// BindUnknown.
+func (b MethodBinding) InterfaceName() string {
+	switch b {
+	case BindAgent:
+		return "Agent"
+	case BindAgentLoader:
+		return "AgentLoader"
+	case BindAgentExperimental:
+		return "AgentExperimental"
+	case BindClient:
+		return "Client"
+	case BindClientExperimental:
+		return "ClientExperimental"
+	case BindClientTerminal:
+		return "ClientTerminal"
+	default:
+		return ""
+	}
+}
+
 // MethodInfo captures association between a wire method and its Go types and binding.
 type MethodInfo struct {
 	Side        string // "agent" or "client"
@@ -96,8 +117,15 @@ func IsNullResponse(def *load.Definition) bool {
 // BuildMethodGroups merges schema-provided links with meta fallback and returns groups.
 func BuildMethodGroups(schema *load.Schema, meta *load.Meta) Groups {
 	groups := Groups{}
-	// From schema
-	for name, def := range schema.Defs {
+	// From schema, in name order so colliding x-method links resolve the same
+	// way on every run.
+	names := make([]string, 0, len(schema.Defs))
+	for name := range schema.Defs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		def := schema.Defs[name]
 		if def == nil || def.XMethod == "" || def.XSide == "" {
 			continue
 		}
@@ -118,7 +146,8 @@ func BuildMethodGroups(schema *load.Schema, meta *load.Meta) Groups {
 		}
 	}
 	// From meta fallback (terminal etc.)
-	for mk, wire := range meta.AgentMethods {
+	for _, mk := range SortedKeys(meta.AgentMethods) {
+		wire := meta.AgentMethods[mk]
 		k := key("agent", wire)
 		if groups[k] == nil {
 			base := inferTypeBaseFromMethodKey(mk)
@@ -138,7 +167,8 @@ func BuildMethodGroups(schema *load.Schema, meta *load.Meta) Groups {
 			}
 		}
 	}
-	for mk, wire := range meta.ClientMethods {
+	for _, mk := range SortedKeys(meta.ClientMethods) {
+		wire := meta.ClientMethods[mk]
 		k := key("client", wire)
 		if groups[k] == nil {
 			base := inferTypeBaseFromMethodKey(mk)
@@ -280,6 +310,15 @@ func DispatchMethodNameForNotification(methodKey, typeName string) string {
 	}
 }
 
+// GoMethodName returns the name of the interface method that handles mi, given
+// its meta key.
+func GoMethodName(methodKey string, mi *MethodInfo) string {
+	if mi.Notif != "" {
+		return DispatchMethodNameForNotification(methodKey, mi.Notif)
+	}
+	return strings.TrimSuffix(mi.Req, "Request")
+}
+
 // SortedKeys returns sorted keys of a map.
 func SortedKeys(m map[string]string) []string {
 	ks := make([]string, 0, len(m))
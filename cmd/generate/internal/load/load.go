@@ -13,6 +13,9 @@ import (
 // Meta mirrors schema/meta.json for method maps and version.
 type Meta struct {
 	Version int `json:"version"`
+	// SchemaVersion is the ACP release the schema files were fetched from, as
+	// pinned in schema/version. Empty when the file is absent.
+	SchemaVersion string `json:"-"`
 
 	AgentMethods    map[string]string `json:"agentMethods"`
 	ClientMethods   map[string]string `json:"clientMethods"`
@@ -47,6 +50,9 @@ type Definition struct {
 	Const       any                    `json:"const"`
 	XSide       string                 `json:"x-side"`
 	XMethod     string                 `json:"x-method"`
+	// Deprecated holds the x-deprecated note, when present. Generators emit it
+	// as a "Deprecated:" paragraph so Go tooling flags usages.
+	Deprecated string `json:"x-deprecated"`
 	// Default holds the JSON Schema default value, when present.
 	// Used by generators to synthesize defaulting behavior.
 	Default any `json:"default"`
@@ -54,10 +60,34 @@ type Definition struct {
 	// Part of JSON Schema's discriminator object support.
 	Discriminator *Discriminator `json:"discriminator,omitempty"`
 
+	// Minimum and Maximum hold inclusive numeric bounds, when present.
+	Minimum *float64 `json:"minimum,omitempty"`
+	Maximum *float64 `json:"maximum,omitempty"`
+	// MinLength and MaxLength bound string length in characters, when present.
+	MinLength *int `json:"minLength,omitempty"`
+	MaxLength *int `json:"maxLength,omitempty"`
+	// Pattern is an ECMA-262 regular expression strings must match, when present.
+	Pattern string `json:"pattern,omitempty"`
+	// AdditionalProperties is the schema for undeclared object keys: a boolean
+	// schema for true/false, or a value schema for typed maps.
+	AdditionalProperties *Definition `json:"additionalProperties,omitempty"`
+
 	// boolSchema records whether this definition was a boolean schema (true/false).
 	// JSON Schema allows boolean schemas, where true matches anything and false matches nothing.
 	// We ignore the semantic difference in codegen and treat both as permissive/unknown shapes.
 	boolSchema *bool `json:"-"`
+	// importedFrom records the file an externally referenced definition was
+	// inlined from. Empty for definitions declared in the root schema.
+	importedFrom string
+}
+
+// BoolSchema reports whether d was decoded from a boolean schema, and if so
+// its value.
+func (d *Definition) BoolSchema() (value, ok bool) {
+	if d == nil || d.boolSchema == nil {
+		return false, false
+	}
+	return *d.boolSchema, true
 }
 
 // UnmarshalJSON allows Definition to decode both object and boolean JSON Schema forms.
@@ -91,10 +121,18 @@ func ReadMeta(schemaDir string) (*Meta, error) {
 	if err := json.Unmarshal(metaBytes, &meta); err != nil {
 		return nil, fmt.Errorf("parse meta.json: %w", err)
 	}
+	versionBytes, err := os.ReadFile(filepath.Join(schemaDir, "version"))
+	switch {
+	case err == nil:
+		meta.SchemaVersion = string(bytes.TrimSpace(versionBytes))
+	case !os.IsNotExist(err):
+		return nil, fmt.Errorf("read version: %w", err)
+	}
 	return &meta, nil
 }
 
-// ReadSchema loads schema/schema.json.
+// ReadSchema loads schema/schema.json, inlining any definitions it references
+// from other files (see resolveExternalRefs).
 func ReadSchema(schemaDir string) (*Schema, error) {
 	schemaBytes, err := os.ReadFile(filepath.Join(schemaDir, "schema.json"))
 	if err != nil {
@@ -104,6 +142,9 @@ func ReadSchema(schemaDir string) (*Schema, error) {
 	if err := json.Unmarshal(schemaBytes, &schema); err != nil {
 		return nil, fmt.Errorf("parse schema.json: %w", err)
 	}
+	if err := resolveExternalRefs(&schema, filepath.Join(schemaDir, "schema.json")); err != nil {
+		return nil, fmt.Errorf("schema.json: %w", err)
+	}
 	return &schema, nil
 }
 
@@ -138,5 +179,8 @@ func ReadSchemaUnstable(schemaDir string) (*Schema, bool, error) {
 	if err := json.Unmarshal(schemaBytes, &schema); err != nil {
 		return nil, true, fmt.Errorf("parse schema.unstable.json: %w", err)
 	}
+	if err := resolveExternalRefs(&schema, filepath.Join(schemaDir, "schema.unstable.json")); err != nil {
+		return nil, true, fmt.Errorf("schema.unstable.json: %w", err)
+	}
 	return &schema, true, nil
 }
@@ -40,6 +40,7 @@ func MergeStableAndUnstable(stableMeta *Meta, stableSchema *Schema, unstableMeta
 
 	combinedMeta := &Meta{
 		Version:         stableMeta.Version,
+		SchemaVersion:   stableMeta.SchemaVersion,
 		AgentMethods:    cloneStringMap(stableMeta.AgentMethods),
 		ClientMethods:   cloneStringMap(stableMeta.ClientMethods),
 		ProtocolMethods: cloneStringMap(stableMeta.ProtocolMethods),
@@ -386,6 +387,7 @@ func visitDefinition(root *Definition, fn func(*Definition)) {
 		for _, v := range d.AllOf {
 			walk(v)
 		}
+		walk(d.AdditionalProperties)
 	}
 	walk(root)
 }
@@ -416,6 +418,7 @@ func deepCopyDefinition(d *Definition) *Definition {
 		}
 	}
 	copyDef.Items = deepCopyDefinition(d.Items)
+	copyDef.AdditionalProperties = deepCopyDefinition(d.AdditionalProperties)
 	if d.AnyOf != nil {
 		copyDef.AnyOf = make([]*Definition, len(d.AnyOf))
 		for i, v := range d.AnyOf {
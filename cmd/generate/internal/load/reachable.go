@@ -0,0 +1,65 @@
+package load
+
+import "sort"
+
+// envelopeRoots are the JSON-RPC message envelopes. They carry no x-method but
+// are the entry points for every method's params and results.
+var envelopeRoots = []string{
+	"AgentNotification", "AgentRequest", "AgentResponse",
+	"ClientNotification", "ClientRequest", "ClientResponse",
+}
+
+// UnreferencedDefinitions returns, in sorted order, the names of definitions
+// that cannot be reached through $refs from any RPC root: a definition with an
+// x-method, or one of the JSON-RPC envelopes. Such definitions are dead weight
+// in the generated code and often indicate schema drift.
+func UnreferencedDefinitions(schema *Schema) []string {
+	if schema == nil {
+		return nil
+	}
+	seen := map[string]struct{}{}
+	queue := []string{}
+	for name, def := range schema.Defs {
+		if def != nil && def.XMethod != "" {
+			seen[name] = struct{}{}
+			queue = append(queue, name)
+		}
+	}
+	for _, name := range envelopeRoots {
+		if _, ok := schema.Defs[name]; ok {
+			if _, dup := seen[name]; !dup {
+				seen[name] = struct{}{}
+				queue = append(queue, name)
+			}
+		}
+	}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		for ref := range collectDefinitionRefs(schema.Defs[name]) {
+			if _, ok := seen[ref]; ok {
+				continue
+			}
+			if _, ok := schema.Defs[ref]; !ok {
+				continue
+			}
+			seen[ref] = struct{}{}
+			queue = append(queue, ref)
+		}
+	}
+	var out []string
+	for name := range schema.Defs {
+		if _, ok := seen[name]; !ok {
+			out = append(out, name)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// PruneDefinitions removes the named definitions from schema.
+func PruneDefinitions(schema *Schema, names []string) {
+	for _, name := range names {
+		delete(schema.Defs, name)
+	}
+}
@@ -0,0 +1,28 @@
+package load
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUnreferencedDefinitions(t *testing.T) {
+	schema := &Schema{Defs: map[string]*Definition{
+		"FooRequest": {XMethod: "foo", Properties: map[string]*Definition{"bar": ref("Bar")}},
+		"Bar":        {AnyOf: []*Definition{ref("Baz")}},
+		"Baz":        {Type: "string"},
+		"AgentRequest": {Properties: map[string]*Definition{
+			"params": {AnyOf: []*Definition{ref("FooRequest"), ref("ExtRequest")}},
+		}},
+		"ExtRequest":  {},
+		"Orphan":      {Properties: map[string]*Definition{"x": ref("OrphanChild")}},
+		"OrphanChild": {Type: "string"},
+	}}
+	dead := UnreferencedDefinitions(schema)
+	if want := []string{"Orphan", "OrphanChild"}; !reflect.DeepEqual(dead, want) {
+		t.Fatalf("UnreferencedDefinitions = %v, want %v", dead, want)
+	}
+	PruneDefinitions(schema, dead)
+	if len(schema.Defs) != 5 || UnreferencedDefinitions(schema) != nil {
+		t.Fatalf("unexpected defs after prune: %v", schema.Defs)
+	}
+}
@@ -0,0 +1,162 @@
+package load
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+const localDefsPrefix = "#/$defs/"
+
+// externalRefs resolves file-relative $refs (e.g. "common.json#/$defs/Foo")
+// by loading the referenced files and inlining their definitions into the root
+// schema. Imported definitions are prefixed with the file's base name
+// ("CommonFoo") so they cannot collide with root definitions.
+type externalRefs struct {
+	root   *Schema
+	rootFS string // cleaned path of the root schema file
+	files  map[string]*Schema
+}
+
+// resolveExternalRefs rewrites every external $ref reachable from schema into a
+// local "#/$defs/" ref, importing the referenced definitions (and anything they
+// reference in turn) into schema.Defs.
+func resolveExternalRefs(schema *Schema, path string) error {
+	r := &externalRefs{
+		root:   schema,
+		rootFS: filepath.Clean(path),
+		files:  map[string]*Schema{},
+	}
+	// Snapshot names first; imports add to schema.Defs while we walk.
+	names := make([]string, 0, len(schema.Defs))
+	for name := range schema.Defs {
+		names = append(names, name)
+	}
+	for _, name := range names {
+		if err := r.rewrite(schema.Defs[name], r.rootFS); err != nil {
+			return fmt.Errorf("resolve refs in %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// rewrite points every ref under root, as seen from file, at a root-level
+// definition, importing external definitions as needed.
+func (r *externalRefs) rewrite(root *Definition, file string) error {
+	var err error
+	visitDefinition(root, func(d *Definition) {
+		if err != nil || d == nil || d.Ref == "" {
+			return
+		}
+		// Local refs in the root schema are already resolved.
+		if file == r.rootFS && strings.HasPrefix(d.Ref, "#") {
+			return
+		}
+		target, name, perr := splitRef(d.Ref, file)
+		if perr != nil {
+			err = perr
+			return
+		}
+		if target == r.rootFS {
+			d.Ref = localDefsPrefix + name
+			return
+		}
+		key, ierr := r.importDef(target, name)
+		if ierr != nil {
+			err = ierr
+			return
+		}
+		d.Ref = localDefsPrefix + key
+	})
+	return err
+}
+
+// importDef copies definition name from file into the root schema and returns
+// its prefixed name there.
+func (r *externalRefs) importDef(file, name string) (string, error) {
+	key := refPrefix(file) + name
+	if existing, ok := r.root.Defs[key]; ok {
+		if existing != nil && existing.importedFrom == file {
+			return key, nil
+		}
+		return "", fmt.Errorf("%s#/$defs/%s: imported name %q collides with an existing definition", filepath.Base(file), name, key)
+	}
+	s, err := r.load(file)
+	if err != nil {
+		return "", err
+	}
+	def, ok := s.Defs[name]
+	if !ok || def == nil {
+		return "", fmt.Errorf("%s: no definition %q", filepath.Base(file), name)
+	}
+	def = deepCopyDefinition(def)
+	def.importedFrom = file
+	if r.root.Defs == nil {
+		r.root.Defs = map[string]*Definition{}
+	}
+	// Register before rewriting so self-referential definitions terminate.
+	r.root.Defs[key] = def
+	if err := r.rewrite(def, file); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+func (r *externalRefs) load(file string) (*Schema, error) {
+	if s, ok := r.files[file]; ok {
+		return s, nil
+	}
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", filepath.Base(file), err)
+	}
+	var s Schema
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", filepath.Base(file), err)
+	}
+	r.files[file] = &s
+	return &s, nil
+}
+
+// splitRef resolves ref relative to the file it appears in, returning the
+// target file's cleaned path and the definition name.
+func splitRef(ref, from string) (string, string, error) {
+	filePart, frag, ok := strings.Cut(ref, "#")
+	if !ok || !strings.HasPrefix("#"+frag, localDefsPrefix) {
+		return "", "", fmt.Errorf("unsupported $ref %q: only <file>#/$defs/<name> is supported", ref)
+	}
+	name := strings.TrimPrefix("#"+frag, localDefsPrefix)
+	if name == "" {
+		return "", "", fmt.Errorf("unsupported $ref %q: missing definition name", ref)
+	}
+	if filePart == "" {
+		return from, name, nil
+	}
+	if filepath.IsAbs(filePart) {
+		return filepath.Clean(filePart), name, nil
+	}
+	return filepath.Join(filepath.Dir(from), filePart), name, nil
+}
+
+// refPrefix derives an exported identifier prefix from a schema file name,
+// e.g. "common-types.json" -> "CommonTypes".
+func refPrefix(file string) string {
+	base := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	var b strings.Builder
+	upper := true
+	for _, c := range base {
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) {
+			upper = true
+			continue
+		}
+		if upper {
+			c = unicode.ToUpper(c)
+			upper = false
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
@@ -0,0 +1,93 @@
+package load
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeSchemaFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, body := range files {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestReadSchemaResolvesExternalRefs(t *testing.T) {
+	dir := writeSchemaFiles(t, map[string]string{
+		"schema.json": `{"$defs": {
+			"Foo": {"type": "object", "properties": {"bar": {"$ref": "common/common-types.json#/$defs/Bar"}}},
+			"Bar": {"type": "string"}
+		}}`,
+		"common/common-types.json": `{"$defs": {
+			"Bar": {"type": "object", "properties": {
+				"baz": {"$ref": "#/$defs/Baz"},
+				"back": {"$ref": "../schema.json#/$defs/Foo"}
+			}},
+			"Baz": {"type": "string"},
+			"Unused": {"type": "string"}
+		}}`,
+	})
+	schema, err := ReadSchema(dir)
+	if err != nil {
+		t.Fatalf("ReadSchema: %v", err)
+	}
+	if got := schema.Defs["Foo"].Properties["bar"].Ref; got != "#/$defs/CommonTypesBar" {
+		t.Fatalf("Foo.bar ref = %q", got)
+	}
+	bar := schema.Defs["CommonTypesBar"]
+	if bar == nil {
+		t.Fatalf("CommonTypesBar not imported; defs: %v", schema.Defs)
+	}
+	if got := bar.Properties["baz"].Ref; got != "#/$defs/CommonTypesBaz" {
+		t.Fatalf("Bar.baz ref = %q", got)
+	}
+	if got := bar.Properties["back"].Ref; got != "#/$defs/Foo" {
+		t.Fatalf("Bar.back ref = %q", got)
+	}
+	if schema.Defs["CommonTypesBaz"] == nil {
+		t.Fatalf("transitively referenced definition not imported")
+	}
+	if _, ok := schema.Defs["CommonTypesUnused"]; ok {
+		t.Fatalf("unreferenced definition imported")
+	}
+	if schema.Defs["Bar"].Ref != "" || schema.Defs["Bar"].Type != "string" {
+		t.Fatalf("root Bar was clobbered: %+v", schema.Defs["Bar"])
+	}
+}
+
+func TestReadSchemaExternalRefErrors(t *testing.T) {
+	for name, files := range map[string]map[string]string{
+		"missing file": {
+			"schema.json": `{"$defs": {"Foo": {"$ref": "nope.json#/$defs/Bar"}}}`,
+		},
+		"missing definition": {
+			"schema.json": `{"$defs": {"Foo": {"$ref": "other.json#/$defs/Bar"}}}`,
+			"other.json":  `{"$defs": {}}`,
+		},
+		"collision": {
+			"schema.json": `{"$defs": {"Foo": {"$ref": "other.json#/$defs/Bar"}, "OtherBar": {"type": "string"}}}`,
+			"other.json":  `{"$defs": {"Bar": {"type": "string"}}}`,
+		},
+		"whole-file ref": {
+			"schema.json": `{"$defs": {"Foo": {"$ref": "other.json"}}}`,
+			"other.json":  `{"$defs": {}}`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := ReadSchema(writeSchemaFiles(t, files))
+			if err == nil || !strings.Contains(err.Error(), "schema.json") {
+				t.Fatalf("expected error, got %v", err)
+			}
+		})
+	}
+}
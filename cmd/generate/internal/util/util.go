@@ -3,6 +3,7 @@
 package util
 
 import (
+	"go/doc/comment"
 	"strings"
 	"unicode"
 )
@@ -20,7 +21,8 @@ func SanitizeComment(s string) string {
 
 // FormatDocComment formats a description as properly structured Go doc comment lines.
 // Preserves paragraph breaks (double newlines) and handles line breaks within paragraphs.
-// Returns slice of comment text without "//" prefix (caller should emit each as a comment line).
+// Returns slice of comment text without "//" prefix (caller should emit each as a comment line),
+// except for code block lines, which keep their "//" so that jen emits them verbatim.
 //
 // Go doc comment conventions:
 //   - Each line of comment text becomes a separate "// line"
@@ -37,11 +39,28 @@ func FormatDocComment(desc string) []string {
 	// Split into lines based on newlines from the JSON schema
 	lines := strings.Split(desc, "\n")
 
+	for i := range lines {
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+
+	// Print the text the way gofmt would reformat it, so that formatting the
+	// generated code never rewrites its comments (for example, a lone capitalized
+	// line between paragraphs becomes a "# " heading).
+	var p comment.Parser
+	printed := (&comment.Printer{}).Comment(p.Parse(strings.Join(lines, "\n")))
 	var result []string
-	for _, line := range lines {
-		trimmed := strings.TrimSpace(line)
-		// Preserve blank lines as empty strings - jen will render them as "//"
-		result = append(result, trimmed)
+	for _, line := range strings.Split(strings.TrimSuffix(string(printed), "\n"), "\n") {
+		switch {
+		case line == "//":
+			// Preserve blank lines as empty strings - jen will render them as "//"
+			result = append(result, "")
+		case strings.HasPrefix(line, "// "):
+			result = append(result, line[len("// "):])
+		default:
+			// Code blocks print as "//\t..."; jen emits lines starting with "//"
+			// verbatim.
+			result = append(result, line)
+		}
 	}
 
 	return result
@@ -3,8 +3,10 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/coder/acp-go-sdk/cmd/generate/internal/emit"
 	"github.com/coder/acp-go-sdk/cmd/generate/internal/load"
@@ -13,8 +15,12 @@ import (
 func main() {
 	var schemaDirFlag string
 	var outDirFlag string
+	var includeFlag string
+	var pruneFlag bool
 	flag.StringVar(&schemaDirFlag, "schema", "", "path to schema directory (defaults to <repo>/schema)")
 	flag.StringVar(&outDirFlag, "out", "", "output directory for generated go files (defaults to <repo>)")
+	flag.StringVar(&includeFlag, "include", includeBoth, "which schema surface to generate: stable, unstable (build-tagged alongside stable) or both (merged)")
+	flag.BoolVar(&pruneFlag, "prune-unreferenced", false, "omit $defs not reachable from any RPC method instead of only warning about them")
 	flag.Parse()
 
 	repoRoot := findRepoRoot()
@@ -27,55 +33,209 @@ func main() {
 		outDir = repoRoot
 	}
 
-	if err := os.MkdirAll(outDir, 0o755); err != nil {
+	opts := options{include: includeFlag, pruneUnreferenced: pruneFlag, warnings: os.Stderr}
+	if err := generate(schemaDir, outDir, opts); err != nil {
 		panic(err)
 	}
+}
+
+// Values accepted by the -include flag.
+const (
+	includeStable   = "stable"
+	includeUnstable = "unstable"
+	includeBoth     = "both"
+)
 
+// unstableBuildTag selects the merged stable+unstable surface when generating
+// with -include=unstable.
+const unstableBuildTag = "acp_unstable"
+
+// generatedFiles lists the files written by writeGenerated, in emit order.
+var generatedFiles = []string{
+	"constants_gen.go",
+	"types_gen.go",
+	"agent_gen.go",
+	"client_gen.go",
+	"unimplemented_gen.go",
+	"methods_manifest_gen.go",
+	"helpers_gen.go",
+	"fuzz_gen_test.go",
+}
+
+// options configures generate.
+type options struct {
+	// include selects the schema surface; see generate.
+	include string
+	// pruneUnreferenced omits definitions unreachable from any RPC root
+	// instead of only warning about them.
+	pruneUnreferenced bool
+	// warnings receives non-fatal diagnostics. Nil discards them.
+	warnings io.Writer
+}
+
+// generate loads the schema from schemaDir and writes the generated Go files to
+// outDir. opts.include selects the surface:
+//
+//   - "stable" ignores the unstable schema.
+//   - "both" merges the unstable schema into the stable one (the default).
+//   - "unstable" writes the stable surface to *_gen.go behind
+//     "//go:build !acp_unstable" and the merged surface to *_unstable_gen.go
+//     behind "//go:build acp_unstable".
+func generate(schemaDir, outDir string, opts options) error {
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		return err
+	}
+	switch include := opts.include; include {
+	case includeStable, includeBoth:
+		if err := removeUnstableVariants(outDir); err != nil {
+			return err
+		}
+		meta, schema, err := loadSchema(schemaDir, include == includeBoth)
+		if err != nil {
+			return err
+		}
+		checkUnreferenced(schema, opts)
+		return writeGenerated(outDir, meta, schema)
+	case includeUnstable:
+		meta, schema, err := loadSchema(schemaDir, false)
+		if err != nil {
+			return err
+		}
+		checkUnreferenced(schema, opts)
+		if err := writeTagged(outDir, meta, schema, "!"+unstableBuildTag, ""); err != nil {
+			return err
+		}
+		meta, schema, err = loadSchema(schemaDir, true)
+		if err != nil {
+			return err
+		}
+		checkUnreferenced(schema, opts)
+		return writeTagged(outDir, meta, schema, unstableBuildTag, "_unstable")
+	default:
+		return fmt.Errorf("invalid -include %q: want %s, %s or %s", include, includeStable, includeUnstable, includeBoth)
+	}
+}
+
+// checkUnreferenced reports definitions unreachable from any RPC root, and
+// drops them from schema when opts.pruneUnreferenced is set.
+func checkUnreferenced(schema *load.Schema, opts options) {
+	dead := load.UnreferencedDefinitions(schema)
+	if len(dead) == 0 {
+		return
+	}
+	if opts.pruneUnreferenced {
+		load.PruneDefinitions(schema, dead)
+		return
+	}
+	if opts.warnings != nil {
+		fmt.Fprintf(opts.warnings, "warning: %d unreferenced $defs (use -prune-unreferenced to omit): %s\n", len(dead), strings.Join(dead, ", "))
+	}
+}
+
+// loadSchema reads the stable schema from schemaDir and, when merge is set and
+// the unstable files exist, merges the unstable schema into it.
+func loadSchema(schemaDir string, merge bool) (*load.Meta, *load.Schema, error) {
 	meta, err := load.ReadMeta(schemaDir)
 	if err != nil {
-		panic(err)
+		return nil, nil, err
 	}
-
 	schema, err := load.ReadSchema(schemaDir)
 	if err != nil {
-		panic(err)
+		return nil, nil, err
+	}
+	if !merge {
+		return meta, schema, nil
 	}
 
 	unstableMeta, unstableMetaFound, err := load.ReadMetaUnstable(schemaDir)
 	if err != nil {
-		panic(err)
+		return nil, nil, err
 	}
 	unstableSchema, unstableSchemaFound, err := load.ReadSchemaUnstable(schemaDir)
 	if err != nil {
-		panic(err)
+		return nil, nil, err
 	}
 	if unstableMetaFound != unstableSchemaFound {
-		panic(fmt.Sprintf("unstable schema/meta mismatch: meta found=%v schema found=%v", unstableMetaFound, unstableSchemaFound))
+		return nil, nil, fmt.Errorf("unstable schema/meta mismatch: meta found=%v schema found=%v", unstableMetaFound, unstableSchemaFound)
 	}
-	if unstableMetaFound {
-		mergedMeta, mergedSchema, err := load.MergeStableAndUnstable(meta, schema, unstableMeta, unstableSchema)
-		if err != nil {
-			panic(err)
-		}
-		meta = mergedMeta
-		schema = mergedSchema
+	if !unstableMetaFound {
+		return meta, schema, nil
 	}
+	return load.MergeStableAndUnstable(meta, schema, unstableMeta, unstableSchema)
+}
 
+// writeGenerated emits every generated file into outDir.
+func writeGenerated(outDir string, meta *load.Meta, schema *load.Schema) error {
 	if err := emit.WriteConstantsJen(outDir, meta); err != nil {
-		panic(err)
+		return err
 	}
 
 	if err := emit.WriteTypesJen(outDir, schema, meta); err != nil {
-		panic(err)
+		return err
 	}
 	if err := emit.WriteDispatchJen(outDir, schema, meta); err != nil {
-		panic(err)
+		return err
+	}
+	if err := emit.WriteUnimplementedJen(outDir, schema, meta); err != nil {
+		return err
+	}
+	if err := emit.WriteManifestJen(outDir, schema, meta); err != nil {
+		return err
 	}
 
 	// Emit helpers after types so they can reference generated structs.
 	if err := emit.WriteHelpersJen(outDir, schema, meta); err != nil {
-		panic(err)
+		return err
+	}
+	return emit.WriteFuzzJen(outDir, schema, meta)
+}
+
+// writeTagged emits the generated files into outDir behind a build constraint,
+// inserting suffix before each file's "_gen.go".
+func writeTagged(outDir string, meta *load.Meta, schema *load.Schema, tag, suffix string) error {
+	tmp, err := os.MkdirTemp("", "acp-generate-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+	if err := writeGenerated(tmp, meta, schema); err != nil {
+		return err
+	}
+	for _, name := range generatedFiles {
+		b, err := os.ReadFile(filepath.Join(tmp, name))
+		if err != nil {
+			return err
+		}
+		b = append([]byte("//go:build "+tag+"\n\n"), b...)
+		out := taggedName(name, suffix)
+		if err := os.WriteFile(filepath.Join(outDir, out), b, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// taggedName inserts suffix before the "_gen" marker of a generated file name,
+// e.g. "types_gen.go" -> "types_unstable_gen.go" and "fuzz_gen_test.go" ->
+// "fuzz_unstable_gen_test.go".
+func taggedName(name, suffix string) string {
+	if base, ok := strings.CutSuffix(name, "_gen_test.go"); ok {
+		return base + suffix + "_gen_test.go"
+	}
+	return strings.TrimSuffix(name, "_gen.go") + suffix + "_gen.go"
+}
+
+// removeUnstableVariants deletes *_unstable_gen.go files left over from a
+// previous -include=unstable run, which would otherwise duplicate declarations
+// under the acp_unstable tag.
+func removeUnstableVariants(outDir string) error {
+	for _, name := range generatedFiles {
+		p := filepath.Join(outDir, taggedName(name, "_unstable"))
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return err
+		}
 	}
+	return nil
 }
 
 func findRepoRoot() string {
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestGenerateDeterministic regenerates from the repository schema several
+// times and requires byte-identical output, so map iteration order can never
+// leak into the generated files.
+func TestGenerateDeterministic(t *testing.T) {
+	schemaDir := filepath.Join("..", "..", "schema")
+	first := t.TempDir()
+	if err := generate(schemaDir, first, options{include: includeBoth}); err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	entries, err := os.ReadDir(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) == 0 {
+		t.Fatalf("generate wrote no files")
+	}
+	for run := 0; run < 3; run++ {
+		dir := t.TempDir()
+		if err := generate(schemaDir, dir, options{include: includeBoth}); err != nil {
+			t.Fatalf("generate: %v", err)
+		}
+		for _, e := range entries {
+			want, err := os.ReadFile(filepath.Join(first, e.Name()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := os.ReadFile(filepath.Join(dir, e.Name()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Fatalf("%s differs between runs", e.Name())
+			}
+		}
+	}
+}
+
+func TestGenerateInclude(t *testing.T) {
+	schemaDir := filepath.Join("..", "..", "schema")
+
+	stable := t.TempDir()
+	if err := generate(schemaDir, stable, options{include: includeStable}); err != nil {
+		t.Fatalf("generate stable: %v", err)
+	}
+	types, err := os.ReadFile(filepath.Join(stable, "types_gen.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(types, []byte("type Unstable")) {
+		t.Fatalf("stable output contains unstable types")
+	}
+
+	// Switching to -include=unstable in the same directory tags both surfaces;
+	// switching back removes the tagged variants.
+	if err := generate(schemaDir, stable, options{include: includeUnstable}); err != nil {
+		t.Fatalf("generate unstable: %v", err)
+	}
+	for _, name := range generatedFiles {
+		variant := taggedName(name, "_unstable")
+		for file, tag := range map[string]string{name: "//go:build !acp_unstable\n", variant: "//go:build acp_unstable\n"} {
+			b, err := os.ReadFile(filepath.Join(stable, file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.HasPrefix(b, []byte(tag)) {
+				t.Fatalf("%s does not start with %q", file, tag)
+			}
+		}
+	}
+	if err := generate(schemaDir, stable, options{include: includeBoth}); err != nil {
+		t.Fatalf("generate both: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(stable, "types_unstable_gen.go")); !os.IsNotExist(err) {
+		t.Fatalf("stale unstable variant left behind: %v", err)
+	}
+
+	if err := generate(schemaDir, t.TempDir(), options{include: "experimental"}); err == nil {
+		t.Fatalf("expected error for invalid -include")
+	}
+}
@@ -0,0 +1,29 @@
+package acp
+
+import "encoding/json"
+
+// Codec encodes and decodes JSON-RPC messages and their payloads. Implementations
+// must be compatible with encoding/json: they must honor struct tags and the
+// json.Marshaler, json.Unmarshaler, and json.RawMessage types.
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// jsonCodec is the default Codec, backed by encoding/json.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error) { return json.Marshal(v) }
+
+func (jsonCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+// WithCodec replaces encoding/json for message envelopes, outbound params, inbound
+// results, and handler results. Request ids are always canonicalized with
+// encoding/json so that numeric ids compare exactly.
+func WithCodec(codec Codec) ConnectionOption {
+	return func(c *Connection) {
+		if codec != nil {
+			c.codec = codec
+		}
+	}
+}
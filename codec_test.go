@@ -0,0 +1,90 @@
+package acp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingCodec delegates to encoding/json while counting calls.
+type countingCodec struct {
+	marshals   atomic.Int64
+	unmarshals atomic.Int64
+}
+
+func (cc *countingCodec) Marshal(v any) ([]byte, error) {
+	cc.marshals.Add(1)
+	return json.Marshal(v)
+}
+
+func (cc *countingCodec) Unmarshal(data []byte, v any) error {
+	cc.unmarshals.Add(1)
+	return json.Unmarshal(data, v)
+}
+
+func newCodecTestPair(tb testing.TB, codec Codec) *Connection {
+	tb.Helper()
+	c2aR, c2aW := io.Pipe()
+	a2cR, a2cW := io.Pipe()
+	tb.Cleanup(func() {
+		_ = c2aR.Close()
+		_ = c2aW.Close()
+		_ = a2cR.Close()
+		_ = a2cW.Close()
+	})
+
+	_ = NewConnection(func(ctx context.Context, method string, params json.RawMessage) (any, *RequestError) {
+		return map[string]string{"echo": method}, nil
+	}, a2cW, c2aR)
+	return NewConnection(nil, c2aW, a2cR, WithCodec(codec))
+}
+
+func TestWithCodec_UsedForRequestsAndResponses(t *testing.T) {
+	codec := &countingCodec{}
+	c := newCodecTestPair(t, codec)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	got, err := SendRequest[map[string]string](c, ctx, "hello", map[string]int{"n": 1})
+	if err != nil {
+		t.Fatalf("SendRequest: %v", err)
+	}
+	if got["echo"] != "hello" {
+		t.Fatalf("unexpected result: %v", got)
+	}
+
+	// params + envelope on the way out; envelope + result on the way back.
+	if m, u := codec.marshals.Load(), codec.unmarshals.Load(); m != 2 || u != 2 {
+		t.Fatalf("codec calls = %d marshal, %d unmarshal; want 2 and 2", m, u)
+	}
+}
+
+func BenchmarkSendRequest_Codec(b *testing.B) {
+	for _, bc := range []struct {
+		name  string
+		codec Codec
+	}{
+		{"stdlib", jsonCodec{}},
+		{"counting", &countingCodec{}},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			c := newCodecTestPair(b, bc.codec)
+			ctx := context.Background()
+			params := map[string]any{"path": "/tmp/file.txt", "line": 1, "limit": 100}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := SendRequest[map[string]string](c, ctx, "fs/read_text_file", params); err != nil {
+					b.Fatalf("SendRequest: %v", err)
+				}
+			}
+			b.StopTimer()
+			if cc, ok := bc.codec.(*countingCodec); ok {
+				b.ReportMetric(float64(cc.marshals.Load()+cc.unmarshals.Load())/float64(b.N), "codec-calls/op")
+			}
+		})
+	}
+}
@@ -6,8 +6,13 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"log/slog"
+	"os"
+	"runtime/debug"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -17,9 +22,31 @@ import (
 const (
 	notificationQueueDrainTimeout = 5 * time.Second
 	defaultMaxQueuedNotifications = 1024
+	recentResponsesSize           = 256
 )
 
-var errNotificationQueueOverflow = errors.New("notification queue overflow")
+// Causes reported by Connection.Err once a connection has shut down.
+var (
+	// ErrPeerClosed indicates that the peer's output stream ended or failed.
+	ErrPeerClosed = errors.New("peer connection closed")
+	// ErrConnectionClosed indicates that Close or Shutdown was called.
+	ErrConnectionClosed = errors.New("connection closed")
+	// ErrNotificationQueueOverflow indicates that the peer sent notifications
+	// faster than they could be handled.
+	ErrNotificationQueueOverflow = errors.New("notification queue overflow")
+	// ErrWriteTimeout indicates that a write exceeded the WithWriteTimeout limit.
+	ErrWriteTimeout = errors.New("write to peer timed out")
+	// ErrKeepAliveTimeout indicates that the peer stopped answering keepalive pings.
+	ErrKeepAliveTimeout = errors.New("keepalive timed out")
+)
+
+// ErrHandlerTimeout is the context cause seen by a request handler that has run
+// for longer than the WithHandlerTimeout limit.
+var ErrHandlerTimeout = errors.New("request handler timed out")
+
+// keepAlivePingMethod is the extension method used for connection keepalives.
+// Connections answer it automatically without consulting the handler.
+const keepAlivePingMethod = "_ping"
 
 type anyMessage struct {
 	JSONRPC string           `json:"jsonrpc"`
@@ -31,8 +58,9 @@ type anyMessage struct {
 }
 
 type queuedNotification struct {
-	seq uint64
-	msg *anyMessage
+	seq     uint64
+	session string
+	msg     *anyMessage
 }
 
 type responseEnvelope struct {
@@ -42,6 +70,15 @@ type responseEnvelope struct {
 
 type pendingResponse struct {
 	ch chan responseEnvelope
+	// session is the sessionId the request carried, if any. The response to a
+	// session-scoped request only waits for notifications of that session and
+	// for notifications that belong to no session.
+	session string
+}
+
+type readResult struct {
+	msg []byte
+	err error
 }
 
 type cancelRequestParams struct {
@@ -51,18 +88,35 @@ type cancelRequestParams struct {
 type MethodHandler func(ctx context.Context, method string, params json.RawMessage) (any, *RequestError)
 
 // Connection is a simple JSON-RPC 2.0 connection over line-delimited JSON.
+// Content-Length framing can be selected with WithFraming.
 type Connection struct {
 	w       io.Writer
 	r       io.Reader
 	handler MethodHandler
 
+	// streamCloser is the stream owned by connections created from an
+	// io.ReadWriteCloser. It is closed once, when the connection shuts down.
+	streamCloser    io.Closer
+	closeStreamOnce sync.Once
+	closeStreamErr  error
+
 	mu                   sync.Mutex
 	writeMu              sync.Mutex
 	nextID               atomic.Uint64
 	pending              map[string]*pendingResponse
-	inflight             map[string]context.CancelCauseFunc
+	inflight             map[string]*inflightRequest
 	pendingCancelRequest []string
 	cancelRequestSignal  chan struct{}
+	// cancelQueueSize bounds pendingCancelRequest; cancelQueueSpace is signaled
+	// whenever sendCancelRequests makes room in it.
+	cancelQueueSize  int
+	cancelQueueSpace chan struct{}
+	// suppressCancelRequests stops sendCancelRequest from notifying the peer.
+	suppressCancelRequests bool
+	// recentResponses is a ring of recently answered request ids (guarded by mu),
+	// used to tell duplicate responses apart from responses to unknown ids.
+	recentResponses    [recentResponsesSize]string
+	recentResponsesPos int
 
 	// ctx/cancel govern connection lifetime and are used for Done() and for canceling
 	// callers waiting on responses when the peer disconnects.
@@ -85,13 +139,86 @@ type Connection struct {
 	// invariant: completedNotificationSeq <= lastEnqueuedNotificationSeq.
 	lastEnqueuedNotificationSeq uint64
 	completedNotificationSeq    uint64
+	// lastSessionNotificationSeq maps a sessionId ("" for notifications without
+	// one) to the sequence of its latest queued notification that has not yet
+	// completed. Entries are removed once that notification completes.
+	lastSessionNotificationSeq map[string]uint64
+	// notificationHighWaterMark is the largest number of notifications that
+	// have been queued but not yet completed at once.
+	notificationHighWaterMark uint64
+	// handlingNotification is the method of the notification being handled, if
+	// any (guarded by notifyMu).
+	handlingNotification string
+
+	// capabilityEnforcement enables outboundCapabilityCheck; see
+	// WithCapabilityEnforcement.
+	capabilityEnforcement   bool
+	outboundCapabilityCheck func(method string) error
+
+	// barrierWatchdog, when positive, bounds how long a response waits for
+	// earlier notifications before a warning is logged; see
+	// WithNotificationBarrierWatchdog.
+	barrierWatchdog     time.Duration
+	barrierWatchdogFail bool
 
 	// notificationQueue serializes notification processing to maintain order.
 	// It is bounded to keep memory usage predictable.
 	notificationQueue chan queuedNotification
+	// notificationBackpressure makes the reader wait for queue space instead of
+	// closing the connection when notificationQueue is full.
+	notificationBackpressure bool
+
+	codec                 Codec
+	framing               Framing
+	initialMessageBufSize int
+	maxMessageBytes       int
+
+	writeTimeout time.Duration
+	// writeErr is set (under writeMu) once a write times out. A timed out write may
+	// still be in progress, so no further writes are attempted.
+	writeErr error
+
+	coalesceDelay time.Duration
+	coalesceBytes int
+	// bw buffers outbound messages when write coalescing is enabled. It and
+	// flushScheduled are guarded by writeMu.
+	bw             *bufio.Writer
+	flushScheduled bool
+
+	requestTimeout  time.Duration
+	handlerTimeout  time.Duration
+	outboundLimiter *tokenBucket
+	// draining is set by Shutdown to reject new inbound requests.
+	draining atomic.Bool
+	// requestSlots limits concurrently running inbound request handlers when non-nil.
+	requestSlots chan struct{}
+	// timedOutHandlers counts handlers still running after their request was
+	// answered with a WithHandlerTimeout error.
+	timedOutHandlers atomic.Int64
+
+	keepAliveInterval time.Duration
+	keepAliveTimeout  time.Duration
+
+	observer   Observer
+	propagator Propagator
+	tracer     Tracer
+	wireTap    WireTap
+
+	protocolErrorHandler func(ProtocolError)
+
+	inboundMiddleware  []InboundMiddleware
+	outboundMiddleware []OutboundMiddleware
+
+	extensionNamespaces []string
 }
 
-func NewConnection(handler MethodHandler, peerInput io.Writer, peerOutput io.Reader) *Connection {
+// writeDeadliner is implemented by writers such as net.Conn that support
+// write deadlines natively.
+type writeDeadliner interface {
+	SetWriteDeadline(t time.Time) error
+}
+
+func NewConnection(handler MethodHandler, peerInput io.Writer, peerOutput io.Reader, opts ...ConnectionOption) *Connection {
 	ctx, cancel := context.WithCancelCause(context.Background())
 	inboundCtx, inboundCancel := context.WithCancelCause(context.Background())
 	c := &Connection{
@@ -99,13 +226,30 @@ func NewConnection(handler MethodHandler, peerInput io.Writer, peerOutput io.Rea
 		r:                   peerOutput,
 		handler:             handler,
 		pending:             make(map[string]*pendingResponse),
-		inflight:            make(map[string]context.CancelCauseFunc),
+		inflight:            make(map[string]*inflightRequest),
 		cancelRequestSignal: make(chan struct{}, 1),
+		cancelQueueSize:     maxPendingCancelRequests,
+		cancelQueueSpace:    make(chan struct{}, 1),
 		ctx:                 ctx,
 		cancel:              cancel,
 		inboundCtx:          inboundCtx,
 		inboundCancel:       inboundCancel,
 		notificationQueue:   make(chan queuedNotification, defaultMaxQueuedNotifications),
+
+		lastSessionNotificationSeq: make(map[string]uint64),
+
+		codec:                 jsonCodec{},
+		initialMessageBufSize: defaultInitialMessageBufSize,
+		maxMessageBytes:       defaultMaxMessageBytes,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.handler != nil {
+		c.handler = chainInbound(c.handler, c.inboundMiddleware)
+	}
+	if c.coalesceBytes > 0 {
+		c.bw = bufio.NewWriterSize(writerFunc(c.write), c.coalesceBytes)
 	}
 	c.notifyCond = sync.NewCond(&c.notifyMu)
 	go func() {
@@ -114,14 +258,25 @@ func NewConnection(handler MethodHandler, peerInput io.Writer, peerOutput io.Rea
 		c.notifyCond.Broadcast()
 		c.notifyMu.Unlock()
 	}()
+	if c.streamCloser != nil {
+		go func() {
+			<-c.ctx.Done()
+			_ = c.closeStream()
+		}()
+	}
 	go c.sendCancelRequests()
 	go c.receive()
 	go c.processNotifications()
+	if c.keepAliveInterval > 0 {
+		go c.keepAlive()
+	}
 	return c
 }
 
 // SetLogger installs a logger used for internal connection diagnostics.
-// If unset, logs are written via the default logger.
+// If unset, logs are written via the default logger. SetLogger is not
+// synchronized with the connection's goroutines, so it must not be called once
+// the peer may be sending; prefer WithLogger.
 func (c *Connection) SetLogger(l *slog.Logger) { c.logger = l }
 
 func (c *Connection) loggerOrDefault() *slog.Logger {
@@ -131,10 +286,26 @@ func (c *Connection) loggerOrDefault() *slog.Logger {
 	return slog.Default()
 }
 
+// rpcLogger returns a logger whose records carry the request's id and method in
+// an "rpc" group, so that all diagnostics for one request can be correlated. id
+// is nil for notifications.
+func (c *Connection) rpcLogger(id *json.RawMessage, method string) *slog.Logger {
+	if id == nil {
+		return c.loggerOrDefault().With(slog.Group("rpc", "method", method))
+	}
+	idStr := string(*id)
+	// Log string ids without their JSON quotes.
+	_ = json.Unmarshal(*id, &idStr)
+	return c.loggerOrDefault().With(slog.Group("rpc", "id", idStr, "method", method))
+}
+
 const (
 	maxCanonicalJSONRPCIDKeyLen   = 4096
 	maxCanonicalJSONRPCIDAbsExp10 = 4096
 	maxPendingCancelRequests      = 1024
+	// cancelQueueFullWait bounds how long a cancellation waits for room in a full
+	// cancel-request queue before it is dropped.
+	cancelQueueFullWait = 250 * time.Millisecond
 )
 
 var (
@@ -363,110 +534,226 @@ func formatCanonicalJSONRPCNumericID(negative bool, digits string, exp10 int) (s
 	return result, nil
 }
 
-func (c *Connection) receive() {
-	const (
-		initialBufSize = 1024 * 1024
-		maxBufSize     = 10 * 1024 * 1024
-	)
+// readBufferSize returns the initial size of the inbound read buffer, which
+// never exceeds the message size limit.
+func (c *Connection) readBufferSize() int {
+	return min(c.initialMessageBufSize, c.maxMessageBytes)
+}
+
+// readMessages reads from the peer on behalf of receive. Reads are performed on
+// a separate goroutine so that Close can stop receive without closing the
+// underlying reader.
+func (c *Connection) readMessages(out chan<- readResult) {
+	mr := newMessageReader(c.framing, c.r, c.readBufferSize(), c.maxMessageBytes)
+	for {
+		line, err := mr.next()
+		select {
+		case out <- readResult{msg: line, err: err}:
+		case <-c.ctx.Done():
+			return
+		}
+		var tooLarge *messageTooLargeError
+		if err != nil && !errors.As(err, &tooLarge) {
+			return
+		}
+	}
+}
 
-	scanner := bufio.NewScanner(c.r)
-	buf := make([]byte, 0, initialBufSize)
-	scanner.Buffer(buf, maxBufSize)
+func (c *Connection) receive() {
+	results := make(chan readResult)
+	go c.readMessages(results)
 
-	for scanner.Scan() {
-		line := scanner.Bytes()
+	var readErr error
+	for {
+		var line []byte
+		var err error
+		select {
+		case r := <-results:
+			line, err = r.msg, r.err
+		case <-c.ctx.Done():
+			c.shutdownReceive(context.Cause(c.ctx))
+			return
+		}
+		if err != nil {
+			var tooLarge *messageTooLargeError
+			if errors.As(err, &tooLarge) {
+				method, id := peekMessageHeader(tooLarge.prefix)
+				c.reportProtocolError(slog.LevelError, tooLarge.prefix, err, "discarding inbound message that exceeds size limit", "err", err, "limit", tooLarge.limit, "size", tooLarge.size, "method", method, "id", id)
+				continue
+			}
+			if !errors.Is(err, io.EOF) {
+				readErr = err
+			}
+			break
+		}
 		if len(bytes.TrimSpace(line)) == 0 {
 			continue
 		}
+		c.tap(DirectionInbound, line)
+
+		if trimmed := bytes.TrimSpace(line); trimmed[0] == '[' {
+			if err := c.handleBatch(trimmed); err != nil {
+				c.shutdownReceive(err)
+				return
+			}
+			continue
+		}
 
 		var msg anyMessage
-		if err := json.Unmarshal(line, &msg); err != nil {
-			c.loggerOrDefault().Error("failed to parse incoming message", "err", err, "raw", string(line))
+		if err := c.codec.Unmarshal(line, &msg); err != nil {
+			c.reportProtocolError(slog.LevelError, line, err, "failed to parse incoming message", "err", err, "raw", string(line))
 			continue
 		}
 
 		// Handle $/cancel_request notifications synchronously so cancellations take effect
 		// immediately and do not participate in notification ordering.
 		if msg.ID == nil && msg.Method == "$/cancel_request" {
+			c.observeNotification(msg.Method, true)
 			c.handleCancelRequest(&msg)
 			continue
 		}
 
 		switch {
 		case msg.ID != nil && msg.Method == "":
-			c.handleResponse(&msg)
+			c.handleResponse(&msg, line)
 		case msg.Method != "":
+			m := msg
 			if msg.ID != nil {
-				idKey, err := canonicalJSONRPCIDKey(*msg.ID)
-				if err != nil {
-					c.loggerOrDefault().Error("failed to canonicalize inbound request id", "err", err, "id", string(*msg.ID))
-					idKey = string(*msg.ID)
-				}
-				reqCtx, cancel := context.WithCancelCause(c.ctx)
-
-				c.mu.Lock()
-				c.inflight[idKey] = cancel
-				c.mu.Unlock()
-
-				m := msg
-				go func(m *anyMessage, idKey string, reqCtx context.Context, cancel context.CancelCauseFunc) {
-					defer func() {
-						c.mu.Lock()
-						delete(c.inflight, idKey)
-						c.mu.Unlock()
-
-						cancel(nil)
-					}()
-					c.handleInbound(reqCtx, m)
-				}(&m, idKey, reqCtx, cancel)
+				reqCtx, done := c.trackInboundRequest(&m)
+				go func() {
+					defer done()
+					c.handleInbound(reqCtx, &m)
+				}()
 				continue
 			}
-
-			// Queue the notification for sequential processing. The sequence number marks
-			// the response-scoped barrier boundary for requests that observe later responses.
-			m := msg
-			c.notifyMu.Lock()
-			c.lastEnqueuedNotificationSeq++
-			seq := c.lastEnqueuedNotificationSeq
-			select {
-			case c.notificationQueue <- queuedNotification{seq: seq, msg: &m}:
-				c.notifyMu.Unlock()
-			default:
-				if c.lastEnqueuedNotificationSeq != seq {
-					c.notifyMu.Unlock()
-					panic("notification sequence advanced while receive goroutine was queueing")
-				}
-				c.lastEnqueuedNotificationSeq--
-				// invariant: completedNotificationSeq never exceeds the highest accepted enqueue.
-				if c.completedNotificationSeq > c.lastEnqueuedNotificationSeq {
-					c.notifyMu.Unlock()
-					panic("completed notification sequence exceeded enqueued notification sequence")
-				}
-				c.notifyMu.Unlock()
-				c.loggerOrDefault().Error("failed to queue notification; closing connection", "err", errNotificationQueueOverflow, "capacity", cap(c.notificationQueue), "queued", len(c.notificationQueue))
-				c.shutdownReceive(errNotificationQueueOverflow)
+			if err := c.enqueueNotification(&m); err != nil {
+				c.shutdownReceive(err)
 				return
 			}
 		default:
-			c.loggerOrDefault().Error("received message with neither id nor method", "raw", string(line))
+			c.reportProtocolError(slog.LevelError, line, errMessageMissingIDAndMethod, "received message with neither id nor method", "raw", string(line))
 		}
 	}
 
-	cause := errors.New("peer connection closed")
-	if err := scanner.Err(); err != nil {
-		cause = err
+	cause := ErrPeerClosed
+	if readErr != nil {
+		cause = fmt.Errorf("%w: %w", ErrPeerClosed, readErr)
 	}
 	c.shutdownReceive(cause)
 }
 
+// trackInboundRequest registers an inbound request so that it can be cancelled via
+// $/cancel_request or connection shutdown. The returned func must be called once
+// the request has been handled.
+func (c *Connection) trackInboundRequest(msg *anyMessage) (context.Context, func()) {
+	idKey, err := canonicalJSONRPCIDKey(*msg.ID)
+	if err != nil {
+		c.loggerOrDefault().Error("failed to canonicalize inbound request id", "err", err, "id", string(*msg.ID))
+		idKey = string(*msg.ID)
+	}
+	reqCtx, cancel := context.WithCancelCause(c.ctx)
+
+	c.mu.Lock()
+	c.inflight[idKey] = &inflightRequest{id: *msg.ID, method: msg.Method, started: time.Now(), cancel: cancel}
+	c.mu.Unlock()
+
+	return reqCtx, func() {
+		c.mu.Lock()
+		delete(c.inflight, idKey)
+		c.mu.Unlock()
+
+		cancel(nil)
+	}
+}
+
+// inflightRequest is an inbound request whose handler has not yet completed.
+type inflightRequest struct {
+	id      json.RawMessage
+	method  string
+	started time.Time
+	cancel  context.CancelCauseFunc
+}
+
+// enqueueNotification queues a notification for sequential processing. The sequence
+// number marks the response-scoped barrier boundary for requests that observe later
+// responses. It returns a non-nil error if the notification could not be queued, in
+// which case the caller must shut the connection down with that error as the cause.
+func (c *Connection) enqueueNotification(msg *anyMessage) error {
+	session := paramsSessionID(msg.Params)
+	c.notifyMu.Lock()
+	c.lastEnqueuedNotificationSeq++
+	seq := c.lastEnqueuedNotificationSeq
+	prevSessionSeq, hadSessionSeq := c.lastSessionNotificationSeq[session]
+	c.lastSessionNotificationSeq[session] = seq
+	queued := queuedNotification{seq: seq, session: session, msg: msg}
+	select {
+	case c.notificationQueue <- queued:
+		c.notifyMu.Unlock()
+		c.observeNotificationBacklog()
+		return nil
+	default:
+	}
+
+	err := ErrNotificationQueueOverflow
+	if c.notificationBackpressure {
+		// Only the receive goroutine enqueues, so the sequence cannot advance while
+		// notifyMu is released. The lock must be released so that processNotifications
+		// can record completions and free up queue space.
+		c.notifyMu.Unlock()
+		select {
+		case c.notificationQueue <- queued:
+			c.observeNotificationBacklog()
+			return nil
+		case <-c.ctx.Done():
+			err = context.Cause(c.ctx)
+		}
+		c.notifyMu.Lock()
+	}
+
+	if c.lastEnqueuedNotificationSeq != seq {
+		c.notifyMu.Unlock()
+		panic("notification sequence advanced while receive goroutine was queueing")
+	}
+	c.lastEnqueuedNotificationSeq--
+	if hadSessionSeq {
+		c.lastSessionNotificationSeq[session] = prevSessionSeq
+	} else {
+		delete(c.lastSessionNotificationSeq, session)
+	}
+	// invariant: completedNotificationSeq never exceeds the highest accepted enqueue.
+	if c.completedNotificationSeq > c.lastEnqueuedNotificationSeq {
+		c.notifyMu.Unlock()
+		panic("completed notification sequence exceeded enqueued notification sequence")
+	}
+	c.notifyMu.Unlock()
+	if errors.Is(err, ErrNotificationQueueOverflow) {
+		c.loggerOrDefault().Error("failed to queue notification; closing connection", "err", err, "capacity", cap(c.notificationQueue), "queued", len(c.notificationQueue))
+	}
+	return err
+}
+
+// observeNotificationBacklog updates the notification high-water mark after a
+// notification has been queued.
+func (c *Connection) observeNotificationBacklog() {
+	c.notifyMu.Lock()
+	defer c.notifyMu.Unlock()
+	c.notificationHighWaterMark = max(c.notificationHighWaterMark, c.lastEnqueuedNotificationSeq-c.completedNotificationSeq)
+}
+
 func (c *Connection) shutdownReceive(cause error) {
 	if cause == nil {
-		cause = errors.New("connection closed")
+		cause = ErrConnectionClosed
 	}
 
 	// First, signal disconnect to callers waiting on responses.
 	c.cancel(cause)
 
+	c.mu.Lock()
+	for idKey := range c.pending {
+		delete(c.pending, idKey)
+	}
+	c.mu.Unlock()
+
 	// Then close the notification queue so already-received messages can drain.
 	// IMPORTANT: Do not block this receive goroutine waiting for the drain to complete;
 	// notification handlers may legitimately block until their context is canceled.
@@ -494,9 +781,15 @@ func (c *Connection) shutdownReceive(cause error) {
 // It terminates when notificationQueue is closed (e.g. on disconnect in receive()).
 func (c *Connection) processNotifications() {
 	for queued := range c.notificationQueue {
+		if c.barrierWatchdog > 0 {
+			c.notifyMu.Lock()
+			c.handlingNotification = queued.msg.Method
+			c.notifyMu.Unlock()
+		}
 		c.handleInbound(c.inboundCtx, queued.msg)
 
 		c.notifyMu.Lock()
+		c.handlingNotification = ""
 		expectedSeq := c.completedNotificationSeq + 1
 		if queued.seq != expectedSeq {
 			c.notifyMu.Unlock()
@@ -507,12 +800,30 @@ func (c *Connection) processNotifications() {
 			c.notifyMu.Unlock()
 			panic("completed notification sequence exceeded enqueued notification sequence")
 		}
+		if c.lastSessionNotificationSeq[queued.session] == queued.seq {
+			delete(c.lastSessionNotificationSeq, queued.session)
+		}
 		c.notifyCond.Broadcast()
 		c.notifyMu.Unlock()
 	}
 }
 
-func (c *Connection) handleResponse(msg *anyMessage) {
+// paramsSessionID returns the top-level sessionId of params, or "" if there is
+// none. It skips decoding params that cannot contain one.
+func paramsSessionID(params json.RawMessage) string {
+	if !bytes.Contains(params, []byte(`"sessionId"`)) {
+		return ""
+	}
+	var p struct {
+		SessionID string `json:"sessionId"`
+	}
+	if json.Unmarshal(params, &p) != nil {
+		return ""
+	}
+	return p.SessionID
+}
+
+func (c *Connection) handleResponse(msg *anyMessage, raw []byte) {
 	idStr, err := canonicalJSONRPCIDKey(*msg.ID)
 	if err != nil {
 		c.loggerOrDefault().Error("failed to canonicalize response id", "err", err, "id", string(*msg.ID))
@@ -521,20 +832,56 @@ func (c *Connection) handleResponse(msg *anyMessage) {
 
 	c.mu.Lock()
 	pr := c.pending[idStr]
+	duplicate := false
 	if pr != nil {
 		delete(c.pending, idStr)
+		c.recentResponses[c.recentResponsesPos] = idStr
+		c.recentResponsesPos = (c.recentResponsesPos + 1) % recentResponsesSize
+	} else {
+		for _, id := range c.recentResponses {
+			if id == idStr {
+				duplicate = true
+				break
+			}
+		}
 	}
 	c.mu.Unlock()
 
-	if pr != nil {
-		c.notifyMu.Lock()
-		watermark := c.lastEnqueuedNotificationSeq
-		if c.completedNotificationSeq > watermark {
-			c.notifyMu.Unlock()
-			panic("completed notification sequence exceeded response watermark")
-		}
+	if pr == nil {
+		c.reportStrayResponse(idStr, duplicate, raw)
+		return
+	}
+
+	c.notifyMu.Lock()
+	watermark := c.lastEnqueuedNotificationSeq
+	if c.completedNotificationSeq > watermark {
 		c.notifyMu.Unlock()
-		pr.ch <- responseEnvelope{msg: *msg, notificationWatermark: watermark}
+		panic("completed notification sequence exceeded response watermark")
+	}
+	if pr.session != "" {
+		// Notifications are handled in queue order, so waiting for the latest
+		// pending one of the session (or of no session) covers all earlier ones.
+		watermark = max(c.lastSessionNotificationSeq[pr.session], c.lastSessionNotificationSeq[""])
+	}
+	c.notifyMu.Unlock()
+	pr.ch <- responseEnvelope{msg: *msg, notificationWatermark: watermark}
+}
+
+// reportStrayResponse handles a response that matches no pending request. Responses
+// to requests this side issued but stopped waiting for (e.g. after cancellation) are
+// expected and only logged at debug level.
+func (c *Connection) reportStrayResponse(idKey string, duplicate bool, raw []byte) {
+	var issued bool
+	if n, err := strconv.ParseUint(idKey, 10, 64); err == nil {
+		issued = n > 0 && n <= c.nextID.Load()
+	}
+	switch {
+	case duplicate:
+		c.reportProtocolError(slog.LevelWarn, raw, errDuplicateResponse, "received duplicate response", "id", idKey)
+	case issued:
+		c.loggerOrDefault().Debug("ignoring response to request that is no longer pending", "id", idKey)
+	default:
+		c.reportProtocolError(slog.LevelWarn, raw, errUnknownResponseID, "received response with unknown id", "id", idKey)
 	}
 }
 
@@ -555,109 +902,521 @@ func (c *Connection) handleCancelRequest(msg *anyMessage) {
 		idKey = string(p.RequestID)
 	}
 
+	c.cancelInflight(idKey)
+}
+
+// CancelInbound cancels the handler of the inbound request with the given id,
+// as listed by InflightRequests, just as if the peer had sent $/cancel_request
+// for it. The id is the request id's JSON encoding, such as 7 or "abc" with the
+// quotes. It reports whether such a request was being handled.
+func (c *Connection) CancelInbound(id string) bool {
+	idKey, err := canonicalJSONRPCIDKey(json.RawMessage(id))
+	if err != nil {
+		idKey = id
+	}
+	return c.cancelInflight(idKey)
+}
+
+func (c *Connection) cancelInflight(idKey string) bool {
 	c.mu.Lock()
-	cancel := c.inflight[idKey]
+	req := c.inflight[idKey]
 	c.mu.Unlock()
-	if cancel == nil {
+	if req == nil {
+		return false
+	}
+	req.cancel(context.Canceled)
+	return true
+}
+
+func (c *Connection) handleInbound(ctx context.Context, req *anyMessage) {
+	log := c.rpcLogger(req.ID, req.Method)
+	if res := c.dispatchInbound(ctx, log, req); res != nil {
+		if err := c.sendMessage(*res); err != nil {
+			c.closeOnResponseWriteError(log, err)
+		}
+	}
+}
+
+// closeOnResponseWriteError closes the connection after a response could not be
+// written. The peer can no longer be answered, so there is no point in waiting for
+// the reader to observe EOF before unblocking outstanding requests.
+func (c *Connection) closeOnResponseWriteError(log *slog.Logger, err error) {
+	if c.ctx.Err() != nil {
 		return
 	}
+	log.Error("failed to write response; closing connection", "err", err)
+	c.cancel(fmt.Errorf("%w: write response: %w", ErrPeerClosed, err))
+}
+
+type notificationContextKey struct{}
 
-	cancel(context.Canceled)
+// isInboundNotification reports whether ctx belongs to a handler invoked for a
+// notification rather than a request.
+func isInboundNotification(ctx context.Context) bool {
+	v, _ := ctx.Value(notificationContextKey{}).(bool)
+	return v
 }
 
-func (c *Connection) handleInbound(ctx context.Context, req *anyMessage) {
+type connectionContextKey struct{}
+
+// IsPeerGone reports whether the connection that invoked the handler owning ctx
+// has shut down, for example because the peer disconnected. It distinguishes a
+// handler context cancelled because there is no one left to reply to from one
+// cancelled by $/cancel_request or session/cancel, for which IsPeerGone returns
+// false; an agent can use it to decide whether partial results of a turn are
+// worth persisting. The connection's Err reports why it shut down. IsPeerGone
+// returns false for contexts not derived from a handler context.
+func IsPeerGone(ctx context.Context) bool {
+	c, ok := ctx.Value(connectionContextKey{}).(*Connection)
+	if !ok {
+		return false
+	}
+	select {
+	case <-c.Done():
+		return true
+	default:
+		return false
+	}
+}
+
+// dispatchInbound invokes the handler for req and returns the response to send,
+// or nil for notifications. Diagnostics go to log, which identifies req.
+func (c *Connection) dispatchInbound(ctx context.Context, log *slog.Logger, req *anyMessage) *anyMessage {
 	res := anyMessage{JSONRPC: "2.0"}
 
 	// copy ID if present
 	if req.ID != nil {
 		res.ID = req.ID
+		c.observeInboundRequest(req.Method)
+	} else {
+		c.observeNotification(req.Method, true)
+	}
+	if req.ID != nil && req.Method == keepAlivePingMethod {
+		res.Result = json.RawMessage("{}")
+		return &res
 	}
 	if c.handler == nil {
 		if req.ID != nil {
 			res.Error = NewMethodNotFound(req.Method)
-			_ = c.sendMessage(res)
+			return &res
 		}
-		return
+		return nil
 	}
 
-	result, err := c.handler(ctx, req.Method, req.Params)
+	var holdingSlot bool
+	if req.ID != nil {
+		if c.draining.Load() {
+			res.Error = NewInternalError(map[string]any{"error": "connection is shutting down"})
+			return &res
+		}
+		if !c.acquireRequestSlot(ctx) {
+			res.Error = NewRequestCancelled(nil)
+			return &res
+		}
+		holdingSlot = true
+		defer func() {
+			if holdingSlot {
+				c.releaseRequestSlot()
+			}
+		}()
+		if c.handlerTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeoutCause(ctx, c.handlerTimeout, ErrHandlerTimeout)
+			defer cancel()
+		}
+	}
+
+	ctx = context.WithValue(ctx, connectionContextKey{}, c)
+	if req.ID == nil {
+		ctx = context.WithValue(ctx, notificationContextKey{}, true)
+	}
+	if meta := parseMeta(req.Params); meta != nil {
+		ctx = context.WithValue(ctx, metaContextKey{}, meta)
+		ctx = c.extractTraceContext(ctx, meta)
+	}
+	var warnings *responseWarnings
+	var responder *Responder
+	if req.ID != nil {
+		var endSpan func(*RequestError)
+		ctx, endSpan = c.startSpan(ctx, req.Method, SpanKindServer)
+		defer func() { endSpan(res.Error) }()
+		warnings = &responseWarnings{}
+		ctx = context.WithValue(ctx, responseWarningsContextKey{}, warnings)
+		responder = newResponder()
+		ctx = context.WithValue(ctx, responderContextKey{}, responder)
+	}
+
+	result, err, panicked, running := c.invokeHandlerWithinTimeout(ctx, log, req)
+	if running != nil {
+		// The timed out handler keeps its slot, and keeps Shutdown waiting,
+		// until it actually returns.
+		c.timedOutHandlers.Add(1)
+		releaseSlot := holdingSlot
+		holdingSlot = false
+		go func() {
+			<-running
+			if releaseSlot {
+				c.releaseRequestSlot()
+			}
+			c.timedOutHandlers.Add(-1)
+		}()
+	}
 	if req.ID == nil {
 		// Notification: no response is sent; log handler errors to surface decode failures.
-		if err != nil {
+		if err != nil && !panicked {
 			// Per ACP, unknown extension notifications should be ignored.
 			if err.Code == -32601 && strings.HasPrefix(req.Method, "_") {
-				return
+				return nil
 			}
-			c.loggerOrDefault().Error("failed to handle notification", "method", req.Method, "err", err)
+			log.Error("failed to handle notification", "err", err)
+		}
+		return nil
+	}
+	if err != nil && !panicked && errors.Is(err, ErrResponseDeferred) {
+		// The handler has returned, so its slot can go to another request while
+		// the response is pending.
+		holdingSlot = false
+		c.releaseRequestSlot()
+		result, err = responder.wait(ctx)
+	} else {
+		responder.resolve(nil, nil)
+	}
+	if err != nil && errors.Is(context.Cause(ctx), ErrHandlerTimeout) {
+		err = &RequestError{
+			Code:    CodeInternalError,
+			Message: "Internal error",
+			Data:    map[string]any{"error": fmt.Sprintf("request handler timed out after %s", c.handlerTimeout)},
+			cause:   ErrHandlerTimeout,
 		}
-		return
 	}
 	if err != nil {
 		res.Error = err
 	} else {
 		// marshal result
-		b, mErr := json.Marshal(result)
+		b, mErr := c.codec.Marshal(result)
 		if mErr != nil {
 			res.Error = NewInternalError(map[string]any{"error": mErr.Error()})
 		} else {
 			res.Result = b
+			if msgs := warnings.snapshot(); len(msgs) > 0 {
+				var ok bool
+				if res.Result, ok = injectResponseWarnings(b, msgs); !ok {
+					log.Warn("dropping response warnings: result is not a JSON object", "warnings", msgs)
+				}
+			}
 		}
 	}
-	_ = c.sendMessage(res)
+	return &res
+}
+
+// invokeHandlerWithinTimeout calls invokeHandler, but for requests bounded by
+// WithHandlerTimeout it stops waiting once the limit passes, so that the peer
+// can be answered even if the handler ignores its context. The handler keeps
+// running and its late result is dropped; running is then non-nil and closed
+// once the handler returns.
+func (c *Connection) invokeHandlerWithinTimeout(ctx context.Context, log *slog.Logger, req *anyMessage) (result any, err *RequestError, panicked bool, running <-chan struct{}) {
+	if c.handlerTimeout <= 0 || req.ID == nil {
+		result, err, panicked = c.invokeHandler(ctx, log, req)
+		return result, err, panicked, nil
+	}
+	type outcome struct {
+		result   any
+		err      *RequestError
+		panicked bool
+	}
+	done := make(chan outcome, 1)
+	returned := make(chan struct{})
+	go func() {
+		defer close(returned)
+		result, err, panicked := c.invokeHandler(ctx, log, req)
+		done <- outcome{result, err, panicked}
+	}()
+	select {
+	case o := <-done:
+		return o.result, o.err, o.panicked, nil
+	case <-ctx.Done():
+	}
+	if cause := context.Cause(ctx); errors.Is(cause, ErrHandlerTimeout) {
+		return nil, toReqErr(cause), false, returned
+	}
+	// Cancelled for another reason, e.g. by the peer: the handler is expected
+	// to notice and return, as without a timeout.
+	o := <-done
+	return o.result, o.err, o.panicked, nil
+}
+
+// acquireRequestSlot waits for capacity to run an inbound request handler. It
+// returns false if ctx is done first, e.g. because the peer cancelled the request
+// while it was queued.
+func (c *Connection) acquireRequestSlot(ctx context.Context) bool {
+	if c.requestSlots == nil {
+		return true
+	}
+	if ctx.Err() != nil {
+		return false
+	}
+	select {
+	case c.requestSlots <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (c *Connection) releaseRequestSlot() {
+	if c.requestSlots != nil {
+		<-c.requestSlots
+	}
+}
+
+// invokeHandler calls the handler, converting a panic into an internal error so that
+// a single faulty handler cannot take down the connection. The panic value and stack
+// are logged but not sent to the peer.
+func (c *Connection) invokeHandler(ctx context.Context, log *slog.Logger, req *anyMessage) (result any, err *RequestError, panicked bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Error("panic in handler", "panic", r, "stack", string(debug.Stack()))
+			result, err, panicked = nil, NewInternalError(nil), true
+		}
+	}()
+	result, err = c.handler(ctx, req.Method, req.Params)
+	return result, err, false
 }
 
 func (c *Connection) sendMessage(msg anyMessage) error {
 	msg.JSONRPC = "2.0"
-	b, err := json.Marshal(msg)
+	b, err := c.codec.Marshal(msg)
 	if err != nil {
 		return err
 	}
-	b = append(b, '\n')
+	return c.writeFrame(b)
+}
+
+// writeFrame frames a single encoded JSON-RPC payload and writes it to the peer.
+func (c *Connection) writeFrame(b []byte) error {
+	framed := frameMessage(c.framing, b)
 
 	c.writeMu.Lock()
 	defer c.writeMu.Unlock()
-	_, err = c.w.Write(b)
+	if c.writeErr != nil {
+		return c.writeErr
+	}
+	c.tap(DirectionOutbound, b)
+	if c.bw != nil {
+		return c.checkWriteErrorLocked(c.bufferLocked(framed))
+	}
+	return c.checkWriteErrorLocked(c.write(framed))
+}
+
+// writeFrames frames several encoded payloads and writes them to the peer as one
+// contiguous write, so that no other message can be interleaved between them.
+func (c *Connection) writeFrames(bs [][]byte) error {
+	var framed []byte
+	for _, b := range bs {
+		framed = append(framed, frameMessage(c.framing, b)...)
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if c.writeErr != nil {
+		return c.writeErr
+	}
+	for _, b := range bs {
+		c.tap(DirectionOutbound, b)
+	}
+	if c.bw != nil {
+		return c.checkWriteErrorLocked(c.bufferLocked(framed))
+	}
+	return c.checkWriteErrorLocked(c.write(framed))
+}
+
+// checkWriteErrorLocked closes the connection if err is a write timeout, since the
+// stream may hold a partially written message. Callers must hold writeMu.
+func (c *Connection) checkWriteErrorLocked(err error) error {
+	if errors.Is(err, ErrWriteTimeout) && c.writeErr == nil {
+		c.writeErr = err
+		c.loggerOrDefault().Error("write to peer timed out; closing connection", "timeout", c.writeTimeout)
+		c.cancel(err)
+	}
 	return err
 }
 
+// write writes b to the peer, honoring the configured write timeout. Callers must
+// hold writeMu.
+func (c *Connection) write(b []byte) error {
+	if c.writeTimeout <= 0 {
+		_, err := c.w.Write(b)
+		return err
+	}
+
+	if d, ok := c.w.(writeDeadliner); ok {
+		if err := d.SetWriteDeadline(time.Now().Add(c.writeTimeout)); err == nil {
+			_, err := c.w.Write(b)
+			_ = d.SetWriteDeadline(time.Time{})
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				return ErrWriteTimeout
+			}
+			return err
+		}
+	}
+
+	// The writer has no deadline support, so wait on the write from a separate
+	// goroutine. On timeout that goroutine stays blocked until the writer is closed.
+	done := make(chan error, 1)
+	go func() {
+		_, err := c.w.Write(b)
+		done <- err
+	}()
+	timer := time.NewTimer(c.writeTimeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		return ErrWriteTimeout
+	}
+}
+
 // SendRequest sends a JSON-RPC request and returns a typed result.
 // For methods that do not return a result, use SendRequestNoResult instead.
 func SendRequest[T any](c *Connection, ctx context.Context, method string, params any) (T, error) {
+	result, _, err := SendRequestTimed[T](c, ctx, method, params)
+	return result, err
+}
+
+// RequestTiming breaks down the time spent in an outbound request.
+type RequestTiming struct {
+	// RoundTrip is the time from just before the request is written until its
+	// response is received, as also reported to Observer.OnResponse.
+	RoundTrip time.Duration
+	// NotificationWait is the time then spent waiting for the handlers of
+	// notifications the peer sent before the response to finish.
+	NotificationWait time.Duration
+}
+
+// SendRequestTimed is like SendRequest but also reports how long the request
+// took. The timing covers whatever part of the request completed, so it is
+// meaningful alongside an error too.
+func SendRequestTimed[T any](c *Connection, ctx context.Context, method string, params any) (T, RequestTiming, error) {
 	var result T
+	var timing RequestTiming
+
+	raw, err := c.call(ctx, method, params, &timing)
+	if err != nil {
+		return result, timing, err
+	}
+
+	if len(raw) > 0 {
+		if err := c.codec.Unmarshal(raw, &result); err != nil {
+			return result, timing, NewInternalError(map[string]any{"error": err.Error()})
+		}
+	}
+	return result, timing, nil
+}
+
+// call sends a request and waits for its response, then for any notifications the
+// peer sent before that response. It returns the raw result payload and, if timing
+// is non-nil, records how long each phase took.
+func (c *Connection) call(ctx context.Context, method string, params any, timing *RequestTiming) (_ json.RawMessage, err error) {
+	ctx, cancel := c.requestContext(ctx)
+	defer cancel()
+
+	ctx, endSpan := c.startSpan(ctx, method, SpanKindClient)
+	defer func() { endSpan(toReqErr(err)) }()
+
+	if err := c.checkOutboundCapability(method); err != nil {
+		return nil, err
+	}
+	if err := c.waitOutboundRate(ctx); err != nil {
+		return nil, toReqErr(err)
+	}
 
 	msg, idKey, err := c.prepareRequest(method, params)
 	if err != nil {
-		return result, err
+		return nil, err
 	}
+	msg.Params = c.injectTraceContext(ctx, msg.Params)
+	log := c.rpcLogger(msg.ID, method)
 
-	pr := &pendingResponse{ch: make(chan responseEnvelope, 1)}
+	pr := &pendingResponse{ch: make(chan responseEnvelope, 1), session: paramsSessionID(msg.Params)}
 	c.mu.Lock()
 	c.pending[idKey] = pr
 	c.mu.Unlock()
 
-	if err := c.sendMessage(msg); err != nil {
+	start := time.Now()
+	err = c.sendOutbound(ctx, msg)
+	if err == nil {
+		err = c.Flush()
+	}
+	if err != nil {
 		c.cleanupPending(idKey)
-		return result, NewInternalError(map[string]any{"error": err.Error()})
+		reqErr := toReqErr(err)
+		c.observeResponse(method, idKey, roundTrip(timing, start), reqErr)
+		return nil, reqErr
 	}
 
-	resp, err := c.waitForResponse(ctx, pr, idKey)
+	resp, err := c.waitForResponse(ctx, log, pr, idKey)
 	if err != nil {
-		return result, err
+		c.observeResponse(method, idKey, roundTrip(timing, start), toReqErr(err))
+		return nil, err
 	}
-	if err := c.waitNotificationsUpTo(ctx, resp.notificationWatermark); err != nil {
-		return result, err
+	c.observeResponse(method, idKey, roundTrip(timing, start), resp.msg.Error)
+
+	if !skipsNotificationBarrier(ctx) {
+		waitStart := time.Now()
+		err = c.waitNotificationsUpTo(ctx, log, resp.notificationWatermark)
+		if timing != nil {
+			timing.NotificationWait = time.Since(waitStart)
+		}
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	if resp.msg.Error != nil {
-		return result, resp.msg.Error
+		return nil, resp.msg.Error
 	}
+	return resp.msg.Result, nil
+}
 
-	if len(resp.msg.Result) > 0 {
-		if err := json.Unmarshal(resp.msg.Result, &result); err != nil {
-			return result, NewInternalError(map[string]any{"error": err.Error()})
-		}
+type skipNotificationBarrierContextKey struct{}
+
+// WithoutNotificationBarrier returns a context that makes requests sent with it
+// return as soon as their response arrives, without first waiting for the
+// handlers of notifications the peer sent before the response. It suits
+// latency-sensitive calls such as fs/read_text_file whose results do not
+// depend on those notifications; calls like session/prompt, which promise that
+// their updates have been handled when they return, should keep the barrier.
+func WithoutNotificationBarrier(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skipNotificationBarrierContextKey{}, true)
+}
+
+func skipsNotificationBarrier(ctx context.Context) bool {
+	v, _ := ctx.Value(skipNotificationBarrierContextKey{}).(bool)
+	return v
+}
+
+// roundTrip returns the time elapsed since start, recording it in timing if
+// timing is non-nil.
+func roundTrip(timing *RequestTiming, start time.Time) time.Duration {
+	d := time.Since(start)
+	if timing != nil {
+		timing.RoundTrip = d
 	}
-	return result, nil
+	return d
+}
+
+// requestContext applies the connection's default request timeout when ctx has no
+// deadline of its own. Timeouts surface as request-cancelled errors.
+func (c *Connection) requestContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.requestTimeout <= 0 {
+		return ctx, func() {}
+	}
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+	cause := NewRequestCancelled(map[string]any{"error": fmt.Sprintf("request timed out after %s", c.requestTimeout)})
+	return context.WithTimeoutCause(ctx, c.requestTimeout, cause)
 }
 
 func (c *Connection) prepareRequest(method string, params any) (anyMessage, string, error) {
@@ -671,13 +1430,14 @@ func (c *Connection) prepareRequest(method string, params any) (anyMessage, stri
 	}
 
 	if params != nil {
-		b, err := json.Marshal(params)
+		b, err := c.codec.Marshal(params)
 		if err != nil {
 			return msg, "", NewInvalidParams(map[string]any{"error": err.Error()})
 		}
 		msg.Params = b
 	}
 
+	c.observeOutboundRequest(method, string(idRaw))
 	return msg, string(idRaw), nil
 }
 
@@ -696,6 +1456,10 @@ func (c *Connection) sendCancelRequests() {
 				idKey := c.pendingCancelRequest[0]
 				c.pendingCancelRequest = c.pendingCancelRequest[1:]
 				c.mu.Unlock()
+				select {
+				case c.cancelQueueSpace <- struct{}{}:
+				default:
+				}
 
 				requestID := json.RawMessage(append([]byte(nil), idKey...))
 				if err := c.SendNotification(context.Background(), "$/cancel_request", cancelRequestParams{RequestID: requestID}); err != nil {
@@ -707,7 +1471,7 @@ func (c *Connection) sendCancelRequests() {
 }
 
 func (c *Connection) sendCancelRequest(idKey string) {
-	if strings.TrimSpace(idKey) == "" {
+	if c.suppressCancelRequests || strings.TrimSpace(idKey) == "" {
 		return
 	}
 
@@ -717,18 +1481,39 @@ func (c *Connection) sendCancelRequest(idKey string) {
 	default:
 	}
 
-	queueFull := false
-	c.mu.Lock()
-	if len(c.pendingCancelRequest) >= maxPendingCancelRequests {
-		queueFull = true
-	} else {
-		c.pendingCancelRequest = append(c.pendingCancelRequest, idKey)
+	limit := c.cancelQueueSize
+	if limit <= 0 {
+		limit = maxPendingCancelRequests
 	}
-	c.mu.Unlock()
+	// A dropped cancellation leaves the peer's handler running, so when the queue is
+	// full, wait briefly for the sender to make room before giving up.
+	var deadline <-chan time.Time
+	for {
+		c.mu.Lock()
+		if len(c.pendingCancelRequest) < limit {
+			c.pendingCancelRequest = append(c.pendingCancelRequest, idKey)
+			c.mu.Unlock()
+			break
+		}
+		c.mu.Unlock()
 
-	if queueFull {
-		c.loggerOrDefault().Debug("dropping $/cancel_request due to full queue", "queue_len", maxPendingCancelRequests)
-		return
+		if c.cancelQueueSpace == nil {
+			c.loggerOrDefault().Warn("dropping $/cancel_request due to full queue", "queue_len", limit)
+			return
+		}
+		if deadline == nil {
+			timer := time.NewTimer(cancelQueueFullWait)
+			defer timer.Stop()
+			deadline = timer.C
+		}
+		select {
+		case <-c.cancelQueueSpace:
+		case <-deadline:
+			c.loggerOrDefault().Warn("dropping $/cancel_request due to full queue", "queue_len", limit)
+			return
+		case <-c.Done():
+			return
+		}
 	}
 
 	select {
@@ -737,9 +1522,19 @@ func (c *Connection) sendCancelRequest(idKey string) {
 	}
 }
 
-func (c *Connection) waitForResponse(ctx context.Context, pr *pendingResponse, idKey string) (responseEnvelope, error) {
-	peerDisconnectedErr := NewInternalError(map[string]any{"error": "peer disconnected before response"})
+// disconnectError returns the error reported to callers whose request could not
+// complete because the connection ended.
+func (c *Connection) disconnectError(detail string) *RequestError {
+	if errors.Is(context.Cause(c.ctx), ErrConnectionClosed) {
+		return NewInternalError(map[string]any{"error": ErrConnectionClosed.Error()})
+	}
+	return NewInternalError(map[string]any{"error": detail})
+}
 
+// waitForResponse waits for the response to the request with idKey, sending a
+// $/cancel_request if ctx ends first. Diagnostics go to log, which identifies
+// the request.
+func (c *Connection) waitForResponse(ctx context.Context, log *slog.Logger, pr *pendingResponse, idKey string) (responseEnvelope, error) {
 	select {
 	case resp := <-pr.ch:
 		return resp, nil
@@ -749,7 +1544,7 @@ func (c *Connection) waitForResponse(ctx context.Context, pr *pendingResponse, i
 		select {
 		case <-c.Done():
 			c.cleanupPending(idKey)
-			return responseEnvelope{}, peerDisconnectedErr
+			return responseEnvelope{}, c.disconnectError("peer disconnected before response")
 		default:
 		}
 
@@ -761,21 +1556,22 @@ func (c *Connection) waitForResponse(ctx context.Context, pr *pendingResponse, i
 			cause = ctx.Err()
 		}
 		if cause != nil {
+			log.Debug("request abandoned before response", "cause", cause)
 			return responseEnvelope{}, toReqErr(cause)
 		}
 		return responseEnvelope{}, NewInternalError(map[string]any{"error": "request context ended without cause"})
 	case <-c.Done():
 		c.cleanupPending(idKey)
-		return responseEnvelope{}, peerDisconnectedErr
+		return responseEnvelope{}, c.disconnectError("peer disconnected before response")
 	}
 }
 
-func (c *Connection) waitNotificationsUpTo(ctx context.Context, target uint64) error {
+func (c *Connection) waitNotificationsUpTo(ctx context.Context, log *slog.Logger, target uint64) error {
 	if target == 0 {
 		return nil
 	}
 
-	peerDisconnectedErr := NewInternalError(map[string]any{"error": "peer disconnected while waiting for pre-response notifications"})
+	const peerDisconnectedDetail = "peer disconnected while waiting for pre-response notifications"
 	stopWake := make(chan struct{})
 	defer close(stopWake)
 
@@ -785,6 +1581,28 @@ func (c *Connection) waitNotificationsUpTo(ctx context.Context, target uint64) e
 		panic("response watermark exceeded last enqueued notification sequence")
 	}
 
+	// Both are guarded by notifyMu; finished keeps a late watchdog quiet.
+	finished, watchdogExpired := false, false
+	defer func() { finished = true }()
+	if c.barrierWatchdog > 0 && c.completedNotificationSeq < target {
+		timer := time.AfterFunc(c.barrierWatchdog, func() {
+			c.notifyMu.Lock()
+			defer c.notifyMu.Unlock()
+			if finished || c.completedNotificationSeq >= target {
+				return
+			}
+			log.Warn("response held back by slow notification handler",
+				"waited", c.barrierWatchdog,
+				"handling", c.handlingNotification,
+				"pending_notifications", target-c.completedNotificationSeq)
+			if c.barrierWatchdogFail {
+				watchdogExpired = true
+				c.notifyCond.Broadcast()
+			}
+		})
+		defer timer.Stop()
+	}
+
 	go func() {
 		select {
 		case <-ctx.Done():
@@ -803,14 +1621,17 @@ func (c *Connection) waitNotificationsUpTo(ctx context.Context, target uint64) e
 
 		select {
 		case <-c.Done():
-			return peerDisconnectedErr
+			return c.disconnectError(peerDisconnectedDetail)
 		default:
 		}
+		if watchdogExpired {
+			return NewInternalError(map[string]any{"error": fmt.Sprintf("timed out after %s waiting for notification handlers", c.barrierWatchdog)})
+		}
 		select {
 		case <-ctx.Done():
 			select {
 			case <-c.Done():
-				return peerDisconnectedErr
+				return c.disconnectError(peerDisconnectedDetail)
 			default:
 			}
 			cause := context.Cause(ctx)
@@ -876,49 +1697,66 @@ func (c *Connection) cleanupPending(idKey string) {
 
 // SendRequestNoResult sends a JSON-RPC request that returns no result payload.
 func (c *Connection) SendRequestNoResult(ctx context.Context, method string, params any) error {
-	msg, idKey, err := c.prepareRequest(method, params)
-	if err != nil {
-		return err
-	}
-
-	pr := &pendingResponse{ch: make(chan responseEnvelope, 1)}
-	c.mu.Lock()
-	c.pending[idKey] = pr
-	c.mu.Unlock()
+	_, err := c.call(ctx, method, params, nil)
+	return err
+}
 
-	if err := c.sendMessage(msg); err != nil {
-		c.cleanupPending(idKey)
-		return NewInternalError(map[string]any{"error": err.Error()})
+func (c *Connection) SendNotification(ctx context.Context, method string, params any) error {
+	select {
+	case <-ctx.Done():
+		return NewInternalError(map[string]any{"error": ctx.Err().Error()})
+	default:
 	}
 
-	resp, err := c.waitForResponse(ctx, pr, idKey)
+	msg, err := c.prepareNotification(method, params)
 	if err != nil {
 		return err
 	}
-	if err := c.waitNotificationsUpTo(ctx, resp.notificationWatermark); err != nil {
-		return err
-	}
+	msg.Params = c.injectTraceContext(ctx, msg.Params)
 
-	if resp.msg.Error != nil {
-		return resp.msg.Error
+	c.observeNotification(method, false)
+	if err := c.sendOutbound(ctx, msg); err != nil {
+		return toReqErr(err)
 	}
 	return nil
 }
 
-func (c *Connection) SendNotification(ctx context.Context, method string, params any) error {
+// sendNotificationGroup sends one notification per params entry, all for the same
+// method, and writes them to the peer contiguously and in order. If any of them
+// fails to encode or is rejected by outbound middleware, none are sent.
+func (c *Connection) sendNotificationGroup(ctx context.Context, method string, params []any) error {
 	select {
 	case <-ctx.Done():
 		return NewInternalError(map[string]any{"error": ctx.Err().Error()})
 	default:
 	}
 
-	msg, err := c.prepareNotification(method, params)
-	if err != nil {
-		return err
+	frames := make([][]byte, 0, len(params))
+	collect := func(msg anyMessage) error {
+		msg.JSONRPC = "2.0"
+		b, err := c.codec.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		frames = append(frames, b)
+		return nil
+	}
+	for _, p := range params {
+		msg, err := c.prepareNotification(method, p)
+		if err != nil {
+			return err
+		}
+		msg.Params = c.injectTraceContext(ctx, msg.Params)
+		if err := c.sendOutboundVia(ctx, msg, collect); err != nil {
+			return toReqErr(err)
+		}
 	}
 
-	if err := c.sendMessage(msg); err != nil {
-		return NewInternalError(map[string]any{"error": err.Error()})
+	for range frames {
+		c.observeNotification(method, false)
+	}
+	if err := c.writeFrames(frames); err != nil {
+		return toReqErr(err)
 	}
 	return nil
 }
@@ -930,7 +1768,7 @@ func (c *Connection) prepareNotification(method string, params any) (anyMessage,
 	}
 
 	if params != nil {
-		b, err := json.Marshal(params)
+		b, err := c.codec.Marshal(params)
 		if err != nil {
 			return msg, NewInvalidParams(map[string]any{"error": err.Error()})
 		}
@@ -940,8 +1778,234 @@ func (c *Connection) prepareNotification(method string, params any) (anyMessage,
 	return msg, nil
 }
 
+// keepAlive periodically pings the peer and closes the connection when a ping
+// goes unanswered for longer than keepAliveTimeout.
+func (c *Connection) keepAlive() {
+	ticker := time.NewTicker(c.keepAliveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.Done():
+			return
+		case <-ticker.C:
+		}
+
+		ctx, cancel := context.WithTimeout(c.ctx, c.keepAliveTimeout)
+		err := c.ping(ctx)
+		timedOut := errors.Is(ctx.Err(), context.DeadlineExceeded)
+		cancel()
+
+		if timedOut {
+			cause := fmt.Errorf("%w: no response to %s within %s", ErrKeepAliveTimeout, keepAlivePingMethod, c.keepAliveTimeout)
+			c.loggerOrDefault().Error("peer failed keepalive; closing connection", "err", cause)
+			c.cancel(cause)
+			return
+		}
+		if err != nil {
+			// Any response, including an error response, proves the peer is alive.
+			c.loggerOrDefault().Debug("keepalive ping returned error", "err", err)
+		}
+	}
+}
+
+// ping sends a keepalive request. Unlike SendRequest, it does not wait for
+// preceding notifications to be processed, so slow notification handlers cannot
+// cause spurious keepalive failures.
+func (c *Connection) ping(ctx context.Context) error {
+	msg, idKey, err := c.prepareRequest(keepAlivePingMethod, nil)
+	if err != nil {
+		return err
+	}
+
+	pr := &pendingResponse{ch: make(chan responseEnvelope, 1)}
+	c.mu.Lock()
+	c.pending[idKey] = pr
+	c.mu.Unlock()
+
+	start := time.Now()
+	err = c.sendMessage(msg)
+	if err == nil {
+		err = c.Flush()
+	}
+	if err != nil {
+		c.cleanupPending(idKey)
+		c.observeResponse(keepAlivePingMethod, idKey, time.Since(start), toReqErr(err))
+		return err
+	}
+
+	resp, err := c.waitForResponse(ctx, c.rpcLogger(msg.ID, keepAlivePingMethod), pr, idKey)
+	if err != nil {
+		c.observeResponse(keepAlivePingMethod, idKey, time.Since(start), toReqErr(err))
+		return err
+	}
+	c.observeResponse(keepAlivePingMethod, idKey, time.Since(start), resp.msg.Error)
+	if resp.msg.Error != nil {
+		return resp.msg.Error
+	}
+	return nil
+}
+
 // Done returns a channel that is closed when the underlying reader loop exits
 // (typically when the peer disconnects or the input stream is closed).
 func (c *Connection) Done() <-chan struct{} {
 	return c.ctx.Done()
 }
+
+// Wait blocks until the connection shuts down or ctx is done. It returns nil if
+// the peer disconnected cleanly or the connection was closed locally, ctx's error
+// if ctx ended first, and the shutdown cause (see Err) otherwise.
+func (c *Connection) Wait(ctx context.Context) error {
+	select {
+	case <-c.Done():
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if err := c.Err(); err != ErrPeerClosed && err != ErrConnectionClosed {
+		return err
+	}
+	return nil
+}
+
+// Err returns nil while the connection is open. Once Done is closed, it returns
+// the reason the connection shut down, which can be matched with errors.Is
+// against ErrPeerClosed, ErrConnectionClosed, and the other Err* causes.
+func (c *Connection) Err() error {
+	return context.Cause(c.ctx)
+}
+
+// ConnectionStats is a point-in-time snapshot of connection activity.
+type ConnectionStats struct {
+	// PendingRequests is the number of outbound requests awaiting a response.
+	PendingRequests int
+	// InflightRequests is the number of inbound requests being handled.
+	InflightRequests int
+	// PendingCancelRequests is the number of queued outbound $/cancel_request notifications.
+	PendingCancelRequests int
+	// PendingNotifications is the number of inbound notifications received but
+	// not yet handled, including the one being handled.
+	PendingNotifications int
+	// NotificationHighWaterMark is the largest PendingNotifications has been
+	// over the life of the connection. A value close to the queue capacity
+	// (see WithNotificationQueueSize) means notification handlers are not
+	// keeping up with the peer.
+	NotificationHighWaterMark int
+}
+
+// Stats returns a snapshot of how busy the connection is, e.g. to wait for
+// outstanding work to finish before calling Close.
+func (c *Connection) Stats() ConnectionStats {
+	c.notifyMu.Lock()
+	pendingNotifications := int(c.lastEnqueuedNotificationSeq - c.completedNotificationSeq)
+	highWaterMark := int(c.notificationHighWaterMark)
+	c.notifyMu.Unlock()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return ConnectionStats{
+		PendingRequests:           len(c.pending),
+		InflightRequests:          len(c.inflight),
+		PendingCancelRequests:     len(c.pendingCancelRequest),
+		PendingNotifications:      pendingNotifications,
+		NotificationHighWaterMark: highWaterMark,
+	}
+}
+
+// InflightInfo describes an inbound request that is being handled.
+type InflightInfo struct {
+	// ID is the request id as sent by the peer.
+	ID json.RawMessage
+	// Method is the request method.
+	Method string
+	// Started is when the request was received.
+	Started time.Time
+	// Elapsed is how long the request had been running when
+	// InflightRequests was called.
+	Elapsed time.Duration
+}
+
+// InflightRequests lists the inbound requests that are being handled, oldest
+// first, e.g. for a debug endpoint. A request is listed from when it is received
+// until its response has been sent.
+func (c *Connection) InflightRequests() []InflightInfo {
+	now := time.Now()
+	c.mu.Lock()
+	out := make([]InflightInfo, 0, len(c.inflight))
+	for _, req := range c.inflight {
+		out = append(out, InflightInfo{
+			ID:      append(json.RawMessage(nil), req.id...),
+			Method:  req.method,
+			Started: req.started,
+			Elapsed: now.Sub(req.started),
+		})
+	}
+	c.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].Started.Before(out[j].Started) })
+	return out
+}
+
+// Close tears down the connection. Pending outbound requests fail with a
+// "connection closed" error, inbound handlers are cancelled, and internal
+// goroutines stop, except for the one reading from the peer. The underlying
+// reader and writer are left open, so that goroutine stays blocked in Read until
+// the peer sends more data or the caller closes the reader; whatever it reads
+// is discarded. Connections created over an io.ReadWriteCloser are the
+// exception: their stream is closed, which also ends the read, and the result of
+// that close is returned. Close is idempotent and safe to call concurrently with
+// in-flight requests.
+func (c *Connection) Close() error {
+	c.cancel(ErrConnectionClosed)
+	return c.closeStream()
+}
+
+// shutdownPollInterval is how often Shutdown checks whether handlers have finished.
+const shutdownPollInterval = 10 * time.Millisecond
+
+// Shutdown gracefully closes the connection. New inbound requests are rejected
+// while Shutdown waits for running request handlers, and for the notifications
+// already queued when it was called, to finish, after which messages buffered by
+// WithWriteCoalescing are flushed and the connection is closed. Notifications
+// that arrive later do not hold it up. Outbound requests are unaffected until
+// then, so handlers may still call the peer while finishing up.
+//
+// If ctx is done before the handlers finish, Shutdown closes the connection
+// anyway, cancelling the remaining handlers, and returns the context's error.
+func (c *Connection) Shutdown(ctx context.Context) error {
+	c.draining.Store(true)
+	c.notifyMu.Lock()
+	watermark := c.lastEnqueuedNotificationSeq
+	c.notifyMu.Unlock()
+
+	ticker := time.NewTicker(shutdownPollInterval)
+	defer ticker.Stop()
+	for !c.idle(watermark) {
+		select {
+		case <-ctx.Done():
+			c.cancel(ErrConnectionClosed)
+			c.inboundCancel(ErrConnectionClosed)
+			return ctx.Err()
+		case <-c.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+	if err := c.Flush(); err != nil {
+		_ = c.Close()
+		return err
+	}
+	return c.Close()
+}
+
+// idle reports whether no inbound request handlers are running and the
+// notifications up to sequence number watermark have been.
+func (c *Connection) idle(watermark uint64) bool {
+	c.mu.Lock()
+	inflight := len(c.inflight)
+	c.mu.Unlock()
+	if inflight > 0 || c.timedOutHandlers.Load() > 0 {
+		return false
+	}
+	c.notifyMu.Lock()
+	defer c.notifyMu.Unlock()
+	return c.completedNotificationSeq >= watermark
+}
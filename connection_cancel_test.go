@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"strings"
 	"testing"
 	"time"
 )
@@ -82,6 +83,81 @@ func TestConnectionInboundCancelRequest_CancelsHandler(t *testing.T) {
 	}
 }
 
+func TestConnectionCancelInbound(t *testing.T) {
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	defer func() {
+		_ = inW.Close()
+		_ = outW.Close()
+		_ = inR.Close()
+		_ = outR.Close()
+	}()
+
+	started := make(chan struct{}, 2)
+	c := NewConnection(func(ctx context.Context, method string, params json.RawMessage) (any, *RequestError) {
+		started <- struct{}{}
+		<-ctx.Done()
+		return nil, toReqErr(ctx.Err())
+	}, outW, inR)
+
+	lines := make(chan []byte, 10)
+	go func() {
+		scanner := bufio.NewScanner(outR)
+		for scanner.Scan() {
+			lines <- append([]byte(nil), scanner.Bytes()...)
+		}
+		close(lines)
+	}()
+
+	for _, req := range []string{
+		`{"jsonrpc":"2.0","id":1,"method":"keep","params":{}}`,
+		`{"jsonrpc":"2.0","id":"b","method":"kill","params":{}}`,
+	} {
+		if _, err := inW.Write([]byte(req + "\n")); err != nil {
+			t.Fatalf("write request: %v", err)
+		}
+		select {
+		case <-started:
+		case <-time.After(2 * time.Second):
+			t.Fatal("handler did not start")
+		}
+	}
+
+	if c.CancelInbound("2") {
+		t.Fatal("CancelInbound reported cancelling an unknown request")
+	}
+	var target string
+	for _, info := range c.InflightRequests() {
+		if info.Method == "kill" {
+			target = string(info.ID)
+		}
+	}
+	if !c.CancelInbound(target) {
+		t.Fatalf("CancelInbound(%s) did not find the request", target)
+	}
+
+	var msg anyMessage
+	select {
+	case raw := <-lines:
+		if err := json.Unmarshal(raw, &msg); err != nil {
+			t.Fatalf("unmarshal response: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for response")
+	}
+	if msg.ID == nil || string(*msg.ID) != `"b"` || msg.Error == nil || msg.Error.Code != CodeRequestCancelled {
+		t.Fatalf("unexpected response %+v, want request b cancelled", msg)
+	}
+	// The request is listed until its response has been sent.
+	deadline := time.Now().Add(2 * time.Second)
+	for len(c.InflightRequests()) != 1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := c.InflightRequests(); len(got) != 1 || got[0].Method != "keep" {
+		t.Fatalf("InflightRequests() = %+v, want only the untouched request", got)
+	}
+}
+
 func TestConnectionInboundCancelRequest_CanonicalizesEquivalentIDs(t *testing.T) {
 	inR, inW := io.Pipe()
 	outR, outW := io.Pipe()
@@ -482,7 +558,62 @@ func TestConnectionOutboundCancelRequest_SendsNotification(t *testing.T) {
 	}
 }
 
-func TestConnectionOutboundRequestTimeout_ReturnsInternalError(t *testing.T) {
+func TestConnectionOutboundCancelRequest_Disabled(t *testing.T) {
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	defer func() {
+		_ = inW.Close()
+		_ = outW.Close()
+		_ = inR.Close()
+		_ = outR.Close()
+	}()
+
+	c := NewConnection(nil, outW, inR, WithOutboundCancelRequests(false))
+
+	methods := make(chan string, 10)
+	go func() {
+		scanner := bufio.NewScanner(outR)
+		for scanner.Scan() {
+			var msg anyMessage
+			_ = json.Unmarshal(scanner.Bytes(), &msg)
+			methods <- msg.Method
+		}
+		close(methods)
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := SendRequest[json.RawMessage](c, ctx, "test/method", nil)
+		errCh <- err
+	}()
+	if m := <-methods; m != "test/method" {
+		t.Fatalf("unexpected first message %q", m)
+	}
+	cancel()
+	if err := <-errCh; !errors.Is(err, ErrRequestCancelled) {
+		t.Fatalf("expected a request cancelled error, got %v", err)
+	}
+
+	if err := c.SendNotification(context.Background(), "test/marker", nil); err != nil {
+		t.Fatalf("SendNotification: %v", err)
+	}
+	select {
+	case m := <-methods:
+		if m != "test/marker" {
+			t.Fatalf("sent %q after cancellation, want only the marker", m)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for marker")
+	}
+	select {
+	case m := <-methods:
+		t.Fatalf("unexpected message %q", m)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestConnectionOutboundRequestTimeout_ReturnsRequestCancelled(t *testing.T) {
 	inR, inW := io.Pipe()
 	defer func() {
 		_ = inW.Close()
@@ -503,8 +634,8 @@ func TestConnectionOutboundRequestTimeout_ReturnsInternalError(t *testing.T) {
 	if !ok {
 		t.Fatalf("expected *RequestError, got %T: %v", err, err)
 	}
-	if re.Code != -32603 {
-		t.Fatalf("expected timeout to map to internal error code -32603, got %d (%s)", re.Code, re.Message)
+	if re.Code != -32800 {
+		t.Fatalf("expected timeout to map to request cancelled code -32800, got %d (%s)", re.Code, re.Message)
 	}
 
 	c.mu.Lock()
@@ -592,7 +723,7 @@ func TestConnectionSendCancelRequest_BoundsPendingQueue(t *testing.T) {
 
 	c := &Connection{
 		pending:             make(map[string]*pendingResponse),
-		inflight:            make(map[string]context.CancelCauseFunc),
+		inflight:            make(map[string]*inflightRequest),
 		cancelRequestSignal: make(chan struct{}, 1),
 		ctx:                 baseCtx,
 		cancel:              baseCancel,
@@ -637,7 +768,7 @@ func TestConnectionWaitForResponse_PeerDisconnectWinsOverDerivedContextCancel(t
 		requestCtx, requestCancel := context.WithCancel(baseCtx)
 		baseCancel(errors.New("peer closed"))
 
-		_, err := c.waitForResponse(requestCtx, pr, idKey)
+		_, err := c.waitForResponse(requestCtx, c.loggerOrDefault(), pr, idKey)
 		requestCancel()
 
 		if err == nil {
@@ -656,3 +787,100 @@ func TestConnectionWaitForResponse_PeerDisconnectWinsOverDerivedContextCancel(t
 		}
 	}
 }
+
+func TestConnectionSendCancelRequest_WaitsForRoomInFullQueue(t *testing.T) {
+	outR, outW := io.Pipe()
+	inR, inW := io.Pipe()
+	defer func() {
+		_ = outR.Close()
+		_ = outW.Close()
+		_ = inR.Close()
+		_ = inW.Close()
+	}()
+
+	c := NewConnection(nil, outW, inR, WithCancelQueueSize(1))
+
+	// Nobody reads yet, so the sender blocks writing "a" and "b" fills the queue.
+	c.sendCancelRequest(`"a"`)
+	deadline := time.Now().Add(2 * time.Second)
+	for c.Stats().PendingCancelRequests != 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	c.sendCancelRequest(`"b"`)
+
+	queued := make(chan struct{})
+	go func() {
+		c.sendCancelRequest(`"c"`)
+		close(queued)
+	}()
+
+	lines := make(chan string, 3)
+	go func() {
+		scanner := bufio.NewScanner(outR)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+
+	select {
+	case <-queued:
+	case <-time.After(2 * time.Second):
+		t.Fatal("cancel request did not get queued once room was made")
+	}
+	for _, want := range []string{`"a"`, `"b"`, `"c"`} {
+		select {
+		case line := <-lines:
+			if !strings.Contains(line, `"requestId":`+want) {
+				t.Fatalf("expected cancel for %s, got %s", want, line)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("cancel for %s was dropped", want)
+		}
+	}
+}
+
+func TestIsPeerGone(t *testing.T) {
+	for _, disconnect := range []bool{false, true} {
+		t.Run(fmt.Sprintf("disconnect=%v", disconnect), func(t *testing.T) {
+			started := make(chan struct{})
+			gone := make(chan bool, 1)
+			agent := agentFuncs{
+				PromptFunc: func(ctx context.Context, _ PromptRequest) (PromptResponse, error) {
+					if IsPeerGone(ctx) {
+						t.Error("IsPeerGone reported true before the turn ended")
+					}
+					close(started)
+					<-ctx.Done()
+					gone <- IsPeerGone(ctx)
+					return PromptResponse{StopReason: StopReasonCancelled}, nil
+				},
+			}
+			agentConn, clientConn := NewInMemoryConnections(agent, &clientFuncs{})
+			t.Cleanup(func() { _ = agentConn.Close() })
+
+			turnCtx, cancelTurn := context.WithCancel(context.Background())
+			defer cancelTurn()
+			go func() {
+				_, _ = clientConn.Prompt(turnCtx, PromptRequest{SessionId: "s", Prompt: []ContentBlock{TextBlock("hi")}})
+			}()
+			<-started
+			if disconnect {
+				_ = clientConn.Close()
+			} else {
+				cancelTurn()
+			}
+
+			select {
+			case got := <-gone:
+				if got != disconnect {
+					t.Fatalf("IsPeerGone = %v, want %v", got, disconnect)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("handler context was not cancelled")
+			}
+		})
+	}
+	if IsPeerGone(context.Background()) {
+		t.Fatal("IsPeerGone reported true outside a handler")
+	}
+}
@@ -0,0 +1,383 @@
+package acp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConnectionClose_FailsPendingRequests(t *testing.T) {
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	defer func() {
+		_ = inW.Close()
+		_ = outW.Close()
+		_ = inR.Close()
+		_ = outR.Close()
+	}()
+	go func() { _, _ = io.Copy(io.Discard, outR) }()
+
+	c := NewConnection(nil, outW, inR)
+
+	const n = 3
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := SendRequest[json.RawMessage](c, context.Background(), "never", nil)
+			errs <- err
+		}()
+	}
+	waitForPendingRequests(t, c, n, 2*time.Second)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.Close(); err != nil {
+				t.Errorf("Close: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i := 0; i < n; i++ {
+		select {
+		case err := <-errs:
+			var re *RequestError
+			if !errors.As(err, &re) {
+				t.Fatalf("expected RequestError, got %T: %v", err, err)
+			}
+			if !strings.Contains(re.Error(), "connection closed") {
+				t.Fatalf("expected connection closed error, got %v", re)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("pending request was not failed by Close")
+		}
+	}
+
+	select {
+	case <-c.Done():
+	default:
+		t.Fatal("Done not closed after Close")
+	}
+	waitForPendingRequests(t, c, 0, time.Second)
+
+	if err := c.Close(); err != nil {
+		t.Fatalf("second Close: %v", err)
+	}
+}
+
+func TestConnectionClose_CancelsInboundHandlers(t *testing.T) {
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	defer func() {
+		_ = inW.Close()
+		_ = outW.Close()
+		_ = inR.Close()
+		_ = outR.Close()
+	}()
+	go func() { _, _ = io.Copy(io.Discard, outR) }()
+
+	started := make(chan struct{})
+	cancelled := make(chan struct{})
+	c := NewConnection(func(ctx context.Context, method string, params json.RawMessage) (any, *RequestError) {
+		close(started)
+		<-ctx.Done()
+		close(cancelled)
+		return nil, toReqErr(ctx.Err())
+	}, outW, inR)
+
+	if _, err := inW.Write([]byte(`{"jsonrpc":"2.0","id":1,"method":"block","params":{}}` + "\n")); err != nil {
+		t.Fatalf("write request: %v", err)
+	}
+	select {
+	case <-started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("handler did not start")
+	}
+
+	_ = c.Close()
+
+	select {
+	case <-cancelled:
+	case <-time.After(2 * time.Second):
+		t.Fatal("handler context was not cancelled by Close")
+	}
+}
+
+// failAfterWriter accepts n writes and then fails every subsequent write.
+type failAfterWriter struct {
+	mu sync.Mutex
+	n  int
+}
+
+var errBrokenPipe = errors.New("broken pipe")
+
+func (w *failAfterWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.n <= 0 {
+		return 0, errBrokenPipe
+	}
+	w.n--
+	return len(p), nil
+}
+
+func TestConnection_ClosesWhenResponseWriteFails(t *testing.T) {
+	inR, inW := io.Pipe()
+	defer func() {
+		_ = inR.Close()
+		_ = inW.Close()
+	}()
+
+	c := NewConnection(func(ctx context.Context, method string, params json.RawMessage) (any, *RequestError) {
+		return map[string]any{}, nil
+	}, &failAfterWriter{n: 1}, inR)
+
+	// The outbound request consumes the only successful write and is never answered.
+	pending := make(chan error, 1)
+	go func() {
+		pending <- c.SendRequestNoResult(context.Background(), "never", nil)
+	}()
+	waitForPendingRequests(t, c, 1, 2*time.Second)
+
+	// Answering this inbound request fails, which must tear down the connection
+	// even though the reader never sees EOF.
+	if _, err := io.WriteString(inW, `{"jsonrpc":"2.0","id":1,"method":"ping"}`+"\n"); err != nil {
+		t.Fatalf("write request: %v", err)
+	}
+
+	select {
+	case err := <-pending:
+		if err == nil {
+			t.Fatal("expected pending request to fail")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("pending request was not unblocked after response write failure")
+	}
+	select {
+	case <-c.Done():
+	case <-time.After(time.Second):
+		t.Fatal("connection not closed after response write failure")
+	}
+	if cause := context.Cause(c.ctx); !errors.Is(cause, errBrokenPipe) {
+		t.Fatalf("unexpected close cause: %v", cause)
+	}
+}
+
+func TestConnectionShutdown_WaitsForInflightHandlers(t *testing.T) {
+	c2aR, c2aW := io.Pipe()
+	a2cR, a2cW := io.Pipe()
+	defer func() {
+		_ = c2aR.Close()
+		_ = c2aW.Close()
+		_ = a2cR.Close()
+		_ = a2cW.Close()
+	}()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	server := NewConnection(func(ctx context.Context, method string, params json.RawMessage) (any, *RequestError) {
+		if method == "slow" {
+			close(started)
+			<-release
+		}
+		return map[string]any{}, nil
+	}, a2cW, c2aR)
+	client := NewConnection(nil, c2aW, a2cR)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	slowErr := make(chan error, 1)
+	go func() { slowErr <- client.SendRequestNoResult(ctx, "slow", nil) }()
+	<-started
+
+	shutdownErr := make(chan error, 1)
+	go func() { shutdownErr <- server.Shutdown(ctx) }()
+
+	// Wait until Shutdown has started draining, then confirm new requests are rejected.
+	deadline := time.Now().Add(2 * time.Second)
+	for !server.draining.Load() && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	err := client.SendRequestNoResult(ctx, "late", nil)
+	if re, ok := err.(*RequestError); !ok || !strings.Contains(re.Error(), "shutting down") {
+		t.Fatalf("expected shutdown rejection, got %v", err)
+	}
+	select {
+	case err := <-shutdownErr:
+		t.Fatalf("Shutdown returned before handler finished: %v", err)
+	default:
+	}
+
+	close(release)
+	if err := <-slowErr; err != nil {
+		t.Fatalf("inflight request failed: %v", err)
+	}
+	select {
+	case err := <-shutdownErr:
+		if err != nil {
+			t.Fatalf("Shutdown: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Shutdown did not return after handlers finished")
+	}
+	select {
+	case <-server.Done():
+	default:
+		t.Fatal("connection not closed after Shutdown")
+	}
+}
+
+func TestConnectionShutdown_ForceCancelsOnContextExpiry(t *testing.T) {
+	inR, inW := io.Pipe()
+	defer func() {
+		_ = inR.Close()
+		_ = inW.Close()
+	}()
+
+	started := make(chan struct{})
+	handlerCancelled := make(chan struct{})
+	c := NewConnection(func(ctx context.Context, method string, params json.RawMessage) (any, *RequestError) {
+		close(started)
+		<-ctx.Done()
+		close(handlerCancelled)
+		return nil, nil
+	}, io.Discard, inR)
+
+	if _, err := io.WriteString(inW, `{"jsonrpc":"2.0","id":1,"method":"stuck"}`+"\n"); err != nil {
+		t.Fatalf("write request: %v", err)
+	}
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := c.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline error, got %v", err)
+	}
+	select {
+	case <-handlerCancelled:
+	case <-time.After(2 * time.Second):
+		t.Fatal("handler was not cancelled after Shutdown deadline")
+	}
+}
+
+func TestConnectionShutdown_IgnoresNotificationsArrivingLater(t *testing.T) {
+	inR, inW := io.Pipe()
+	defer func() {
+		_ = inR.Close()
+		_ = inW.Close()
+	}()
+
+	started := make(chan struct{})
+	var once sync.Once
+	c := NewConnection(func(ctx context.Context, method string, params json.RawMessage) (any, *RequestError) {
+		once.Do(func() { close(started) })
+		time.Sleep(time.Millisecond)
+		return nil, nil
+	}, io.Discard, inR, WithNotificationBackpressure())
+
+	// The peer streams notifications faster than they are handled, and
+	// backpressure keeps the connection open, so the queue never runs empty.
+	go func() {
+		for {
+			if _, err := io.WriteString(inW, `{"jsonrpc":"2.0","method":"update"}`+"\n"); err != nil {
+				return
+			}
+		}
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := c.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown while the peer keeps sending notifications: %v", err)
+	}
+}
+
+func TestConnectionErr_ReportsShutdownCause(t *testing.T) {
+	t.Run("close", func(t *testing.T) {
+		inR, inW := io.Pipe()
+		defer func() {
+			_ = inR.Close()
+			_ = inW.Close()
+		}()
+		c := NewConnection(nil, io.Discard, inR)
+		if err := c.Err(); err != nil {
+			t.Fatalf("Err on open connection: %v", err)
+		}
+		_ = c.Close()
+		if err := c.Err(); !errors.Is(err, ErrConnectionClosed) {
+			t.Fatalf("expected ErrConnectionClosed, got %v", err)
+		}
+	})
+
+	t.Run("peer eof", func(t *testing.T) {
+		c := NewConnection(nil, io.Discard, strings.NewReader(""))
+		select {
+		case <-c.Done():
+		case <-time.After(2 * time.Second):
+			t.Fatal("connection did not close on EOF")
+		}
+		if err := c.Err(); !errors.Is(err, ErrPeerClosed) {
+			t.Fatalf("expected ErrPeerClosed, got %v", err)
+		}
+	})
+
+	t.Run("read error", func(t *testing.T) {
+		inR, inW := io.Pipe()
+		c := NewConnection(nil, io.Discard, inR)
+		_ = inW.CloseWithError(errBrokenPipe)
+		select {
+		case <-c.Done():
+		case <-time.After(2 * time.Second):
+			t.Fatal("connection did not close on read error")
+		}
+		if err := c.Err(); !errors.Is(err, ErrPeerClosed) || !errors.Is(err, errBrokenPipe) {
+			t.Fatalf("expected ErrPeerClosed wrapping the read error, got %v", err)
+		}
+	})
+}
+
+func TestConnectionWait(t *testing.T) {
+	t.Run("clean disconnect", func(t *testing.T) {
+		c := NewConnection(nil, io.Discard, strings.NewReader(""))
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		if err := c.Wait(ctx); err != nil {
+			t.Fatalf("Wait: %v", err)
+		}
+	})
+
+	t.Run("read error", func(t *testing.T) {
+		inR, inW := io.Pipe()
+		c := NewConnection(nil, io.Discard, inR)
+		_ = inW.CloseWithError(errBrokenPipe)
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		if err := c.Wait(ctx); !errors.Is(err, errBrokenPipe) {
+			t.Fatalf("expected read error, got %v", err)
+		}
+	})
+
+	t.Run("context done", func(t *testing.T) {
+		inR, inW := io.Pipe()
+		defer func() {
+			_ = inR.Close()
+			_ = inW.Close()
+		}()
+		c := NewConnection(nil, io.Discard, inR)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		if err := c.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected deadline error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,78 @@
+package acp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConnection_LogsCarryRPCGroup(t *testing.T) {
+	c2aR, c2aW := io.Pipe()
+	a2cR, a2cW := io.Pipe()
+	defer func() {
+		_ = c2aR.Close()
+		_ = c2aW.Close()
+		_ = a2cR.Close()
+		_ = a2cW.Close()
+	}()
+
+	notified := make(chan struct{})
+	server := NewConnection(func(ctx context.Context, method string, params json.RawMessage) (any, *RequestError) {
+		switch method {
+		case "boom":
+			panic("boom")
+		case "notify/fail":
+			defer close(notified)
+			return nil, NewInternalError(nil)
+		}
+		return nil, nil
+	}, a2cW, c2aR)
+	var logs syncBuffer
+	server.SetLogger(slog.New(slog.NewJSONHandler(&logs, nil)))
+	client := NewConnection(nil, c2aW, a2cR)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := client.SendRequestNoResult(ctx, "boom", nil); err == nil {
+		t.Fatal("expected an error from the panicking handler")
+	}
+	if err := client.SendNotification(ctx, "notify/fail", nil); err != nil {
+		t.Fatalf("SendNotification: %v", err)
+	}
+	<-notified
+	// The notification is logged after its handler returns.
+	deadline := time.Now().Add(2 * time.Second)
+	for !strings.Contains(logs.String(), "failed to handle notification") && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	type record struct {
+		Msg string `json:"msg"`
+		RPC struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		} `json:"rpc"`
+	}
+	got := map[string]record{}
+	sc := bufio.NewScanner(strings.NewReader(logs.String()))
+	sc.Buffer(nil, 1<<20)
+	for sc.Scan() {
+		var r record
+		if err := json.Unmarshal(sc.Bytes(), &r); err != nil {
+			t.Fatalf("decode log line %q: %v", sc.Text(), err)
+		}
+		got[r.Msg] = r
+	}
+
+	if r, ok := got["panic in handler"]; !ok || r.RPC.Method != "boom" || len(r.RPC.ID) == 0 {
+		t.Fatalf("panic log = %+v, want rpc.method=boom with an rpc.id\n%s", r, logs.String())
+	}
+	if r, ok := got["failed to handle notification"]; !ok || r.RPC.Method != "notify/fail" || r.RPC.ID != nil {
+		t.Fatalf("notification log = %+v, want rpc.method=notify/fail without an id\n%s", r, logs.String())
+	}
+}
@@ -2,8 +2,12 @@ package acp
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -13,19 +17,11 @@ import (
 func newNotificationBarrierTestPair(t *testing.T, client Client, agent Agent) (*ClientSideConnection, *AgentSideConnection) {
 	t.Helper()
 
-	c2aR, c2aW := io.Pipe()
-	a2cR, a2cW := io.Pipe()
-
-	clientConn := NewClientSideConnection(client, c2aW, a2cR)
-	agentConn := NewAgentSideConnection(agent, a2cW, c2aR)
-
+	agentConn, clientConn := NewInMemoryConnections(agent, client)
 	t.Cleanup(func() {
-		_ = c2aR.Close()
-		_ = c2aW.Close()
-		_ = a2cR.Close()
-		_ = a2cW.Close()
+		_ = clientConn.Close()
+		_ = agentConn.Close()
 	})
-
 	return clientConn, agentConn
 }
 
@@ -392,11 +388,9 @@ func TestShutdownDrainsNotifications_WithBarrier(t *testing.T) {
 		t.Fatalf("timeout waiting for shutdown notification handler to start")
 	}
 
-	if writer, ok := agentConn.conn.w.(*io.PipeWriter); ok {
-		_ = writer.Close()
-	} else {
-		t.Fatalf("expected io.PipeWriter, got %T", agentConn.conn.w)
-	}
+	// Closing the agent closes its end of the pipe, which the client sees as a
+	// peer disconnect.
+	_ = agentConn.Close()
 
 	select {
 	case <-clientConn.conn.inboundCtx.Done():
@@ -407,3 +401,187 @@ func TestShutdownDrainsNotifications_WithBarrier(t *testing.T) {
 		t.Fatalf("timeout waiting for shutdown to drain notifications")
 	}
 }
+
+func TestNotificationBarrierWatchdog(t *testing.T) {
+	t.Parallel()
+	for _, failRequest := range []bool{false, true} {
+		failRequest := failRequest
+		t.Run(fmt.Sprintf("fail=%v", failRequest), func(t *testing.T) {
+			t.Parallel()
+			c2aR, c2aW := io.Pipe()
+			a2cR, a2cW := io.Pipe()
+			t.Cleanup(func() {
+				_ = c2aW.Close()
+				_ = a2cW.Close()
+			})
+
+			release := make(chan struct{})
+			var releaseOnce sync.Once
+			t.Cleanup(func() { releaseOnce.Do(func() { close(release) }) })
+			clientConn := NewClientSideConnection(&clientFuncs{
+				SessionUpdateFunc: func(context.Context, SessionNotification) error {
+					<-release
+					return nil
+				},
+			}, c2aW, a2cR, WithNotificationBarrierWatchdog(20*time.Millisecond, failRequest))
+			var logs syncBuffer
+			clientConn.SetLogger(slog.New(slog.NewTextHandler(&logs, nil)))
+
+			var agentConn *AgentSideConnection
+			agentConn = NewAgentSideConnection(agentFuncs{
+				PromptFunc: func(ctx context.Context, p PromptRequest) (PromptResponse, error) {
+					if err := agentConn.SessionUpdate(ctx, testSessionUpdate(p.SessionId, 1)); err != nil {
+						return PromptResponse{}, err
+					}
+					return PromptResponse{StopReason: StopReasonEndTurn}, nil
+				},
+			}, a2cW, c2aR)
+
+			if !failRequest {
+				// Unblock the handler well after the watchdog fired.
+				time.AfterFunc(200*time.Millisecond, func() { releaseOnce.Do(func() { close(release) }) })
+			}
+			_, err := clientConn.Prompt(context.Background(), PromptRequest{SessionId: "s", Prompt: []ContentBlock{TextBlock("hi")}})
+			if failRequest {
+				var reqErr *RequestError
+				if !errors.As(err, &reqErr) || reqErr.Code != CodeInternalError {
+					t.Fatalf("expected internal error from watchdog, got %v", err)
+				}
+			} else if err != nil {
+				t.Fatalf("Prompt: %v", err)
+			}
+			out := logs.String()
+			for _, want := range []string{"response held back by slow notification handler", "method=session/prompt", "handling=session/update"} {
+				if !strings.Contains(out, want) {
+					t.Fatalf("log missing %q:\n%s", want, out)
+				}
+			}
+		})
+	}
+}
+
+func TestSendRequest_WaitsOnlyForOwnSessionNotifications(t *testing.T) {
+	handlerStarted := make(chan struct{})
+	releaseHandler := make(chan struct{})
+	var releaseOnce sync.Once
+	release := func() { releaseOnce.Do(func() { close(releaseHandler) }) }
+	t.Cleanup(release)
+
+	client := &clientFuncs{
+		SessionUpdateFunc: func(_ context.Context, n SessionNotification) error {
+			if n.SessionId == "busy" {
+				close(handlerStarted)
+				<-releaseHandler
+			}
+			return nil
+		},
+	}
+	agent := agentFuncs{
+		LoadSessionFunc: func(context.Context, LoadSessionRequest) (LoadSessionResponse, error) {
+			return LoadSessionResponse{}, nil
+		},
+		NewSessionFunc: func(context.Context, NewSessionRequest) (NewSessionResponse, error) {
+			return NewSessionResponse{SessionId: "new"}, nil
+		},
+	}
+	clientConn, agentConn := newNotificationBarrierTestPair(t, client, agent)
+
+	if err := agentConn.SessionUpdate(context.Background(), testSessionUpdate("busy", 1)); err != nil {
+		t.Fatalf("SessionUpdate: %v", err)
+	}
+	select {
+	case <-handlerStarted:
+	case <-time.After(time.Second):
+		t.Fatalf("timeout waiting for notification handler to start")
+	}
+
+	// Another session's response is not held back by the busy session.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := clientConn.LoadSession(ctx, testLoadSessionRequest("idle")); err != nil {
+		t.Fatalf("LoadSession for an unrelated session: %v", err)
+	}
+
+	// A request without a session still waits for every earlier notification.
+	newSessionDone := make(chan error, 1)
+	go func() {
+		_, err := clientConn.NewSession(context.Background(), NewSessionRequest{Cwd: "/", McpServers: []McpServer{}})
+		newSessionDone <- err
+	}()
+	select {
+	case err := <-newSessionDone:
+		t.Fatalf("NewSession returned before the notification handler finished: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+	release()
+	select {
+	case err := <-newSessionDone:
+		if err != nil {
+			t.Fatalf("NewSession: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("timeout waiting for NewSession after the notification handler finished")
+	}
+}
+
+func TestSendRequestTimed_SeparatesNotificationWait(t *testing.T) {
+	c2aR, c2aW := io.Pipe()
+	a2cR, a2cW := io.Pipe()
+	t.Cleanup(func() {
+		_ = c2aW.Close()
+		_ = a2cW.Close()
+	})
+
+	var server *Connection
+	server = NewConnection(func(ctx context.Context, method string, _ json.RawMessage) (any, *RequestError) {
+		if err := server.SendNotification(ctx, "progress", nil); err != nil {
+			return nil, toReqErr(err)
+		}
+		time.Sleep(30 * time.Millisecond)
+		return map[string]any{}, nil
+	}, a2cW, c2aR)
+	client := NewConnection(func(context.Context, string, json.RawMessage) (any, *RequestError) {
+		time.Sleep(100 * time.Millisecond)
+		return nil, nil
+	}, c2aW, a2cR)
+
+	_, timing, err := SendRequestTimed[map[string]any](client, context.Background(), "work", nil)
+	if err != nil {
+		t.Fatalf("SendRequestTimed: %v", err)
+	}
+	if timing.RoundTrip < 30*time.Millisecond {
+		t.Fatalf("RoundTrip = %s, want at least the handler's 30ms", timing.RoundTrip)
+	}
+	if timing.NotificationWait < 40*time.Millisecond {
+		t.Fatalf("NotificationWait = %s, want the rest of the notification handler's 100ms", timing.NotificationWait)
+	}
+}
+
+func TestWithoutNotificationBarrier(t *testing.T) {
+	c2aR, c2aW := io.Pipe()
+	a2cR, a2cW := io.Pipe()
+	t.Cleanup(func() {
+		_ = c2aW.Close()
+		_ = a2cW.Close()
+	})
+
+	var server *Connection
+	server = NewConnection(func(ctx context.Context, method string, _ json.RawMessage) (any, *RequestError) {
+		if err := server.SendNotification(ctx, "progress", nil); err != nil {
+			return nil, toReqErr(err)
+		}
+		return map[string]any{}, nil
+	}, a2cW, c2aR)
+	release := make(chan struct{})
+	client := NewConnection(func(context.Context, string, json.RawMessage) (any, *RequestError) {
+		<-release
+		return nil, nil
+	}, c2aW, a2cR)
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := client.SendRequestNoResult(WithoutNotificationBarrier(ctx), "work", nil); err != nil {
+		t.Fatalf("SendRequestNoResult returned %v while the notification handler was blocked", err)
+	}
+}
@@ -0,0 +1,186 @@
+package acp
+
+import (
+	"log/slog"
+	"time"
+)
+
+const (
+	defaultInitialMessageBufSize = 1024 * 1024
+	defaultMaxMessageBytes       = 10 * 1024 * 1024
+)
+
+// ConnectionOption configures optional Connection behavior at construction time.
+type ConnectionOption func(c *Connection)
+
+// WithLogger sets the logger used for internal connection diagnostics. Unlike
+// SetLogger, it takes effect before the connection starts reading, so it also
+// covers diagnostics about the first messages. If unset, logs are written via
+// the default logger.
+func WithLogger(l *slog.Logger) ConnectionOption {
+	return func(c *Connection) {
+		c.logger = l
+	}
+}
+
+// WithMaxMessageBytes sets the maximum size in bytes of a single inbound message.
+// Messages larger than n are discarded and reported to the connection logger;
+// the connection keeps reading subsequent messages. The default is 10MB.
+// The initial read buffer is capped at n so small limits also shrink memory use.
+func WithMaxMessageBytes(n int) ConnectionOption {
+	return func(c *Connection) {
+		if n <= 0 {
+			return
+		}
+		c.maxMessageBytes = n
+	}
+}
+
+// WithInitialMessageBufferBytes sets the size in bytes of the buffer inbound
+// messages are first read into. Larger messages are still accepted up to the
+// WithMaxMessageBytes limit, at the cost of extra copying, so n trades memory
+// per connection against throughput for big messages. The default is 1MB; a
+// size above the message limit is capped at it.
+func WithInitialMessageBufferBytes(n int) ConnectionOption {
+	return func(c *Connection) {
+		if n <= 0 {
+			return
+		}
+		c.initialMessageBufSize = n
+	}
+}
+
+// WithFraming selects how messages are delimited on the wire. Both peers must use
+// the same framing. The default is FramingNewline.
+func WithFraming(f Framing) ConnectionOption {
+	return func(c *Connection) {
+		c.framing = f
+	}
+}
+
+// WithWriteTimeout bounds how long a single write to the peer may block. When a
+// write exceeds d, it fails and the connection is closed, since a partially
+// written message leaves the stream unusable.
+//
+// Writers that implement SetWriteDeadline (such as net.Conn) use native
+// deadlines. For other writers, such as io.Pipe or os.Stdout, the write runs on a
+// background goroutine; after a timeout that goroutine remains blocked until the
+// writer is closed or the peer resumes reading.
+func WithWriteTimeout(d time.Duration) ConnectionOption {
+	return func(c *Connection) {
+		c.writeTimeout = d
+	}
+}
+
+// WithRequestTimeout sets a default timeout for outbound requests whose context
+// has no deadline. A timed out request sends $/cancel_request to the peer, just
+// like explicit cancellation, and fails with a request-cancelled error (-32800).
+// Contexts that already carry a deadline are left unchanged.
+func WithRequestTimeout(d time.Duration) ConnectionOption {
+	return func(c *Connection) {
+		c.requestTimeout = d
+	}
+}
+
+// WithHandlerTimeout limits how long an inbound request handler may run. Once d
+// has elapsed, the handler's context is cancelled with cause ErrHandlerTimeout
+// and the peer immediately receives an internal error (-32603) saying that the
+// handler timed out. A handler that keeps running has its eventual result
+// dropped, but it holds on to its WithMaxConcurrentRequests slot and keeps
+// Shutdown waiting until it returns. Notification handlers are not limited.
+func WithHandlerTimeout(d time.Duration) ConnectionOption {
+	return func(c *Connection) {
+		c.handlerTimeout = d
+	}
+}
+
+// WithKeepAlive enables periodic keepalive pings to detect half-open connections.
+// Every interval, the connection sends a "_ping" extension request; if no
+// response arrives within timeout, the connection is closed. Peers built on this
+// SDK answer "_ping" automatically, without involving the Agent or Client.
+// A non-positive timeout defaults to interval.
+func WithKeepAlive(interval, timeout time.Duration) ConnectionOption {
+	return func(c *Connection) {
+		if timeout <= 0 {
+			timeout = interval
+		}
+		c.keepAliveInterval = interval
+		c.keepAliveTimeout = timeout
+	}
+}
+
+// WithNotificationQueueSize sets how many inbound notifications may be queued
+// awaiting the handler. Notifications are always handled one at a time in arrival
+// order. The default is 1024.
+func WithNotificationQueueSize(n int) ConnectionOption {
+	return func(c *Connection) {
+		if n <= 0 {
+			return
+		}
+		c.notificationQueue = make(chan queuedNotification, n)
+	}
+}
+
+// WithNotificationBackpressure makes the connection stop reading from the peer
+// while the notification queue is full, resuming once the handler catches up. By
+// default, a full queue is treated as a misbehaving peer and the connection is
+// closed. With backpressure enabled, a slow notification handler also delays
+// responses to outbound requests, since they share the same reader.
+func WithNotificationBackpressure() ConnectionOption {
+	return func(c *Connection) {
+		c.notificationBackpressure = true
+	}
+}
+
+// WithMaxConcurrentRequests caps how many inbound request handlers run at once.
+// Further requests wait until a running handler finishes; a queued request
+// cancelled by the peer via $/cancel_request is answered with a cancellation error
+// without ever reaching the handler. Notifications are not counted against n, as
+// they are handled sequentially on their own queue. By default there is no limit.
+func WithMaxConcurrentRequests(n int) ConnectionOption {
+	return func(c *Connection) {
+		if n <= 0 {
+			return
+		}
+		c.requestSlots = make(chan struct{}, n)
+	}
+}
+
+// WithCancelQueueSize sets how many outbound $/cancel_request notifications may be
+// queued while earlier ones are still being written. When the queue is full, a
+// cancellation waits briefly for room before it is dropped. The default is 1024.
+func WithCancelQueueSize(n int) ConnectionOption {
+	return func(c *Connection) {
+		if n <= 0 {
+			return
+		}
+		c.cancelQueueSize = n
+	}
+}
+
+// WithOutboundCancelRequests controls whether a request whose context ends
+// before its response arrives sends $/cancel_request to the peer. It is enabled
+// by default; disable it for peers that reject the notification. The request
+// fails with a request cancelled error either way, and inbound $/cancel_request
+// handling is unaffected.
+func WithOutboundCancelRequests(enabled bool) ConnectionOption {
+	return func(c *Connection) {
+		c.suppressCancelRequests = !enabled
+	}
+}
+
+// WithNotificationBarrierWatchdog reports outbound requests whose response is
+// held back for longer than d while notifications the peer sent before it are
+// still being handled, which usually means a notification handler is stuck.
+// The connection logs a warning naming the request and the notification being
+// handled; if failRequest is set, the request also fails with an internal error
+// instead of waiting further. By default a request waits indefinitely.
+func WithNotificationBarrierWatchdog(d time.Duration, failRequest bool) ConnectionOption {
+	return func(c *Connection) {
+		if d <= 0 {
+			return
+		}
+		c.barrierWatchdog = d
+		c.barrierWatchdogFail = failRequest
+	}
+}
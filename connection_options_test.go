@@ -0,0 +1,528 @@
+package acp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConnectionMaxMessageBytes_DiscardsOversizedLineAndContinues(t *testing.T) {
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	defer func() {
+		_ = inW.Close()
+		_ = outW.Close()
+		_ = inR.Close()
+		_ = outR.Close()
+	}()
+
+	c := NewConnection(func(ctx context.Context, method string, params json.RawMessage) (any, *RequestError) {
+		return map[string]any{"method": method}, nil
+	}, outW, inR, WithMaxMessageBytes(128))
+
+	var logBuf bytes.Buffer
+	c.SetLogger(slog.New(slog.NewTextHandler(&logBuf, nil)))
+
+	lines := make(chan []byte, 10)
+	go func() {
+		scanner := bufio.NewScanner(outR)
+		for scanner.Scan() {
+			lines <- append([]byte(nil), scanner.Bytes()...)
+		}
+		close(lines)
+	}()
+
+	big := `{"jsonrpc":"2.0","id":7,"method":"big","params":{"data":"` + strings.Repeat("x", 512) + `"}}` + "\n"
+	if _, err := inW.Write([]byte(big)); err != nil {
+		t.Fatalf("write oversized request: %v", err)
+	}
+	if _, err := inW.Write([]byte(`{"jsonrpc":"2.0","id":8,"method":"small","params":{}}` + "\n")); err != nil {
+		t.Fatalf("write request: %v", err)
+	}
+
+	var raw []byte
+	select {
+	case raw = <-lines:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for response")
+	}
+
+	var msg anyMessage
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+	if msg.ID == nil || string(*msg.ID) != "8" {
+		t.Fatalf("expected response to id 8, got %s", string(raw))
+	}
+
+	logs := logBuf.String()
+	if !strings.Contains(logs, "exceeds size limit") {
+		t.Fatalf("expected oversized message log, got: %s", logs)
+	}
+	if !strings.Contains(logs, "method=big") || !strings.Contains(logs, "id=7") {
+		t.Fatalf("expected method and id in log, got: %s", logs)
+	}
+
+	select {
+	case <-c.Done():
+		t.Fatal("connection closed after oversized message")
+	default:
+	}
+}
+
+func TestConnectionInitialMessageBufferBytes(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		opts []ConnectionOption
+		want int
+	}{
+		{name: "default", want: defaultInitialMessageBufSize},
+		{name: "initial", opts: []ConnectionOption{WithInitialMessageBufferBytes(4096)}, want: 4096},
+		{name: "capped by max", opts: []ConnectionOption{WithInitialMessageBufferBytes(4096), WithMaxMessageBytes(1024)}, want: 1024},
+		{name: "max then initial", opts: []ConnectionOption{WithMaxMessageBytes(1024), WithInitialMessageBufferBytes(512)}, want: 512},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewConnection(nil, io.Discard, strings.NewReader(""), tc.opts...)
+			if got := c.readBufferSize(); got != tc.want {
+				t.Fatalf("read buffer size = %d, want %d", got, tc.want)
+			}
+		})
+	}
+
+	// Messages larger than the initial buffer are still read whole.
+	c2aR, c2aW := io.Pipe()
+	a2cR, a2cW := io.Pipe()
+	defer func() {
+		_ = c2aW.Close()
+		_ = a2cW.Close()
+	}()
+	NewConnection(func(ctx context.Context, method string, params json.RawMessage) (any, *RequestError) {
+		return params, nil
+	}, a2cW, c2aR, WithInitialMessageBufferBytes(64))
+	client := NewConnection(nil, c2aW, a2cR)
+	data := strings.Repeat("x", 4096)
+	resp, err := SendRequest[map[string]string](client, context.Background(), "echo", map[string]string{"data": data})
+	if err != nil || resp["data"] != data {
+		t.Fatalf("echo of a message larger than the initial buffer failed: %v", err)
+	}
+}
+
+func TestConnectionWriteTimeout_FailsConnection(t *testing.T) {
+	pipeWriter := func(t *testing.T) (io.Writer, io.Reader) {
+		inR, inW := io.Pipe()
+		_, outW := io.Pipe()
+		t.Cleanup(func() {
+			_ = inR.Close()
+			_ = inW.Close()
+			_ = outW.Close()
+		})
+		return outW, inR
+	}
+	netPipeWriter := func(t *testing.T) (io.Writer, io.Reader) {
+		a, b := net.Pipe()
+		t.Cleanup(func() {
+			_ = a.Close()
+			_ = b.Close()
+		})
+		return a, a
+	}
+
+	for name, setup := range map[string]func(*testing.T) (io.Writer, io.Reader){
+		"io.Pipe":  pipeWriter,
+		"net.Pipe": netPipeWriter,
+	} {
+		t.Run(name, func(t *testing.T) {
+			w, r := setup(t)
+			c := NewConnection(nil, w, r, WithWriteTimeout(50*time.Millisecond))
+			c.SetLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+			start := time.Now()
+			err := c.SendNotification(context.Background(), "stuck", nil)
+			if err == nil {
+				t.Fatal("expected write timeout error")
+			}
+			if elapsed := time.Since(start); elapsed > 2*time.Second {
+				t.Fatalf("write did not time out promptly: %v", elapsed)
+			}
+
+			select {
+			case <-c.Done():
+			case <-time.After(2 * time.Second):
+				t.Fatal("connection not closed after write timeout")
+			}
+
+			if err := c.SendNotification(context.Background(), "after", nil); err == nil {
+				t.Fatal("expected subsequent writes to fail")
+			}
+		})
+	}
+}
+
+func TestConnectionRequestTimeout_CancelsRequestWithoutDeadline(t *testing.T) {
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	defer func() {
+		_ = inW.Close()
+		_ = outW.Close()
+		_ = inR.Close()
+		_ = outR.Close()
+	}()
+
+	c := NewConnection(nil, outW, inR, WithRequestTimeout(50*time.Millisecond))
+
+	lines := make(chan []byte, 10)
+	go func() {
+		scanner := bufio.NewScanner(outR)
+		for scanner.Scan() {
+			lines <- append([]byte(nil), scanner.Bytes()...)
+		}
+		close(lines)
+	}()
+
+	_, err := SendRequest[json.RawMessage](c, context.Background(), "slow", nil)
+	re, ok := err.(*RequestError)
+	if !ok {
+		t.Fatalf("expected *RequestError, got %T: %v", err, err)
+	}
+	if re.Code != -32800 {
+		t.Fatalf("expected error code -32800, got %d (%s)", re.Code, re.Message)
+	}
+
+	var methods []string
+	for len(methods) < 2 {
+		select {
+		case raw := <-lines:
+			var msg anyMessage
+			if err := json.Unmarshal(raw, &msg); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+			methods = append(methods, msg.Method)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for outbound messages, got %v", methods)
+		}
+	}
+	if methods[0] != "slow" || methods[1] != "$/cancel_request" {
+		t.Fatalf("unexpected outbound messages: %v", methods)
+	}
+}
+
+func TestConnectionHandlerTimeout_FailsStuckHandler(t *testing.T) {
+	c2aR, c2aW := io.Pipe()
+	a2cR, a2cW := io.Pipe()
+	defer func() {
+		_ = c2aW.Close()
+		_ = a2cW.Close()
+	}()
+
+	causes := make(chan error, 1)
+	notificationDeadline := make(chan bool, 1)
+	NewConnection(func(ctx context.Context, method string, _ json.RawMessage) (any, *RequestError) {
+		if method == "notify" {
+			_, ok := ctx.Deadline()
+			notificationDeadline <- ok
+			return nil, nil
+		}
+		<-ctx.Done()
+		causes <- context.Cause(ctx)
+		return nil, toReqErr(ctx.Err())
+	}, a2cW, c2aR, WithHandlerTimeout(50*time.Millisecond))
+	client := NewConnection(nil, c2aW, a2cR)
+
+	_, err := SendRequest[json.RawMessage](client, context.Background(), "stuck", nil)
+	var re *RequestError
+	if !errors.As(err, &re) || re.Code != CodeInternalError || !strings.Contains(re.Error(), "timed out after 50ms") {
+		t.Fatalf("expected an internal error reporting the timeout, got %v", err)
+	}
+	if cause := <-causes; !errors.Is(cause, ErrHandlerTimeout) {
+		t.Fatalf("handler context cause = %v, want ErrHandlerTimeout", cause)
+	}
+
+	if err := client.SendNotification(context.Background(), "notify", nil); err != nil {
+		t.Fatalf("SendNotification: %v", err)
+	}
+	if <-notificationDeadline {
+		t.Fatal("notification handler context has a deadline")
+	}
+}
+
+func TestConnectionHandlerTimeout_AnswersWithoutWaitingForHandler(t *testing.T) {
+	c2aR, c2aW := io.Pipe()
+	a2cR, a2cW := io.Pipe()
+	defer func() {
+		_ = c2aW.Close()
+		_ = a2cW.Close()
+	}()
+
+	unblock := make(chan struct{})
+	server := NewConnection(func(ctx context.Context, method string, _ json.RawMessage) (any, *RequestError) {
+		if method == "ignores_ctx" {
+			<-unblock
+			return map[string]any{"late": true}, nil
+		}
+		return map[string]any{}, nil
+	}, a2cW, c2aR, WithHandlerTimeout(50*time.Millisecond), WithMaxConcurrentRequests(1))
+	client := NewConnection(nil, c2aW, a2cR)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	_, err := SendRequest[json.RawMessage](client, ctx, "ignores_ctx", nil)
+	var re *RequestError
+	if !errors.As(err, &re) || re.Code != CodeInternalError || !strings.Contains(re.Error(), "timed out after 50ms") {
+		t.Fatalf("expected an internal error reporting the timeout, got %v", err)
+	}
+
+	// The stuck handler still holds the only request slot and keeps Shutdown
+	// waiting.
+	shortCtx, shortCancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer shortCancel()
+	if _, err := SendRequest[json.RawMessage](client, shortCtx, "fast", nil); err == nil {
+		t.Fatal("request ran while the timed out handler held the only slot")
+	}
+	if server.idle(0) {
+		t.Fatal("connection reported idle while the timed out handler was running")
+	}
+
+	close(unblock)
+	if _, err := SendRequest[json.RawMessage](client, ctx, "fast", nil); err != nil {
+		t.Fatalf("request after the timed out handler returned: %v", err)
+	}
+	if err := server.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+}
+
+func TestConnectionRequestTimeout_KeepsCallerDeadline(t *testing.T) {
+	c2aR, c2aW := io.Pipe()
+	a2cR, a2cW := io.Pipe()
+	defer func() {
+		_ = c2aR.Close()
+		_ = c2aW.Close()
+		_ = a2cR.Close()
+		_ = a2cW.Close()
+	}()
+
+	_ = NewConnection(func(ctx context.Context, method string, params json.RawMessage) (any, *RequestError) {
+		time.Sleep(100 * time.Millisecond)
+		return map[string]any{}, nil
+	}, a2cW, c2aR)
+	c := NewConnection(nil, c2aW, a2cR, WithRequestTimeout(10*time.Millisecond))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if _, err := SendRequest[json.RawMessage](c, ctx, "slow", nil); err != nil {
+		t.Fatalf("expected caller deadline to take precedence, got %v", err)
+	}
+}
+
+func TestConnectionKeepAlive_PeerAnswersPingAutomatically(t *testing.T) {
+	c2aR, c2aW := io.Pipe()
+	a2cR, a2cW := io.Pipe()
+	defer func() {
+		_ = c2aR.Close()
+		_ = c2aW.Close()
+		_ = a2cR.Close()
+		_ = a2cW.Close()
+	}()
+
+	handlerCalled := make(chan string, 1)
+	_ = NewConnection(func(ctx context.Context, method string, params json.RawMessage) (any, *RequestError) {
+		handlerCalled <- method
+		return nil, NewMethodNotFound(method)
+	}, a2cW, c2aR)
+	c := NewConnection(nil, c2aW, a2cR, WithKeepAlive(10*time.Millisecond, time.Second))
+
+	select {
+	case <-c.Done():
+		t.Fatalf("connection closed despite responsive peer: %v", context.Cause(c.ctx))
+	case method := <-handlerCalled:
+		t.Fatalf("handler unexpectedly invoked for %q", method)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestConnectionKeepAlive_ClosesWhenPeerUnresponsive(t *testing.T) {
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	defer func() {
+		_ = inW.Close()
+		_ = outW.Close()
+		_ = inR.Close()
+		_ = outR.Close()
+	}()
+	go func() { _, _ = io.Copy(io.Discard, outR) }()
+
+	c := NewConnection(nil, outW, inR, WithKeepAlive(10*time.Millisecond, 20*time.Millisecond))
+	c.SetLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	select {
+	case <-c.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("connection not closed after keepalive timeout")
+	}
+	if cause := context.Cause(c.ctx); !errors.Is(cause, ErrKeepAliveTimeout) {
+		t.Fatalf("unexpected close cause: %v", cause)
+	}
+}
+
+func TestConnectionNotificationBackpressure_BlocksInsteadOfClosing(t *testing.T) {
+	incomingR, incomingW := io.Pipe()
+	defer func() {
+		_ = incomingR.Close()
+		_ = incomingW.Close()
+	}()
+
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var seen []int
+	c := NewConnection(func(ctx context.Context, method string, params json.RawMessage) (any, *RequestError) {
+		<-release
+		var p struct{ N int }
+		_ = json.Unmarshal(params, &p)
+		mu.Lock()
+		seen = append(seen, p.N)
+		mu.Unlock()
+		return nil, nil
+	}, io.Discard, incomingR, WithNotificationQueueSize(2), WithNotificationBackpressure())
+
+	const total = 10
+	written := make(chan error, 1)
+	go func() {
+		for i := 0; i < total; i++ {
+			if _, err := fmt.Fprintf(incomingW, `{"jsonrpc":"2.0","method":"test/notify","params":{"n":%d}}`+"\n", i); err != nil {
+				written <- err
+				return
+			}
+		}
+		written <- nil
+	}()
+
+	// The reader must stall once the queue is full rather than close the connection.
+	select {
+	case err := <-written:
+		t.Fatalf("writer finished without backpressure: %v", err)
+	case <-c.Done():
+		t.Fatalf("connection closed: %v", context.Cause(c.ctx))
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(release)
+	if err := <-written; err != nil {
+		t.Fatalf("write notifications: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		n := len(seen)
+		mu.Unlock()
+		if n == total {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(seen) != total {
+		t.Fatalf("handled %d notifications, want %d", len(seen), total)
+	}
+	for i, n := range seen {
+		if n != i {
+			t.Fatalf("notifications handled out of order: %v", seen)
+		}
+	}
+}
+
+func TestConnectionMaxConcurrentRequests_QueuesAndHonorsCancel(t *testing.T) {
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	defer func() {
+		_ = inR.Close()
+		_ = inW.Close()
+		_ = outR.Close()
+		_ = outW.Close()
+	}()
+
+	slowStarted := make(chan struct{})
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var started []string
+	_ = NewConnection(func(ctx context.Context, method string, params json.RawMessage) (any, *RequestError) {
+		mu.Lock()
+		started = append(started, method)
+		mu.Unlock()
+		if method == "slow" {
+			close(slowStarted)
+			<-release
+		}
+		return map[string]any{}, nil
+	}, outW, inR, WithMaxConcurrentRequests(1))
+
+	lines := make(chan string, 10)
+	go func() {
+		scanner := bufio.NewScanner(outR)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+
+	if _, err := io.WriteString(inW, `{"jsonrpc":"2.0","id":1,"method":"slow"}`+"\n"); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	select {
+	case <-slowStarted:
+	case <-time.After(2 * time.Second):
+		t.Fatal("first request did not start")
+	}
+	for _, msg := range []string{
+		`{"jsonrpc":"2.0","id":2,"method":"queued"}`,
+		`{"jsonrpc":"2.0","method":"$/cancel_request","params":{"requestId":2}}`,
+	} {
+		if _, err := io.WriteString(inW, msg+"\n"); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	readResponse := func() anyMessage {
+		t.Helper()
+		select {
+		case line := <-lines:
+			var msg anyMessage
+			if err := json.Unmarshal([]byte(line), &msg); err != nil {
+				t.Fatalf("decode %q: %v", line, err)
+			}
+			return msg
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for response")
+			return anyMessage{}
+		}
+	}
+
+	res := readResponse()
+	if string(*res.ID) != "2" || res.Error == nil || res.Error.Code != -32800 {
+		t.Fatalf("expected cancellation for queued request, got id=%s err=%v", *res.ID, res.Error)
+	}
+
+	close(release)
+	res = readResponse()
+	if string(*res.ID) != "1" || res.Error != nil {
+		t.Fatalf("expected success for running request, got id=%s err=%v", *res.ID, res.Error)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(started) != 1 || started[0] != "slow" {
+		t.Fatalf("cancelled request reached the handler: %v", started)
+	}
+}
@@ -0,0 +1,95 @@
+package acp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConnection_RecoversFromHandlerPanic(t *testing.T) {
+	c2aR, c2aW := io.Pipe()
+	a2cR, a2cW := io.Pipe()
+	defer func() {
+		_ = c2aR.Close()
+		_ = c2aW.Close()
+		_ = a2cR.Close()
+		_ = a2cW.Close()
+	}()
+
+	notified := make(chan struct{}, 1)
+	server := NewConnection(func(ctx context.Context, method string, params json.RawMessage) (any, *RequestError) {
+		switch method {
+		case "boom", "notify/boom":
+			panic("secret detail")
+		case "notify/ok":
+			notified <- struct{}{}
+		}
+		return map[string]any{}, nil
+	}, a2cW, c2aR)
+	var logs syncBuffer
+	server.SetLogger(slog.New(slog.NewTextHandler(&logs, nil)))
+	client := NewConnection(nil, c2aW, a2cR)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	err := client.SendRequestNoResult(ctx, "boom", nil)
+	re, ok := err.(*RequestError)
+	if !ok || re.Code != -32603 {
+		t.Fatalf("expected -32603 error, got %v", err)
+	}
+	if strings.Contains(re.Error(), "secret detail") {
+		t.Fatalf("panic value leaked to peer: %v", re)
+	}
+
+	if err := client.SendNotification(ctx, "notify/boom", nil); err != nil {
+		t.Fatalf("SendNotification: %v", err)
+	}
+	if err := client.SendNotification(ctx, "notify/ok", nil); err != nil {
+		t.Fatalf("SendNotification: %v", err)
+	}
+	select {
+	case <-notified:
+	case <-time.After(2 * time.Second):
+		t.Fatal("notifications stopped being handled after a panic")
+	}
+
+	if err := client.SendRequestNoResult(ctx, "ok", nil); err != nil {
+		t.Fatalf("connection unusable after panic: %v", err)
+	}
+	// inflight entries are removed just after the response is written.
+	deadline := time.Now().Add(2 * time.Second)
+	for server.Stats().InflightRequests != 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := server.Stats().InflightRequests; got != 0 {
+		t.Fatalf("inflight entries leaked after panic: %d", got)
+	}
+	if out := logs.String(); !strings.Contains(out, "panic in handler") || !strings.Contains(out, "method=notify/boom") || !strings.Contains(out, "goroutine") {
+		t.Fatalf("panic not logged with stack: %s", out)
+	}
+}
+
+// syncBuffer is a bytes.Buffer that is safe for concurrent use by slog handlers.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
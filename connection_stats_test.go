@@ -0,0 +1,120 @@
+package acp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestConnectionStats_TracksPendingAndInflight(t *testing.T) {
+	c2aR, c2aW := io.Pipe()
+	a2cR, a2cW := io.Pipe()
+	defer func() {
+		_ = c2aR.Close()
+		_ = c2aW.Close()
+		_ = a2cR.Close()
+		_ = a2cW.Close()
+	}()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	server := NewConnection(func(ctx context.Context, method string, params json.RawMessage) (any, *RequestError) {
+		close(started)
+		<-release
+		return map[string]any{}, nil
+	}, a2cW, c2aR)
+	client := NewConnection(nil, c2aW, a2cR)
+
+	if got := client.Stats(); got != (ConnectionStats{}) {
+		t.Fatalf("expected empty stats, got %+v", got)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- client.SendRequestNoResult(context.Background(), "block", nil)
+	}()
+
+	select {
+	case <-started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("handler did not start")
+	}
+
+	if got := client.Stats(); got.PendingRequests != 1 {
+		t.Fatalf("client stats = %+v, want 1 pending request", got)
+	}
+	if got := server.Stats(); got.InflightRequests != 1 {
+		t.Fatalf("server stats = %+v, want 1 inflight request", got)
+	}
+	if got := server.InflightRequests(); len(got) != 1 || got[0].Method != "block" || string(got[0].ID) != "1" || got[0].Elapsed < 0 {
+		t.Fatalf("InflightRequests() = %+v, want the blocked request", got)
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("request: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for server.Stats().InflightRequests != 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := server.Stats(); got.InflightRequests != 0 {
+		t.Fatalf("server stats = %+v, want no inflight requests", got)
+	}
+	if got := server.InflightRequests(); len(got) != 0 {
+		t.Fatalf("InflightRequests() = %+v after the request completed", got)
+	}
+	if got := client.Stats(); got.PendingRequests != 0 {
+		t.Fatalf("client stats = %+v, want no pending requests", got)
+	}
+}
+
+func TestConnectionStats_TracksNotificationBacklog(t *testing.T) {
+	c2aR, c2aW := io.Pipe()
+	a2cR, a2cW := io.Pipe()
+	defer func() {
+		_ = c2aR.Close()
+		_ = c2aW.Close()
+		_ = a2cR.Close()
+		_ = a2cW.Close()
+	}()
+
+	started := make(chan struct{}, 3)
+	release := make(chan struct{})
+	server := NewConnection(func(ctx context.Context, method string, params json.RawMessage) (any, *RequestError) {
+		started <- struct{}{}
+		<-release
+		return nil, nil
+	}, a2cW, c2aR)
+	client := NewConnection(nil, c2aW, a2cR)
+
+	for i := 0; i < 3; i++ {
+		if err := client.SendNotification(context.Background(), "note", nil); err != nil {
+			t.Fatalf("notification %d: %v", i, err)
+		}
+	}
+	select {
+	case <-started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("handler did not start")
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for server.Stats().PendingNotifications != 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := server.Stats(); got.PendingNotifications != 3 || got.NotificationHighWaterMark != 3 {
+		t.Fatalf("server stats = %+v, want 3 pending notifications and a high-water mark of 3", got)
+	}
+
+	close(release)
+	deadline = time.Now().Add(2 * time.Second)
+	for server.Stats().PendingNotifications != 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := server.Stats(); got.PendingNotifications != 0 || got.NotificationHighWaterMark != 3 {
+		t.Fatalf("server stats = %+v, want no pending notifications and a high-water mark of 3", got)
+	}
+}
@@ -5,6 +5,9 @@ package acp
 // ProtocolVersionNumber is the ACP protocol version supported by this SDK.
 const ProtocolVersionNumber = 1
 
+// SchemaVersion is the ACP schema release these bindings were generated from.
+const SchemaVersion = "0.13.5"
+
 // Agent method names
 const (
 	AgentMethodAuthenticate           = "authenticate"
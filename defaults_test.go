@@ -5,11 +5,11 @@ import (
 	"testing"
 )
 
-// Ensure InitializeResponse.authMethods encodes to [] when nil or empty,
+// Ensure InitializeResponse.authMethods is omitted when nil or empty,
 // and decodes to [] when missing or null.
 func TestInitializeResponse_AuthMethods_Defaults(t *testing.T) {
 	t.Parallel()
-	t.Run("marshal_nil_slice_encodes_empty_array", func(t *testing.T) {
+	t.Run("marshal_nil_slice_is_omitted", func(t *testing.T) {
 		t.Parallel()
 		resp := InitializeResponse{ProtocolVersion: 1}
 		b, err := json.Marshal(resp)
@@ -20,17 +20,12 @@ func TestInitializeResponse_AuthMethods_Defaults(t *testing.T) {
 		if err := json.Unmarshal(b, &m); err != nil {
 			t.Fatalf("roundtrip unmarshal error: %v", err)
 		}
-		v, ok := m["authMethods"]
-		if !ok {
-			t.Fatalf("authMethods missing in JSON: %s", string(b))
-		}
-		arr, ok := v.([]any)
-		if !ok || len(arr) != 0 {
-			t.Fatalf("authMethods should be empty array; got: %#v (json=%s)", v, string(b))
+		if v, ok := m["authMethods"]; ok {
+			t.Fatalf("authMethods should be absent; got: %#v (json=%s)", v, string(b))
 		}
 	})
 
-	t.Run("marshal_empty_slice_encodes_empty_array", func(t *testing.T) {
+	t.Run("marshal_empty_slice_is_omitted", func(t *testing.T) {
 		t.Parallel()
 		resp := InitializeResponse{ProtocolVersion: 1, AuthMethods: []AuthMethod{}}
 		b, err := json.Marshal(resp)
@@ -41,13 +36,8 @@ func TestInitializeResponse_AuthMethods_Defaults(t *testing.T) {
 		if err := json.Unmarshal(b, &m); err != nil {
 			t.Fatalf("roundtrip unmarshal error: %v", err)
 		}
-		v, ok := m["authMethods"]
-		if !ok {
-			t.Fatalf("authMethods missing in JSON: %s", string(b))
-		}
-		arr, ok := v.([]any)
-		if !ok || len(arr) != 0 {
-			t.Fatalf("authMethods should be empty array; got: %#v (json=%s)", v, string(b))
+		if v, ok := m["authMethods"]; ok {
+			t.Fatalf("authMethods should be absent; got: %#v (json=%s)", v, string(b))
 		}
 	})
 
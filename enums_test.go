@@ -0,0 +1,39 @@
+package acp
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestEnumHelpers(t *testing.T) {
+	all := AllStopReason()
+	if len(all) != 5 || all[0] != StopReasonEndTurn {
+		t.Fatalf("AllStopReason() = %v", all)
+	}
+	for _, v := range all {
+		if !v.IsValid() {
+			t.Fatalf("%q should be valid", v)
+		}
+	}
+	if StopReason("bogus").IsValid() {
+		t.Fatalf("unknown stop reason reported as valid")
+	}
+	if got := fmt.Sprint(ToolKindSwitchMode); got != "switch_mode" {
+		t.Fatalf("String() = %q", got)
+	}
+	if ToolKind("").IsValid() {
+		t.Fatalf("empty tool kind reported as valid")
+	}
+}
+
+func TestValidateRejectsUnknownEnumValues(t *testing.T) {
+	ok := PromptResponse{StopReason: StopReasonEndTurn}
+	if err := ok.Validate(); err != nil {
+		t.Fatalf("valid response rejected: %v", err)
+	}
+	bad := PromptResponse{StopReason: "frobnicate"}
+	err := bad.Validate()
+	if err == nil || err.Error() != `invalid stopReason: "frobnicate"` {
+		t.Fatalf("Validate() = %v", err)
+	}
+}
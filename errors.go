@@ -12,6 +12,10 @@ type RequestError struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
 	Data    any    `json:"data,omitempty"`
+
+	// cause is the local error this RequestError was converted from, if any.
+	// It is never sent over the wire.
+	cause error
 }
 
 func (e *RequestError) Error() string {
@@ -39,32 +43,138 @@ func (e *RequestError) Error() string {
 	return fmt.Sprintf("code %d: %s", e.Code, e.Message)
 }
 
+// Error codes defined by JSON-RPC 2.0 and ACP.
+const (
+	CodeParseError       = -32700
+	CodeInvalidRequest   = -32600
+	CodeMethodNotFound   = -32601
+	CodeInvalidParams    = -32602
+	CodeInternalError    = -32603
+	CodeAuthRequired     = -32000
+	CodeResourceNotFound = -32002
+	CodeRequestCancelled = -32800
+)
+
+// Sentinel errors for the standard JSON-RPC and ACP error codes. A RequestError
+// matches a sentinel under errors.Is when their codes are equal, regardless of
+// Message and Data:
+//
+//	if errors.Is(err, acp.ErrMethodNotFound) { ... }
+var (
+	ErrParse            = &RequestError{Code: CodeParseError, Message: "Parse error"}
+	ErrInvalidRequest   = &RequestError{Code: CodeInvalidRequest, Message: "Invalid request"}
+	ErrMethodNotFound   = &RequestError{Code: CodeMethodNotFound, Message: "Method not found"}
+	ErrInvalidParams    = &RequestError{Code: CodeInvalidParams, Message: "Invalid params"}
+	ErrInternal         = &RequestError{Code: CodeInternalError, Message: "Internal error"}
+	ErrAuthRequired     = &RequestError{Code: CodeAuthRequired, Message: "Authentication required"}
+	ErrResourceNotFound = &RequestError{Code: CodeResourceNotFound, Message: "Resource not found"}
+	ErrRequestCancelled = &RequestError{Code: CodeRequestCancelled, Message: "Request cancelled"}
+)
+
+// Unwrap returns the Go error a handler returned, when e was converted from one.
+// It lets local logging and middleware inspect the original with errors.Is and
+// errors.As; the cause is not transmitted to the peer.
+func (e *RequestError) Unwrap() error {
+	if e == nil {
+		return nil
+	}
+	return e.cause
+}
+
+// DataAs decodes e.Data into v, which must be a pointer, as if Data were the raw
+// JSON received from the peer. It returns an error if e carries no data.
+func (e *RequestError) DataAs(v any) error {
+	if e == nil || e.Data == nil {
+		return errors.New("request error has no data")
+	}
+	raw, ok := e.Data.(json.RawMessage)
+	if !ok {
+		var err error
+		if raw, err = json.Marshal(e.Data); err != nil {
+			return fmt.Errorf("encode error data: %w", err)
+		}
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("decode error data: %w", err)
+	}
+	return nil
+}
+
+// Is reports whether target is a *RequestError with the same code as e.
+func (e *RequestError) Is(target error) bool {
+	t, ok := target.(*RequestError)
+	return ok && e != nil && t != nil && e.Code == t.Code
+}
+
 func NewParseError(data any) *RequestError {
-	return &RequestError{Code: -32700, Message: "Parse error", Data: data}
+	return &RequestError{Code: CodeParseError, Message: "Parse error", Data: data}
 }
 
 func NewInvalidRequest(data any) *RequestError {
-	return &RequestError{Code: -32600, Message: "Invalid request", Data: data}
+	return &RequestError{Code: CodeInvalidRequest, Message: "Invalid request", Data: data}
 }
 
 func NewMethodNotFound(method string) *RequestError {
-	return &RequestError{Code: -32601, Message: "Method not found", Data: map[string]any{"method": method}}
+	return &RequestError{Code: CodeMethodNotFound, Message: "Method not found", Data: map[string]any{"method": method}}
 }
 
 func NewInvalidParams(data any) *RequestError {
-	return &RequestError{Code: -32602, Message: "Invalid params", Data: data}
+	return &RequestError{Code: CodeInvalidParams, Message: "Invalid params", Data: data}
+}
+
+// ValidationError is returned by the generated Validate methods when a field
+// fails a schema check. Incoming requests that fail validation are rejected
+// with an invalid params error whose data carries the error message under
+// "error" and Field and Reason under "field" and "reason".
+type ValidationError struct {
+	// Field is the JSON name of the offending field.
+	Field string
+	// Reason is a short description of the failed check, such as "required",
+	// "unknown enum" or "below minimum".
+	Reason string
+
+	msg string
+}
+
+func newValidationError(field, reason, format string, args ...any) error {
+	return &ValidationError{Field: field, Reason: reason, msg: fmt.Sprintf(format, args...)}
+}
+
+func (e *ValidationError) Error() string { return e.msg }
+
+// invalidParamsFromValidation converts a Validate failure into an invalid params
+// error, exposing the offending field if err is a *ValidationError.
+func invalidParamsFromValidation(err error) *RequestError {
+	data := map[string]any{"error": err.Error()}
+	var ve *ValidationError
+	if errors.As(err, &ve) {
+		data["field"] = ve.Field
+		data["reason"] = ve.Reason
+	}
+	return &RequestError{Code: CodeInvalidParams, Message: "Invalid params", Data: data, cause: err}
 }
 
 func NewInternalError(data any) *RequestError {
-	return &RequestError{Code: -32603, Message: "Internal error", Data: data}
+	return &RequestError{Code: CodeInternalError, Message: "Internal error", Data: data}
 }
 
 func NewRequestCancelled(data any) *RequestError {
-	return &RequestError{Code: -32800, Message: "Request cancelled", Data: data}
+	return &RequestError{Code: CodeRequestCancelled, Message: "Request cancelled", Data: data}
 }
 
 func NewAuthRequired(data any) *RequestError {
-	return &RequestError{Code: -32000, Message: "Authentication required", Data: data}
+	return &RequestError{Code: CodeAuthRequired, Message: "Authentication required", Data: data}
+}
+
+// NewResourceNotFound reports that the resource at uri, such as a file, does not exist.
+func NewResourceNotFound(uri string) *RequestError {
+	return &RequestError{Code: CodeResourceNotFound, Message: "Resource not found", Data: map[string]any{"uri": uri}}
+}
+
+// NewSessionNotFound reports that the agent has no session with the given ID.
+// ACP has no dedicated code for this, so it uses CodeResourceNotFound.
+func NewSessionNotFound(sessionId SessionId) *RequestError {
+	return &RequestError{Code: CodeResourceNotFound, Message: "Session not found", Data: map[string]any{"sessionId": sessionId}}
 }
 
 // toReqErr coerces arbitrary errors into JSON-RPC RequestError.
@@ -75,8 +185,14 @@ func toReqErr(err error) *RequestError {
 	if re, ok := err.(*RequestError); ok {
 		return re
 	}
-	if errors.Is(err, context.Canceled) {
-		return NewRequestCancelled(map[string]any{"error": err.Error()})
+	var re *RequestError
+	// A deadline is a cancellation from the caller's point of view. Reporting it
+	// as an internal error would make clients treat a timeout as a broken agent.
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		re = NewRequestCancelled(map[string]any{"error": err.Error()})
+	} else {
+		re = NewInternalError(map[string]any{"error": err.Error()})
 	}
-	return NewInternalError(map[string]any{"error": err.Error()})
+	re.cause = err
+	return re
 }
@@ -2,7 +2,9 @@ package acp
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"testing"
 )
 
@@ -17,12 +19,101 @@ func TestToReqErr_ContextCanceledMapsToRequestCancelled(t *testing.T) {
 	}
 }
 
-func TestToReqErr_DeadlineExceededMapsToInternalError(t *testing.T) {
+func TestToReqErr_DeadlineExceededMapsToRequestCancelled(t *testing.T) {
 	re := toReqErr(context.DeadlineExceeded)
 	if re == nil {
 		t.Fatal("expected request error")
 	}
-	if re.Code != -32603 {
-		t.Fatalf("expected code -32603, got %d", re.Code)
+	if re.Code != -32800 {
+		t.Fatalf("expected code -32800, got %d", re.Code)
+	}
+}
+
+func TestRequestError_IsMatchesSentinelByCode(t *testing.T) {
+	err := fmt.Errorf("call failed: %w", NewMethodNotFound("foo/bar"))
+	if !errors.Is(err, ErrMethodNotFound) {
+		t.Fatal("expected errors.Is to match ErrMethodNotFound")
+	}
+	if errors.Is(err, ErrInvalidParams) {
+		t.Fatal("unexpected match with ErrInvalidParams")
+	}
+	if !errors.Is(toReqErr(context.Canceled), ErrRequestCancelled) {
+		t.Fatal("expected cancelled error to match ErrRequestCancelled")
+	}
+	if !errors.Is(&RequestError{Code: -32602, Message: "custom", Data: "x"}, ErrInvalidParams) {
+		t.Fatal("expected match regardless of message and data")
+	}
+}
+
+func TestDomainErrorConstructors(t *testing.T) {
+	cases := []struct {
+		err      *RequestError
+		sentinel *RequestError
+		key      string
+		want     any
+	}{
+		{NewSessionNotFound("sess_1"), ErrResourceNotFound, "sessionId", SessionId("sess_1")},
+		{NewResourceNotFound("file:///missing.txt"), ErrResourceNotFound, "uri", "file:///missing.txt"},
+	}
+	for _, tc := range cases {
+		if !errors.Is(tc.err, tc.sentinel) {
+			t.Errorf("%v does not match %v", tc.err, tc.sentinel)
+		}
+		data, ok := tc.err.Data.(map[string]any)
+		if !ok || data[tc.key] != tc.want {
+			t.Errorf("unexpected data for %v: %#v", tc.err, tc.err.Data)
+		}
+	}
+	if !errors.Is(NewAuthRequired(nil), ErrAuthRequired) {
+		t.Error("NewAuthRequired does not match ErrAuthRequired")
+	}
+}
+
+func TestToReqErr_UnwrapsToOriginalError(t *testing.T) {
+	errNoRows := errors.New("no rows")
+	re := toReqErr(fmt.Errorf("db: %w", errNoRows))
+	if !errors.Is(re, errNoRows) {
+		t.Fatal("expected RequestError to unwrap to the original error")
+	}
+	if !errors.Is(re, ErrInternal) {
+		t.Fatal("expected RequestError to still match ErrInternal")
+	}
+	b, err := json.Marshal(re)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if string(b) != `{"code":-32603,"message":"Internal error","data":{"error":"db: no rows"}}` {
+		t.Fatalf("unexpected wire form: %s", b)
+	}
+}
+
+func TestRequestError_DataAs(t *testing.T) {
+	type retryHint struct {
+		RetryAfterMs int      `json:"retryAfterMs"`
+		Methods      []string `json:"methods"`
+	}
+
+	// Data as received from the wire is a generic map.
+	var re RequestError
+	if err := json.Unmarshal([]byte(`{"code":-32000,"message":"Authentication required","data":{"retryAfterMs":250,"methods":["oauth"]}}`), &re); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	var hint retryHint
+	if err := re.DataAs(&hint); err != nil {
+		t.Fatalf("DataAs: %v", err)
+	}
+	if hint.RetryAfterMs != 250 || len(hint.Methods) != 1 || hint.Methods[0] != "oauth" {
+		t.Fatalf("unexpected data: %+v", hint)
+	}
+
+	var sess struct {
+		SessionId SessionId `json:"sessionId"`
+	}
+	if err := NewSessionNotFound("sess_1").DataAs(&sess); err != nil || sess.SessionId != "sess_1" {
+		t.Fatalf("DataAs on local error: %+v, %v", sess, err)
+	}
+
+	if err := NewInternalError(nil).DataAs(&hint); err == nil {
+		t.Fatal("expected error when data is absent")
 	}
 }
@@ -145,8 +145,12 @@ func (a *exampleAgent) CloseSession(ctx context.Context, params acp.CloseSession
 func (a *exampleAgent) SetAgentConnection(conn *acp.AgentSideConnection) { a.conn = conn }
 
 func (a *exampleAgent) Initialize(ctx context.Context, params acp.InitializeRequest) (acp.InitializeResponse, error) {
+	version, err := acp.NegotiateProtocolVersion(int(params.ProtocolVersion), acp.MaxSupportedProtocolVersion)
+	if err != nil {
+		return acp.InitializeResponse{}, acp.NewInvalidParams(map[string]any{"error": err.Error()})
+	}
 	return acp.InitializeResponse{
-		ProtocolVersion: acp.ProtocolVersionNumber,
+		ProtocolVersion: acp.ProtocolVersion(version),
 		AgentCapabilities: acp.AgentCapabilities{
 			LoadSession: false,
 		},
@@ -0,0 +1,181 @@
+package acp
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+)
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// implementsMarshaler reports whether values of t encode themselves.
+func implementsMarshaler(t reflect.Type) bool {
+	return t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) ||
+		(t.Kind() != reflect.Pointer && (reflect.PointerTo(t).Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType)))
+}
+
+// checkJSONEncodable returns an error naming the first field of t that
+// encoding/json can never encode, such as a channel or func. Fields of interface
+// type depend on their dynamic value and are not checked.
+func checkJSONEncodable(t reflect.Type) error {
+	if path, bad := unencodableType(t, "", map[reflect.Type]bool{}); bad != nil {
+		return fmt.Errorf("%s cannot be encoded as JSON: %s has type %s", t, fieldPathOrRoot(path), bad)
+	}
+	return nil
+}
+
+func unencodableType(t reflect.Type, path string, seen map[reflect.Type]bool) (string, reflect.Type) {
+	if seen[t] || implementsMarshaler(t) {
+		return "", nil
+	}
+	switch t.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Complex64, reflect.Complex128, reflect.UnsafePointer:
+		return path, t
+	case reflect.Pointer:
+		return unencodableType(t.Elem(), path, seen)
+	case reflect.Slice, reflect.Array:
+		return unencodableType(t.Elem(), path+"[]", seen)
+	case reflect.Map:
+		if !isJSONMapKey(t.Key()) {
+			return path, t
+		}
+		return unencodableType(t.Elem(), path+"[]", seen)
+	case reflect.Struct:
+		seen[t] = true
+		defer delete(seen, t)
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name, ok := jsonFieldName(f)
+			if !ok {
+				continue
+			}
+			if p, bad := unencodableType(f.Type, joinFieldPath(path, name), seen); bad != nil {
+				return p, bad
+			}
+		}
+	}
+	return "", nil
+}
+
+// unencodableValue returns the path of the first value within v that
+// encoding/json cannot encode, and why, or "" and "" if it finds none.
+func unencodableValue(v reflect.Value, path string, depth int) (string, string) {
+	if !v.IsValid() || depth > 100 {
+		return "", ""
+	}
+	if implementsMarshaler(v.Type()) {
+		return "", ""
+	}
+	switch v.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Complex64, reflect.Complex128, reflect.UnsafePointer:
+		return path, "unsupported type " + v.Type().String()
+	case reflect.Float32, reflect.Float64:
+		if f := v.Float(); math.IsNaN(f) || math.IsInf(f, 0) {
+			return path, fmt.Sprintf("unsupported value %v", f)
+		}
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			return unencodableValue(v.Elem(), path, depth+1)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if p, why := unencodableValue(v.Index(i), fmt.Sprintf("%s[%d]", path, i), depth+1); why != "" {
+				return p, why
+			}
+		}
+	case reflect.Map:
+		if !isJSONMapKey(v.Type().Key()) {
+			return path, "unsupported map key type " + v.Type().Key().String()
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			if p, why := unencodableValue(iter.Value(), fmt.Sprintf("%s[%v]", path, iter.Key()), depth+1); why != "" {
+				return p, why
+			}
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			name, ok := jsonFieldName(t.Field(i))
+			if !ok {
+				continue
+			}
+			if p, why := unencodableValue(v.Field(i), joinFieldPath(path, name), depth+1); why != "" {
+				return p, why
+			}
+		}
+	}
+	return "", ""
+}
+
+// jsonFieldName returns the name encoding/json uses for f, or "" for an
+// embedded struct whose fields are promoted. It reports false for fields
+// encoding/json skips.
+func jsonFieldName(f reflect.StructField) (string, bool) {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	if f.Anonymous && name == "" {
+		t := f.Type
+		if t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if t.Kind() == reflect.Struct {
+			return "", true
+		}
+	}
+	if !f.IsExported() {
+		return "", false
+	}
+	if name == "" {
+		name = f.Name
+	}
+	return name, true
+}
+
+func isJSONMapKey(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return t.Implements(textMarshalerType)
+}
+
+func joinFieldPath(path, name string) string {
+	if name == "" || path == "" {
+		return path + name
+	}
+	return path + "." + name
+}
+
+func fieldPathOrRoot(path string) string {
+	if path == "" {
+		return "the value"
+	}
+	return "field " + path
+}
+
+// encodeExtensionResult encodes the result of the handler for the extension
+// method, so that a result that cannot be encoded is reported to the peer with
+// the offending field rather than as an opaque internal error.
+func encodeExtensionResult(codec Codec, method string, result any) (json.RawMessage, *RequestError) {
+	b, err := codec.Marshal(result)
+	if err == nil {
+		return b, nil
+	}
+	data := map[string]any{"error": err.Error(), "method": method}
+	if path, why := unencodableValue(reflect.ValueOf(result), "", 0); why != "" {
+		data["field"] = path
+		data["reason"] = why
+	}
+	return nil, &RequestError{Code: CodeInternalError, Message: "Internal error", Data: data, cause: err}
+}
@@ -0,0 +1,110 @@
+package acp
+
+import (
+	"errors"
+	"sync"
+)
+
+// MetaKeyExtensions is the _meta key under which InitializeRequest and
+// InitializeResponse advertise the extension methods a peer supports.
+const MetaKeyExtensions = "extensions"
+
+// ErrExtensionNotSupported is wrapped by the error CallExtension and
+// NotifyExtension return, without contacting the peer, when the peer advertised
+// its extension methods during initialize and the method is not among them.
+// The error also matches ErrMethodNotFound.
+var ErrExtensionNotSupported = errors.New("extension method not advertised by peer")
+
+// WithExtensions returns a copy of r whose _meta advertises the given extension
+// methods to the agent.
+func (r InitializeRequest) WithExtensions(methods []string) InitializeRequest {
+	r.Meta = withExtensionsMeta(r.Meta, methods)
+	return r
+}
+
+// SupportedExtensions returns the extension methods the client advertised, or nil
+// if it advertised none.
+func (r InitializeRequest) SupportedExtensions() []string {
+	methods, _ := extensionsFromMeta(r.Meta)
+	return methods
+}
+
+// WithExtensions returns a copy of r whose _meta advertises the given extension
+// methods to the client.
+func (r InitializeResponse) WithExtensions(methods []string) InitializeResponse {
+	r.Meta = withExtensionsMeta(r.Meta, methods)
+	return r
+}
+
+// SupportedExtensions returns the extension methods the agent advertised, or nil
+// if it advertised none.
+func (r InitializeResponse) SupportedExtensions() []string {
+	methods, _ := extensionsFromMeta(r.Meta)
+	return methods
+}
+
+func withExtensionsMeta(meta map[string]any, methods []string) map[string]any {
+	out := make(map[string]any, len(meta)+1)
+	for k, v := range meta {
+		out[k] = v
+	}
+	out[MetaKeyExtensions] = append([]string{}, methods...)
+	return out
+}
+
+// extensionsFromMeta reads the advertised extension methods from meta, which may
+// have been built locally or decoded from JSON. It reports whether meta
+// advertised extensions at all.
+func extensionsFromMeta(meta map[string]any) ([]string, bool) {
+	switch v := meta[MetaKeyExtensions].(type) {
+	case []string:
+		return v, true
+	case []any:
+		methods := make([]string, 0, len(v))
+		for _, m := range v {
+			if s, ok := m.(string); ok {
+				methods = append(methods, s)
+			}
+		}
+		return methods, true
+	}
+	return nil, false
+}
+
+// peerExtensions records the extension methods a peer advertised during
+// initialize, so that calls to methods it does not support fail locally.
+type peerExtensions struct {
+	mu         sync.Mutex
+	advertised bool
+	methods    map[string]struct{}
+}
+
+func (p *peerExtensions) record(meta map[string]any) {
+	methods, ok := extensionsFromMeta(meta)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.advertised = ok
+	p.methods = make(map[string]struct{}, len(methods))
+	for _, m := range methods {
+		p.methods[m] = struct{}{}
+	}
+}
+
+// check returns an error if the peer advertised its extensions and method is not
+// among them. Peers that advertise nothing are assumed to support any method.
+func (p *peerExtensions) check(method string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.advertised {
+		return nil
+	}
+	if _, ok := p.methods[method]; ok {
+		return nil
+	}
+	return &RequestError{
+		Code:    CodeMethodNotFound,
+		Message: "Method not found",
+		Data:    map[string]any{"method": method, "error": ErrExtensionNotSupported.Error()},
+		cause:   ErrExtensionNotSupported,
+	}
+}
@@ -0,0 +1,84 @@
+package acp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestExtensionNegotiation(t *testing.T) {
+	c2aR, c2aW := io.Pipe()
+	a2cR, a2cW := io.Pipe()
+	defer func() {
+		_ = c2aR.Close()
+		_ = c2aW.Close()
+		_ = a2cR.Close()
+		_ = a2cW.Close()
+	}()
+
+	var agentCalls atomic.Int32
+	var clientExts []string
+	as := NewAgentSideConnection(agentFuncs{
+		InitializeFunc: func(_ context.Context, p InitializeRequest) (InitializeResponse, error) {
+			clientExts = p.SupportedExtensions()
+			return InitializeResponse{ProtocolVersion: ProtocolVersionNumber}.WithExtensions([]string{"_vendor/echo"}), nil
+		},
+		HandleExtensionMethodFunc: func(context.Context, string, json.RawMessage) (any, error) {
+			agentCalls.Add(1)
+			return map[string]any{}, nil
+		},
+	}, a2cW, c2aR)
+	cs := NewClientSideConnection(&clientFuncs{}, c2aW, a2cR)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	// Before initialize nothing is known, so calls go through.
+	if _, err := cs.CallExtension(ctx, "_vendor/other", nil); err != nil {
+		t.Fatalf("CallExtension before initialize: %v", err)
+	}
+
+	resp, err := cs.Initialize(ctx, InitializeRequest{ProtocolVersion: ProtocolVersionNumber}.WithExtensions([]string{"_client/open"}))
+	if err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	if got := resp.SupportedExtensions(); len(got) != 1 || got[0] != "_vendor/echo" {
+		t.Fatalf("unexpected agent extensions: %v", got)
+	}
+	if len(clientExts) != 1 || clientExts[0] != "_client/open" {
+		t.Fatalf("unexpected client extensions: %v", clientExts)
+	}
+
+	if _, err := cs.CallExtension(ctx, "_vendor/echo", nil); err != nil {
+		t.Fatalf("CallExtension advertised: %v", err)
+	}
+	_, err = cs.CallExtension(ctx, "_vendor/other", nil)
+	if !errors.Is(err, ErrExtensionNotSupported) || !errors.Is(err, ErrMethodNotFound) {
+		t.Fatalf("expected ErrExtensionNotSupported, got %v", err)
+	}
+	if err := cs.NotifyExtension(ctx, "_vendor/other", nil); !errors.Is(err, ErrExtensionNotSupported) {
+		t.Fatalf("expected ErrExtensionNotSupported from NotifyExtension, got %v", err)
+	}
+	if n := agentCalls.Load(); n != 2 {
+		t.Fatalf("expected 2 calls to reach the agent, got %d", n)
+	}
+
+	if _, err := as.CallExtension(ctx, "_client/closed", nil); !errors.Is(err, ErrExtensionNotSupported) {
+		t.Fatalf("expected agent-side ErrExtensionNotSupported, got %v", err)
+	}
+}
+
+func TestInitializeWithExtensions_DoesNotMutateMeta(t *testing.T) {
+	meta := map[string]any{"vendor": "acme"}
+	req := InitializeRequest{Meta: meta}.WithExtensions([]string{"_acme/a"})
+	if _, ok := meta[MetaKeyExtensions]; ok {
+		t.Fatal("WithExtensions mutated the caller's meta map")
+	}
+	if req.Meta["vendor"] != "acme" {
+		t.Fatalf("existing meta lost: %v", req.Meta)
+	}
+}
@@ -0,0 +1,150 @@
+package acp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type extensionFunc func(ctx context.Context, params json.RawMessage) (any, error)
+
+// ExtensionRegistry dispatches extension methods to typed handlers registered
+// with RegisterExtension and RegisterExtensionNotification. It implements
+// ExtensionMethodHandler, so an Agent or Client can embed a *ExtensionRegistry
+// instead of writing HandleExtensionMethod by hand. Unregistered methods yield
+// MethodNotFound.
+type ExtensionRegistry struct {
+	mu       sync.RWMutex
+	handlers map[string]extensionFunc
+}
+
+var _ ExtensionMethodHandler = (*ExtensionRegistry)(nil)
+
+// NewExtensionRegistry returns an empty ExtensionRegistry.
+func NewExtensionRegistry() *ExtensionRegistry {
+	return &ExtensionRegistry{handlers: make(map[string]extensionFunc)}
+}
+
+// RegisterExtension registers a typed handler for the extension method. The
+// request params are decoded into Req, and an error decoding them is reported to
+// the peer as InvalidParams. It panics if method is not a valid extension method
+// name or is already registered, or if Resp has a field that can never be
+// encoded as JSON, such as a channel or func.
+func RegisterExtension[Req, Resp any](reg *ExtensionRegistry, method string, h func(ctx context.Context, params Req) (Resp, error)) {
+	if err := checkJSONEncodable(reflect.TypeOf((*Resp)(nil)).Elem()); err != nil {
+		panic(fmt.Sprintf("acp: extension method %q: %v", method, err))
+	}
+	reg.register(method, func(ctx context.Context, raw json.RawMessage) (any, error) {
+		var params Req
+		if err := decodeExtensionParams(raw, &params); err != nil {
+			return nil, err
+		}
+		return h(ctx, params)
+	})
+}
+
+// RegisterExtensionNotification registers a typed handler for an extension
+// notification. It panics under the same conditions as RegisterExtension.
+func RegisterExtensionNotification[T any](reg *ExtensionRegistry, method string, h func(ctx context.Context, params T) error) {
+	reg.register(method, func(ctx context.Context, raw json.RawMessage) (any, error) {
+		var params T
+		if err := decodeExtensionParams(raw, &params); err != nil {
+			return nil, err
+		}
+		return nil, h(ctx, params)
+	})
+}
+
+func (r *ExtensionRegistry) register(method string, h extensionFunc) {
+	if err := validateExtensionMethodName(method); err != nil {
+		panic(err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.handlers[method]; ok {
+		panic(fmt.Sprintf("acp: extension method %q registered twice", method))
+	}
+	r.handlers[method] = h
+}
+
+// HandleExtensionMethod implements ExtensionMethodHandler.
+func (r *ExtensionRegistry) HandleExtensionMethod(ctx context.Context, method string, params json.RawMessage) (any, error) {
+	r.mu.RLock()
+	h, ok := r.handlers[method]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, NewMethodNotFound(method)
+	}
+	return h(ctx, params)
+}
+
+func decodeExtensionParams(raw json.RawMessage, v any) error {
+	if len(raw) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return NewInvalidParams(map[string]any{"error": err.Error()})
+	}
+	return nil
+}
+
+// PrefixExtensionRouter routes extension methods to sub-handlers by namespace
+// prefix, such as "_acme/terminal/", so that separate subsystems can own their
+// extension namespaces. The longest matching prefix wins, and methods matching
+// no prefix yield MethodNotFound. Requests and notifications are routed alike.
+// A PrefixExtensionRouter implements ExtensionMethodHandler, and its
+// sub-handlers are typically ExtensionRegistry values.
+type PrefixExtensionRouter struct {
+	mu     sync.RWMutex
+	routes []extensionRoute // sorted by descending prefix length
+}
+
+type extensionRoute struct {
+	prefix  string
+	handler ExtensionMethodHandler
+}
+
+var _ ExtensionMethodHandler = (*PrefixExtensionRouter)(nil)
+
+// NewPrefixExtensionRouter returns a router with no routes.
+func NewPrefixExtensionRouter() *PrefixExtensionRouter {
+	return &PrefixExtensionRouter{}
+}
+
+// Handle routes extension methods starting with prefix to h. It panics if prefix
+// does not start with "_" or is already routed.
+func (r *PrefixExtensionRouter) Handle(prefix string, h ExtensionMethodHandler) {
+	if err := validateExtensionMethodName(prefix); err != nil {
+		panic(err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, rt := range r.routes {
+		if rt.prefix == prefix {
+			panic(fmt.Sprintf("acp: extension prefix %q routed twice", prefix))
+		}
+	}
+	r.routes = append(r.routes, extensionRoute{prefix: prefix, handler: h})
+	sort.SliceStable(r.routes, func(i, j int) bool { return len(r.routes[i].prefix) > len(r.routes[j].prefix) })
+}
+
+// HandleExtensionMethod implements ExtensionMethodHandler.
+func (r *PrefixExtensionRouter) HandleExtensionMethod(ctx context.Context, method string, params json.RawMessage) (any, error) {
+	r.mu.RLock()
+	var h ExtensionMethodHandler
+	for _, rt := range r.routes {
+		if strings.HasPrefix(method, rt.prefix) {
+			h = rt.handler
+			break
+		}
+	}
+	r.mu.RUnlock()
+	if h == nil {
+		return nil, NewMethodNotFound(method)
+	}
+	return h.HandleExtensionMethod(ctx, method, params)
+}
@@ -0,0 +1,246 @@
+package acp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+type registryAgent struct {
+	agentNoExtensions
+	*ExtensionRegistry
+}
+
+func TestExtensionRegistry_DispatchesTypedHandlers(t *testing.T) {
+	c2aR, c2aW := io.Pipe()
+	a2cR, a2cW := io.Pipe()
+	defer func() {
+		_ = c2aR.Close()
+		_ = c2aW.Close()
+		_ = a2cR.Close()
+		_ = a2cW.Close()
+	}()
+
+	reg := NewExtensionRegistry()
+	RegisterExtension(reg, "_vendor.test/echo", func(_ context.Context, p extEchoParams) (extEchoResult, error) {
+		return extEchoResult{Msg: p.Msg}, nil
+	})
+	notified := make(chan string, 1)
+	RegisterExtensionNotification(reg, "_vendor.test/notify", func(_ context.Context, p extEchoParams) error {
+		notified <- p.Msg
+		return nil
+	})
+	NewAgentSideConnection(registryAgent{ExtensionRegistry: reg}, a2cW, c2aR)
+	c := NewClientSideConnection(&clientFuncs{}, c2aW, a2cR)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	raw, err := c.CallExtension(ctx, "_vendor.test/echo", extEchoParams{Msg: "hi"})
+	if err != nil {
+		t.Fatalf("CallExtension: %v", err)
+	}
+	var resp extEchoResult
+	if err := json.Unmarshal(raw, &resp); err != nil || resp.Msg != "hi" {
+		t.Fatalf("unexpected response %s: %v", raw, err)
+	}
+
+	if err := c.NotifyExtension(ctx, "_vendor.test/notify", extEchoParams{Msg: "ping"}); err != nil {
+		t.Fatalf("NotifyExtension: %v", err)
+	}
+	select {
+	case msg := <-notified:
+		if msg != "ping" {
+			t.Fatalf("unexpected notification payload %q", msg)
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for notification")
+	}
+
+	_, err = c.CallExtension(ctx, "_vendor.test/missing", nil)
+	if !errors.Is(err, ErrMethodNotFound) {
+		t.Fatalf("expected method not found, got %v", err)
+	}
+	_, err = c.CallExtension(ctx, "_vendor.test/echo", map[string]any{"msg": 42})
+	if !errors.Is(err, ErrInvalidParams) {
+		t.Fatalf("expected invalid params, got %v", err)
+	}
+}
+
+func TestExtensionRegistry_RegisterPanicsOnInvalidOrDuplicateMethod(t *testing.T) {
+	reg := NewExtensionRegistry()
+	noop := func(context.Context, struct{}) (struct{}, error) { return struct{}{}, nil }
+	RegisterExtension(reg, "_vendor/a", noop)
+
+	for _, method := range []string{"vendor/a", "_vendor/a"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected panic registering %q", method)
+				}
+			}()
+			RegisterExtension(reg, method, noop)
+		}()
+	}
+}
+
+func TestExtensionRegistry_ReportsUnencodableResults(t *testing.T) {
+	type streaming struct {
+		Updates chan int `json:"updates"`
+	}
+	reg := NewExtensionRegistry()
+	func() {
+		defer func() {
+			if r := recover(); r == nil || !strings.Contains(fmt.Sprint(r), "field updates has type chan int") {
+				t.Errorf("expected a panic naming the channel field, got %v", r)
+			}
+		}()
+		RegisterExtension(reg, "_vendor/stream", func(context.Context, struct{}) (streaming, error) { return streaming{}, nil })
+	}()
+
+	type dynamic struct {
+		Extra any `json:"extra"`
+	}
+	RegisterExtension(reg, "_vendor/dynamic", func(context.Context, struct{}) (dynamic, error) {
+		return dynamic{Extra: []any{1, func() {}}}, nil
+	})
+	clientConn, _ := newNotificationBarrierTestPair(t, &clientFuncs{}, registryAgent{ExtensionRegistry: reg})
+
+	_, err := clientConn.CallExtension(context.Background(), "_vendor/dynamic", nil)
+	var re *RequestError
+	if !errors.As(err, &re) || re.Code != CodeInternalError {
+		t.Fatalf("CallExtension: got %v, want an internal error", err)
+	}
+	var data struct {
+		Field  string `json:"field"`
+		Reason string `json:"reason"`
+	}
+	if err := re.DataAs(&data); err != nil {
+		t.Fatalf("DataAs: %v", err)
+	}
+	if data.Field != "extra[1]" || data.Reason != "unsupported type func()" {
+		t.Fatalf("error data = %+v, want the func field", data)
+	}
+}
+
+func TestCallExtensionTyped(t *testing.T) {
+	c2aR, c2aW := io.Pipe()
+	a2cR, a2cW := io.Pipe()
+	defer func() {
+		_ = c2aR.Close()
+		_ = c2aW.Close()
+		_ = a2cR.Close()
+		_ = a2cW.Close()
+	}()
+
+	reg := NewExtensionRegistry()
+	RegisterExtension(reg, "_vendor.test/echo", func(_ context.Context, p extEchoParams) (extEchoResult, error) {
+		return extEchoResult{Msg: p.Msg}, nil
+	})
+	as := NewAgentSideConnection(registryAgent{ExtensionRegistry: reg}, a2cW, c2aR)
+	cs := NewClientSideConnection(&clientFuncs{
+		HandleExtensionMethodFunc: func(_ context.Context, _ string, params json.RawMessage) (any, error) {
+			return extEchoResult{Msg: "from client"}, nil
+		},
+	}, c2aW, a2cR)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	resp, err := CallExtensionTyped[extEchoResult](cs, ctx, "_vendor.test/echo", extEchoParams{Msg: "hi"})
+	if err != nil || resp.Msg != "hi" {
+		t.Fatalf("client to agent: %+v, %v", resp, err)
+	}
+	resp, err = CallExtensionTyped[extEchoResult](as, ctx, "_vendor.test/anything", nil)
+	if err != nil || resp.Msg != "from client" {
+		t.Fatalf("agent to client: %+v, %v", resp, err)
+	}
+	if _, err := CallExtensionTyped[extEchoResult](cs, ctx, "_vendor.test/missing", nil); !errors.Is(err, ErrMethodNotFound) {
+		t.Fatalf("expected method not found, got %v", err)
+	}
+}
+
+func TestNotifyExtensionTyped_EnforcesNamespaces(t *testing.T) {
+	c2aR, c2aW := io.Pipe()
+	a2cR, a2cW := io.Pipe()
+	defer func() {
+		_ = c2aR.Close()
+		_ = c2aW.Close()
+		_ = a2cR.Close()
+		_ = a2cW.Close()
+	}()
+
+	reg := NewExtensionRegistry()
+	notified := make(chan string, 1)
+	RegisterExtensionNotification(reg, "_acme/ping", func(_ context.Context, p extEchoParams) error {
+		notified <- p.Msg
+		return nil
+	})
+	NewAgentSideConnection(registryAgent{ExtensionRegistry: reg}, a2cW, c2aR)
+	cs := NewClientSideConnection(&clientFuncs{}, c2aW, a2cR, WithExtensionNamespaces("_acme/"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if err := NotifyExtensionTyped(cs, ctx, "_acme/ping", extEchoParams{Msg: "hi"}); err != nil {
+		t.Fatalf("NotifyExtensionTyped: %v", err)
+	}
+	select {
+	case msg := <-notified:
+		if msg != "hi" {
+			t.Fatalf("unexpected payload %q", msg)
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for notification")
+	}
+
+	if err := NotifyExtensionTyped(cs, ctx, "_other/ping", extEchoParams{Msg: "hi"}); err == nil {
+		t.Fatal("expected namespace violation for notification")
+	}
+	if _, err := cs.CallExtension(ctx, "_other/echo", nil); err == nil {
+		t.Fatal("expected namespace violation for request")
+	}
+}
+
+func TestPrefixExtensionRouter(t *testing.T) {
+	terminal := NewExtensionRegistry()
+	RegisterExtension(terminal, "_acme/terminal/open", func(context.Context, struct{}) (string, error) {
+		return "terminal", nil
+	})
+	fs := NewExtensionRegistry()
+	RegisterExtension(fs, "_acme/fs/read", func(context.Context, struct{}) (string, error) {
+		return "fs", nil
+	})
+	fallback := NewExtensionRegistry()
+	RegisterExtension(fallback, "_acme/terminal/other", func(context.Context, struct{}) (string, error) {
+		return "fallback", nil
+	})
+
+	router := NewPrefixExtensionRouter()
+	router.Handle("_acme/", fallback)
+	router.Handle("_acme/terminal/", terminal)
+	router.Handle("_acme/fs/", fs)
+
+	ctx := context.Background()
+	for method, want := range map[string]string{
+		"_acme/terminal/open": "terminal",
+		"_acme/fs/read":       "fs",
+	} {
+		got, err := router.HandleExtensionMethod(ctx, method, nil)
+		if err != nil || got != want {
+			t.Errorf("%s: got %v, %v; want %q", method, got, err, want)
+		}
+	}
+	// The longest prefix owns the method even if a shorter route could handle it.
+	if _, err := router.HandleExtensionMethod(ctx, "_acme/terminal/other", nil); !errors.Is(err, ErrMethodNotFound) {
+		t.Errorf("expected method not found from terminal route, got %v", err)
+	}
+	if _, err := router.HandleExtensionMethod(ctx, "_other/x", nil); !errors.Is(err, ErrMethodNotFound) {
+		t.Errorf("expected method not found for unrouted method, got %v", err)
+	}
+}
@@ -30,11 +30,39 @@ func validateExtensionMethodName(method string) error {
 	return nil
 }
 
+// WithExtensionNamespaces restricts outbound extension requests and notifications
+// to methods that start with one of the given prefixes, such as "_acme/". Calls to
+// any other extension method fail without being sent. By default any method
+// starting with "_" is allowed.
+func WithExtensionNamespaces(prefixes ...string) ConnectionOption {
+	return func(c *Connection) {
+		c.extensionNamespaces = append(c.extensionNamespaces, prefixes...)
+	}
+}
+
+// checkOutboundExtension validates an outbound extension method name against the
+// ACP naming rule and any namespaces configured with WithExtensionNamespaces.
+func (c *Connection) checkOutboundExtension(method string) error {
+	if err := validateExtensionMethodName(method); err != nil {
+		return err
+	}
+	if len(c.extensionNamespaces) == 0 {
+		return nil
+	}
+	for _, prefix := range c.extensionNamespaces {
+		if strings.HasPrefix(method, prefix) {
+			return nil
+		}
+	}
+	return fmt.Errorf("extension method %q is outside the allowed namespaces %q", method, c.extensionNamespaces)
+}
+
 func isExtensionMethodName(method string) bool {
 	return strings.HasPrefix(method, "_")
 }
 
 func (a *AgentSideConnection) handleWithExtensions(ctx context.Context, method string, params json.RawMessage) (any, *RequestError) {
+	ctx = withNegotiatedCapabilities(ctx, &a.capabilities)
 	if isExtensionMethodName(method) {
 		h, ok := a.agent.(ExtensionMethodHandler)
 		if !ok {
@@ -44,13 +72,17 @@ func (a *AgentSideConnection) handleWithExtensions(ctx context.Context, method s
 		if err != nil {
 			return nil, toReqErr(err)
 		}
-		return resp, nil
+		return encodeExtensionResult(a.conn.codec, method, resp)
 	}
 
 	return a.handle(ctx, method, params)
 }
 
 func (c *ClientSideConnection) handleWithExtensions(ctx context.Context, method string, params json.RawMessage) (any, *RequestError) {
+	ctx = withNegotiatedCapabilities(ctx, &c.capabilities)
+	if method == ProgressNotificationMethod {
+		return nil, c.routeProgress(params)
+	}
 	if isExtensionMethodName(method) {
 		h, ok := c.client.(ExtensionMethodHandler)
 		if !ok {
@@ -60,16 +92,25 @@ func (c *ClientSideConnection) handleWithExtensions(ctx context.Context, method
 		if err != nil {
 			return nil, toReqErr(err)
 		}
-		return resp, nil
+		return encodeExtensionResult(c.conn.codec, method, resp)
 	}
 
+	if method == ClientMethodSessionUpdate {
+		c.observeModeUpdate(params)
+		c.routeSessionUpdate(params)
+	}
 	return c.handle(ctx, method, params)
 }
 
 // CallExtension sends an ACP extension-method request (method names starting with "_")
-// from an agent to its client.
+// from an agent to its client. If the client advertised its extensions during
+// initialize (see InitializeRequest.WithExtensions), methods it did not list fail
+// with ErrExtensionNotSupported without a round-trip.
 func (c *AgentSideConnection) CallExtension(ctx context.Context, method string, params any) (json.RawMessage, error) {
-	if err := validateExtensionMethodName(method); err != nil {
+	if err := c.conn.checkOutboundExtension(method); err != nil {
+		return nil, err
+	}
+	if err := c.peerExtensions.check(method); err != nil {
 		return nil, err
 	}
 	return SendRequest[json.RawMessage](c.conn, ctx, method, params)
@@ -78,16 +119,24 @@ func (c *AgentSideConnection) CallExtension(ctx context.Context, method string,
 // NotifyExtension sends an ACP extension-method notification (method names starting with "_")
 // from an agent to its client.
 func (c *AgentSideConnection) NotifyExtension(ctx context.Context, method string, params any) error {
-	if err := validateExtensionMethodName(method); err != nil {
+	if err := c.conn.checkOutboundExtension(method); err != nil {
+		return err
+	}
+	if err := c.peerExtensions.check(method); err != nil {
 		return err
 	}
 	return c.conn.SendNotification(ctx, method, params)
 }
 
 // CallExtension sends an ACP extension-method request (method names starting with "_")
-// from a client to its agent.
+// from a client to its agent. If the agent advertised its extensions during
+// initialize (see InitializeResponse.WithExtensions), methods it did not list fail
+// with ErrExtensionNotSupported without a round-trip.
 func (c *ClientSideConnection) CallExtension(ctx context.Context, method string, params any) (json.RawMessage, error) {
-	if err := validateExtensionMethodName(method); err != nil {
+	if err := c.conn.checkOutboundExtension(method); err != nil {
+		return nil, err
+	}
+	if err := c.peerExtensions.check(method); err != nil {
 		return nil, err
 	}
 	return SendRequest[json.RawMessage](c.conn, ctx, method, params)
@@ -96,8 +145,46 @@ func (c *ClientSideConnection) CallExtension(ctx context.Context, method string,
 // NotifyExtension sends an ACP extension-method notification (method names starting with "_")
 // from a client to its agent.
 func (c *ClientSideConnection) NotifyExtension(ctx context.Context, method string, params any) error {
-	if err := validateExtensionMethodName(method); err != nil {
+	if err := c.conn.checkOutboundExtension(method); err != nil {
+		return err
+	}
+	if err := c.peerExtensions.check(method); err != nil {
 		return err
 	}
 	return c.conn.SendNotification(ctx, method, params)
 }
+
+// ExtensionCaller is implemented by both AgentSideConnection and
+// ClientSideConnection, so extension helpers can work with either side.
+type ExtensionCaller interface {
+	CallExtension(ctx context.Context, method string, params any) (json.RawMessage, error)
+	NotifyExtension(ctx context.Context, method string, params any) error
+}
+
+var (
+	_ ExtensionCaller = (*AgentSideConnection)(nil)
+	_ ExtensionCaller = (*ClientSideConnection)(nil)
+)
+
+// CallExtensionTyped calls an extension method through conn and decodes its result
+// into Resp.
+func CallExtensionTyped[Resp any](conn ExtensionCaller, ctx context.Context, method string, params any) (Resp, error) {
+	var result Resp
+	raw, err := conn.CallExtension(ctx, method, params)
+	if err != nil {
+		return result, err
+	}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &result); err != nil {
+			return result, NewInternalError(map[string]any{"error": err.Error()})
+		}
+	}
+	return result, nil
+}
+
+// NotifyExtensionTyped sends an extension notification through conn. It is
+// NotifyExtension with params constrained to T, so that a vendor's notification
+// payloads are checked at compile time.
+func NotifyExtensionTyped[T any](conn ExtensionCaller, ctx context.Context, method string, params T) error {
+	return conn.NotifyExtension(ctx, method, params)
+}
@@ -0,0 +1,250 @@
+package acp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Framing selects how JSON-RPC messages are delimited on the wire.
+type Framing int
+
+const (
+	// FramingNewline delimits messages with a single newline. This is the default.
+	FramingNewline Framing = iota
+	// FramingContentLength prefixes each message with LSP-style headers
+	// ("Content-Length: N\r\n\r\n"), allowing messages to contain embedded newlines.
+	FramingContentLength
+)
+
+// messageReader yields successive raw messages from the peer.
+type messageReader interface {
+	// next returns the next message. The returned slice is owned by the caller.
+	next() ([]byte, error)
+}
+
+func newMessageReader(framing Framing, r io.Reader, initialBufSize int, limit int) messageReader {
+	if framing == FramingContentLength {
+		return newContentLengthReader(r, initialBufSize, limit)
+	}
+	return newLineReader(r, initialBufSize, limit)
+}
+
+// frameMessage appends the framing for body according to framing.
+func frameMessage(framing Framing, body []byte) []byte {
+	if framing == FramingContentLength {
+		header := "Content-Length: " + strconv.Itoa(len(body)) + "\r\n\r\n"
+		return append([]byte(header), body...)
+	}
+	return append(body, '\n')
+}
+
+// maxOversizedMessagePrefix bounds how much of an oversized message is retained
+// for diagnostics (e.g. extracting its method and id) after it is discarded.
+const maxOversizedMessagePrefix = 64 * 1024
+
+// messageTooLargeError reports an inbound message that exceeded the configured
+// size limit. The message has already been consumed from the stream.
+type messageTooLargeError struct {
+	size   int
+	limit  int
+	prefix []byte
+}
+
+func (e *messageTooLargeError) Error() string {
+	return fmt.Sprintf("inbound message of %d bytes exceeds limit of %d bytes", e.size, e.limit)
+}
+
+// lineReader reads newline-delimited messages. Unlike bufio.Scanner, it recovers
+// from lines that exceed the size limit by discarding them and continuing.
+type lineReader struct {
+	r     *bufio.Reader
+	limit int
+}
+
+func newLineReader(r io.Reader, initialBufSize int, limit int) *lineReader {
+	return &lineReader{r: bufio.NewReaderSize(r, initialBufSize), limit: limit}
+}
+
+// next returns the next line without its line terminator. A trailing line
+// without a newline is returned before io.EOF, matching bufio.ScanLines.
+func (lr *lineReader) next() ([]byte, error) {
+	var (
+		line     []byte
+		size     int
+		tooLarge bool
+	)
+	for {
+		chunk, err := lr.r.ReadSlice('\n')
+		size += len(chunk)
+		if !tooLarge {
+			line = append(line, chunk...)
+			if contentLen(line) > lr.limit {
+				tooLarge = true
+				if len(line) > maxOversizedMessagePrefix {
+					line = line[:maxOversizedMessagePrefix]
+				}
+			}
+		}
+
+		switch {
+		case errors.Is(err, bufio.ErrBufferFull):
+			continue
+		case err == nil || (errors.Is(err, io.EOF) && size > 0):
+			if tooLarge {
+				if err == nil {
+					size--
+				}
+				return nil, &messageTooLargeError{size: size, limit: lr.limit, prefix: line}
+			}
+			return dropCR(line[:contentLen(line)]), nil
+		default:
+			return nil, err
+		}
+	}
+}
+
+// contentLen returns the length of line excluding a trailing newline.
+func contentLen(line []byte) int {
+	if n := len(line); n > 0 && line[n-1] == '\n' {
+		return n - 1
+	}
+	return len(line)
+}
+
+// contentLengthReader reads messages framed with LSP-style headers.
+type contentLengthReader struct {
+	r     *bufio.Reader
+	limit int
+}
+
+func newContentLengthReader(r io.Reader, initialBufSize int, limit int) *contentLengthReader {
+	return &contentLengthReader{r: bufio.NewReaderSize(r, initialBufSize), limit: limit}
+}
+
+// maxHeaderLineBytes bounds a single Content-Length framing header line, so that
+// a peer cannot grow memory without bound by never ending a header.
+const maxHeaderLineBytes = 4096
+
+// readHeaderLine reads one header line, including its newline, failing once it
+// exceeds maxHeaderLineBytes.
+func (cr *contentLengthReader) readHeaderLine() (string, error) {
+	var line []byte
+	for {
+		chunk, err := cr.r.ReadSlice('\n')
+		if len(line)+len(chunk) > maxHeaderLineBytes {
+			return "", fmt.Errorf("message header line exceeds %d bytes", maxHeaderLineBytes)
+		}
+		line = append(line, chunk...)
+		if !errors.Is(err, bufio.ErrBufferFull) {
+			return string(line), err
+		}
+	}
+}
+
+func (cr *contentLengthReader) next() ([]byte, error) {
+	length := -1
+	sawHeader := false
+	for {
+		line, err := cr.readHeaderLine()
+		if err != nil {
+			if errors.Is(err, io.EOF) && (sawHeader || strings.TrimSpace(line) != "") {
+				return nil, io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			if !sawHeader {
+				// Tolerate blank lines between messages.
+				continue
+			}
+			break
+		}
+		sawHeader = true
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("malformed message header %q", line)
+		}
+		if strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			n, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid Content-Length header %q", value)
+			}
+			length = n
+		}
+	}
+	if length < 0 {
+		return nil, errors.New("message headers missing Content-Length")
+	}
+
+	if length > cr.limit {
+		prefixLen := length
+		if prefixLen > maxOversizedMessagePrefix {
+			prefixLen = maxOversizedMessagePrefix
+		}
+		prefix := make([]byte, prefixLen)
+		if _, err := io.ReadFull(cr.r, prefix); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		if _, err := io.CopyN(io.Discard, cr.r, int64(length-prefixLen)); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		return nil, &messageTooLargeError{size: length, limit: cr.limit, prefix: prefix}
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(cr.r, body); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	return body, nil
+}
+
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+func dropCR(line []byte) []byte {
+	if len(line) > 0 && line[len(line)-1] == '\r' {
+		return line[:len(line)-1]
+	}
+	return line
+}
+
+// peekMessageHeader makes a best-effort attempt to extract the top-level method
+// and id from a possibly truncated JSON-RPC message.
+func peekMessageHeader(prefix []byte) (method string, id string) {
+	dec := json.NewDecoder(bytes.NewReader(prefix))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return "", ""
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return method, id
+		}
+		key, _ := tok.(string)
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return method, id
+		}
+		switch key {
+		case "method":
+			_ = json.Unmarshal(raw, &method)
+		case "id":
+			id = string(raw)
+		}
+		if method != "" && id != "" {
+			return method, id
+		}
+	}
+	return method, id
+}
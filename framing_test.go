@@ -0,0 +1,204 @@
+package acp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestContentLengthFraming_RoundTrip(t *testing.T) {
+	c2aR, c2aW := io.Pipe()
+	a2cR, a2cW := io.Pipe()
+	defer func() {
+		_ = c2aR.Close()
+		_ = c2aW.Close()
+		_ = a2cR.Close()
+		_ = a2cW.Close()
+	}()
+
+	_ = NewConnection(func(ctx context.Context, method string, params json.RawMessage) (any, *RequestError) {
+		var p map[string]string
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, NewInvalidParams(map[string]any{"error": err.Error()})
+		}
+		return map[string]string{"echo": p["text"]}, nil
+	}, a2cW, c2aR, WithFraming(FramingContentLength))
+	client := NewConnection(nil, c2aW, a2cR, WithFraming(FramingContentLength))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	got, err := SendRequest[map[string]string](client, ctx, "echo", map[string]string{"text": "line1\nline2"})
+	if err != nil {
+		t.Fatalf("SendRequest: %v", err)
+	}
+	if got["echo"] != "line1\nline2" {
+		t.Fatalf("unexpected echo: %q", got["echo"])
+	}
+}
+
+func TestContentLengthFraming_AcceptsEmbeddedNewlines(t *testing.T) {
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	defer func() {
+		_ = inW.Close()
+		_ = outW.Close()
+		_ = inR.Close()
+		_ = outR.Close()
+	}()
+
+	_ = NewConnection(func(ctx context.Context, method string, params json.RawMessage) (any, *RequestError) {
+		return map[string]string{"method": method}, nil
+	}, outW, inR, WithFraming(FramingContentLength))
+
+	body := "{\n  \"jsonrpc\": \"2.0\",\n  \"id\": 1,\n  \"method\": \"pretty\",\n  \"params\": {}\n}"
+	go func() {
+		_, _ = fmt.Fprintf(inW, "Content-Length: %d\r\nContent-Type: application/json\r\n\r\n%s", len(body), body)
+	}()
+
+	type result struct {
+		msg anyMessage
+		err error
+	}
+	results := make(chan result, 1)
+	go func() {
+		mr := newContentLengthReader(outR, 4096, 1024*1024)
+		raw, err := mr.next()
+		if err != nil {
+			results <- result{err: err}
+			return
+		}
+		var msg anyMessage
+		err = json.Unmarshal(raw, &msg)
+		results <- result{msg: msg, err: err}
+	}()
+
+	select {
+	case r := <-results:
+		if r.err != nil {
+			t.Fatalf("read response: %v", r.err)
+		}
+		if r.msg.ID == nil || string(*r.msg.ID) != "1" {
+			t.Fatalf("unexpected response id")
+		}
+		if !strings.Contains(string(r.msg.Result), "pretty") {
+			t.Fatalf("unexpected result: %s", string(r.msg.Result))
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for response")
+	}
+}
+
+func TestContentLengthReader_DiscardsOversizedMessage(t *testing.T) {
+	big := `{"jsonrpc":"2.0","id":3,"method":"big","params":"` + strings.Repeat("x", 100) + `"}`
+	small := `{"jsonrpc":"2.0","id":4,"method":"small"}`
+	stream := fmt.Sprintf("Content-Length: %d\r\n\r\n%sContent-Length: %d\r\n\r\n%s", len(big), big, len(small), small)
+
+	mr := newContentLengthReader(bufio.NewReader(strings.NewReader(stream)), 16, 64)
+	_, err := mr.next()
+	tooLarge, ok := err.(*messageTooLargeError)
+	if !ok {
+		t.Fatalf("expected messageTooLargeError, got %v", err)
+	}
+	if method, id := peekMessageHeader(tooLarge.prefix); method != "big" || id != "3" {
+		t.Fatalf("unexpected header: method=%q id=%q", method, id)
+	}
+
+	raw, err := mr.next()
+	if err != nil {
+		t.Fatalf("next: %v", err)
+	}
+	if string(raw) != small {
+		t.Fatalf("unexpected message: %s", raw)
+	}
+}
+
+// endlessReader yields an unbounded stream of the same byte.
+type endlessReader byte
+
+func (r endlessReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = byte(r)
+	}
+	return len(p), nil
+}
+
+func TestContentLengthReader_RejectsUnterminatedHeader(t *testing.T) {
+	mr := newContentLengthReader(endlessReader('a'), 16, 64)
+	if _, err := mr.next(); err == nil || !strings.Contains(err.Error(), "header line exceeds") {
+		t.Fatalf("expected a header length error, got %v", err)
+	}
+}
+
+func TestLineReader_TrailingLineWithoutNewline(t *testing.T) {
+	lr := newLineReader(strings.NewReader("a\r\nbb\nccc"), 16, 1024)
+	for _, want := range []string{"a", "bb", "ccc"} {
+		line, err := lr.next()
+		if err != nil {
+			t.Fatalf("next: %v", err)
+		}
+		if string(line) != want {
+			t.Fatalf("line = %q, want %q", line, want)
+		}
+	}
+	if _, err := lr.next(); err != io.EOF {
+		t.Fatalf("expected io.EOF, got %v", err)
+	}
+}
+
+func TestNewlineFraming_AcceptsCRLF(t *testing.T) {
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	defer func() {
+		_ = inW.Close()
+		_ = outW.Close()
+		_ = inR.Close()
+		_ = outR.Close()
+	}()
+
+	blocked := make(chan struct{})
+	_ = NewConnection(func(ctx context.Context, method string, params json.RawMessage) (any, *RequestError) {
+		if method == "block" {
+			close(blocked)
+			<-ctx.Done()
+			return nil, NewRequestCancelled(nil)
+		}
+		return map[string]string{"method": method}, nil
+	}, outW, inR)
+
+	lines := make(chan string, 4)
+	go func() {
+		scanner := bufio.NewScanner(outR)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+	next := func() string {
+		t.Helper()
+		select {
+		case line := <-lines:
+			return line
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for response")
+			return ""
+		}
+	}
+
+	_, _ = io.WriteString(inW, `{"jsonrpc":"2.0","id":"a","method":"first"}`+"\r\n")
+	if line := next(); !strings.Contains(line, `"id":"a"`) || !strings.Contains(line, `"method":"first"`) {
+		t.Fatalf("unexpected response: %s", line)
+	}
+
+	// $/cancel_request must still match the request id when lines end in CRLF.
+	_, _ = io.WriteString(inW, `{"jsonrpc":"2.0","id":7,"method":"block"}`+"\r\n")
+	<-blocked
+	_, _ = io.WriteString(inW, `{"jsonrpc":"2.0","method":"$/cancel_request","params":{"requestId":7}}`+"\r\n")
+	if line := next(); !strings.Contains(line, `"id":7`) || !strings.Contains(line, `-32800`) {
+		t.Fatalf("expected cancelled response, got: %s", line)
+	}
+}
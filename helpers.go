@@ -43,6 +43,63 @@ func ResourceBlock(res EmbeddedResourceResource) ContentBlock {
 	}}
 }
 
+// TextResourceBlock constructs an embedded resource content block with text contents.
+func TextResourceBlock(uri string, text string, mimeType string) ContentBlock {
+	var mt *string
+	if mimeType != "" {
+		mt = &mimeType
+	}
+	return ResourceBlock(EmbeddedResourceResource{TextResourceContents: &TextResourceContents{
+		MimeType: mt,
+		Text:     text,
+		Uri:      uri,
+	}})
+}
+
+// BlobResourceBlock constructs an embedded resource content block with base64-encoded
+// binary contents.
+func BlobResourceBlock(uri string, blob string, mimeType string) ContentBlock {
+	var mt *string
+	if mimeType != "" {
+		mt = &mimeType
+	}
+	return ResourceBlock(EmbeddedResourceResource{BlobResourceContents: &BlobResourceContents{
+		Blob:     blob,
+		MimeType: mt,
+		Uri:      uri,
+	}})
+}
+
+// ContentBlockKind identifies which variant of a ContentBlock is set. Its values
+// match the block's "type" discriminator on the wire.
+type ContentBlockKind string
+
+const (
+	ContentBlockKindText         ContentBlockKind = "text"
+	ContentBlockKindImage        ContentBlockKind = "image"
+	ContentBlockKindAudio        ContentBlockKind = "audio"
+	ContentBlockKindResourceLink ContentBlockKind = "resource_link"
+	ContentBlockKindResource     ContentBlockKind = "resource"
+)
+
+// Kind reports which variant of b is set, or "" if none is. If several are set,
+// the first in declaration order wins; use Validate to reject such blocks.
+func (b ContentBlock) Kind() ContentBlockKind {
+	switch {
+	case b.Text != nil:
+		return ContentBlockKindText
+	case b.Image != nil:
+		return ContentBlockKindImage
+	case b.Audio != nil:
+		return ContentBlockKindAudio
+	case b.ResourceLink != nil:
+		return ContentBlockKindResourceLink
+	case b.Resource != nil:
+		return ContentBlockKindResource
+	}
+	return ""
+}
+
 // ToolContent wraps a content block as tool-call content.
 func ToolContent(block ContentBlock) ToolCallContent {
 	return ToolCallContent{Content: &ToolCallContentContent{
@@ -78,6 +135,15 @@ func Ptr[T any](v T) *T {
 	return &v
 }
 
+// Deref returns *p, or fallback if p is nil. It is convenient for reading
+// optional fields such as ToolCallUpdate.Title.
+func Deref[T any](p *T, fallback T) T {
+	if p == nil {
+		return fallback
+	}
+	return *p
+}
+
 // UpdateUserMessage constructs a user_message_chunk update with the given content.
 func UpdateUserMessage(content ContentBlock) SessionUpdate {
 	return SessionUpdate{UserMessageChunk: &SessionUpdateUserMessageChunk{Content: content}}
@@ -113,6 +179,43 @@ func UpdatePlan(entries ...PlanEntry) SessionUpdate {
 	return SessionUpdate{Plan: &SessionUpdatePlan{Entries: entries}}
 }
 
+// AgentMessageNotification constructs a ready-to-send agent_message_chunk
+// notification for the session. It is named with a Notification suffix, as are its
+// siblings, to avoid clashing with the generated ToolCallUpdate and PlanUpdate types.
+func AgentMessageNotification(sessionId SessionId, content ContentBlock) SessionNotification {
+	return SessionNotification{SessionId: sessionId, Update: UpdateAgentMessage(content)}
+}
+
+// AgentThoughtNotification constructs a ready-to-send agent_thought_chunk
+// notification for the session.
+func AgentThoughtNotification(sessionId SessionId, content ContentBlock) SessionNotification {
+	return SessionNotification{SessionId: sessionId, Update: UpdateAgentThought(content)}
+}
+
+// ToolCallUpdateNotification constructs a ready-to-send tool_call_update
+// notification for the session from u.
+func ToolCallUpdateNotification(sessionId SessionId, u ToolCallUpdate) SessionNotification {
+	return SessionNotification{SessionId: sessionId, Update: SessionUpdate{ToolCallUpdate: &SessionToolCallUpdate{
+		Content:    u.Content,
+		Kind:       u.Kind,
+		Locations:  u.Locations,
+		Meta:       u.Meta,
+		RawInput:   u.RawInput,
+		RawOutput:  u.RawOutput,
+		Status:     u.Status,
+		Title:      u.Title,
+		ToolCallId: u.ToolCallId,
+	}}}
+}
+
+// PlanNotification constructs a ready-to-send plan notification for the session.
+func PlanNotification(sessionId SessionId, plan Plan) SessionNotification {
+	return SessionNotification{SessionId: sessionId, Update: SessionUpdate{Plan: &SessionUpdatePlan{
+		Entries: plan.Entries,
+		Meta:    plan.Meta,
+	}}}
+}
+
 type ToolCallStartOpt func(tc *SessionUpdateToolCall)
 
 // StartToolCall constructs a tool_call update with required fields and applies optional modifiers.
@@ -91,6 +91,34 @@ func NewUnstableCreateElicitationResponseCancel() UnstableCreateElicitationRespo
 	return UnstableCreateElicitationResponse{Cancel: &UnstableCreateElicitationCancel{Action: "cancel"}}
 }
 
+// NewUnstableElicitationPropertySchemaString constructs a UnstableElicitationPropertySchema using the 'string' variant.
+func NewUnstableElicitationPropertySchemaString() UnstableElicitationPropertySchema {
+	return UnstableElicitationPropertySchema{String: &UnstableElicitationPropertySchemaString{Type: "string"}}
+}
+
+// NewUnstableElicitationPropertySchemaNumber constructs a UnstableElicitationPropertySchema using the 'number' variant.
+func NewUnstableElicitationPropertySchemaNumber() UnstableElicitationPropertySchema {
+	return UnstableElicitationPropertySchema{Number: &UnstableElicitationPropertySchemaNumber{Type: "number"}}
+}
+
+// NewUnstableElicitationPropertySchemaInteger constructs a UnstableElicitationPropertySchema using the 'integer' variant.
+func NewUnstableElicitationPropertySchemaInteger() UnstableElicitationPropertySchema {
+	return UnstableElicitationPropertySchema{Integer: &UnstableElicitationPropertySchemaInteger{Type: "integer"}}
+}
+
+// NewUnstableElicitationPropertySchemaBoolean constructs a UnstableElicitationPropertySchema using the 'boolean' variant.
+func NewUnstableElicitationPropertySchemaBoolean() UnstableElicitationPropertySchema {
+	return UnstableElicitationPropertySchema{Boolean: &UnstableElicitationPropertySchemaBoolean{Type: "boolean"}}
+}
+
+// NewUnstableElicitationPropertySchemaArray constructs a UnstableElicitationPropertySchema using the 'array' variant.
+func NewUnstableElicitationPropertySchemaArray(items UnstableMultiSelectItems) UnstableElicitationPropertySchema {
+	return UnstableElicitationPropertySchema{Array: &UnstableElicitationPropertySchemaArray{
+		Items: items,
+		Type:  "array",
+	}}
+}
+
 // NewUnstableNesSuggestionEdit constructs a UnstableNesSuggestion using the 'edit' variant.
 func NewUnstableNesSuggestionEdit(id string, uri string, edits []UnstableNesTextEdit) UnstableNesSuggestion {
 	return UnstableNesSuggestion{Edit: &UnstableNesSuggestionEdit{
@@ -149,3 +177,186 @@ func NewUnstableSessionConfigOptionBoolean(currentValue bool) UnstableSessionCon
 		Type:         "boolean",
 	}}
 }
+
+// NewAuthenticateRequest constructs a AuthenticateRequest with its required fields set.
+func NewAuthenticateRequest(methodId string) AuthenticateRequest {
+	return AuthenticateRequest{MethodId: methodId}
+}
+
+// NewCloseSessionRequest constructs a CloseSessionRequest with its required fields set.
+func NewCloseSessionRequest(sessionId SessionId) CloseSessionRequest {
+	return CloseSessionRequest{SessionId: sessionId}
+}
+
+// NewCreateTerminalRequest constructs a CreateTerminalRequest with its required fields set.
+func NewCreateTerminalRequest(sessionId SessionId, command string) CreateTerminalRequest {
+	return CreateTerminalRequest{
+		Command:   command,
+		SessionId: sessionId,
+	}
+}
+
+// NewInitializeRequest constructs a InitializeRequest with its required fields set.
+func NewInitializeRequest(protocolVersion ProtocolVersion) InitializeRequest {
+	return InitializeRequest{ProtocolVersion: protocolVersion}
+}
+
+// NewKillTerminalRequest constructs a KillTerminalRequest with its required fields set.
+func NewKillTerminalRequest(sessionId SessionId, terminalId string) KillTerminalRequest {
+	return KillTerminalRequest{
+		SessionId:  sessionId,
+		TerminalId: terminalId,
+	}
+}
+
+// NewLoadSessionRequest constructs a LoadSessionRequest with its required fields set.
+func NewLoadSessionRequest(mcpServers []McpServer, cwd string, sessionId SessionId) LoadSessionRequest {
+	return LoadSessionRequest{
+		Cwd:        cwd,
+		McpServers: mcpServers,
+		SessionId:  sessionId,
+	}
+}
+
+// NewNewSessionRequest constructs a NewSessionRequest with its required fields set.
+func NewNewSessionRequest(cwd string, mcpServers []McpServer) NewSessionRequest {
+	return NewSessionRequest{
+		Cwd:        cwd,
+		McpServers: mcpServers,
+	}
+}
+
+// NewPromptRequest constructs a PromptRequest with its required fields set.
+func NewPromptRequest(sessionId SessionId, prompt []ContentBlock) PromptRequest {
+	return PromptRequest{
+		Prompt:    prompt,
+		SessionId: sessionId,
+	}
+}
+
+// NewReadTextFileRequest constructs a ReadTextFileRequest with its required fields set.
+func NewReadTextFileRequest(sessionId SessionId, path string) ReadTextFileRequest {
+	return ReadTextFileRequest{
+		Path:      path,
+		SessionId: sessionId,
+	}
+}
+
+// NewReleaseTerminalRequest constructs a ReleaseTerminalRequest with its required fields set.
+func NewReleaseTerminalRequest(sessionId SessionId, terminalId string) ReleaseTerminalRequest {
+	return ReleaseTerminalRequest{
+		SessionId:  sessionId,
+		TerminalId: terminalId,
+	}
+}
+
+// NewRequestPermissionRequest constructs a RequestPermissionRequest with its required fields set.
+func NewRequestPermissionRequest(sessionId SessionId, toolCall ToolCallUpdate, options []PermissionOption) RequestPermissionRequest {
+	return RequestPermissionRequest{
+		Options:   options,
+		SessionId: sessionId,
+		ToolCall:  toolCall,
+	}
+}
+
+// NewResumeSessionRequest constructs a ResumeSessionRequest with its required fields set.
+func NewResumeSessionRequest(sessionId SessionId, cwd string) ResumeSessionRequest {
+	return ResumeSessionRequest{
+		Cwd:       cwd,
+		SessionId: sessionId,
+	}
+}
+
+// NewSetSessionModeRequest constructs a SetSessionModeRequest with its required fields set.
+func NewSetSessionModeRequest(sessionId SessionId, modeId SessionModeId) SetSessionModeRequest {
+	return SetSessionModeRequest{
+		ModeId:    modeId,
+		SessionId: sessionId,
+	}
+}
+
+// NewTerminalOutputRequest constructs a TerminalOutputRequest with its required fields set.
+func NewTerminalOutputRequest(sessionId SessionId, terminalId string) TerminalOutputRequest {
+	return TerminalOutputRequest{
+		SessionId:  sessionId,
+		TerminalId: terminalId,
+	}
+}
+
+// NewUnstableCloseNesRequest constructs a UnstableCloseNesRequest with its required fields set.
+func NewUnstableCloseNesRequest(sessionId SessionId) UnstableCloseNesRequest {
+	return UnstableCloseNesRequest{SessionId: sessionId}
+}
+
+// NewUnstableConnectMcpRequest constructs a UnstableConnectMcpRequest with its required fields set.
+func NewUnstableConnectMcpRequest(acpId UnstableMcpServerAcpId) UnstableConnectMcpRequest {
+	return UnstableConnectMcpRequest{AcpId: acpId}
+}
+
+// NewUnstableDeleteSessionRequest constructs a UnstableDeleteSessionRequest with its required fields set.
+func NewUnstableDeleteSessionRequest(sessionId SessionId) UnstableDeleteSessionRequest {
+	return UnstableDeleteSessionRequest{SessionId: sessionId}
+}
+
+// NewUnstableDisableProviderRequest constructs a UnstableDisableProviderRequest with its required fields set.
+func NewUnstableDisableProviderRequest(id string) UnstableDisableProviderRequest {
+	return UnstableDisableProviderRequest{Id: id}
+}
+
+// NewUnstableDisconnectMcpRequest constructs a UnstableDisconnectMcpRequest with its required fields set.
+func NewUnstableDisconnectMcpRequest(connectionId UnstableMcpConnectionId) UnstableDisconnectMcpRequest {
+	return UnstableDisconnectMcpRequest{ConnectionId: connectionId}
+}
+
+// NewUnstableForkSessionRequest constructs a UnstableForkSessionRequest with its required fields set.
+func NewUnstableForkSessionRequest(sessionId SessionId, cwd string) UnstableForkSessionRequest {
+	return UnstableForkSessionRequest{
+		Cwd:       cwd,
+		SessionId: sessionId,
+	}
+}
+
+// NewUnstableMessageMcpRequest constructs a UnstableMessageMcpRequest with its required fields set.
+func NewUnstableMessageMcpRequest(connectionId UnstableMcpConnectionId, method string) UnstableMessageMcpRequest {
+	return UnstableMessageMcpRequest{
+		ConnectionId: connectionId,
+		Method:       method,
+	}
+}
+
+// NewUnstableSetProviderRequest constructs a UnstableSetProviderRequest with its required fields set.
+func NewUnstableSetProviderRequest(id string, apiType UnstableLlmProtocol, baseUrl string) UnstableSetProviderRequest {
+	return UnstableSetProviderRequest{
+		ApiType: apiType,
+		BaseUrl: baseUrl,
+		Id:      id,
+	}
+}
+
+// NewUnstableSuggestNesRequest constructs a UnstableSuggestNesRequest with its required fields set.
+func NewUnstableSuggestNesRequest(sessionId SessionId, uri string, version int, position UnstablePosition, triggerKind UnstableNesTriggerKind) UnstableSuggestNesRequest {
+	return UnstableSuggestNesRequest{
+		Position:    position,
+		SessionId:   sessionId,
+		TriggerKind: triggerKind,
+		Uri:         uri,
+		Version:     version,
+	}
+}
+
+// NewWaitForTerminalExitRequest constructs a WaitForTerminalExitRequest with its required fields set.
+func NewWaitForTerminalExitRequest(sessionId SessionId, terminalId string) WaitForTerminalExitRequest {
+	return WaitForTerminalExitRequest{
+		SessionId:  sessionId,
+		TerminalId: terminalId,
+	}
+}
+
+// NewWriteTextFileRequest constructs a WriteTextFileRequest with its required fields set.
+func NewWriteTextFileRequest(sessionId SessionId, path string, content string) WriteTextFileRequest {
+	return WriteTextFileRequest{
+		Content:   content,
+		Path:      path,
+		SessionId: sessionId,
+	}
+}
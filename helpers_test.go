@@ -0,0 +1,82 @@
+package acp
+
+import "testing"
+
+func TestContentBlock_Kind(t *testing.T) {
+	cases := []struct {
+		block ContentBlock
+		want  ContentBlockKind
+	}{
+		{TextBlock("hi"), ContentBlockKindText},
+		{ImageBlock("aGk=", "image/png"), ContentBlockKindImage},
+		{AudioBlock("aGk=", "audio/wav"), ContentBlockKindAudio},
+		{ResourceLinkBlock("doc", "file:///doc"), ContentBlockKindResourceLink},
+		{TextResourceBlock("file:///a.txt", "a", ""), ContentBlockKindResource},
+		{ContentBlock{}, ""},
+	}
+	for _, tc := range cases {
+		if got := tc.block.Kind(); got != tc.want {
+			t.Errorf("Kind() = %q, want %q", got, tc.want)
+		}
+	}
+}
+
+func TestContentBlock_ValidateRequiresExactlyOneVariant(t *testing.T) {
+	block := TextBlock("hi")
+	if err := block.Validate(); err != nil {
+		t.Fatalf("valid block rejected: %v", err)
+	}
+	if err := (&ContentBlock{}).Validate(); err == nil {
+		t.Fatal("expected error for empty block")
+	}
+	block.Image = ImageBlock("aGk=", "image/png").Image
+	if err := block.Validate(); err == nil {
+		t.Fatal("expected error for block with two variants")
+	}
+}
+
+func TestDeref(t *testing.T) {
+	if got := Deref(Ptr("title"), "fallback"); got != "title" {
+		t.Fatalf("Deref(non-nil) = %q", got)
+	}
+	var kind *ToolKind
+	if got := Deref(kind, ToolKindOther); got != ToolKindOther {
+		t.Fatalf("Deref(nil) = %q", got)
+	}
+}
+
+func TestSessionNotificationBuilders(t *testing.T) {
+	n := AgentMessageNotification("s1", TextBlock("hi"))
+	if n.SessionId != "s1" || n.Update.AgentMessageChunk == nil || n.Update.AgentMessageChunk.Content.Text.Text != "hi" {
+		t.Fatalf("unexpected agent message notification: %+v", n)
+	}
+	n = AgentThoughtNotification("s1", TextBlock("hmm"))
+	if n.Update.AgentThoughtChunk == nil || n.Update.AgentThoughtChunk.Content.Text.Text != "hmm" {
+		t.Fatalf("unexpected agent thought notification: %+v", n)
+	}
+	n = ToolCallUpdateNotification("s1", NewToolCall("call_1").Status(ToolCallStatusCompleted).Build())
+	if u := n.Update.ToolCallUpdate; u == nil || u.ToolCallId != "call_1" || Deref(u.Status, "") != ToolCallStatusCompleted {
+		t.Fatalf("unexpected tool call notification: %+v", n)
+	}
+	n = PlanNotification("s1", Plan{Entries: []PlanEntry{{Content: "step", Priority: PlanEntryPriorityHigh, Status: PlanEntryStatusPending}}})
+	if n.Update.Plan == nil || len(n.Update.Plan.Entries) != 1 {
+		t.Fatalf("unexpected plan notification: %+v", n)
+	}
+	if err := n.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+}
+
+func TestRequestConstructors(t *testing.T) {
+	req := NewWriteTextFileRequest("s1", "/a.txt", "body")
+	if req.SessionId != "s1" || req.Path != "/a.txt" || req.Content != "body" || req.Meta != nil {
+		t.Fatalf("unexpected request: %+v", req)
+	}
+	if err := req.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	p := NewPromptRequest("s1", []ContentBlock{TextBlock("hi")})
+	if err := p.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+}
@@ -87,12 +87,18 @@ func TestJSONGolden_ContentBlocks(t *testing.T) {
 			res := EmbeddedResourceResource{TextResourceContents: &TextResourceContents{Uri: "file:///home/user/script.py", MimeType: Ptr("text/x-python"), Text: "def hello():\n    print('Hello, world!')"}}
 			return ResourceBlock(res)
 		},
+		func() ContentBlock {
+			return TextResourceBlock("file:///home/user/script.py", "def hello():\n    print('Hello, world!')", "text/x-python")
+		},
 	))
 	t.Run("content_resource_blob", runGolden(
 		func() ContentBlock {
 			res := EmbeddedResourceResource{BlobResourceContents: &BlobResourceContents{Uri: "file:///home/user/document.pdf", MimeType: Ptr("application/pdf"), Blob: "<b64>"}}
 			return ResourceBlock(res)
 		},
+		func() ContentBlock {
+			return BlobResourceBlock("file:///home/user/document.pdf", "<b64>", "application/pdf")
+		},
 	))
 	t.Run("content_resource_link", runGolden(
 		func() ContentBlock {
@@ -270,3 +276,48 @@ func TestJSONGolden_MethodPayloads(t *testing.T) {
 	}))
 	t.Run("cancel_notification", runGolden(func() CancelNotification { return CancelNotification{SessionId: "sess_abc123def456"} }))
 }
+
+func TestJSONRoundTrip_AdditionalProperties(t *testing.T) {
+	raw := `{"_meta":{"vendor.example/trace":{"id":"t1"}},"apiType":"openai","baseUrl":"https://llm.example","headers":{"X-Team":"acp"},"id":"p1"}`
+	var req UnstableSetProviderRequest
+	if err := json.Unmarshal([]byte(raw), &req); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if req.Headers["X-Team"] != "acp" {
+		t.Fatalf("typed headers not decoded: %+v", req.Headers)
+	}
+	b, err := json.Marshal(req)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if ok, got, want := equalJSON(b, []byte(raw)); !ok {
+		t.Fatalf("round trip changed payload:\n got %s\nwant %s", got, want)
+	}
+}
+
+// TestJSONGolden_MinimalRequests pins the wire form of every stable request
+// built with only its required fields: optional slices, maps and pointers must
+// be absent rather than null or empty, since strict peers reject those.
+func TestJSONGolden_MinimalRequests(t *testing.T) {
+	t.Parallel()
+	t.Run("authenticate_request_minimal", runGolden(func() AuthenticateRequest { return NewAuthenticateRequest("api_key") }))
+	t.Run("close_session_request_minimal", runGolden(func() CloseSessionRequest { return NewCloseSessionRequest("sess_1") }))
+	t.Run("create_terminal_request_minimal", runGolden(func() CreateTerminalRequest { return NewCreateTerminalRequest("sess_1", "make") }))
+	t.Run("initialize_request_minimal", runGolden(func() InitializeRequest { return NewInitializeRequest(ProtocolVersionNumber) }))
+	t.Run("kill_terminal_request_minimal", runGolden(func() KillTerminalRequest { return NewKillTerminalRequest("sess_1", "term_1") }))
+	t.Run("list_sessions_request_minimal", runGolden(func() ListSessionsRequest { return ListSessionsRequest{} }))
+	t.Run("load_session_request_minimal", runGolden(func() LoadSessionRequest { return NewLoadSessionRequest([]McpServer{}, "/home/user/project", "sess_1") }))
+	t.Run("logout_request_minimal", runGolden(func() LogoutRequest { return LogoutRequest{} }))
+	t.Run("new_session_request_minimal", runGolden(func() NewSessionRequest { return NewNewSessionRequest("/home/user/project", []McpServer{}) }))
+	t.Run("prompt_request_minimal", runGolden(func() PromptRequest { return NewPromptRequest("sess_1", []ContentBlock{TextBlock("hi")}) }))
+	t.Run("read_text_file_request_minimal", runGolden(func() ReadTextFileRequest { return NewReadTextFileRequest("sess_1", "/tmp/a.txt") }))
+	t.Run("release_terminal_request_minimal", runGolden(func() ReleaseTerminalRequest { return NewReleaseTerminalRequest("sess_1", "term_1") }))
+	t.Run("request_permission_request_minimal", runGolden(func() RequestPermissionRequest {
+		return NewRequestPermissionRequest("sess_1", ToolCallUpdate{ToolCallId: "call_1"}, []PermissionOption{{OptionId: "allow", Name: "Allow", Kind: PermissionOptionKindAllowOnce}})
+	}))
+	t.Run("resume_session_request_minimal", runGolden(func() ResumeSessionRequest { return NewResumeSessionRequest("sess_1", "/home/user/project") }))
+	t.Run("set_session_mode_request_minimal", runGolden(func() SetSessionModeRequest { return NewSetSessionModeRequest("sess_1", "code") }))
+	t.Run("terminal_output_request_minimal", runGolden(func() TerminalOutputRequest { return NewTerminalOutputRequest("sess_1", "term_1") }))
+	t.Run("wait_for_terminal_exit_request_minimal", runGolden(func() WaitForTerminalExitRequest { return NewWaitForTerminalExitRequest("sess_1", "term_1") }))
+	t.Run("write_text_file_request_minimal", runGolden(func() WriteTextFileRequest { return NewWriteTextFileRequest("sess_1", "/tmp/a.txt", "hello") }))
+}
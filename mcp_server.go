@@ -0,0 +1,63 @@
+package acp
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// Validate checks that exactly one transport is configured and that it has the
+// fields needed to connect: a name and command for stdio, a name and an http(s)
+// URL for HTTP and SSE, and a name and id for ACP. Header and environment
+// variable names must be non-empty. The transport checks shared by every
+// variant come from the schema's required fields, so they cover whichever
+// transports the generated surface includes.
+func (u *McpServer) Validate() error {
+	if err := u.validateVariant(); err != nil {
+		return err
+	}
+	switch {
+	case u.Stdio != nil:
+		for i, env := range u.Stdio.Env {
+			if env.Name == "" {
+				return fmt.Errorf("stdio %q: env[%d]: name is required", u.Stdio.Name, i)
+			}
+		}
+	case u.Http != nil:
+		return validateMcpServerURL("http", u.Http.Name, u.Http.Url, u.Http.Headers)
+	case u.Sse != nil:
+		return validateMcpServerURL("sse", u.Sse.Name, u.Sse.Url, u.Sse.Headers)
+	}
+	return nil
+}
+
+func validateMcpServerURL(transport, name, rawURL string, headers []HttpHeader) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("%s %q: invalid url: %w", transport, name, err)
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("%s %q: url %q must be an absolute http or https URL", transport, name, rawURL)
+	}
+	for i, h := range headers {
+		if h.Name == "" {
+			return fmt.Errorf("%s %q: headers[%d]: name is required", transport, name, i)
+		}
+	}
+	return nil
+}
+
+// ValidateMcpServers validates each server with McpServer.Validate, reporting
+// the index of the first invalid one. Agents can call it at the top of
+// NewSession and reject the request with an invalid params error:
+//
+//	if err := acp.ValidateMcpServers(params.McpServers); err != nil {
+//		return acp.NewSessionResponse{}, acp.NewInvalidParams(map[string]any{"error": err.Error()})
+//	}
+func ValidateMcpServers(servers []McpServer) error {
+	for i := range servers {
+		if err := servers[i].Validate(); err != nil {
+			return fmt.Errorf("mcpServers[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package acp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMcpServerValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		server  McpServer
+		wantErr string
+	}{
+		{name: "stdio", server: McpServer{Stdio: &McpServerStdio{Name: "fs", Command: "/bin/mcp-fs", Env: []EnvVariable{{Name: "HOME", Value: "/"}}}}},
+		{name: "http", server: McpServer{Http: &McpServerHttpInline{Name: "web", Url: "https://example.com/mcp"}}},
+		{name: "sse", server: McpServer{Sse: &McpServerSseInline{Name: "events", Url: "http://localhost:8080/sse"}}},
+		{name: "acp", server: McpServer{Acp: &McpServerAcpInline{Name: "tools", Id: "tools-1"}}},
+		{name: "no transport", server: McpServer{}, wantErr: "exactly one variant set, got 0"},
+		{
+			name: "two transports",
+			server: McpServer{
+				Stdio: &McpServerStdio{Name: "fs", Command: "mcp-fs"},
+				Http:  &McpServerHttpInline{Name: "web", Url: "https://example.com"},
+			},
+			wantErr: "exactly one variant set, got 2",
+		},
+		{name: "stdio without command", server: McpServer{Stdio: &McpServerStdio{Name: "fs"}}, wantErr: `stdio "fs": command is required`},
+		{name: "stdio env without name", server: McpServer{Stdio: &McpServerStdio{Name: "fs", Command: "mcp-fs", Env: []EnvVariable{{Value: "x"}}}}, wantErr: "env[0]: name is required"},
+		{name: "http without name", server: McpServer{Http: &McpServerHttpInline{Url: "https://example.com"}}, wantErr: "http: name is required"},
+		{name: "http relative url", server: McpServer{Http: &McpServerHttpInline{Name: "web", Url: "/mcp"}}, wantErr: "must be an absolute http or https URL"},
+		{name: "sse wrong scheme", server: McpServer{Sse: &McpServerSseInline{Name: "events", Url: "ftp://example.com"}}, wantErr: "must be an absolute http or https URL"},
+		{name: "http header without name", server: McpServer{Http: &McpServerHttpInline{Name: "web", Url: "https://example.com", Headers: []HttpHeader{{Value: "v"}}}}, wantErr: "headers[0]: name is required"},
+		{name: "acp without id", server: McpServer{Acp: &McpServerAcpInline{Name: "tools"}}, wantErr: `acp "tools": id is required`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.server.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateMcpServers(t *testing.T) {
+	if err := ValidateMcpServers(nil); err != nil {
+		t.Fatalf("ValidateMcpServers(nil) = %v", err)
+	}
+	servers := []McpServer{
+		{Stdio: &McpServerStdio{Name: "fs", Command: "mcp-fs"}},
+		{Http: &McpServerHttpInline{Name: "web"}},
+	}
+	err := ValidateMcpServers(servers)
+	if err == nil || !strings.HasPrefix(err.Error(), "mcpServers[1]: ") {
+		t.Fatalf("ValidateMcpServers() = %v, want error for mcpServers[1]", err)
+	}
+}
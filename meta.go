@@ -0,0 +1,32 @@
+package acp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+)
+
+type metaContextKey struct{}
+
+// MetaFromContext returns the _meta object of the inbound request or notification
+// being handled, keyed by field name. It reports false if the message had no
+// _meta object. The params passed to the handler still include _meta.
+func MetaFromContext(ctx context.Context) (map[string]json.RawMessage, bool) {
+	meta, ok := ctx.Value(metaContextKey{}).(map[string]json.RawMessage)
+	return meta, ok
+}
+
+// parseMeta returns the top-level _meta object of params, or nil if there is none.
+func parseMeta(params json.RawMessage) map[string]json.RawMessage {
+	// Avoid decoding params that cannot contain _meta.
+	if !bytes.Contains(params, []byte(`"_meta"`)) {
+		return nil
+	}
+	var envelope struct {
+		Meta map[string]json.RawMessage `json:"_meta"`
+	}
+	if err := json.Unmarshal(params, &envelope); err != nil {
+		return nil
+	}
+	return envelope.Meta
+}
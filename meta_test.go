@@ -0,0 +1,57 @@
+package acp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestMetaFromContext_ExposesInboundMeta(t *testing.T) {
+	c2aR, c2aW := io.Pipe()
+	a2cR, a2cW := io.Pipe()
+	defer func() {
+		_ = c2aR.Close()
+		_ = c2aW.Close()
+		_ = a2cR.Close()
+		_ = a2cW.Close()
+	}()
+
+	_ = NewConnection(func(ctx context.Context, method string, params json.RawMessage) (any, *RequestError) {
+		meta, ok := MetaFromContext(ctx)
+		var p map[string]json.RawMessage
+		_ = json.Unmarshal(params, &p)
+		_, paramsHaveMeta := p["_meta"]
+		return map[string]any{"ok": ok, "tenant": string(meta["tenant"]), "paramsHaveMeta": paramsHaveMeta}, nil
+	}, a2cW, c2aR)
+	c := NewConnection(nil, c2aW, a2cR)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	got, err := SendRequest[map[string]any](c, ctx, "with", map[string]any{"_meta": map[string]any{"tenant": "t1"}})
+	if err != nil {
+		t.Fatalf("SendRequest: %v", err)
+	}
+	if got["ok"] != true || got["tenant"] != `"t1"` || got["paramsHaveMeta"] != true {
+		t.Fatalf("unexpected meta from handler: %v", got)
+	}
+
+	got, err = SendRequest[map[string]any](c, ctx, "without", map[string]any{"x": 1})
+	if err != nil {
+		t.Fatalf("SendRequest: %v", err)
+	}
+	if got["ok"] != false {
+		t.Fatalf("expected no meta, got %v", got)
+	}
+}
+
+func TestParseMeta_IgnoresNestedMeta(t *testing.T) {
+	if meta := parseMeta(json.RawMessage(`{"inner":{"_meta":{"a":1}}}`)); meta != nil {
+		t.Fatalf("expected nil for nested _meta, got %v", meta)
+	}
+	if meta := parseMeta(json.RawMessage(`{"_meta":{"a":1}}`)); string(meta["a"]) != "1" {
+		t.Fatalf("unexpected meta: %v", meta)
+	}
+}
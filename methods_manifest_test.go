@@ -0,0 +1,52 @@
+package acp
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestMethodRouting_MatchesInterfaces checks every MethodRouting entry against
+// the generated interfaces, so the manifest cannot drift from dispatch.
+func TestMethodRouting_MatchesInterfaces(t *testing.T) {
+	t.Parallel()
+	interfaces := map[string]reflect.Type{
+		"Agent":              reflect.TypeOf((*Agent)(nil)).Elem(),
+		"AgentLoader":        reflect.TypeOf((*AgentLoader)(nil)).Elem(),
+		"AgentExperimental":  reflect.TypeOf((*AgentExperimental)(nil)).Elem(),
+		"Client":             reflect.TypeOf((*Client)(nil)).Elem(),
+		"ClientExperimental": reflect.TypeOf((*ClientExperimental)(nil)).Elem(),
+	}
+	for wire, info := range MethodRouting {
+		iface, ok := interfaces[info.Binding]
+		if !ok {
+			t.Errorf("%s: unknown binding %q", wire, info.Binding)
+			continue
+		}
+		m, ok := iface.MethodByName(info.GoMethod)
+		if !ok {
+			t.Errorf("%s: %s has no method %s", wire, info.Binding, info.GoMethod)
+			continue
+		}
+		if got := m.Type.In(1).Name(); got != info.Params {
+			t.Errorf("%s: params type %s, manifest says %s", wire, got, info.Params)
+		}
+		result := ""
+		if m.Type.NumOut() == 2 {
+			result = m.Type.Out(0).Name()
+		}
+		if result != info.Result {
+			t.Errorf("%s: result type %q, manifest says %q", wire, result, info.Result)
+		}
+	}
+	for _, wire := range []string{AgentMethodSessionPrompt, AgentMethodSessionLoad, ClientMethodSessionUpdate, ClientMethodFsReadTextFile} {
+		if _, ok := MethodRouting[wire]; !ok {
+			t.Errorf("MethodRouting missing %s", wire)
+		}
+	}
+	if info := MethodRouting[AgentMethodSessionLoad]; info.Side != "agent" || info.Binding != "AgentLoader" {
+		t.Errorf("session/load routed to %+v", info)
+	}
+	if info := MethodRouting[ClientMethodSessionUpdate]; !info.Notification || info.Side != "client" {
+		t.Errorf("session/update routed to %+v", info)
+	}
+}
@@ -0,0 +1,66 @@
+package acp
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// InboundMiddleware wraps the handler that dispatches inbound requests and
+// notifications. Middleware can inspect the request context and params, and can
+// short-circuit by returning a RequestError without calling next.
+type InboundMiddleware func(next MethodHandler) MethodHandler
+
+// OutboundFunc sends an outbound request or notification with the given method
+// and params.
+type OutboundFunc func(ctx context.Context, method string, params json.RawMessage) error
+
+// OutboundMiddleware wraps outbound sends. Middleware may observe or rewrite the
+// method and params before calling next, or return an error to abort the send.
+type OutboundMiddleware func(next OutboundFunc) OutboundFunc
+
+// WithInboundMiddleware adds middleware around inbound dispatch. Middleware runs
+// in registration order: the first registered is the outermost. It has no effect
+// on connections created without a handler.
+func WithInboundMiddleware(mw ...InboundMiddleware) ConnectionOption {
+	return func(c *Connection) {
+		c.inboundMiddleware = append(c.inboundMiddleware, mw...)
+	}
+}
+
+// WithOutboundMiddleware adds middleware around outbound requests and
+// notifications. Middleware runs in registration order: the first registered is
+// the outermost. Responses to inbound requests are not affected.
+func WithOutboundMiddleware(mw ...OutboundMiddleware) ConnectionOption {
+	return func(c *Connection) {
+		c.outboundMiddleware = append(c.outboundMiddleware, mw...)
+	}
+}
+
+func chainInbound(h MethodHandler, mw []InboundMiddleware) MethodHandler {
+	for i := len(mw) - 1; i >= 0; i-- {
+		h = mw[i](h)
+	}
+	return h
+}
+
+// sendOutbound sends msg through the outbound middleware chain.
+func (c *Connection) sendOutbound(ctx context.Context, msg anyMessage) error {
+	return c.sendOutboundVia(ctx, msg, c.sendMessage)
+}
+
+// sendOutboundVia runs msg through the outbound middleware chain and hands the
+// result to send.
+func (c *Connection) sendOutboundVia(ctx context.Context, msg anyMessage, send func(anyMessage) error) error {
+	if len(c.outboundMiddleware) == 0 {
+		return send(msg)
+	}
+	next := OutboundFunc(func(ctx context.Context, method string, params json.RawMessage) error {
+		msg.Method = method
+		msg.Params = params
+		return send(msg)
+	})
+	for i := len(c.outboundMiddleware) - 1; i >= 0; i-- {
+		next = c.outboundMiddleware[i](next)
+	}
+	return next(ctx, msg.Method, msg.Params)
+}
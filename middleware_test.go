@@ -0,0 +1,136 @@
+package acp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+type authKey struct{}
+
+func TestInboundMiddleware_ComposesInOrderAndShortCircuits(t *testing.T) {
+	c2aR, c2aW := io.Pipe()
+	a2cR, a2cW := io.Pipe()
+	defer func() {
+		_ = c2aR.Close()
+		_ = c2aW.Close()
+		_ = a2cR.Close()
+		_ = a2cW.Close()
+	}()
+
+	var mu sync.Mutex
+	var order []string
+	trace := func(name string) InboundMiddleware {
+		return func(next MethodHandler) MethodHandler {
+			return func(ctx context.Context, method string, params json.RawMessage) (any, *RequestError) {
+				mu.Lock()
+				order = append(order, name)
+				mu.Unlock()
+				return next(ctx, method, params)
+			}
+		}
+	}
+	auth := func(next MethodHandler) MethodHandler {
+		return func(ctx context.Context, method string, params json.RawMessage) (any, *RequestError) {
+			if method == "secret" {
+				return nil, NewInvalidRequest(map[string]any{"error": "unauthenticated"})
+			}
+			return next(context.WithValue(ctx, authKey{}, "user"), method, params)
+		}
+	}
+
+	_ = NewConnection(func(ctx context.Context, method string, params json.RawMessage) (any, *RequestError) {
+		return map[string]any{"user": ctx.Value(authKey{})}, nil
+	}, a2cW, c2aR, WithInboundMiddleware(trace("first"), trace("second")), WithInboundMiddleware(auth))
+	c := NewConnection(nil, c2aW, a2cR)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	got, err := SendRequest[map[string]any](c, ctx, "public", nil)
+	if err != nil {
+		t.Fatalf("public request: %v", err)
+	}
+	if got["user"] != "user" {
+		t.Fatalf("handler did not see middleware context: %v", got)
+	}
+
+	_, err = SendRequest[map[string]any](c, ctx, "secret", nil)
+	re, ok := err.(*RequestError)
+	if !ok || re.Code != -32600 {
+		t.Fatalf("expected -32600 error, got %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if fmt.Sprint(order) != "[first second first second]" {
+		t.Fatalf("unexpected middleware order: %v", order)
+	}
+}
+
+func TestOutboundMiddleware_RewritesParams(t *testing.T) {
+	c2aR, c2aW := io.Pipe()
+	a2cR, a2cW := io.Pipe()
+	defer func() {
+		_ = c2aR.Close()
+		_ = c2aW.Close()
+		_ = a2cR.Close()
+		_ = a2cW.Close()
+	}()
+
+	_ = NewConnection(func(ctx context.Context, method string, params json.RawMessage) (any, *RequestError) {
+		return map[string]any{"method": method, "params": params}, nil
+	}, a2cW, c2aR)
+
+	var mu sync.Mutex
+	var seen []string
+	observe := func(next OutboundFunc) OutboundFunc {
+		return func(ctx context.Context, method string, params json.RawMessage) error {
+			mu.Lock()
+			seen = append(seen, method)
+			mu.Unlock()
+			return next(ctx, method, params)
+		}
+	}
+	redact := func(next OutboundFunc) OutboundFunc {
+		return func(ctx context.Context, method string, params json.RawMessage) error {
+			return next(ctx, method, json.RawMessage(`{"redacted":true}`))
+		}
+	}
+	block := func(next OutboundFunc) OutboundFunc {
+		return func(ctx context.Context, method string, params json.RawMessage) error {
+			if method == "blocked" {
+				return NewInvalidRequest(nil)
+			}
+			return next(ctx, method, params)
+		}
+	}
+	c := NewConnection(nil, c2aW, a2cR, WithOutboundMiddleware(observe, block, redact))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	got, err := SendRequest[map[string]json.RawMessage](c, ctx, "echo", map[string]any{"password": "hunter2"})
+	if err != nil {
+		t.Fatalf("echo: %v", err)
+	}
+	if string(got["params"]) != `{"redacted":true}` {
+		t.Fatalf("params were not rewritten: %s", got["params"])
+	}
+
+	err = c.SendRequestNoResult(ctx, "blocked", nil)
+	if re, ok := err.(*RequestError); !ok || re.Code != -32600 {
+		t.Fatalf("expected middleware error, got %v", err)
+	}
+	waitForPendingRequests(t, c, 0, time.Second)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if fmt.Sprint(seen) != "[echo blocked]" {
+		t.Fatalf("unexpected observed methods: %v", seen)
+	}
+}
@@ -0,0 +1,50 @@
+package acp
+
+import "time"
+
+// Observer receives connection lifecycle events, e.g. for metrics. Hooks are
+// invoked synchronously from connection goroutines without internal locks held,
+// so implementations must be safe for concurrent use and should return quickly.
+type Observer interface {
+	// OnOutboundRequest is called when a request to the peer is issued.
+	OnOutboundRequest(method string, id string)
+	// OnInboundRequest is called before an inbound request is dispatched to the handler.
+	OnInboundRequest(method string)
+	// OnResponse is called when an outbound request completes. d measures the time
+	// from sending the request to receiving its response; err is nil on success.
+	OnResponse(method string, id string, d time.Duration, err *RequestError)
+	// OnNotification is called for each notification sent (inbound=false) or
+	// received (inbound=true).
+	OnNotification(method string, inbound bool)
+}
+
+// WithObserver installs an Observer for request, response, and notification events.
+func WithObserver(o Observer) ConnectionOption {
+	return func(c *Connection) {
+		c.observer = o
+	}
+}
+
+func (c *Connection) observeOutboundRequest(method string, id string) {
+	if c.observer != nil {
+		c.observer.OnOutboundRequest(method, id)
+	}
+}
+
+func (c *Connection) observeInboundRequest(method string) {
+	if c.observer != nil {
+		c.observer.OnInboundRequest(method)
+	}
+}
+
+func (c *Connection) observeResponse(method string, id string, d time.Duration, err *RequestError) {
+	if c.observer != nil {
+		c.observer.OnResponse(method, id, d, err)
+	}
+}
+
+func (c *Connection) observeNotification(method string, inbound bool) {
+	if c.observer != nil {
+		c.observer.OnNotification(method, inbound)
+	}
+}
@@ -0,0 +1,114 @@
+package acp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingObserver struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (o *recordingObserver) record(format string, args ...any) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.events = append(o.events, fmt.Sprintf(format, args...))
+}
+
+func (o *recordingObserver) OnOutboundRequest(method string, id string) {
+	o.record("outbound-request %s %s", method, id)
+}
+
+func (o *recordingObserver) OnInboundRequest(method string) {
+	o.record("inbound-request %s", method)
+}
+
+func (o *recordingObserver) OnResponse(method string, id string, d time.Duration, err *RequestError) {
+	if err != nil {
+		o.record("response %s %s code=%d", method, id, err.Code)
+		return
+	}
+	o.record("response %s %s ok", method, id)
+}
+
+func (o *recordingObserver) OnNotification(method string, inbound bool) {
+	o.record("notification %s inbound=%t", method, inbound)
+}
+
+func (o *recordingObserver) snapshot() []string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]string(nil), o.events...)
+}
+
+func TestObserver_ReceivesLifecycleEvents(t *testing.T) {
+	c2aR, c2aW := io.Pipe()
+	a2cR, a2cW := io.Pipe()
+	defer func() {
+		_ = c2aR.Close()
+		_ = c2aW.Close()
+		_ = a2cR.Close()
+		_ = a2cW.Close()
+	}()
+
+	notified := make(chan struct{})
+	serverObs := &recordingObserver{}
+	_ = NewConnection(func(ctx context.Context, method string, params json.RawMessage) (any, *RequestError) {
+		switch method {
+		case "ok":
+			return map[string]any{}, nil
+		case "note":
+			close(notified)
+			return nil, nil
+		default:
+			return nil, NewMethodNotFound(method)
+		}
+	}, a2cW, c2aR, WithObserver(serverObs))
+
+	clientObs := &recordingObserver{}
+	c := NewConnection(nil, c2aW, a2cR, WithObserver(clientObs))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if err := c.SendRequestNoResult(ctx, "ok", nil); err != nil {
+		t.Fatalf("ok request: %v", err)
+	}
+	if err := c.SendRequestNoResult(ctx, "missing", nil); err == nil {
+		t.Fatal("expected missing request to fail")
+	}
+	if err := c.SendNotification(ctx, "note", nil); err != nil {
+		t.Fatalf("notification: %v", err)
+	}
+	select {
+	case <-notified:
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for notification")
+	}
+
+	wantClient := []string{
+		"outbound-request ok 1",
+		"response ok 1 ok",
+		"outbound-request missing 2",
+		"response missing 2 code=-32601",
+		"notification note inbound=false",
+	}
+	if got := clientObs.snapshot(); fmt.Sprint(got) != fmt.Sprint(wantClient) {
+		t.Fatalf("client events = %v, want %v", got, wantClient)
+	}
+
+	wantServer := []string{
+		"inbound-request ok",
+		"inbound-request missing",
+		"notification note inbound=true",
+	}
+	if got := serverObs.snapshot(); fmt.Sprint(got) != fmt.Sprint(wantServer) {
+		t.Fatalf("server events = %v, want %v", got, wantServer)
+	}
+}
@@ -0,0 +1,53 @@
+package acp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// RequestPermissionWithTimeout asks the client for permission like
+// RequestPermission, but gives the user at most d to answer. If d elapses
+// first, the request is cancelled on the client and onTimeout is returned as
+// the outcome, e.g. NewRequestPermissionOutcomeSelected with the id of a
+// reject option. onTimeout must be a cancelled outcome or select one of
+// req.Options.
+//
+// If ctx ends first, for example because the prompt turn was cancelled, the
+// response carries the cancelled outcome, as the client would have sent, and
+// the error is the reason ctx ended. Callers that only look at the outcome can
+// therefore treat both paths alike.
+func (c *AgentSideConnection) RequestPermissionWithTimeout(ctx context.Context, req RequestPermissionRequest, d time.Duration, onTimeout RequestPermissionOutcome) (RequestPermissionResponse, error) {
+	if err := onTimeout.Validate(); err != nil {
+		return RequestPermissionResponse{}, fmt.Errorf("request permission: timeout outcome: %w", err)
+	}
+	if sel := onTimeout.Selected; sel != nil && !hasPermissionOption(req.Options, sel.OptionId) {
+		return RequestPermissionResponse{}, fmt.Errorf("request permission: timeout outcome selects unknown option %q", sel.OptionId)
+	}
+
+	timeoutCtx, cancel := context.WithTimeoutCause(ctx, d, errPermissionTimeout)
+	defer cancel()
+	resp, err := c.RequestPermission(timeoutCtx, req)
+	if err == nil {
+		return resp, nil
+	}
+	if ctx.Err() != nil {
+		return RequestPermissionResponse{Outcome: NewRequestPermissionOutcomeCancelled()}, context.Cause(ctx)
+	}
+	if errors.Is(context.Cause(timeoutCtx), errPermissionTimeout) {
+		return RequestPermissionResponse{Outcome: onTimeout}, nil
+	}
+	return resp, err
+}
+
+var errPermissionTimeout = errors.New("permission request timed out")
+
+func hasPermissionOption(options []PermissionOption, id PermissionOptionId) bool {
+	for _, o := range options {
+		if o.OptionId == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package acp
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testPermissionRequest() RequestPermissionRequest {
+	return RequestPermissionRequest{
+		SessionId: "s1",
+		ToolCall:  ToolCallUpdate{ToolCallId: "call-1"},
+		Options: []PermissionOption{
+			{OptionId: "allow", Name: "Allow", Kind: PermissionOptionKindAllowOnce},
+			{OptionId: "reject", Name: "Reject", Kind: PermissionOptionKindRejectOnce},
+		},
+	}
+}
+
+func TestRequestPermissionWithTimeout(t *testing.T) {
+	var answer atomic.Bool
+	var calls atomic.Int32
+	client := &clientFuncs{
+		RequestPermissionFunc: func(ctx context.Context, _ RequestPermissionRequest) (RequestPermissionResponse, error) {
+			calls.Add(1)
+			if answer.Load() {
+				return RequestPermissionResponse{Outcome: NewRequestPermissionOutcomeSelected("allow")}, nil
+			}
+			<-ctx.Done()
+			return RequestPermissionResponse{}, ctx.Err()
+		},
+	}
+	_, agentConn := newNotificationBarrierTestPair(t, client, agentFuncs{})
+	reject := NewRequestPermissionOutcomeSelected("reject")
+
+	t.Run("answered", func(t *testing.T) {
+		answer.Store(true)
+		defer answer.Store(false)
+		resp, err := agentConn.RequestPermissionWithTimeout(context.Background(), testPermissionRequest(), time.Second, reject)
+		if err != nil {
+			t.Fatalf("RequestPermissionWithTimeout: %v", err)
+		}
+		if resp.Outcome.Selected == nil || resp.Outcome.Selected.OptionId != "allow" {
+			t.Fatalf("outcome = %+v, want the client's answer", resp.Outcome)
+		}
+	})
+
+	t.Run("timeout selects fallback", func(t *testing.T) {
+		resp, err := agentConn.RequestPermissionWithTimeout(context.Background(), testPermissionRequest(), 50*time.Millisecond, reject)
+		if err != nil {
+			t.Fatalf("RequestPermissionWithTimeout: %v", err)
+		}
+		if resp.Outcome.Selected == nil || resp.Outcome.Selected.OptionId != "reject" {
+			t.Fatalf("outcome = %+v, want the fallback option", resp.Outcome)
+		}
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(50*time.Millisecond, cancel)
+		resp, err := agentConn.RequestPermissionWithTimeout(ctx, testPermissionRequest(), time.Minute, reject)
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("err = %v, want context.Canceled", err)
+		}
+		if resp.Outcome.Cancelled == nil {
+			t.Fatalf("outcome = %+v, want cancelled", resp.Outcome)
+		}
+	})
+
+	t.Run("unknown fallback option", func(t *testing.T) {
+		before := calls.Load()
+		_, err := agentConn.RequestPermissionWithTimeout(context.Background(), testPermissionRequest(), time.Second, NewRequestPermissionOutcomeSelected("maybe"))
+		if err == nil {
+			t.Fatal("expected an error for a fallback that selects an unknown option")
+		}
+		if calls.Load() != before {
+			t.Fatal("request was sent despite an invalid fallback")
+		}
+	})
+}
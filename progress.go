@@ -0,0 +1,80 @@
+package acp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ProgressNotificationMethod is the extension notification ReportProgress sends.
+const ProgressNotificationMethod = "_progress"
+
+// Progress reports how far a long-running operation, such as a tool call, has
+// got. JSON-RPC allows a single response per request, so progress travels in
+// separate notifications.
+type Progress struct {
+	// Token identifies the operation, e.g. by its tool call id.
+	Token string `json:"token"`
+	// Fraction is the share of the work done, from 0 to 1.
+	Fraction float64 `json:"fraction"`
+	// Message optionally describes the current step.
+	Message string `json:"message,omitempty"`
+}
+
+// ReportProgress tells the client how far the operation identified by token has
+// got. Clients built on this SDK receive it through
+// ClientSideConnection.OnProgress; others ignore it like any unknown extension
+// notification. fraction must be between 0 and 1.
+func (c *AgentSideConnection) ReportProgress(ctx context.Context, token string, fraction float64, message string) error {
+	if token == "" {
+		return errors.New("progress token is required")
+	}
+	if !(fraction >= 0 && fraction <= 1) {
+		return fmt.Errorf("progress fraction %v is outside [0, 1]", fraction)
+	}
+	return c.conn.SendNotification(ctx, ProgressNotificationMethod, Progress{Token: token, Fraction: fraction, Message: message})
+}
+
+// progressHandlers holds the callbacks registered with OnProgress, by token.
+type progressHandlers struct {
+	mu       sync.Mutex
+	handlers map[string]*func(Progress)
+}
+
+// OnProgress calls fn with each progress report the agent sends for token, in
+// the order they arrive, until the returned func is called. Registering a token
+// again replaces its callback. Reports for tokens with no callback are dropped.
+func (c *ClientSideConnection) OnProgress(token string, fn func(Progress)) (unregister func()) {
+	h := &c.progress
+	entry := &fn
+	h.mu.Lock()
+	if h.handlers == nil {
+		h.handlers = make(map[string]*func(Progress))
+	}
+	h.handlers[token] = entry
+	h.mu.Unlock()
+	return func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if h.handlers[token] == entry {
+			delete(h.handlers, token)
+		}
+	}
+}
+
+// routeProgress delivers a progress notification to the callback for its token.
+func (c *ClientSideConnection) routeProgress(params json.RawMessage) *RequestError {
+	var p Progress
+	if err := json.Unmarshal(params, &p); err != nil {
+		return NewInvalidParams(map[string]any{"error": err.Error()})
+	}
+	c.progress.mu.Lock()
+	fn := c.progress.handlers[p.Token]
+	c.progress.mu.Unlock()
+	if fn != nil {
+		(*fn)(p)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package acp
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestReportProgress(t *testing.T) {
+	var agentConn *AgentSideConnection
+	agent := agentFuncs{
+		PromptFunc: func(ctx context.Context, _ PromptRequest) (PromptResponse, error) {
+			for _, p := range []Progress{{"call-1", 0.5, "halfway"}, {"other", 0.1, ""}, {"call-1", 1, ""}} {
+				if err := agentConn.ReportProgress(ctx, p.Token, p.Fraction, p.Message); err != nil {
+					return PromptResponse{}, err
+				}
+			}
+			return PromptResponse{StopReason: StopReasonEndTurn}, nil
+		},
+	}
+	clientConn, created := newNotificationBarrierTestPair(t, &clientFuncs{}, agent)
+	agentConn = created
+
+	var got []Progress
+	unregister := clientConn.OnProgress("call-1", func(p Progress) { got = append(got, p) })
+	prompt := func() {
+		t.Helper()
+		if _, err := clientConn.Prompt(context.Background(), PromptRequest{SessionId: "s", Prompt: []ContentBlock{TextBlock("hi")}}); err != nil {
+			t.Fatalf("Prompt: %v", err)
+		}
+	}
+	// Prompt returns once the notifications sent before its response are handled.
+	prompt()
+	want := []Progress{{"call-1", 0.5, "halfway"}, {"call-1", 1, ""}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("progress = %+v, want %+v", got, want)
+	}
+
+	unregister()
+	prompt()
+	if len(got) != len(want) {
+		t.Fatalf("callback ran after unregister: %+v", got)
+	}
+
+	for _, fraction := range []float64{-0.1, 1.5, math.NaN()} {
+		if err := agentConn.ReportProgress(context.Background(), "call-1", fraction, ""); err == nil {
+			t.Errorf("ReportProgress accepted fraction %v", fraction)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package acp
+
+import "strings"
+
+// TextContent returns the text of every text block in the prompt, joined by
+// newlines. Other content blocks are skipped.
+func (r PromptRequest) TextContent() string {
+	var parts []string
+	for _, b := range r.Prompt {
+		if b.Text != nil {
+			parts = append(parts, b.Text.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// ResourceLinks returns the resource links included in the prompt, in order.
+func (r PromptRequest) ResourceLinks() []ResourceLink {
+	var links []ResourceLink
+	for _, b := range r.Prompt {
+		if l := b.ResourceLink; l != nil {
+			links = append(links, ResourceLink{
+				Meta:        l.Meta,
+				Annotations: l.Annotations,
+				Description: l.Description,
+				MimeType:    l.MimeType,
+				Name:        l.Name,
+				Size:        l.Size,
+				Title:       l.Title,
+				Uri:         l.Uri,
+			})
+		}
+	}
+	return links
+}
+
+// ContentByKind returns the prompt's content blocks of the given kind, in order.
+func (r PromptRequest) ContentByKind(kind ContentBlockKind) []ContentBlock {
+	var blocks []ContentBlock
+	for _, b := range r.Prompt {
+		if b.Kind() == kind {
+			blocks = append(blocks, b)
+		}
+	}
+	return blocks
+}
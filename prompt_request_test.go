@@ -0,0 +1,30 @@
+package acp
+
+import "testing"
+
+func TestPromptRequestAccessors(t *testing.T) {
+	req := PromptRequest{
+		SessionId: "s1",
+		Prompt: []ContentBlock{
+			TextBlock("Summarize"),
+			ResourceLinkBlock("notes.md", "file:///notes.md"),
+			ImageBlock("aGk=", "image/png"),
+			TextBlock("briefly."),
+			ResourceLinkBlock("todo.md", "file:///todo.md"),
+		},
+	}
+
+	if got := req.TextContent(); got != "Summarize\nbriefly." {
+		t.Fatalf("TextContent() = %q", got)
+	}
+	links := req.ResourceLinks()
+	if len(links) != 2 || links[0].Uri != "file:///notes.md" || links[1].Name != "todo.md" {
+		t.Fatalf("ResourceLinks() = %+v", links)
+	}
+	if got := req.ContentByKind(ContentBlockKindImage); len(got) != 1 || got[0].Image == nil {
+		t.Fatalf("ContentByKind(image) = %+v", got)
+	}
+	if got := req.ContentByKind(ContentBlockKindAudio); len(got) != 0 {
+		t.Fatalf("ContentByKind(audio) = %+v", got)
+	}
+}
@@ -0,0 +1,138 @@
+package acp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// PromptStream iterates over the session updates an agent streams while handling
+// a single prompt turn, then yields the turn's final result. Create one with
+// ClientSideConnection.PromptStream.
+type PromptStream struct {
+	mu      sync.Mutex
+	pending []SessionUpdate
+	closed  bool
+	// notify wakes Next when updates arrive or the stream closes.
+	notify chan struct{}
+	done   chan struct{}
+
+	resp PromptResponse
+	err  error
+}
+
+func newPromptStream() *PromptStream {
+	return &PromptStream{
+		notify: make(chan struct{}, 1),
+		done:   make(chan struct{}),
+	}
+}
+
+// Next blocks until the agent sends the next session update for the turn and
+// returns it. It returns false once the turn has ended and every update has been
+// consumed; call Result to learn how the turn ended.
+func (s *PromptStream) Next() (SessionUpdate, bool) {
+	for {
+		s.mu.Lock()
+		if len(s.pending) > 0 {
+			u := s.pending[0]
+			s.pending[0] = SessionUpdate{}
+			s.pending = s.pending[1:]
+			s.mu.Unlock()
+			return u, true
+		}
+		closed := s.closed
+		s.mu.Unlock()
+		if closed {
+			return SessionUpdate{}, false
+		}
+		<-s.notify
+	}
+}
+
+// Result blocks until the turn ends and returns the agent's response to the prompt.
+func (s *PromptStream) Result() (PromptResponse, error) {
+	<-s.done
+	return s.resp, s.err
+}
+
+// push buffers an update without blocking, so that a slow consumer cannot stall
+// the connection's notification processing.
+func (s *PromptStream) push(u SessionUpdate) {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.pending = append(s.pending, u)
+	s.mu.Unlock()
+	s.wake()
+}
+
+func (s *PromptStream) finish(resp PromptResponse, err error) {
+	s.mu.Lock()
+	s.closed = true
+	s.resp, s.err = resp, err
+	s.mu.Unlock()
+	close(s.done)
+	s.wake()
+}
+
+func (s *PromptStream) wake() {
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+// PromptStream sends a prompt and returns a stream of the session updates the
+// agent sends for that session until the turn ends. Updates are still delivered
+// to the Client's SessionUpdate method as well. Only one stream may be active per
+// session at a time. Cancelling ctx cancels the prompt, as with Prompt.
+func (c *ClientSideConnection) PromptStream(ctx context.Context, params PromptRequest) (*PromptStream, error) {
+	s := newPromptStream()
+	c.streamsMu.Lock()
+	if _, ok := c.streams[params.SessionId]; ok {
+		c.streamsMu.Unlock()
+		return nil, fmt.Errorf("prompt stream already active for session %q", params.SessionId)
+	}
+	if c.streams == nil {
+		c.streams = make(map[SessionId]*PromptStream)
+	}
+	c.streams[params.SessionId] = s
+	c.streamsMu.Unlock()
+
+	go func() {
+		resp, err := c.Prompt(ctx, params)
+		// Prompt returns only after the session updates preceding its response have
+		// been handled, so every update for the turn has been pushed by now.
+		c.streamsMu.Lock()
+		delete(c.streams, params.SessionId)
+		c.streamsMu.Unlock()
+		s.finish(resp, err)
+	}()
+	return s, nil
+}
+
+// routeSessionUpdate forwards a session/update notification to the active prompt
+// stream for its session, if any.
+func (c *ClientSideConnection) routeSessionUpdate(params json.RawMessage) {
+	c.streamsMu.Lock()
+	active := len(c.streams) > 0
+	c.streamsMu.Unlock()
+	if !active {
+		return
+	}
+
+	var n SessionNotification
+	if err := json.Unmarshal(params, &n); err != nil {
+		return
+	}
+	c.streamsMu.Lock()
+	s := c.streams[n.SessionId]
+	c.streamsMu.Unlock()
+	if s != nil {
+		s.push(n.Update)
+	}
+}
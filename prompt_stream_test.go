@@ -0,0 +1,85 @@
+package acp
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestClientSideConnectionPromptStream(t *testing.T) {
+	c2aR, c2aW := io.Pipe()
+	a2cR, a2cW := io.Pipe()
+	defer func() {
+		_ = c2aR.Close()
+		_ = c2aW.Close()
+		_ = a2cR.Close()
+		_ = a2cW.Close()
+	}()
+
+	received := make(chan SessionNotification, 10)
+	cs := NewClientSideConnection(&clientFuncs{
+		SessionUpdateFunc: func(_ context.Context, n SessionNotification) error {
+			received <- n
+			return nil
+		},
+	}, c2aW, a2cR)
+
+	var agentSide *AgentSideConnection
+	agentSide = NewAgentSideConnection(agentFuncs{
+		PromptFunc: func(ctx context.Context, p PromptRequest) (PromptResponse, error) {
+			for _, text := range []string{"one", "two", "three"} {
+				if err := agentSide.SessionUpdate(ctx, SessionNotification{SessionId: p.SessionId, Update: UpdateAgentMessageText(text)}); err != nil {
+					return PromptResponse{}, err
+				}
+			}
+			// Updates for other sessions must not leak into the stream.
+			if err := agentSide.SessionUpdate(ctx, SessionNotification{SessionId: "other", Update: UpdateAgentMessageText("other")}); err != nil {
+				return PromptResponse{}, err
+			}
+			return PromptResponse{StopReason: StopReasonEndTurn}, nil
+		},
+	}, a2cW, c2aR)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	stream, err := cs.PromptStream(ctx, PromptRequest{SessionId: "s1", Prompt: []ContentBlock{TextBlock("hi")}})
+	if err != nil {
+		t.Fatalf("PromptStream: %v", err)
+	}
+	if _, err := cs.PromptStream(ctx, PromptRequest{SessionId: "s1"}); err == nil {
+		t.Fatal("expected error for a second stream on the same session")
+	}
+
+	var got []string
+	for {
+		u, ok := stream.Next()
+		if !ok {
+			break
+		}
+		got = append(got, u.AgentMessageChunk.Content.Text.Text)
+	}
+	if len(got) != 3 || got[0] != "one" || got[1] != "two" || got[2] != "three" {
+		t.Fatalf("unexpected updates: %v", got)
+	}
+	resp, err := stream.Result()
+	if err != nil {
+		t.Fatalf("Result: %v", err)
+	}
+	if resp.StopReason != StopReasonEndTurn {
+		t.Fatalf("unexpected stop reason: %q", resp.StopReason)
+	}
+	if len(received) != 4 {
+		t.Fatalf("expected Client.SessionUpdate to see all 4 updates, got %d", len(received))
+	}
+
+	// The session is free for another stream once the turn ends.
+	stream, err = cs.PromptStream(ctx, PromptRequest{SessionId: "s1", Prompt: []ContentBlock{TextBlock("again")}})
+	if err != nil {
+		t.Fatalf("second PromptStream: %v", err)
+	}
+	if _, err := stream.Result(); err != nil {
+		t.Fatalf("second Result: %v", err)
+	}
+}
@@ -0,0 +1,46 @@
+package acp
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+var (
+	errMessageMissingIDAndMethod = errors.New("message has neither id nor method")
+	errUnknownResponseID         = errors.New("response id does not match any request")
+	errDuplicateResponse         = errors.New("duplicate response to request")
+)
+
+// ProtocolError describes an inbound message that could not be processed, such as
+// malformed JSON or a message exceeding the size limit. The connection skips the
+// message and keeps reading.
+type ProtocolError struct {
+	// Raw holds the offending bytes. For oversized messages it holds only a prefix.
+	Raw []byte
+	// Err describes what was wrong with the message.
+	Err error
+}
+
+func (e ProtocolError) Error() string { return "acp protocol error: " + e.Err.Error() }
+
+func (e ProtocolError) Unwrap() error { return e.Err }
+
+// WithProtocolErrorHandler installs a callback for inbound messages that could not
+// be processed, e.g. to record metrics or Close a misbehaving peer. The callback
+// runs on the connection's read loop and replaces the default error log.
+func WithProtocolErrorHandler(h func(ProtocolError)) ConnectionOption {
+	return func(c *Connection) {
+		c.protocolErrorHandler = h
+	}
+}
+
+// reportProtocolError hands a malformed inbound message to the protocol error
+// handler, or logs msg and args at level if none is installed.
+func (c *Connection) reportProtocolError(level slog.Level, raw []byte, err error, msg string, args ...any) {
+	if c.protocolErrorHandler != nil {
+		c.protocolErrorHandler(ProtocolError{Raw: append([]byte(nil), raw...), Err: err})
+		return
+	}
+	c.loggerOrDefault().Log(context.Background(), level, msg, args...)
+}
@@ -0,0 +1,126 @@
+package acp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestProtocolErrorHandler_ReceivesMalformedMessages(t *testing.T) {
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	defer func() {
+		_ = inR.Close()
+		_ = inW.Close()
+		_ = outR.Close()
+		_ = outW.Close()
+	}()
+
+	var mu sync.Mutex
+	var got []ProtocolError
+	_ = NewConnection(func(ctx context.Context, method string, params json.RawMessage) (any, *RequestError) {
+		return map[string]any{}, nil
+	}, outW, inR, WithMaxMessageBytes(128), WithProtocolErrorHandler(func(pe ProtocolError) {
+		mu.Lock()
+		got = append(got, pe)
+		mu.Unlock()
+	}))
+
+	lines := make(chan string, 1)
+	go func() {
+		scanner := bufio.NewScanner(outR)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+
+	oversized := `{"jsonrpc":"2.0","method":"big","params":"` + strings.Repeat("x", 256) + `"}`
+	for _, msg := range []string{"not json", oversized, `{"jsonrpc":"2.0","id":1,"method":"ok"}`} {
+		if _, err := io.WriteString(inW, msg+"\n"); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	select {
+	case line := <-lines:
+		if !strings.Contains(line, `"id":1`) {
+			t.Fatalf("unexpected response: %s", line)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("connection stopped processing after protocol errors")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) != 2 {
+		t.Fatalf("expected 2 protocol errors, got %d: %v", len(got), got)
+	}
+	if string(got[0].Raw) != "not json" {
+		t.Fatalf("unexpected raw bytes for parse error: %q", got[0].Raw)
+	}
+	var syntaxErr *json.SyntaxError
+	if !errors.As(got[0], &syntaxErr) {
+		t.Fatalf("expected wrapped json syntax error, got %v", got[0].Err)
+	}
+	var tooLarge *messageTooLargeError
+	if !errors.As(got[1], &tooLarge) || !strings.HasPrefix(string(got[1].Raw), `{"jsonrpc":"2.0","method":"big"`) {
+		t.Fatalf("unexpected oversized protocol error: %v raw=%q", got[1].Err, got[1].Raw)
+	}
+}
+
+func TestConnection_LogsStrayResponses(t *testing.T) {
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	defer func() {
+		_ = inR.Close()
+		_ = inW.Close()
+		_ = outR.Close()
+		_ = outW.Close()
+	}()
+
+	c := NewConnection(nil, outW, inR)
+	var logs syncBuffer
+	c.SetLogger(slog.New(slog.NewTextHandler(&logs, nil)))
+
+	// Answer the first outbound request twice.
+	go func() {
+		scanner := bufio.NewScanner(outR)
+		for scanner.Scan() {
+			var req anyMessage
+			if err := json.Unmarshal(scanner.Bytes(), &req); err != nil || req.ID == nil {
+				continue
+			}
+			res := `{"jsonrpc":"2.0","id":` + string(*req.ID) + `,"result":{}}` + "\n"
+			_, _ = io.WriteString(inW, res+res)
+		}
+	}()
+
+	if _, err := io.WriteString(inW, `{"jsonrpc":"2.0","id":999,"result":{}}`+"\n"); err != nil {
+		t.Fatalf("write stray response: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := c.SendRequestNoResult(ctx, "req", nil); err != nil {
+		t.Fatalf("SendRequestNoResult: %v", err)
+	}
+	// A second request proves the connection survived and orders after the duplicate.
+	if err := c.SendRequestNoResult(ctx, "req", nil); err != nil {
+		t.Fatalf("SendRequestNoResult: %v", err)
+	}
+
+	out := logs.String()
+	if !strings.Contains(out, "received response with unknown id") || !strings.Contains(out, "id=999") {
+		t.Fatalf("stray response not logged: %s", out)
+	}
+	if !strings.Contains(out, "received duplicate response") || !strings.Contains(out, "id=1") {
+		t.Fatalf("duplicate response not logged: %s", out)
+	}
+}
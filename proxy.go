@@ -0,0 +1,186 @@
+package acp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+)
+
+// Sides of an ACP connection, as used by MethodInfo.Side and ProxyCall.From.
+const (
+	SideAgent  = "agent"
+	SideClient = "client"
+)
+
+// ProxyCall describes a message passing through a Proxy.
+type ProxyCall struct {
+	// Method is the wire method name.
+	Method string
+	// Info is the method's MethodRouting entry. It is the zero value for
+	// methods the manifest does not know, such as extension methods.
+	Info MethodInfo
+	// From is the side that sent the message: SideClient or SideAgent.
+	From string
+	// Notification reports whether the message is a notification, in which case
+	// any result is discarded.
+	Notification bool
+	// Params is the raw params of the message.
+	Params json.RawMessage
+}
+
+// ProxyForward sends a message on to the other side with the given params and
+// returns the peer's raw result. For notifications the result is nil.
+type ProxyForward func(ctx context.Context, params json.RawMessage) (json.RawMessage, *RequestError)
+
+// ProxyHook intercepts a message passing through a Proxy. A hook typically
+// inspects or records call, calls next (optionally with rewritten params) and
+// returns its result. Returning an error without calling next rejects the
+// message without forwarding it.
+type ProxyHook func(ctx context.Context, call ProxyCall, next ProxyForward) (json.RawMessage, *RequestError)
+
+// ProxyOption configures a Proxy.
+type ProxyOption func(p *Proxy)
+
+// WithProxyHook runs hook for messages with the given wire method, or for every
+// message if method is "". Hooks run in the order they were added, each
+// wrapping the next, with method-specific hooks inside the catch-all ones.
+func WithProxyHook(method string, hook ProxyHook) ProxyOption {
+	return func(p *Proxy) {
+		if method == "" {
+			p.allHooks = append(p.allHooks, hook)
+			return
+		}
+		if p.hooks == nil {
+			p.hooks = make(map[string][]ProxyHook)
+		}
+		p.hooks[method] = append(p.hooks[method], hook)
+	}
+}
+
+// WithProxyClientOptions applies opts to the connection facing the client.
+func WithProxyClientOptions(opts ...ConnectionOption) ProxyOption {
+	return func(p *Proxy) {
+		p.clientOpts = append(p.clientOpts, opts...)
+	}
+}
+
+// WithProxyAgentOptions applies opts to the connection facing the agent.
+func WithProxyAgentOptions(opts ...ConnectionOption) ProxyOption {
+	return func(p *Proxy) {
+		p.agentOpts = append(p.agentOpts, opts...)
+	}
+}
+
+// Proxy sits between a client and an agent and forwards every request and
+// notification from one to the other, consulting MethodRouting to tell which
+// side handles each method. Cancellation of a forwarded request propagates to
+// the other side, and notifications the agent sends before answering a request
+// are delivered to the client before the answer.
+//
+// Messages for a known method that arrive from the side that should handle it
+// are rejected with a method-not-found error rather than echoed back.
+type Proxy struct {
+	client *Connection
+	agent  *Connection
+
+	hooks      map[string][]ProxyHook
+	allHooks   []ProxyHook
+	clientOpts []ConnectionOption
+	agentOpts  []ConnectionOption
+
+	done chan struct{}
+}
+
+// NewProxy connects to a client through clientInput/clientOutput and to an
+// agent through agentInput/agentOutput, and starts forwarding between them.
+// The proxy shuts down when either side disconnects.
+func NewProxy(clientInput io.Writer, clientOutput io.Reader, agentInput io.Writer, agentOutput io.Reader, opts ...ProxyOption) *Proxy {
+	p := &Proxy{done: make(chan struct{})}
+	for _, opt := range opts {
+		opt(p)
+	}
+	p.client = NewConnection(p.handler(SideClient), clientInput, clientOutput, p.clientOpts...)
+	p.agent = NewConnection(p.handler(SideAgent), agentInput, agentOutput, p.agentOpts...)
+	go func() {
+		select {
+		case <-p.client.Done():
+		case <-p.agent.Done():
+		}
+		p.client.Close()
+		p.agent.Close()
+		close(p.done)
+	}()
+	return p
+}
+
+// ClientConnection returns the connection facing the client.
+func (p *Proxy) ClientConnection() *Connection { return p.client }
+
+// AgentConnection returns the connection facing the agent.
+func (p *Proxy) AgentConnection() *Connection { return p.agent }
+
+// Done returns a channel that is closed once both connections have shut down.
+func (p *Proxy) Done() <-chan struct{} { return p.done }
+
+// Close shuts down both connections.
+func (p *Proxy) Close() error {
+	clientErr := p.client.Close()
+	agentErr := p.agent.Close()
+	if clientErr != nil {
+		return clientErr
+	}
+	return agentErr
+}
+
+// handler returns the MethodHandler for the connection facing from, which
+// forwards to the connection facing the other side.
+func (p *Proxy) handler(from string) MethodHandler {
+	return func(ctx context.Context, method string, params json.RawMessage) (any, *RequestError) {
+		info, known := MethodRouting[method]
+		// A side only sends methods the other side handles.
+		if known && info.Side == from {
+			return nil, NewMethodNotFound(method)
+		}
+		to := p.agent
+		if from == SideAgent {
+			to = p.client
+		}
+		call := ProxyCall{
+			Method:       method,
+			Info:         info,
+			From:         from,
+			Notification: isInboundNotification(ctx),
+			Params:       params,
+		}
+		var next ProxyForward = func(ctx context.Context, params json.RawMessage) (json.RawMessage, *RequestError) {
+			if call.Notification {
+				return nil, toReqErr(to.SendNotification(ctx, method, forwardedParams(params)))
+			}
+			raw, err := SendRequest[json.RawMessage](to, ctx, method, forwardedParams(params))
+			return raw, toReqErr(err)
+		}
+		hooks := append(append([]ProxyHook(nil), p.allHooks...), p.hooks[method]...)
+		for i := len(hooks) - 1; i >= 0; i-- {
+			hook, inner := hooks[i], next
+			next = func(ctx context.Context, params json.RawMessage) (json.RawMessage, *RequestError) {
+				c := call
+				c.Params = params
+				return hook(ctx, c, inner)
+			}
+		}
+		raw, err := next(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		return raw, nil
+	}
+}
+
+// forwardedParams returns params for sending on unchanged. Absent params must stay
+// absent: a nil json.RawMessage in an interface would be encoded as null.
+func forwardedParams(params json.RawMessage) any {
+	if params == nil {
+		return nil
+	}
+	return params
+}
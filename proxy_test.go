@@ -0,0 +1,154 @@
+package acp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestProxy_ForwardsBothDirections(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	c2pR, c2pW := io.Pipe()
+	p2cR, p2cW := io.Pipe()
+	p2aR, p2aW := io.Pipe()
+	a2pR, a2pW := io.Pipe()
+
+	var mu sync.Mutex
+	var seen []string
+	var updates int
+	record := func(ctx context.Context, call ProxyCall, next ProxyForward) (json.RawMessage, *RequestError) {
+		mu.Lock()
+		seen = append(seen, call.From+" "+call.Method)
+		mu.Unlock()
+		return next(ctx, call.Params)
+	}
+	deny := func(ctx context.Context, call ProxyCall, next ProxyForward) (json.RawMessage, *RequestError) {
+		return nil, NewAuthRequired(map[string]any{"reason": "blocked by proxy"})
+	}
+	proxy := NewProxy(p2cW, c2pR, p2aW, a2pR, WithProxyHook("", record), WithProxyHook(AgentMethodAuthenticate, deny))
+	defer proxy.Close()
+
+	client := NewClientSideConnection(&clientFuncs{
+		ReadTextFileFunc: func(context.Context, ReadTextFileRequest) (ReadTextFileResponse, error) {
+			return ReadTextFileResponse{Content: "hello"}, nil
+		},
+		SessionUpdateFunc: func(context.Context, SessionNotification) error {
+			mu.Lock()
+			updates++
+			mu.Unlock()
+			return nil
+		},
+	}, c2pW, p2cR)
+
+	var agent *AgentSideConnection
+	agent = NewAgentSideConnection(agentFuncs{
+		PromptFunc: func(ctx context.Context, p PromptRequest) (PromptResponse, error) {
+			resp, err := agent.ReadTextFile(ctx, ReadTextFileRequest{SessionId: p.SessionId, Path: "/a.txt"})
+			if err != nil {
+				return PromptResponse{}, err
+			}
+			for i := 0; i < 3; i++ {
+				if err := agent.SessionUpdate(ctx, SessionNotification{SessionId: p.SessionId, Update: UpdateAgentMessageText(resp.Content)}); err != nil {
+					return PromptResponse{}, err
+				}
+			}
+			return PromptResponse{StopReason: StopReasonEndTurn}, nil
+		},
+	}, a2pW, p2aR)
+
+	resp, err := client.Prompt(ctx, PromptRequest{SessionId: "s", Prompt: []ContentBlock{TextBlock("hi")}})
+	if err != nil {
+		t.Fatalf("Prompt: %v", err)
+	}
+	if resp.StopReason != StopReasonEndTurn {
+		t.Fatalf("stop reason %q", resp.StopReason)
+	}
+	mu.Lock()
+	if updates != 3 {
+		t.Errorf("client saw %d session updates before the prompt response, want 3", updates)
+	}
+	want := []string{"client session/prompt", "agent fs/read_text_file", "agent session/update", "agent session/update", "agent session/update"}
+	if len(seen) != len(want) {
+		t.Errorf("hooks saw %v, want %v", seen, want)
+	} else {
+		for i := range want {
+			if seen[i] != want[i] {
+				t.Errorf("hooks saw %v, want %v", seen, want)
+				break
+			}
+		}
+	}
+	mu.Unlock()
+
+	_, err = client.Authenticate(ctx, AuthenticateRequest{MethodId: "x"})
+	var reqErr *RequestError
+	if !errors.As(err, &reqErr) || reqErr.Code != CodeAuthRequired {
+		t.Fatalf("expected auth-required from proxy hook, got %v", err)
+	}
+
+	// A client must not send methods the client itself handles.
+	if _, err := SendRequest[json.RawMessage](client.conn, ctx, ClientMethodFsReadTextFile, ReadTextFileRequest{SessionId: "s", Path: "/a"}); err == nil {
+		t.Fatalf("expected misrouted request to be rejected")
+	}
+
+	// The proxy shuts down when either side goes away.
+	a2pW.Close()
+	<-proxy.Done()
+	if proxy.ClientConnection().Err() == nil {
+		t.Fatalf("client-facing connection still open after the agent disconnected")
+	}
+}
+
+func TestProxy_ForwardsAbsentParamsUnchanged(t *testing.T) {
+	t.Parallel()
+	c2pR, c2pW := io.Pipe()
+	p2cR, p2cW := io.Pipe()
+	p2aR, p2aW := io.Pipe()
+	a2pR, a2pW := io.Pipe()
+	proxy := NewProxy(p2cW, c2pR, p2aW, a2pR)
+	defer proxy.Close()
+	client := NewConnection(nil, c2pW, p2cR)
+
+	// The agent answers every request with an empty result and records the raw
+	// messages it receives.
+	received := make(chan map[string]json.RawMessage, 2)
+	go func() {
+		scanner := bufio.NewScanner(p2aR)
+		for scanner.Scan() {
+			var msg map[string]json.RawMessage
+			if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+				continue
+			}
+			received <- msg
+			if id, ok := msg["id"]; ok {
+				_, _ = fmt.Fprintf(a2pW, `{"jsonrpc":"2.0","id":%s,"result":{}}`+"\n", id)
+			}
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := client.SendNotification(ctx, "_example/note", nil); err != nil {
+		t.Fatalf("SendNotification: %v", err)
+	}
+	if _, err := SendRequest[json.RawMessage](client, ctx, "_example/ping", nil); err != nil {
+		t.Fatalf("SendRequest: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case msg := <-received:
+			if params, ok := msg["params"]; ok {
+				t.Fatalf("proxy forwarded %s with params %s, want none", msg["method"], params)
+			}
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for forwarded messages")
+		}
+	}
+}
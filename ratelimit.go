@@ -0,0 +1,65 @@
+package acp
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// WithOutboundRateLimit limits how fast this side of the connection issues
+// requests to the peer, e.g. to avoid overwhelming a client with fs/read_text_file
+// calls. Requests are admitted at perSecond on average, with bursts of up to burst
+// requests. A request waiting for admission fails if its context is done first.
+// Notifications, $/cancel_request, and responses are never delayed. The limit
+// applies only to this side's sends; it does not throttle the peer.
+func WithOutboundRateLimit(perSecond float64, burst int) ConnectionOption {
+	return func(c *Connection) {
+		if perSecond <= 0 || burst <= 0 {
+			return
+		}
+		c.outboundLimiter = &tokenBucket{rate: perSecond, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+	}
+}
+
+// tokenBucket is a minimal token bucket rate limiter.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64 // tokens per second
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// wait takes a token, blocking until one is available or ctx is done.
+func (b *tokenBucket) wait(ctx context.Context) error {
+	b.mu.Lock()
+	now := time.Now()
+	b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	// Reserve the token up front so that concurrent waiters queue behind each other.
+	b.tokens--
+	delay := time.Duration(-b.tokens / b.rate * float64(time.Second))
+	b.mu.Unlock()
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		b.mu.Lock()
+		b.tokens = min(b.burst, b.tokens+1)
+		b.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+func (c *Connection) waitOutboundRate(ctx context.Context) error {
+	if c.outboundLimiter == nil {
+		return nil
+	}
+	return c.outboundLimiter.wait(ctx)
+}
@@ -0,0 +1,66 @@
+package acp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestOutboundRateLimit_DelaysRequestsButNotNotifications(t *testing.T) {
+	c2aR, c2aW := io.Pipe()
+	a2cR, a2cW := io.Pipe()
+	defer func() {
+		_ = c2aR.Close()
+		_ = c2aW.Close()
+		_ = a2cR.Close()
+		_ = a2cW.Close()
+	}()
+
+	var requests atomic.Int64
+	_ = NewConnection(func(ctx context.Context, method string, params json.RawMessage) (any, *RequestError) {
+		if method == "req" {
+			requests.Add(1)
+		}
+		return map[string]any{}, nil
+	}, a2cW, c2aR)
+	c := NewConnection(nil, c2aW, a2cR, WithOutboundRateLimit(20, 1))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := c.SendRequestNoResult(ctx, "req", nil); err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+	}
+	// The first request uses the burst; the next two wait ~50ms each.
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Fatalf("requests were not rate limited: took %v", elapsed)
+	}
+
+	start = time.Now()
+	for i := 0; i < 10; i++ {
+		if err := c.SendNotification(ctx, "note", nil); err != nil {
+			t.Fatalf("notification %d: %v", i, err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 250*time.Millisecond {
+		t.Fatalf("notifications were rate limited: took %v", elapsed)
+	}
+
+	// Drain the bucket, then make sure a waiting request gives up with its context.
+	waitCtx, waitCancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer waitCancel()
+	_ = c.SendRequestNoResult(ctx, "req", nil)
+	before := requests.Load()
+	if err := c.SendRequestNoResult(waitCtx, "req", nil); err == nil {
+		t.Fatal("expected rate-limited request to fail when its context expired")
+	}
+	if got := requests.Load(); got != before {
+		t.Fatalf("cancelled request was still sent: %d requests, want %d", got, before)
+	}
+}
@@ -0,0 +1,32 @@
+package acp
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+)
+
+// ReadTextFileRange reads lineCount lines of path starting at startLine, which
+// is 1-based as in the protocol: the first line of the file is line 1. To read
+// a file in chunks of n lines, call it with startLine 1, n+1, 2n+1, and so on
+// until the returned content has fewer than n lines.
+//
+// The range is checked before anything is sent: startLine and lineCount must
+// be at least 1 and path must be absolute.
+func (c *AgentSideConnection) ReadTextFileRange(ctx context.Context, path string, sessionId SessionId, startLine, lineCount int) (ReadTextFileResponse, error) {
+	if !filepath.IsAbs(path) {
+		return ReadTextFileResponse{}, fmt.Errorf("read text file range: path %q is not absolute", path)
+	}
+	if startLine < 1 {
+		return ReadTextFileResponse{}, fmt.Errorf("read text file range: start line must be >= 1 (lines are 1-based), got %d", startLine)
+	}
+	if lineCount < 1 {
+		return ReadTextFileResponse{}, fmt.Errorf("read text file range: line count must be >= 1, got %d", lineCount)
+	}
+	return c.ReadTextFile(ctx, ReadTextFileRequest{
+		SessionId: sessionId,
+		Path:      path,
+		Line:      Ptr(startLine),
+		Limit:     Ptr(lineCount),
+	})
+}
@@ -0,0 +1,57 @@
+package acp
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadTextFileRange(t *testing.T) {
+	var got []ReadTextFileRequest
+	client := &clientFuncs{
+		ReadTextFileFunc: func(_ context.Context, req ReadTextFileRequest) (ReadTextFileResponse, error) {
+			got = append(got, req)
+			return ReadTextFileResponse{Content: "line"}, nil
+		},
+	}
+	_, agentConn := newNotificationBarrierTestPair(t, client, agentFuncs{})
+	path, err := filepath.Abs("file.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := agentConn.ReadTextFileRange(context.Background(), path, "s1", 11, 10)
+	if err != nil {
+		t.Fatalf("ReadTextFileRange: %v", err)
+	}
+	if resp.Content != "line" {
+		t.Fatalf("content = %q", resp.Content)
+	}
+	if len(got) != 1 {
+		t.Fatalf("client received %d requests, want 1", len(got))
+	}
+	req := got[0]
+	if req.Path != path || req.SessionId != "s1" || req.Line == nil || *req.Line != 11 || req.Limit == nil || *req.Limit != 10 {
+		t.Fatalf("unexpected request %+v", req)
+	}
+
+	for _, tt := range []struct {
+		name             string
+		path             string
+		start, lineCount int
+		wantErr          string
+	}{
+		{"zero start line", path, 0, 10, "1-based"},
+		{"zero line count", path, 1, 0, "line count must be >= 1"},
+		{"relative path", "file.txt", 1, 10, "not absolute"},
+	} {
+		_, err := agentConn.ReadTextFileRange(context.Background(), tt.path, "s1", tt.start, tt.lineCount)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Fatalf("%s: got %v, want error containing %q", tt.name, err, tt.wantErr)
+		}
+	}
+	if len(got) != 1 {
+		t.Fatalf("invalid ranges were sent to the client: %+v", got[1:])
+	}
+}
@@ -0,0 +1,230 @@
+package acp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// TranscriptEntry is one message of a recorded transcript. A transcript is
+// stored as JSON Lines, one entry per line, in the order messages crossed the
+// wire.
+type TranscriptEntry struct {
+	// Direction is relative to the recorded connection: inbound messages came
+	// from its peer, outbound messages were sent by it.
+	Direction Direction `json:"direction"`
+	// Message is the raw JSON-RPC message, without framing.
+	Message json.RawMessage `json:"message,omitempty"`
+	// Malformed holds the bytes of an inbound message that was not valid JSON,
+	// in which case Message is empty.
+	Malformed string `json:"malformed,omitempty"`
+}
+
+// Recorder writes every message exchanged by a connection to a JSON Lines
+// transcript that a Replayer can later play back. Install it with WithRecorder.
+type Recorder struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+	err error
+}
+
+// NewRecorder returns a Recorder that writes its transcript to w.
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{enc: json.NewEncoder(w)}
+}
+
+// WithRecorder records the connection's traffic with r. It installs r as the
+// connection's WireTap, replacing any tap set with WithWireTap.
+func WithRecorder(r *Recorder) ConnectionOption {
+	return WithWireTap(r.Tap)
+}
+
+// Tap is a WireTap that appends a message to the transcript. After the first
+// write error, further messages are dropped; see Err.
+func (r *Recorder) Tap(direction Direction, raw []byte) {
+	entry := TranscriptEntry{Direction: direction}
+	if json.Valid(raw) {
+		entry.Message = raw
+	} else {
+		entry.Malformed = string(raw)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err == nil {
+		r.err = r.enc.Encode(entry)
+	}
+}
+
+// Err returns the first error encountered writing the transcript.
+func (r *Recorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// ReadTranscript parses a JSON Lines transcript written by a Recorder.
+func ReadTranscript(r io.Reader) ([]TranscriptEntry, error) {
+	var entries []TranscriptEntry
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), defaultMaxMessageBytes)
+	for line := 1; sc.Scan(); line++ {
+		if len(bytes.TrimSpace(sc.Bytes())) == 0 {
+			continue
+		}
+		var e TranscriptEntry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("transcript line %d: %w", line, err)
+		}
+		entries = append(entries, e)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// ReplayMismatchError reports an outbound message that differs from the one
+// recorded in the transcript.
+type ReplayMismatchError struct {
+	// Index is the position of the expected message in the transcript.
+	Index int
+	Want  json.RawMessage
+	Got   json.RawMessage
+	// Err is the comparer's explanation, if any.
+	Err error
+}
+
+func (e *ReplayMismatchError) Error() string {
+	msg := fmt.Sprintf("replay mismatch at transcript entry %d:\n want: %s\n  got: %s", e.Index, e.Want, e.Got)
+	if e.Err != nil {
+		msg += "\n" + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *ReplayMismatchError) Unwrap() error { return e.Err }
+
+// ReplayComparer decides whether an outbound message matches the recorded one,
+// returning a non-nil error describing the difference if it does not.
+type ReplayComparer func(want, got json.RawMessage) error
+
+// ReplayerOption configures a Replayer.
+type ReplayerOption func(r *Replayer)
+
+// WithReplayFraming sets the framing the replayed connection uses. The default
+// is FramingNewline.
+func WithReplayFraming(f Framing) ReplayerOption {
+	return func(r *Replayer) {
+		r.framing = f
+	}
+}
+
+// WithReplayComparer replaces the default comparison, which requires outbound
+// messages to be structurally equal JSON. Use it to ignore fields that vary
+// between runs, such as generated session IDs or timestamps.
+func WithReplayComparer(cmp ReplayComparer) ReplayerOption {
+	return func(r *Replayer) {
+		r.compare = cmp
+	}
+}
+
+// Replayer plays the peer's side of a recorded transcript against a live
+// connection: it sends the recorded inbound messages and checks that the
+// connection's outbound messages match the recorded ones, in order. This makes
+// handler behavior reproducible in tests as long as the handlers emit their
+// messages in a deterministic order.
+type Replayer struct {
+	entries []TranscriptEntry
+	framing Framing
+	compare ReplayComparer
+}
+
+// NewReplayer returns a Replayer for entries, typically from ReadTranscript.
+func NewReplayer(entries []TranscriptEntry, opts ...ReplayerOption) *Replayer {
+	r := &Replayer{entries: entries, compare: equalJSONMessages}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Replay acts as the peer of a connection whose input is connInput and whose
+// output is connOutput, i.e. the writer and reader passed to NewConnection the
+// other way around. It returns nil once every transcript entry has been
+// replayed, a *ReplayMismatchError if an outbound message differs, or an error
+// if ctx ends or either stream fails first. Messages the connection sends after
+// the last recorded one are not checked. Replay does not close either stream.
+func (r *Replayer) Replay(ctx context.Context, connInput io.Writer, connOutput io.Reader) error {
+	outbound := 0
+	for _, e := range r.entries {
+		if e.Direction == DirectionOutbound {
+			outbound++
+		}
+	}
+	// Buffer everything the transcript expects so the connection never blocks
+	// writing while the replayer is busy sending.
+	received := make(chan readResult, outbound+1)
+	go func() {
+		mr := newMessageReader(r.framing, connOutput, defaultInitialMessageBufSize, defaultMaxMessageBytes)
+		for i := 0; i <= outbound; i++ {
+			msg, err := mr.next()
+			received <- readResult{msg: msg, err: err}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	for i, e := range r.entries {
+		switch e.Direction {
+		case DirectionInbound:
+			raw := []byte(e.Message)
+			if len(raw) == 0 {
+				raw = []byte(e.Malformed)
+			}
+			if _, err := connInput.Write(frameMessage(r.framing, append([]byte(nil), raw...))); err != nil {
+				return fmt.Errorf("replay entry %d: write: %w", i, err)
+			}
+		case DirectionOutbound:
+			var res readResult
+			select {
+			case res = <-received:
+			case <-ctx.Done():
+				return fmt.Errorf("replay entry %d: %w", i, ctx.Err())
+			}
+			if res.err != nil {
+				return fmt.Errorf("replay entry %d: read: %w", i, res.err)
+			}
+			if err := r.compare(e.Message, res.msg); err != nil {
+				return &ReplayMismatchError{Index: i, Want: e.Message, Got: res.msg, Err: err}
+			}
+		default:
+			return fmt.Errorf("replay entry %d: invalid direction %d", i, int(e.Direction))
+		}
+	}
+	return nil
+}
+
+var errReplayMessagesDiffer = errors.New("messages differ")
+
+// equalJSONMessages is the default ReplayComparer.
+func equalJSONMessages(want, got json.RawMessage) error {
+	var w, g any
+	if err := json.Unmarshal(want, &w); err != nil {
+		return fmt.Errorf("recorded message: %w", err)
+	}
+	if err := json.Unmarshal(got, &g); err != nil {
+		return fmt.Errorf("outbound message: %w", err)
+	}
+	wb, _ := json.Marshal(w)
+	gb, _ := json.Marshal(g)
+	if !bytes.Equal(wb, gb) {
+		return errReplayMessagesDiffer
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package acp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+// recordAgentSession runs a short session against agent and returns the agent
+// connection's transcript.
+func recordAgentSession(t *testing.T, agent agentFuncs) []byte {
+	t.Helper()
+	var transcript bytes.Buffer
+	rec := NewRecorder(&transcript)
+	c2aR, c2aW := io.Pipe()
+	a2cR, a2cW := io.Pipe()
+	agentConn := NewAgentSideConnection(agent, a2cW, c2aR, WithRecorder(rec))
+	client := NewClientSideConnection(&clientFuncs{
+		SessionUpdateFunc: func(context.Context, SessionNotification) error { return nil },
+	}, c2aW, a2cR)
+
+	ctx := context.Background()
+	if _, err := client.NewSession(ctx, NewSessionRequest{Cwd: "/", McpServers: []McpServer{}}); err != nil {
+		t.Fatalf("NewSession: %v", err)
+	}
+	if _, err := client.Prompt(ctx, PromptRequest{SessionId: "s1", Prompt: []ContentBlock{TextBlock("hi")}}); err != nil {
+		t.Fatalf("Prompt: %v", err)
+	}
+	c2aW.Close()
+	<-agentConn.Done()
+	if err := rec.Err(); err != nil {
+		t.Fatalf("recorder: %v", err)
+	}
+	return transcript.Bytes()
+}
+
+func replayAgent(t *testing.T, agent agentFuncs, entries []TranscriptEntry, opts ...ReplayerOption) error {
+	t.Helper()
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	conn := NewAgentSideConnection(agent, outW, inR)
+	defer func() {
+		inW.Close()
+		<-conn.Done()
+		outR.Close()
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return NewReplayer(entries, opts...).Replay(ctx, inW, outR)
+}
+
+func echoAgent(reply string) agentFuncs {
+	return agentFuncs{
+		NewSessionFunc: func(context.Context, NewSessionRequest) (NewSessionResponse, error) {
+			return NewSessionResponse{SessionId: "s1"}, nil
+		},
+		PromptFunc: func(context.Context, PromptRequest) (PromptResponse, error) {
+			return PromptResponse{StopReason: StopReason(reply)}, nil
+		},
+	}
+}
+
+func TestRecorderReplayer_RoundTrip(t *testing.T) {
+	t.Parallel()
+	raw := recordAgentSession(t, echoAgent(string(StopReasonEndTurn)))
+	entries, err := ReadTranscript(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("ReadTranscript: %v", err)
+	}
+	var in, out int
+	for _, e := range entries {
+		switch e.Direction {
+		case DirectionInbound:
+			in++
+		case DirectionOutbound:
+			out++
+		}
+	}
+	if in != 2 || out != 2 {
+		t.Fatalf("transcript has %d inbound and %d outbound messages, want 2 and 2:\n%s", in, out, raw)
+	}
+
+	if err := replayAgent(t, echoAgent(string(StopReasonEndTurn)), entries); err != nil {
+		t.Fatalf("replay against the same agent: %v", err)
+	}
+
+	err = replayAgent(t, echoAgent(string(StopReasonMaxTokens)), entries)
+	var mismatch *ReplayMismatchError
+	if !errors.As(err, &mismatch) || mismatch.Index != 3 {
+		t.Fatalf("expected mismatch at entry 3, got %v", err)
+	}
+
+	ignoreResults := func(want, got json.RawMessage) error { return nil }
+	if err := replayAgent(t, echoAgent(string(StopReasonMaxTokens)), entries, WithReplayComparer(ignoreResults)); err != nil {
+		t.Fatalf("replay with custom comparer: %v", err)
+	}
+}
+
+func TestRecorder_MalformedInbound(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	rec := NewRecorder(&buf)
+	rec.Tap(DirectionInbound, []byte("{not json"))
+	rec.Tap(DirectionOutbound, []byte(`{"jsonrpc":"2.0","id":1,"result":{}}`))
+	entries, err := ReadTranscript(&buf)
+	if err != nil {
+		t.Fatalf("ReadTranscript: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Malformed != "{not json" || entries[1].Direction != DirectionOutbound {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+}
@@ -0,0 +1,83 @@
+package acp
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrResponseDeferred is returned by a request handler that will answer later
+// through the Responder obtained with ResponderFromContext. The handler's
+// return value is then ignored, and nothing is sent until the Responder is
+// resolved or the request is cancelled.
+var ErrResponseDeferred = errors.New("response deferred")
+
+type responderContextKey struct{}
+
+// Responder sends the response to an inbound request after its handler has
+// returned ErrResponseDeferred, e.g. once a user has answered a prompt shown
+// by another goroutine. Only the first call to Reply or Fail has an effect.
+//
+// The request stays in flight until the Responder is resolved, although it no
+// longer counts against WithMaxConcurrentRequests. If the peer cancels it with
+// $/cancel_request, the connection closes or the WithHandlerTimeout limit passes
+// first, an error is sent instead and later calls to Reply and Fail report false.
+type Responder struct {
+	mu     sync.Mutex
+	done   chan struct{}
+	result any
+	err    *RequestError
+}
+
+func newResponder() *Responder {
+	return &Responder{done: make(chan struct{})}
+}
+
+// ResponderFromContext returns the Responder for the inbound request being
+// handled. It reports false outside a request handler, including in
+// notification handlers.
+func ResponderFromContext(ctx context.Context) (*Responder, bool) {
+	r, ok := ctx.Value(responderContextKey{}).(*Responder)
+	return r, ok
+}
+
+// Reply resolves the request with result. It reports whether this call
+// resolved it.
+func (r *Responder) Reply(result any) bool {
+	return r.resolve(result, nil)
+}
+
+// Fail resolves the request with err, or with an internal error if err is nil.
+// It reports whether this call resolved it.
+func (r *Responder) Fail(err *RequestError) bool {
+	if err == nil {
+		err = NewInternalError(nil)
+	}
+	return r.resolve(nil, err)
+}
+
+func (r *Responder) resolve(result any, err *RequestError) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	select {
+	case <-r.done:
+		return false
+	default:
+	}
+	r.result, r.err = result, err
+	close(r.done)
+	return true
+}
+
+// wait blocks until r is resolved or ctx, the request's context, is done, in
+// which case the request is resolved as cancelled.
+func (r *Responder) wait(ctx context.Context) (any, *RequestError) {
+	select {
+	case <-r.done:
+	case <-ctx.Done():
+		r.resolve(nil, toReqErr(context.Cause(ctx)))
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.result, r.err
+}
@@ -0,0 +1,99 @@
+package acp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestResponder_DeferredResponse(t *testing.T) {
+	responders := make(chan *Responder, 1)
+	client := &clientFuncs{
+		RequestPermissionFunc: func(ctx context.Context, _ RequestPermissionRequest) (RequestPermissionResponse, error) {
+			r, ok := ResponderFromContext(ctx)
+			if !ok {
+				t.Error("no Responder in request handler context")
+			}
+			responders <- r
+			return RequestPermissionResponse{}, ErrResponseDeferred
+		},
+	}
+	// A single request slot shows that a deferred request does not hold one.
+	agentConn, clientConn := NewInMemoryConnections(agentFuncs{}, client, WithMaxConcurrentRequests(1))
+	t.Cleanup(func() { _ = clientConn.Close() })
+
+	if _, ok := ResponderFromContext(context.Background()); ok {
+		t.Fatal("ResponderFromContext reported a Responder outside a handler")
+	}
+
+	t.Run("reply", func(t *testing.T) {
+		done := make(chan RequestPermissionResponse, 1)
+		go func() {
+			resp, err := agentConn.RequestPermission(context.Background(), testPermissionRequest())
+			if err != nil {
+				t.Errorf("RequestPermission: %v", err)
+			}
+			done <- resp
+		}()
+		r := <-responders
+		select {
+		case <-done:
+			t.Fatal("response sent before the Responder was resolved")
+		case <-time.After(50 * time.Millisecond):
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if _, err := agentConn.CreateTerminal(ctx, CreateTerminalRequest{SessionId: "s1", Command: "true"}); err != nil {
+			t.Fatalf("CreateTerminal while a response is deferred: %v", err)
+		}
+		if !r.Reply(RequestPermissionResponse{Outcome: NewRequestPermissionOutcomeSelected("allow")}) {
+			t.Fatal("Reply did not resolve the request")
+		}
+		if r.Fail(NewInternalError(nil)) {
+			t.Fatal("Fail resolved an already resolved request")
+		}
+		select {
+		case resp := <-done:
+			if resp.Outcome.Selected == nil || resp.Outcome.Selected.OptionId != "allow" {
+				t.Fatalf("outcome = %+v, want the deferred reply", resp.Outcome)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("deferred reply was not delivered")
+		}
+	})
+
+	t.Run("fail", func(t *testing.T) {
+		errc := make(chan error, 1)
+		go func() {
+			_, err := agentConn.RequestPermission(context.Background(), testPermissionRequest())
+			errc <- err
+		}()
+		(<-responders).Fail(NewAuthRequired(nil))
+		if err := <-errc; !errors.Is(err, ErrAuthRequired) {
+			t.Fatalf("RequestPermission error = %v, want the deferred failure", err)
+		}
+	})
+
+	t.Run("cancelled by peer", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		errc := make(chan error, 1)
+		go func() {
+			_, err := agentConn.RequestPermission(ctx, testPermissionRequest())
+			errc <- err
+		}()
+		r := <-responders
+		cancel()
+		if err := <-errc; !errors.Is(err, context.Canceled) {
+			t.Fatalf("RequestPermission error = %v, want context.Canceled", err)
+		}
+		select {
+		case <-r.done:
+		case <-time.After(2 * time.Second):
+			t.Fatal("$/cancel_request did not resolve the deferred request")
+		}
+		if r.Reply(RequestPermissionResponse{}) {
+			t.Fatal("Reply resolved a cancelled request")
+		}
+	})
+}
@@ -0,0 +1,101 @@
+package acp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ErrUnknownSessionMode indicates a mode id that the agent did not list among
+// the session's available modes.
+var ErrUnknownSessionMode = errors.New("unknown session mode")
+
+// Modes returns the modes available in the session, or nil if s is nil, i.e.
+// the agent does not support modes.
+func (s *SessionModeState) Modes() []SessionMode {
+	if s == nil {
+		return nil
+	}
+	return s.AvailableModes
+}
+
+// sessionModes records the mode state agents report when sessions are created
+// or loaded, so that SetMode can check mode ids locally.
+type sessionModes struct {
+	mu     sync.Mutex
+	states map[SessionId]SessionModeState
+}
+
+func (m *sessionModes) record(id SessionId, state *SessionModeState) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if state == nil {
+		delete(m.states, id)
+		return
+	}
+	if m.states == nil {
+		m.states = make(map[SessionId]SessionModeState)
+	}
+	m.states[id] = *state
+}
+
+func (m *sessionModes) get(id SessionId) (SessionModeState, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	state, ok := m.states[id]
+	return state, ok
+}
+
+func (m *sessionModes) setCurrent(id SessionId, mode SessionModeId) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if state, ok := m.states[id]; ok {
+		state.CurrentModeId = mode
+		m.states[id] = state
+	}
+}
+
+// observeModeUpdate applies a current_mode_update session/update notification
+// to the recorded mode state of its session.
+func (c *ClientSideConnection) observeModeUpdate(params json.RawMessage) {
+	if !bytes.Contains(params, []byte(`"current_mode_update"`)) {
+		return
+	}
+	var n SessionNotification
+	if err := json.Unmarshal(params, &n); err != nil || n.Update.CurrentModeUpdate == nil {
+		return
+	}
+	c.sessionModes.setCurrent(n.SessionId, n.Update.CurrentModeUpdate.CurrentModeId)
+}
+
+// SessionModes returns the mode state the agent reported for a session created
+// or loaded through this connection, with the current mode kept up to date by
+// SetMode, SetSessionMode and the agent's current_mode_update notifications. It reports
+// false if the agent reported no modes for the session.
+func (c *ClientSideConnection) SessionModes(sessionId SessionId) (SessionModeState, bool) {
+	return c.sessionModes.get(sessionId)
+}
+
+// SetMode switches a session to modeId. If the agent reported the session's
+// available modes, an unknown modeId fails locally with an error wrapping
+// ErrUnknownSessionMode that lists the valid ids, instead of being sent to the
+// agent. Otherwise the request is sent as is, like SetSessionMode.
+func (c *ClientSideConnection) SetMode(ctx context.Context, sessionId SessionId, modeId SessionModeId) error {
+	if state, ok := c.sessionModes.get(sessionId); ok {
+		ids := make([]string, 0, len(state.AvailableModes))
+		known := false
+		for _, m := range state.AvailableModes {
+			ids = append(ids, string(m.Id))
+			known = known || m.Id == modeId
+		}
+		if !known {
+			return fmt.Errorf("%w %q for session %s; available modes: %s", ErrUnknownSessionMode, modeId, sessionId, strings.Join(ids, ", "))
+		}
+	}
+	_, err := c.SetSessionMode(ctx, SetSessionModeRequest{SessionId: sessionId, ModeId: modeId})
+	return err
+}
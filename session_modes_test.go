@@ -0,0 +1,134 @@
+package acp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestClientSetMode_ValidatesAgainstSessionModes(t *testing.T) {
+	var setModes []SessionModeId
+	agent := agentFuncs{
+		NewSessionFunc: func(context.Context, NewSessionRequest) (NewSessionResponse, error) {
+			return NewSessionResponse{
+				SessionId: "s1",
+				Modes: &SessionModeState{
+					AvailableModes: []SessionMode{{Id: "ask", Name: "Ask"}, {Id: "code", Name: "Code"}},
+					CurrentModeId:  "ask",
+				},
+			}, nil
+		},
+		SetSessionModeFunc: func(_ context.Context, req SetSessionModeRequest) (SetSessionModeResponse, error) {
+			setModes = append(setModes, req.ModeId)
+			return SetSessionModeResponse{}, nil
+		},
+	}
+	clientConn, _ := newNotificationBarrierTestPair(t, &clientFuncs{}, agent)
+	ctx := context.Background()
+
+	resp, err := clientConn.NewSession(ctx, NewSessionRequest{Cwd: "/", McpServers: []McpServer{}})
+	if err != nil {
+		t.Fatalf("NewSession: %v", err)
+	}
+	if got := len(resp.Modes.Modes()); got != 2 {
+		t.Fatalf("Modes() returned %d modes, want 2", got)
+	}
+
+	err = clientConn.SetMode(ctx, "s1", "cdoe")
+	if !errors.Is(err, ErrUnknownSessionMode) {
+		t.Fatalf("SetMode with a typo: got %v, want ErrUnknownSessionMode", err)
+	}
+	if !strings.Contains(err.Error(), "ask, code") {
+		t.Fatalf("error %q does not list the available modes", err)
+	}
+	if len(setModes) != 0 {
+		t.Fatalf("unknown mode was sent to the agent: %v", setModes)
+	}
+
+	if err := clientConn.SetMode(ctx, "s1", "code"); err != nil {
+		t.Fatalf("SetMode: %v", err)
+	}
+	if state, ok := clientConn.SessionModes("s1"); !ok || state.CurrentModeId != "code" {
+		t.Fatalf("SessionModes = %+v, %v; want current mode code", state, ok)
+	}
+
+	// Sessions without reported modes are not validated locally.
+	if err := clientConn.SetMode(ctx, "unknown-session", "anything"); err != nil {
+		t.Fatalf("SetMode for a session without modes: %v", err)
+	}
+	if want := []SessionModeId{"code", "anything"}; len(setModes) != 2 || setModes[0] != want[0] || setModes[1] != want[1] {
+		t.Fatalf("agent received modes %v, want %v", setModes, want)
+	}
+}
+
+func TestClientSessionModes_FollowsCurrentModeUpdate(t *testing.T) {
+	var agentConn *AgentSideConnection
+	agent := agentFuncs{
+		NewSessionFunc: func(context.Context, NewSessionRequest) (NewSessionResponse, error) {
+			return NewSessionResponse{
+				SessionId: "s1",
+				Modes: &SessionModeState{
+					AvailableModes: []SessionMode{{Id: "ask", Name: "Ask"}, {Id: "code", Name: "Code"}},
+					CurrentModeId:  "ask",
+				},
+			}, nil
+		},
+		PromptFunc: func(ctx context.Context, p PromptRequest) (PromptResponse, error) {
+			err := agentConn.SessionUpdate(ctx, SessionNotification{
+				SessionId: p.SessionId,
+				Update:    SessionUpdate{CurrentModeUpdate: &SessionCurrentModeUpdate{CurrentModeId: "code"}},
+			})
+			return PromptResponse{StopReason: StopReasonEndTurn}, err
+		},
+	}
+	clientConn, created := newNotificationBarrierTestPair(t, &clientFuncs{}, agent)
+	agentConn = created
+	ctx := context.Background()
+
+	if _, err := clientConn.NewSession(ctx, NewSessionRequest{Cwd: "/", McpServers: []McpServer{}}); err != nil {
+		t.Fatalf("NewSession: %v", err)
+	}
+	if _, err := clientConn.Prompt(ctx, PromptRequest{SessionId: "s1", Prompt: []ContentBlock{TextBlock("switch")}}); err != nil {
+		t.Fatalf("Prompt: %v", err)
+	}
+	if state, ok := clientConn.SessionModes("s1"); !ok || state.CurrentModeId != "code" {
+		t.Fatalf("SessionModes = %+v, %v; want current mode code", state, ok)
+	}
+}
+
+func TestClientSessionModes_FollowsSetSessionMode(t *testing.T) {
+	agent := agentFuncs{
+		NewSessionFunc: func(context.Context, NewSessionRequest) (NewSessionResponse, error) {
+			return NewSessionResponse{
+				SessionId: "s1",
+				Modes: &SessionModeState{
+					AvailableModes: []SessionMode{{Id: "ask", Name: "Ask"}, {Id: "code", Name: "Code"}},
+					CurrentModeId:  "ask",
+				},
+			}, nil
+		},
+		SetSessionModeFunc: func(context.Context, SetSessionModeRequest) (SetSessionModeResponse, error) {
+			return SetSessionModeResponse{}, nil
+		},
+	}
+	clientConn, _ := newNotificationBarrierTestPair(t, &clientFuncs{}, agent)
+	ctx := context.Background()
+
+	if _, err := clientConn.NewSession(ctx, NewSessionRequest{Cwd: "/", McpServers: []McpServer{}}); err != nil {
+		t.Fatalf("NewSession: %v", err)
+	}
+	if _, err := clientConn.SetSessionMode(ctx, SetSessionModeRequest{SessionId: "s1", ModeId: "code"}); err != nil {
+		t.Fatalf("SetSessionMode: %v", err)
+	}
+	if state, ok := clientConn.SessionModes("s1"); !ok || state.CurrentModeId != "code" {
+		t.Fatalf("SessionModes = %+v, %v; want current mode code", state, ok)
+	}
+}
+
+func TestSessionModeState_ModesNil(t *testing.T) {
+	var s *SessionModeState
+	if got := s.Modes(); got != nil {
+		t.Fatalf("Modes() on nil state = %v, want nil", got)
+	}
+}
@@ -0,0 +1,204 @@
+package acp
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+)
+
+// SessionUpdateSequenceMetaKey is the _meta key under which SessionUpdateSequenced
+// sends a notification's sequence number.
+const SessionUpdateSequenceMetaKey = "sequence"
+
+// sessionUpdateCounters hands out per-session sequence numbers, starting at 1.
+type sessionUpdateCounters struct {
+	mu   sync.Mutex
+	next map[SessionId]uint64
+}
+
+func (s *sessionUpdateCounters) nextSeq(id SessionId) uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.next == nil {
+		s.next = make(map[SessionId]uint64)
+	}
+	s.next[id]++
+	return s.next[id]
+}
+
+// SessionUpdateSequenced sends n like SessionUpdate, tagged in its _meta with the
+// next sequence number for its session so that a client can restore the send
+// order with a SessionUpdateSequencer. Numbers start at 1 and are consumed even
+// if sending fails, which the client's sequencer sees as a gap.
+func (c *AgentSideConnection) SessionUpdateSequenced(ctx context.Context, n SessionNotification) error {
+	meta := make(map[string]any, len(n.Meta)+1)
+	for k, v := range n.Meta {
+		meta[k] = v
+	}
+	meta[SessionUpdateSequenceMetaKey] = c.updateSeqs.nextSeq(n.SessionId)
+	n.Meta = meta
+	return c.SessionUpdate(ctx, n)
+}
+
+// SessionUpdateSequence returns the sequence number SessionUpdateSequenced
+// attached to n, if any.
+func SessionUpdateSequence(n SessionNotification) (uint64, bool) {
+	switch v := n.Meta[SessionUpdateSequenceMetaKey].(type) {
+	case uint64:
+		return v, true
+	case float64:
+		if v >= 1 && v == float64(uint64(v)) {
+			return uint64(v), true
+		}
+	case json.Number:
+		if i, err := v.Int64(); err == nil && i >= 1 {
+			return uint64(i), true
+		}
+	}
+	return 0, false
+}
+
+// SessionUpdateSequencer restores the order of session updates sent with
+// SessionUpdateSequenced. Feed it every notification from the client's
+// SessionUpdate handler with Add; it calls deliver with each session's updates
+// strictly in sequence order, holding back updates that arrive ahead of a
+// missing one. If the gap is not filled within the sequencer's gap timeout, the
+// missing updates are skipped, and any of them that arrive later are dropped.
+// Notifications without a sequence number are delivered immediately.
+//
+// deliver is called with the sequencer's lock held, from Add or from a timer
+// goroutine, and must not call back into the sequencer.
+type SessionUpdateSequencer struct {
+	gapTimeout time.Duration
+	deliver    func(SessionNotification)
+
+	mu       sync.Mutex
+	sessions map[SessionId]*sequencedSession
+}
+
+type sequencedSession struct {
+	next    uint64
+	pending map[uint64]SessionNotification
+	timer   *time.Timer
+	// timerGen identifies the current timer, so that a timer that fires after
+	// being stopped can tell it is stale.
+	timerGen uint64
+}
+
+// NewSessionUpdateSequencer returns a sequencer that passes updates to deliver in
+// order, waiting at most gapTimeout for a missing update.
+func NewSessionUpdateSequencer(gapTimeout time.Duration, deliver func(SessionNotification)) *SessionUpdateSequencer {
+	return &SessionUpdateSequencer{
+		gapTimeout: gapTimeout,
+		deliver:    deliver,
+		sessions:   make(map[SessionId]*sequencedSession),
+	}
+}
+
+// Add delivers n, and any updates it was holding back, if n is next in its
+// session's sequence, and otherwise buffers it.
+func (s *SessionUpdateSequencer) Add(n SessionNotification) {
+	seq, ok := SessionUpdateSequence(n)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !ok {
+		s.deliver(n)
+		return
+	}
+	sess := s.sessions[n.SessionId]
+	if sess == nil {
+		sess = &sequencedSession{next: 1, pending: make(map[uint64]SessionNotification)}
+		s.sessions[n.SessionId] = sess
+	}
+	if seq < sess.next {
+		return
+	}
+	sess.pending[seq] = n
+	s.drainLocked(sess)
+	if len(sess.pending) > 0 && sess.timer == nil {
+		s.startTimerLocked(n.SessionId, sess)
+	}
+}
+
+// Flush delivers the updates held back for id in sequence order, skipping any
+// gaps, e.g. once the prompt turn they belong to has ended.
+func (s *SessionUpdateSequencer) Flush(id SessionId) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if sess := s.sessions[id]; sess != nil {
+		s.flushLocked(sess)
+	}
+}
+
+func (s *SessionUpdateSequencer) startTimerLocked(id SessionId, sess *sequencedSession) {
+	sess.timerGen++
+	gen := sess.timerGen
+	sess.timer = time.AfterFunc(s.gapTimeout, func() { s.skipGap(id, gen) })
+}
+
+// skipGap gives up on the updates missing from id's sequence, unless the timer
+// of generation gen has since been stopped.
+func (s *SessionUpdateSequencer) skipGap(id SessionId, gen uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sess := s.sessions[id]
+	if sess == nil || sess.timer == nil || sess.timerGen != gen {
+		return
+	}
+	sess.timer = nil
+	if len(sess.pending) == 0 {
+		return
+	}
+	// Skip only to the next buffered update; later gaps get their own timeout.
+	sess.next = minPendingSeq(sess.pending)
+	s.drainLocked(sess)
+	if len(sess.pending) > 0 {
+		s.startTimerLocked(id, sess)
+	}
+}
+
+func (s *SessionUpdateSequencer) flushLocked(sess *sequencedSession) {
+	if sess.timer != nil {
+		sess.timer.Stop()
+		sess.timer = nil
+	}
+	seqs := make([]uint64, 0, len(sess.pending))
+	for seq := range sess.pending {
+		seqs = append(seqs, seq)
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+	for _, seq := range seqs {
+		s.deliver(sess.pending[seq])
+		delete(sess.pending, seq)
+		sess.next = seq + 1
+	}
+}
+
+// drainLocked delivers the run of consecutive updates starting at sess.next.
+func (s *SessionUpdateSequencer) drainLocked(sess *sequencedSession) {
+	for {
+		n, ok := sess.pending[sess.next]
+		if !ok {
+			break
+		}
+		delete(sess.pending, sess.next)
+		sess.next++
+		s.deliver(n)
+	}
+	if len(sess.pending) == 0 && sess.timer != nil {
+		sess.timer.Stop()
+		sess.timer = nil
+	}
+}
+
+func minPendingSeq(pending map[uint64]SessionNotification) uint64 {
+	var lowest uint64
+	for seq := range pending {
+		if lowest == 0 || seq < lowest {
+			lowest = seq
+		}
+	}
+	return lowest
+}
@@ -0,0 +1,102 @@
+package acp
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func sequencedUpdate(id SessionId, seq uint64) SessionNotification {
+	return SessionNotification{SessionId: id, Meta: map[string]any{SessionUpdateSequenceMetaKey: float64(seq)}}
+}
+
+func TestSessionUpdateSequencer(t *testing.T) {
+	var mu sync.Mutex
+	var got []uint64
+	s := NewSessionUpdateSequencer(20*time.Millisecond, func(n SessionNotification) {
+		seq, _ := SessionUpdateSequence(n)
+		mu.Lock()
+		got = append(got, seq)
+		mu.Unlock()
+	})
+	delivered := func() []uint64 {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]uint64(nil), got...)
+	}
+
+	s.Add(sequencedUpdate("a", 3))
+	s.Add(sequencedUpdate("b", 1))
+	s.Add(sequencedUpdate("a", 1))
+	s.Add(SessionNotification{SessionId: "a"})
+	s.Add(sequencedUpdate("a", 2))
+	if want := []uint64{1, 1, 0, 2, 3}; !reflect.DeepEqual(delivered(), want) {
+		t.Fatalf("delivered %v, want %v", delivered(), want)
+	}
+
+	// 4 never arrives: 5 is held back until the gap times out.
+	mu.Lock()
+	got = nil
+	mu.Unlock()
+	s.Add(sequencedUpdate("a", 5))
+	if len(delivered()) != 0 {
+		t.Fatalf("delivered %v across a gap", delivered())
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for len(delivered()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	s.Add(sequencedUpdate("a", 4))
+	s.Add(sequencedUpdate("a", 7))
+	s.Flush("a")
+	if want := []uint64{5, 7}; !reflect.DeepEqual(delivered(), want) {
+		t.Fatalf("delivered %v, want %v", delivered(), want)
+	}
+}
+
+func TestSessionUpdateSequenced(t *testing.T) {
+	var mu sync.Mutex
+	var seqs []uint64
+	sequencer := NewSessionUpdateSequencer(time.Second, func(n SessionNotification) {
+		seq, _ := SessionUpdateSequence(n)
+		seqs = append(seqs, seq)
+	})
+	client := &clientFuncs{
+		SessionUpdateFunc: func(_ context.Context, n SessionNotification) error {
+			mu.Lock()
+			defer mu.Unlock()
+			sequencer.Add(n)
+			return nil
+		},
+	}
+	var agentConn *AgentSideConnection
+	agent := agentFuncs{
+		PromptFunc: func(ctx context.Context, p PromptRequest) (PromptResponse, error) {
+			for i := 0; i < 3; i++ {
+				n := testSessionUpdate(p.SessionId, i)
+				if err := agentConn.SessionUpdateSequenced(ctx, n); err != nil {
+					return PromptResponse{}, err
+				}
+				if _, ok := n.Meta[SessionUpdateSequenceMetaKey]; ok {
+					t.Error("SessionUpdateSequenced modified the caller's meta")
+				}
+			}
+			return PromptResponse{StopReason: StopReasonEndTurn}, nil
+		},
+	}
+	clientConn, created := newNotificationBarrierTestPair(t, client, agent)
+	agentConn = created
+
+	for _, id := range []SessionId{"s1", "s2"} {
+		if _, err := clientConn.Prompt(context.Background(), PromptRequest{SessionId: id, Prompt: []ContentBlock{TextBlock("hi")}}); err != nil {
+			t.Fatalf("Prompt: %v", err)
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if want := []uint64{1, 2, 3, 1, 2, 3}; !reflect.DeepEqual(seqs, want) {
+		t.Fatalf("delivered sequence numbers %v, want %v", seqs, want)
+	}
+}
@@ -0,0 +1,116 @@
+package acp
+
+import (
+	"context"
+	"sync"
+)
+
+// SessionStore tracks per-session state of type T for an agent implementation.
+// Typically an agent calls Create in NewSession, Get in Prompt and Cancel, and
+// Delete when the session ends. It also ties each session's running prompt to a
+// context, so that a session/cancel can stop it; see PromptContext. The zero
+// value is ready to use, and a SessionStore is safe for concurrent use.
+type SessionStore[T any] struct {
+	mu       sync.Mutex
+	sessions map[SessionId]*T
+	prompts  map[SessionId]*promptCancel
+}
+
+type promptCancel struct{ cancel context.CancelFunc }
+
+// Create stores new zero-valued state for id, replacing any existing state, and
+// returns it.
+func (s *SessionStore[T]) Create(id SessionId) *T {
+	v := new(T)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sessions == nil {
+		s.sessions = make(map[SessionId]*T)
+	}
+	s.sessions[id] = v
+	return v
+}
+
+// Get returns the state for id, if any.
+func (s *SessionStore[T]) Get(id SessionId) (*T, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.sessions[id]
+	return v, ok
+}
+
+// Delete removes the state for id and cancels its running prompt, if any.
+func (s *SessionStore[T]) Delete(id SessionId) {
+	s.mu.Lock()
+	delete(s.sessions, id)
+	s.mu.Unlock()
+	s.CancelSession(id)
+}
+
+// Len returns the number of stored sessions.
+func (s *SessionStore[T]) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.sessions)
+}
+
+// Range calls f for each stored session until f returns false. It iterates over
+// a snapshot, so f may call other SessionStore methods.
+func (s *SessionStore[T]) Range(f func(id SessionId, state *T) bool) {
+	s.mu.Lock()
+	ids := make([]SessionId, 0, len(s.sessions))
+	states := make([]*T, 0, len(s.sessions))
+	for id, v := range s.sessions {
+		ids = append(ids, id)
+		states = append(states, v)
+	}
+	s.mu.Unlock()
+	for i, id := range ids {
+		if !f(id, states[i]) {
+			return
+		}
+	}
+}
+
+// PromptContext returns a context derived from parent that is cancelled when
+// CancelSession is called for id. Call it at the start of a Prompt handler with
+// the handler's context, and call CancelSession from the Cancel handler. Starting
+// a new prompt for id cancels the previous one. The association ends once the
+// returned context is done.
+func (s *SessionStore[T]) PromptContext(id SessionId, parent context.Context) context.Context {
+	ctx, cancel := context.WithCancel(parent)
+	p := &promptCancel{cancel: cancel}
+	s.mu.Lock()
+	if s.prompts == nil {
+		s.prompts = make(map[SessionId]*promptCancel)
+	}
+	prev := s.prompts[id]
+	s.prompts[id] = p
+	s.mu.Unlock()
+	if prev != nil {
+		prev.cancel()
+	}
+
+	context.AfterFunc(ctx, func() {
+		s.mu.Lock()
+		if s.prompts[id] == p {
+			delete(s.prompts, id)
+		}
+		s.mu.Unlock()
+	})
+	return ctx
+}
+
+// CancelSession cancels the context returned by PromptContext for id, if one is
+// active, and reports whether it did.
+func (s *SessionStore[T]) CancelSession(id SessionId) bool {
+	s.mu.Lock()
+	p := s.prompts[id]
+	delete(s.prompts, id)
+	s.mu.Unlock()
+	if p == nil {
+		return false
+	}
+	p.cancel()
+	return true
+}
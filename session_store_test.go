@@ -0,0 +1,115 @@
+package acp
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSessionStore(t *testing.T) {
+	type state struct{ turns int }
+	var store SessionStore[state]
+
+	if _, ok := store.Get("missing"); ok {
+		t.Fatal("expected no state for unknown session")
+	}
+	st := store.Create("s1")
+	st.turns++
+	if got, ok := store.Get("s1"); !ok || got.turns != 1 {
+		t.Fatalf("Get(s1) = %+v, %v", got, ok)
+	}
+	store.Create("s2")
+
+	var ids []string
+	store.Range(func(id SessionId, _ *state) bool {
+		ids = append(ids, string(id))
+		// Range must tolerate calls back into the store.
+		store.Get(id)
+		return true
+	})
+	sort.Strings(ids)
+	if len(ids) != 2 || ids[0] != "s1" || ids[1] != "s2" {
+		t.Fatalf("Range visited %v", ids)
+	}
+
+	store.Delete("s1")
+	if _, ok := store.Get("s1"); ok || store.Len() != 1 {
+		t.Fatalf("expected s1 deleted, len=%d", store.Len())
+	}
+}
+
+func TestSessionStore_ConcurrentUse(t *testing.T) {
+	var store SessionStore[int]
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			id := SessionId(strconv.Itoa(i))
+			store.Create(id)
+			store.Get(id)
+			store.Range(func(SessionId, *int) bool { return true })
+			if i%2 == 0 {
+				store.Delete(id)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if store.Len() != 25 {
+		t.Fatalf("expected 25 sessions, got %d", store.Len())
+	}
+}
+
+func TestSessionStore_PromptContext(t *testing.T) {
+	var store SessionStore[struct{}]
+
+	ctx := store.PromptContext("s1", context.Background())
+	if !store.CancelSession("s1") {
+		t.Fatal("expected an active prompt to cancel")
+	}
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("prompt context was not cancelled")
+	}
+	if store.CancelSession("s1") {
+		t.Fatal("expected no active prompt after cancel")
+	}
+
+	// A new prompt for the same session supersedes the previous one.
+	first := store.PromptContext("s1", context.Background())
+	second := store.PromptContext("s1", context.Background())
+	if first.Err() == nil {
+		t.Fatal("expected previous prompt context to be cancelled")
+	}
+	if second.Err() != nil {
+		t.Fatal("new prompt context cancelled unexpectedly")
+	}
+
+	// The association ends once the parent is done.
+	parent, cancel := context.WithCancel(context.Background())
+	_ = store.PromptContext("s2", parent)
+	cancel()
+	deadline := time.Now().Add(time.Second)
+	for {
+		store.mu.Lock()
+		_, active := store.prompts["s2"]
+		store.mu.Unlock()
+		if !active {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("prompt for s2 still registered after its parent was cancelled")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	store.Create("s1")
+	store.Delete("s1")
+	if second.Err() == nil {
+		t.Fatal("expected Delete to cancel the running prompt")
+	}
+}
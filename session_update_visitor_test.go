@@ -0,0 +1,114 @@
+package acp
+
+import "testing"
+
+type recordingVisitor struct{ visited []string }
+
+func (r *recordingVisitor) record(kind string) error {
+	r.visited = append(r.visited, kind)
+	return nil
+}
+
+func (r *recordingVisitor) VisitUserMessageChunk(*SessionUpdateUserMessageChunk) error {
+	return r.record("user_message_chunk")
+}
+
+func (r *recordingVisitor) VisitAgentMessageChunk(*SessionUpdateAgentMessageChunk) error {
+	return r.record("agent_message_chunk")
+}
+
+func (r *recordingVisitor) VisitAgentThoughtChunk(*SessionUpdateAgentThoughtChunk) error {
+	return r.record("agent_thought_chunk")
+}
+
+func (r *recordingVisitor) VisitToolCall(*SessionUpdateToolCall) error {
+	return r.record("tool_call")
+}
+
+func (r *recordingVisitor) VisitToolCallUpdate(*SessionToolCallUpdate) error {
+	return r.record("tool_call_update")
+}
+
+func (r *recordingVisitor) VisitPlan(*SessionUpdatePlan) error {
+	return r.record("plan")
+}
+
+func (r *recordingVisitor) VisitPlanUpdate(*SessionPlanUpdate) error {
+	return r.record("plan_update")
+}
+
+func (r *recordingVisitor) VisitPlanRemoved(*SessionUpdatePlanRemoved) error {
+	return r.record("plan_removed")
+}
+
+func (r *recordingVisitor) VisitAvailableCommandsUpdate(*SessionAvailableCommandsUpdate) error {
+	return r.record("available_commands_update")
+}
+
+func (r *recordingVisitor) VisitCurrentModeUpdate(*SessionCurrentModeUpdate) error {
+	return r.record("current_mode_update")
+}
+
+func (r *recordingVisitor) VisitConfigOptionUpdate(*SessionConfigOptionUpdate) error {
+	return r.record("config_option_update")
+}
+
+func (r *recordingVisitor) VisitSessionInfoUpdate(*SessionSessionInfoUpdate) error {
+	return r.record("session_info_update")
+}
+
+func (r *recordingVisitor) VisitUsageUpdate(*SessionUsageUpdate) error {
+	return r.record("usage_update")
+}
+
+func TestSessionUpdateAccept(t *testing.T) {
+	v := &recordingVisitor{}
+	for _, u := range []SessionUpdate{
+		UpdateAgentMessageText("hi"),
+		UpdateAgentThoughtText("hmm"),
+		StartToolCall("call-1", "Read file"),
+		UpdateToolCall("call-1"),
+		UpdatePlan(),
+	} {
+		if err := u.Accept(v); err != nil {
+			t.Fatalf("Accept: %v", err)
+		}
+	}
+	want := []string{"agent_message_chunk", "agent_thought_chunk", "tool_call", "tool_call_update", "plan"}
+	if len(v.visited) != len(want) {
+		t.Fatalf("visited %v, want %v", v.visited, want)
+	}
+	for i := range want {
+		if v.visited[i] != want[i] {
+			t.Fatalf("visited %v, want %v", v.visited, want)
+		}
+	}
+
+	if err := (SessionUpdate{}).Accept(v); err == nil {
+		t.Fatal("expected error for empty SessionUpdate")
+	}
+}
+
+func TestSessionUpdateKindAndVariant(t *testing.T) {
+	var empty SessionUpdate
+	if empty.Kind() != "" || empty.Variant() != nil {
+		t.Fatalf("empty update: kind %q, variant %v", empty.Kind(), empty.Variant())
+	}
+	u := UpdateAgentMessageText("hi")
+	if u.Kind() != SessionUpdateKindAgentMessageChunk {
+		t.Fatalf("Kind() = %q", u.Kind())
+	}
+	switch v := u.Variant().(type) {
+	case *SessionUpdateAgentMessageChunk:
+		if v.SessionUpdateKind() != u.Kind() || v.Content.Text.Text != "hi" {
+			t.Fatalf("unexpected variant %+v", v)
+		}
+	default:
+		t.Fatalf("unexpected variant type %T", v)
+	}
+	for _, k := range AllSessionUpdateKind() {
+		if !k.IsValid() {
+			t.Fatalf("%q should be valid", k)
+		}
+	}
+}
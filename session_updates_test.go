@@ -0,0 +1,102 @@
+package acp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAgentSideConnectionSessionUpdates_WritesContiguously(t *testing.T) {
+	c2aR, c2aW := io.Pipe()
+	a2cR, a2cW := io.Pipe()
+	defer func() {
+		_ = c2aR.Close()
+		_ = c2aW.Close()
+		_ = a2cR.Close()
+		_ = a2cW.Close()
+	}()
+
+	var mu sync.Mutex
+	var got []string
+	NewClientSideConnection(&clientFuncs{
+		SessionUpdateFunc: func(_ context.Context, n SessionNotification) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if n.SessionId != "s1" {
+				t.Errorf("unexpected session %q", n.SessionId)
+			}
+			switch {
+			case n.Update.ToolCall != nil:
+				got = append(got, "tool_call")
+			case n.Update.ToolCallUpdate != nil:
+				got = append(got, "tool_call_update")
+			case n.Update.AgentMessageChunk != nil:
+				got = append(got, "agent_message_chunk")
+			}
+			return nil
+		},
+	}, c2aW, a2cR)
+	cw := &countingWriter{w: a2cW}
+	as := NewAgentSideConnection(agentFuncs{}, cw, c2aR)
+
+	err := as.SessionUpdates(context.Background(), "s1",
+		StartToolCall("call_1", "Run tests"),
+		UpdateToolCall("call_1", WithUpdateStatus(ToolCallStatusCompleted)),
+		UpdateAgentMessageText("All tests pass."),
+	)
+	if err != nil {
+		t.Fatalf("SessionUpdates: %v", err)
+	}
+	if n := cw.writes.Load(); n != 1 {
+		t.Fatalf("expected a single write, got %d", n)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		mu.Lock()
+		n := len(got)
+		mu.Unlock()
+		if n == 3 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) != 3 || got[0] != "tool_call" || got[1] != "tool_call_update" || got[2] != "agent_message_chunk" {
+		t.Fatalf("unexpected updates: %v", got)
+	}
+}
+
+func TestAgentSideConnectionSessionUpdates_SendsNothingWhenMiddlewareRejects(t *testing.T) {
+	errRejected := errors.New("rejected")
+	var seen int
+	inR, inW := io.Pipe()
+	defer func() {
+		_ = inW.Close()
+		_ = inR.Close()
+	}()
+	cw := &countingWriter{w: io.Discard}
+	as := NewAgentSideConnection(agentFuncs{}, cw, inR,
+		WithOutboundMiddleware(func(next OutboundFunc) OutboundFunc {
+			return func(ctx context.Context, method string, params json.RawMessage) error {
+				seen++
+				if seen == 2 {
+					return errRejected
+				}
+				return next(ctx, method, params)
+			}
+		}))
+
+	err := as.SessionUpdates(context.Background(), "s1", UpdateAgentMessageText("a"), UpdateAgentMessageText("b"))
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if n := cw.writes.Load(); n != 0 {
+		t.Fatalf("expected no writes, got %d", n)
+	}
+}
@@ -0,0 +1,84 @@
+package acp
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"sync"
+)
+
+// SpawnAgent starts cmd as an agent subprocess and returns a client-side
+// connection speaking to it over the child's stdin and stdout. The child's stderr
+// goes to cmd.Stderr if set, or to os.Stderr otherwise. The connection closes when
+// the process exits, and the process is killed if ctx is done first.
+//
+// The returned cleanup func closes the connection, kills the process if it is
+// still running, and waits for it to exit. It returns the process's exit error if
+// the process had already exited on its own, and is safe to call more than once.
+func SpawnAgent(ctx context.Context, cmd *exec.Cmd, client Client, opts ...ConnectionOption) (*ClientSideConnection, func() error, error) {
+	// Use our own pipes rather than cmd.StdinPipe/StdoutPipe: cmd.Wait closes those
+	// as soon as the process exits, racing with the connection's reader.
+	childIn, peerInput, err := os.Pipe()
+	if err != nil {
+		return nil, nil, err
+	}
+	peerOutput, childOut, err := os.Pipe()
+	if err != nil {
+		_ = childIn.Close()
+		_ = peerInput.Close()
+		return nil, nil, err
+	}
+	cmd.Stdin = childIn
+	cmd.Stdout = childOut
+	if cmd.Stderr == nil {
+		cmd.Stderr = os.Stderr
+	}
+	err = cmd.Start()
+	// The child holds its own copies of these ends now.
+	_ = childIn.Close()
+	_ = childOut.Close()
+	if err != nil {
+		_ = peerInput.Close()
+		_ = peerOutput.Close()
+		return nil, nil, err
+	}
+
+	conn := NewClientSideConnection(client, peerInput, peerOutput, opts...)
+
+	exited := make(chan struct{})
+	var waitErr error
+	go func() {
+		waitErr = cmd.Wait()
+		close(exited)
+		_ = conn.Close()
+	}()
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = cmd.Process.Kill()
+		case <-exited:
+		}
+	}()
+
+	var once sync.Once
+	var cleanupErr error
+	cleanup := func() error {
+		once.Do(func() {
+			_ = conn.Close()
+			select {
+			case <-exited:
+				cleanupErr = waitErr
+			default:
+				if err := cmd.Process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+					cleanupErr = err
+				}
+				<-exited
+			}
+			_ = peerInput.Close()
+			_ = peerOutput.Close()
+		})
+		return cleanupErr
+	}
+	return conn, cleanup, nil
+}
@@ -0,0 +1,71 @@
+package acp
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+// TestSpawnAgentHelperProcess is not a real test. It runs as the agent subprocess
+// for the SpawnAgent tests.
+func TestSpawnAgentHelperProcess(t *testing.T) {
+	if os.Getenv("ACP_SPAWN_AGENT_HELPER") != "1" {
+		t.Skip("helper process")
+	}
+	conn := NewStdioAgentConnection(agentFuncs{
+		InitializeFunc: func(context.Context, InitializeRequest) (InitializeResponse, error) {
+			return InitializeResponse{ProtocolVersion: ProtocolVersionNumber}, nil
+		},
+	})
+	<-conn.Done()
+	os.Exit(0)
+}
+
+func spawnHelperAgent(t *testing.T, ctx context.Context) (*ClientSideConnection, func() error) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], "-test.run=^TestSpawnAgentHelperProcess$")
+	cmd.Env = append(os.Environ(), "ACP_SPAWN_AGENT_HELPER=1")
+	conn, cleanup, err := SpawnAgent(ctx, cmd, &clientFuncs{})
+	if err != nil {
+		t.Fatalf("SpawnAgent: %v", err)
+	}
+	t.Cleanup(func() { _ = cleanup() })
+	return conn, cleanup
+}
+
+func TestSpawnAgent_ConnectsToSubprocess(t *testing.T) {
+	conn, cleanup := spawnHelperAgent(t, context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	res, err := conn.Initialize(ctx, InitializeRequest{ProtocolVersion: ProtocolVersionNumber})
+	if err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	if res.ProtocolVersion != ProtocolVersionNumber {
+		t.Fatalf("unexpected protocol version: %v", res.ProtocolVersion)
+	}
+
+	if err := cleanup(); err != nil {
+		t.Fatalf("cleanup: %v", err)
+	}
+	select {
+	case <-conn.Done():
+	default:
+		t.Fatal("connection still open after cleanup")
+	}
+}
+
+func TestSpawnAgent_ClosesConnectionWhenContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	conn, _ := spawnHelperAgent(t, ctx)
+
+	cancel()
+	select {
+	case <-conn.Done():
+	case <-time.After(10 * time.Second):
+		t.Fatal("connection not closed after the agent process was killed")
+	}
+}
@@ -0,0 +1,28 @@
+package acp
+
+import (
+	"log/slog"
+	"os"
+)
+
+// NewStdioAgentConnection creates an agent-side connection that talks to the client
+// over the process's stdin and stdout, as is usual for agents launched as
+// subprocesses. Connection diagnostics are logged to stderr so that they cannot
+// corrupt the JSON-RPC stream on stdout, unless opts include a WithLogger of
+// their own; the application must likewise never write anything else to stdout.
+func NewStdioAgentConnection(agent Agent, opts ...ConnectionOption) *AgentSideConnection {
+	opts = append([]ConnectionOption{WithLogger(stderrLogger())}, opts...)
+	return NewAgentSideConnection(agent, os.Stdout, os.Stdin, opts...)
+}
+
+// NewStdioClientConnection creates a client-side connection that talks to the agent
+// over the process's stdin and stdout. As with NewStdioAgentConnection, connection
+// diagnostics are logged to stderr.
+func NewStdioClientConnection(client Client, opts ...ConnectionOption) *ClientSideConnection {
+	opts = append([]ConnectionOption{WithLogger(stderrLogger())}, opts...)
+	return NewClientSideConnection(client, os.Stdout, os.Stdin, opts...)
+}
+
+func stderrLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stderr, nil))
+}
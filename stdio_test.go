@@ -0,0 +1,66 @@
+package acp
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewStdioAgentConnection_LogsToStderr(t *testing.T) {
+	stdinR, stdinW, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdoutR, stdoutW, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderrR, stderrW, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldStdin, oldStdout, oldStderr := os.Stdin, os.Stdout, os.Stderr
+	os.Stdin, os.Stdout, os.Stderr = stdinR, stdoutW, stderrW
+	t.Cleanup(func() { os.Stdin, os.Stdout, os.Stderr = oldStdin, oldStdout, oldStderr })
+
+	// The malformed line is already waiting when the connection starts reading,
+	// so the logger must be in place before its goroutines start.
+	if _, err := io.WriteString(stdinW, "not json\n"); err != nil {
+		t.Fatal(err)
+	}
+	conn := NewStdioAgentConnection(agentFuncs{})
+	// Restore the real files once the connection has captured the pipes, so that
+	// test output is not swallowed.
+	os.Stdin, os.Stdout, os.Stderr = oldStdin, oldStdout, oldStderr
+
+	logged := make(chan string, 1)
+	go func() {
+		line, _ := bufio.NewReader(stderrR).ReadString('\n')
+		logged <- line
+	}()
+	select {
+	case line := <-logged:
+		if !strings.Contains(line, "failed to parse incoming message") {
+			t.Fatalf("stderr = %q, want the parse failure", line)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("malformed input was not logged to stderr")
+	}
+
+	_ = stdinW.Close()
+	<-conn.Done()
+	_ = stdoutW.Close()
+	out, err := io.ReadAll(stdoutR)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if line != "" && !json.Valid([]byte(line)) {
+			t.Fatalf("stdout carries a non-JSON line %q", line)
+		}
+	}
+}
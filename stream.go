@@ -0,0 +1,50 @@
+package acp
+
+import (
+	"io"
+	"net"
+	"slices"
+)
+
+// NewAgentSideStreamConnection creates an agent-side connection over a single
+// bidirectional stream such as a net.Conn. The connection owns the stream: it is
+// closed exactly once, when the connection is closed or the peer disconnects.
+func NewAgentSideStreamConnection(agent Agent, stream io.ReadWriteCloser, opts ...ConnectionOption) *AgentSideConnection {
+	return NewAgentSideConnection(agent, stream, stream, append(slices.Clip(opts), withStreamCloser(stream))...)
+}
+
+// NewClientSideStreamConnection creates a client-side connection over a single
+// bidirectional stream such as a net.Conn. The connection owns the stream: it is
+// closed exactly once, when the connection is closed or the peer disconnects.
+func NewClientSideStreamConnection(client Client, stream io.ReadWriteCloser, opts ...ConnectionOption) *ClientSideConnection {
+	return NewClientSideConnection(client, stream, stream, append(slices.Clip(opts), withStreamCloser(stream))...)
+}
+
+// NewInMemoryConnections connects agent and client to each other in memory and
+// returns both ends, ready to use. It is meant for tests that exercise an Agent
+// and a Client together without spawning a process. Each connection owns its
+// end of the pipe, so closing either one disconnects the other. opts are applied
+// to both connections.
+func NewInMemoryConnections(agent Agent, client Client, opts ...ConnectionOption) (*AgentSideConnection, *ClientSideConnection) {
+	agentEnd, clientEnd := net.Pipe()
+	return NewAgentSideStreamConnection(agent, agentEnd, opts...), NewClientSideStreamConnection(client, clientEnd, opts...)
+}
+
+// withStreamCloser makes the connection close closer once it shuts down.
+func withStreamCloser(closer io.Closer) ConnectionOption {
+	return func(c *Connection) {
+		c.streamCloser = closer
+	}
+}
+
+// closeStream closes the owned stream, if any, exactly once and returns the
+// result of that close.
+func (c *Connection) closeStream() error {
+	if c.streamCloser == nil {
+		return nil
+	}
+	c.closeStreamOnce.Do(func() {
+		c.closeStreamErr = c.streamCloser.Close()
+	})
+	return c.closeStreamErr
+}
@@ -0,0 +1,108 @@
+package acp
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingCloser counts Close calls on a net.Conn.
+type countingCloser struct {
+	net.Conn
+	closes atomic.Int64
+}
+
+func (c *countingCloser) Close() error {
+	c.closes.Add(1)
+	return c.Conn.Close()
+}
+
+func TestStreamConnection_ClosesStreamOnce(t *testing.T) {
+	agentEnd, clientEnd := net.Pipe()
+	agentStream := &countingCloser{Conn: agentEnd}
+	clientStream := &countingCloser{Conn: clientEnd}
+
+	agentConn := NewAgentSideStreamConnection(agentFuncs{
+		InitializeFunc: func(context.Context, InitializeRequest) (InitializeResponse, error) {
+			return InitializeResponse{ProtocolVersion: ProtocolVersionNumber}, nil
+		},
+	}, agentStream)
+	clientConn := NewClientSideStreamConnection(&clientFuncs{}, clientStream)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if _, err := clientConn.Initialize(ctx, InitializeRequest{ProtocolVersion: ProtocolVersionNumber}); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+
+	if err := clientConn.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err := clientConn.Close(); err != nil {
+		t.Fatalf("second Close: %v", err)
+	}
+
+	// Closing one end disconnects the peer, which must close its own stream.
+	select {
+	case <-agentConn.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("agent connection did not observe the disconnect")
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for agentStream.closes.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	_ = agentConn.Close()
+
+	if got := clientStream.closes.Load(); got != 1 {
+		t.Fatalf("client stream closed %d times, want 1", got)
+	}
+	if got := agentStream.closes.Load(); got != 1 {
+		t.Fatalf("agent stream closed %d times, want 1", got)
+	}
+}
+
+func TestNewInMemoryConnections(t *testing.T) {
+	agentConn, clientConn := NewInMemoryConnections(agentFuncs{
+		InitializeFunc: func(context.Context, InitializeRequest) (InitializeResponse, error) {
+			return InitializeResponse{ProtocolVersion: ProtocolVersionNumber}, nil
+		},
+	}, &clientFuncs{
+		ReadTextFileFunc: func(context.Context, ReadTextFileRequest) (ReadTextFileResponse, error) {
+			return ReadTextFileResponse{Content: "hello"}, nil
+		},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if _, err := clientConn.Initialize(ctx, InitializeRequest{ProtocolVersion: ProtocolVersionNumber}); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	resp, err := agentConn.ReadTextFile(ctx, ReadTextFileRequest{SessionId: "s", Path: "/f"})
+	if err != nil {
+		t.Fatalf("ReadTextFile: %v", err)
+	}
+	if resp.Content != "hello" {
+		t.Fatalf("content = %q, want hello", resp.Content)
+	}
+
+	if err := agentConn.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	select {
+	case <-clientConn.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("client connection did not observe the disconnect")
+	}
+}
+
+func TestStreamConnections_DoNotWriteIntoCallerOptions(t *testing.T) {
+	opts := make([]ConnectionOption, 0, 2)
+	agentConn, clientConn := NewInMemoryConnections(agentFuncs{}, &clientFuncs{}, opts...)
+	_, _ = agentConn.Close(), clientConn.Close()
+	if opts[:cap(opts)][0] != nil {
+		t.Fatal("constructor appended into the caller's options slice")
+	}
+}
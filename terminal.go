@@ -0,0 +1,212 @@
+package acp
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TerminalHandle refers to a terminal created on the client with CreateTerminal.
+// It remembers the session and terminal IDs so that follow-up calls need only a
+// context. Callers should Release (or Close) the terminal when done with it.
+type TerminalHandle struct {
+	conn      *AgentSideConnection
+	sessionId SessionId
+	id        string
+
+	releaseOnce sync.Once
+	releaseErr  error
+}
+
+// NewTerminal asks the client to run a command in a new terminal and returns a
+// handle to it.
+func (c *AgentSideConnection) NewTerminal(ctx context.Context, params CreateTerminalRequest) (*TerminalHandle, error) {
+	resp, err := c.CreateTerminal(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return &TerminalHandle{conn: c, sessionId: params.SessionId, id: resp.TerminalId}, nil
+}
+
+// ID returns the terminal ID assigned by the client.
+func (t *TerminalHandle) ID() string { return t.id }
+
+// SessionId returns the session the terminal belongs to.
+func (t *TerminalHandle) SessionId() SessionId { return t.sessionId }
+
+// Content returns tool call content that embeds the terminal, for use in
+// session updates. It must be sent before the terminal is released.
+func (t *TerminalHandle) Content() ToolCallContent { return ToolTerminalRef(t.id) }
+
+// Output returns the terminal's current output and, if the command has exited,
+// its exit status.
+func (t *TerminalHandle) Output(ctx context.Context) (TerminalOutputResponse, error) {
+	return t.conn.TerminalOutput(ctx, TerminalOutputRequest{SessionId: t.sessionId, TerminalId: t.id})
+}
+
+// WaitForExit blocks until the command exits and returns its exit status.
+func (t *TerminalHandle) WaitForExit(ctx context.Context) (WaitForTerminalExitResponse, error) {
+	return t.conn.WaitForTerminalExit(ctx, WaitForTerminalExitRequest{SessionId: t.sessionId, TerminalId: t.id})
+}
+
+// Kill terminates the command without releasing the terminal, so its output
+// remains available.
+func (t *TerminalHandle) Kill(ctx context.Context) error {
+	_, err := t.conn.KillTerminal(ctx, KillTerminalRequest{SessionId: t.sessionId, TerminalId: t.id})
+	return err
+}
+
+// Release kills the command if it is still running and releases the terminal's
+// resources on the client. Only the first call contacts the client; later calls
+// return its result.
+func (t *TerminalHandle) Release(ctx context.Context) error {
+	t.releaseOnce.Do(func() {
+		_, t.releaseErr = t.conn.ReleaseTerminal(ctx, ReleaseTerminalRequest{SessionId: t.sessionId, TerminalId: t.id})
+	})
+	return t.releaseErr
+}
+
+// Close releases the terminal. It is equivalent to Release(context.Background()).
+func (t *TerminalHandle) Close() error { return t.Release(context.Background()) }
+
+// terminalStreamPollInterval is how often Stream polls the client for new output.
+const terminalStreamPollInterval = 50 * time.Millisecond
+
+// TerminalChunk is a piece of terminal output delivered by TerminalHandle.Stream.
+type TerminalChunk struct {
+	// Output holds the bytes appended since the previous chunk.
+	Output string
+	// Truncated reports that the client is dropping output from the start of
+	// its buffer to stay within its byte limit. Output then holds everything the
+	// client still retains, which may repeat bytes from earlier chunks, and some
+	// bytes may have been missed.
+	Truncated bool
+	// ExitStatus is set on the final chunk once the command has exited.
+	ExitStatus *TerminalExitStatus
+	// Err is set on the final chunk if streaming stopped because of an error.
+	Err error
+}
+
+// Stream follows the terminal's output, tail -f style. It polls TerminalOutput
+// and sends only newly appended output on the returned channel, or all of the
+// retained output marked Truncated once the client starts dropping the start of
+// its buffer. The channel is closed after a final chunk carrying the exit status
+// once WaitForExit resolves, after a chunk carrying Err if a call fails, or when
+// ctx is done.
+func (t *TerminalHandle) Stream(ctx context.Context) (<-chan TerminalChunk, error) {
+	first, err := t.Output(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	exited := make(chan struct{})
+	var exit WaitForTerminalExitResponse
+	var exitErr error
+	go func() {
+		exit, exitErr = t.WaitForExit(ctx)
+		close(exited)
+	}()
+
+	ch := make(chan TerminalChunk)
+	go func() {
+		defer close(ch)
+		send := func(c TerminalChunk) bool {
+			select {
+			case ch <- c:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		// last is the output as of the previous poll. Until the client
+		// truncates, each poll extends it and only the new suffix is sent. Once
+		// it truncates, the retained buffer can change without growing, so any
+		// change resends the whole buffer.
+		var last string
+		delta := func(resp TerminalOutputResponse) (TerminalChunk, bool) {
+			out := resp.Output
+			if resp.Truncated || len(out) < len(last) || !strings.HasPrefix(out, last) {
+				changed := out != last
+				last = out
+				return TerminalChunk{Output: out, Truncated: true}, changed
+			}
+			c := TerminalChunk{Output: out[len(last):]}
+			last = out
+			return c, c.Output != ""
+		}
+
+		if c, ok := delta(first); ok && !send(c) {
+			return
+		}
+		ticker := time.NewTicker(terminalStreamPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-exited:
+				if exitErr != nil {
+					send(TerminalChunk{Err: exitErr})
+					return
+				}
+				out, err := t.Output(ctx)
+				if err != nil {
+					send(TerminalChunk{Err: err})
+					return
+				}
+				c, ok := delta(out)
+				if !ok {
+					c = TerminalChunk{}
+				}
+				c.ExitStatus = &TerminalExitStatus{ExitCode: exit.ExitCode, Signal: exit.Signal}
+				send(c)
+				return
+			case <-ticker.C:
+				out, err := t.Output(ctx)
+				if err != nil {
+					send(TerminalChunk{Err: err})
+					return
+				}
+				if c, ok := delta(out); ok && !send(c) {
+					return
+				}
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// terminalKillTimeout bounds how long WaitOrKill waits for the client to kill the
+// command and report its exit status once ctx is done.
+const terminalKillTimeout = 5 * time.Second
+
+// WaitOrKill waits for the command to exit, like WaitForExit. If ctx is done
+// first, it kills the command and waits for its exit status, returning that
+// status together with ctx.Err(). If the command had already exited by then, the
+// kill is skipped.
+func (t *TerminalHandle) WaitOrKill(ctx context.Context) (WaitForTerminalExitResponse, error) {
+	resp, err := t.WaitForExit(ctx)
+	if err == nil || ctx.Err() == nil {
+		return resp, err
+	}
+
+	killCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), terminalKillTimeout)
+	defer cancel()
+	out, err := t.Output(killCtx)
+	if err != nil {
+		return WaitForTerminalExitResponse{}, err
+	}
+	if s := out.ExitStatus; s != nil {
+		return WaitForTerminalExitResponse{ExitCode: s.ExitCode, Signal: s.Signal}, nil
+	}
+	if err := t.Kill(killCtx); err != nil {
+		return WaitForTerminalExitResponse{}, err
+	}
+	resp, err = t.WaitForExit(killCtx)
+	if err != nil {
+		return WaitForTerminalExitResponse{}, err
+	}
+	return resp, ctx.Err()
+}
@@ -0,0 +1,332 @@
+package acp
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTerminalTestAgent connects an agent-side connection to the given client and
+// returns it.
+func newTerminalTestAgent(t *testing.T, client *clientFuncs) *AgentSideConnection {
+	t.Helper()
+	c2aR, c2aW := io.Pipe()
+	a2cR, a2cW := io.Pipe()
+	t.Cleanup(func() {
+		_ = c2aR.Close()
+		_ = c2aW.Close()
+		_ = a2cR.Close()
+		_ = a2cW.Close()
+	})
+	NewClientSideConnection(client, c2aW, a2cR)
+	return NewAgentSideConnection(agentFuncs{}, a2cW, c2aR)
+}
+
+func TestTerminalHandle(t *testing.T) {
+	var mu sync.Mutex
+	var calls []string
+	record := func(method string, sessionId SessionId, terminalId string) {
+		mu.Lock()
+		defer mu.Unlock()
+		if sessionId != "s1" || terminalId != "term-1" {
+			t.Errorf("%s: unexpected ids %q/%q", method, sessionId, terminalId)
+		}
+		calls = append(calls, method)
+	}
+	as := newTerminalTestAgent(t, &clientFuncs{
+		CreateTerminalFunc: func(_ context.Context, p CreateTerminalRequest) (CreateTerminalResponse, error) {
+			if p.Command != "make" {
+				t.Errorf("unexpected command %q", p.Command)
+			}
+			return CreateTerminalResponse{TerminalId: "term-1"}, nil
+		},
+		TerminalOutputFunc: func(_ context.Context, p TerminalOutputRequest) (TerminalOutputResponse, error) {
+			record("output", p.SessionId, p.TerminalId)
+			return TerminalOutputResponse{Output: "building"}, nil
+		},
+		WaitForTerminalExitFunc: func(_ context.Context, p WaitForTerminalExitRequest) (WaitForTerminalExitResponse, error) {
+			record("wait", p.SessionId, p.TerminalId)
+			return WaitForTerminalExitResponse{ExitCode: Ptr(0)}, nil
+		},
+		KillTerminalFunc: func(_ context.Context, p KillTerminalRequest) (KillTerminalResponse, error) {
+			record("kill", p.SessionId, p.TerminalId)
+			return KillTerminalResponse{}, nil
+		},
+		ReleaseTerminalFunc: func(_ context.Context, p ReleaseTerminalRequest) (ReleaseTerminalResponse, error) {
+			record("release", p.SessionId, p.TerminalId)
+			return ReleaseTerminalResponse{}, nil
+		},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	term, err := as.NewTerminal(ctx, CreateTerminalRequest{SessionId: "s1", Command: "make"})
+	if err != nil {
+		t.Fatalf("NewTerminal: %v", err)
+	}
+	if term.ID() != "term-1" || term.SessionId() != "s1" {
+		t.Fatalf("unexpected handle ids %q/%q", term.ID(), term.SessionId())
+	}
+	if ref := term.Content(); ref.Terminal == nil || ref.Terminal.TerminalId != "term-1" {
+		t.Fatalf("unexpected content: %+v", ref)
+	}
+	if out, err := term.Output(ctx); err != nil || out.Output != "building" {
+		t.Fatalf("Output: %+v, %v", out, err)
+	}
+	if exit, err := term.WaitForExit(ctx); err != nil || Deref(exit.ExitCode, -1) != 0 {
+		t.Fatalf("WaitForExit: %+v, %v", exit, err)
+	}
+	if err := term.Kill(ctx); err != nil {
+		t.Fatalf("Kill: %v", err)
+	}
+	if err := term.Release(ctx); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	if err := term.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{"output", "wait", "kill", "release"}
+	if len(calls) != len(want) {
+		t.Fatalf("calls = %v, want %v", calls, want)
+	}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Fatalf("calls = %v, want %v", calls, want)
+		}
+	}
+}
+
+func TestTerminalHandleStream_EmitsDeltasUntilExit(t *testing.T) {
+	var mu sync.Mutex
+	output := "line 1\n"
+	appendOutput := func(s string) {
+		mu.Lock()
+		output += s
+		mu.Unlock()
+	}
+	exit := make(chan struct{})
+	as := newTerminalTestAgent(t, &clientFuncs{
+		TerminalOutputFunc: func(context.Context, TerminalOutputRequest) (TerminalOutputResponse, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			return TerminalOutputResponse{Output: output}, nil
+		},
+		WaitForTerminalExitFunc: func(ctx context.Context, _ WaitForTerminalExitRequest) (WaitForTerminalExitResponse, error) {
+			select {
+			case <-exit:
+				return WaitForTerminalExitResponse{ExitCode: Ptr(2)}, nil
+			case <-ctx.Done():
+				return WaitForTerminalExitResponse{}, ctx.Err()
+			}
+		},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	term, err := as.NewTerminal(ctx, CreateTerminalRequest{SessionId: "s1", Command: "make"})
+	if err != nil {
+		t.Fatalf("NewTerminal: %v", err)
+	}
+	chunks, err := term.Stream(ctx)
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+
+	next := func() TerminalChunk {
+		t.Helper()
+		select {
+		case c, ok := <-chunks:
+			if !ok {
+				t.Fatal("stream closed early")
+			}
+			return c
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for chunk")
+		}
+		return TerminalChunk{}
+	}
+
+	if c := next(); c.Output != "line 1\n" || c.ExitStatus != nil {
+		t.Fatalf("unexpected first chunk: %+v", c)
+	}
+	appendOutput("line 2\n")
+	if c := next(); c.Output != "line 2\n" || c.ExitStatus != nil {
+		t.Fatalf("unexpected second chunk: %+v", c)
+	}
+	appendOutput("done\n")
+	close(exit)
+
+	var tail string
+	var last TerminalChunk
+	for c := range chunks {
+		tail += c.Output
+		last = c
+	}
+	if tail != "done\n" {
+		t.Fatalf("unexpected trailing output %q", tail)
+	}
+	if last.Err != nil || last.ExitStatus == nil || Deref(last.ExitStatus.ExitCode, -1) != 2 {
+		t.Fatalf("unexpected final chunk: %+v", last)
+	}
+}
+
+func TestTerminalHandleStream_ResyncsWhenTruncated(t *testing.T) {
+	// The client keeps only the last 8 bytes, so once it truncates the buffer
+	// changes without growing.
+	const limit = 8
+	var mu sync.Mutex
+	var output string
+	var truncated bool
+	appendOutput := func(s string) {
+		mu.Lock()
+		defer mu.Unlock()
+		output += s
+		if len(output) > limit {
+			output = output[len(output)-limit:]
+			truncated = true
+		}
+	}
+	appendOutput("abcdefgh")
+	exit := make(chan struct{})
+	as := newTerminalTestAgent(t, &clientFuncs{
+		TerminalOutputFunc: func(context.Context, TerminalOutputRequest) (TerminalOutputResponse, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			return TerminalOutputResponse{Output: output, Truncated: truncated}, nil
+		},
+		WaitForTerminalExitFunc: func(ctx context.Context, _ WaitForTerminalExitRequest) (WaitForTerminalExitResponse, error) {
+			select {
+			case <-exit:
+				return WaitForTerminalExitResponse{ExitCode: Ptr(0)}, nil
+			case <-ctx.Done():
+				return WaitForTerminalExitResponse{}, ctx.Err()
+			}
+		},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	term, err := as.NewTerminal(ctx, CreateTerminalRequest{SessionId: "s1", Command: "yes"})
+	if err != nil {
+		t.Fatalf("NewTerminal: %v", err)
+	}
+	chunks, err := term.Stream(ctx)
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	next := func() TerminalChunk {
+		t.Helper()
+		select {
+		case c, ok := <-chunks:
+			if !ok {
+				t.Fatal("stream closed early")
+			}
+			return c
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for chunk")
+		}
+		return TerminalChunk{}
+	}
+
+	if c := next(); c.Output != "abcdefgh" || c.Truncated {
+		t.Fatalf("unexpected first chunk: %+v", c)
+	}
+	appendOutput("ij")
+	if c := next(); c.Output != "cdefghij" || !c.Truncated {
+		t.Fatalf("chunk after truncation = %+v, want the retained output marked truncated", c)
+	}
+	select {
+	case c := <-chunks:
+		t.Fatalf("unexpected chunk while the truncated output is unchanged: %+v", c)
+	case <-time.After(3 * terminalStreamPollInterval):
+	}
+	appendOutput("kl")
+	if c := next(); c.Output != "efghijkl" || !c.Truncated {
+		t.Fatalf("chunk after further output = %+v, want the retained output marked truncated", c)
+	}
+	close(exit)
+	for c := range chunks {
+		if c.Output != "" {
+			t.Fatalf("unexpected trailing output %q", c.Output)
+		}
+		if c.ExitStatus == nil {
+			t.Fatalf("unexpected final chunk: %+v", c)
+		}
+	}
+}
+
+func TestTerminalHandleWaitOrKill(t *testing.T) {
+	newAgent := func(t *testing.T, exited bool, killed chan struct{}) *AgentSideConnection {
+		var once sync.Once
+		return newTerminalTestAgent(t, &clientFuncs{
+			TerminalOutputFunc: func(context.Context, TerminalOutputRequest) (TerminalOutputResponse, error) {
+				resp := TerminalOutputResponse{Output: "sleeping"}
+				if exited {
+					resp.ExitStatus = &TerminalExitStatus{ExitCode: Ptr(0)}
+				}
+				return resp, nil
+			},
+			WaitForTerminalExitFunc: func(ctx context.Context, _ WaitForTerminalExitRequest) (WaitForTerminalExitResponse, error) {
+				select {
+				case <-killed:
+					return WaitForTerminalExitResponse{Signal: Ptr("SIGKILL")}, nil
+				case <-ctx.Done():
+					return WaitForTerminalExitResponse{}, ctx.Err()
+				}
+			},
+			KillTerminalFunc: func(context.Context, KillTerminalRequest) (KillTerminalResponse, error) {
+				once.Do(func() { close(killed) })
+				return KillTerminalResponse{}, nil
+			},
+		})
+	}
+
+	t.Run("kills on deadline", func(t *testing.T) {
+		killed := make(chan struct{})
+		as := newAgent(t, false, killed)
+		term, err := as.NewTerminal(context.Background(), CreateTerminalRequest{SessionId: "s1", Command: "sleep"})
+		if err != nil {
+			t.Fatalf("NewTerminal: %v", err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		resp, err := term.WaitOrKill(ctx)
+		if err != context.DeadlineExceeded {
+			t.Fatalf("expected deadline exceeded, got %v", err)
+		}
+		if Deref(resp.Signal, "") != "SIGKILL" {
+			t.Fatalf("unexpected exit status: %+v", resp)
+		}
+	})
+
+	t.Run("skips kill when already exited", func(t *testing.T) {
+		killed := make(chan struct{})
+		as := newAgent(t, true, killed)
+		term, err := as.NewTerminal(context.Background(), CreateTerminalRequest{SessionId: "s1", Command: "true"})
+		if err != nil {
+			t.Fatalf("NewTerminal: %v", err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		resp, err := term.WaitOrKill(ctx)
+		if err != nil {
+			t.Fatalf("WaitOrKill: %v", err)
+		}
+		if Deref(resp.ExitCode, -1) != 0 {
+			t.Fatalf("unexpected exit status: %+v", resp)
+		}
+		select {
+		case <-killed:
+			t.Fatal("kill was sent for an exited command")
+		default:
+		}
+	})
+}
@@ -0,0 +1,83 @@
+package acp
+
+// ToolCallBuilder assembles a ToolCallUpdate fluently, taking care of optional
+// pointer fields. Create one with NewToolCall.
+type ToolCallBuilder struct {
+	u ToolCallUpdate
+}
+
+// NewToolCall starts building a ToolCallUpdate for the given tool call ID.
+func NewToolCall(id ToolCallId) *ToolCallBuilder {
+	return &ToolCallBuilder{u: ToolCallUpdate{ToolCallId: id}}
+}
+
+// Title sets the human-readable title.
+func (b *ToolCallBuilder) Title(title string) *ToolCallBuilder {
+	b.u.Title = Ptr(title)
+	return b
+}
+
+// Kind sets the tool kind.
+func (b *ToolCallBuilder) Kind(kind ToolKind) *ToolCallBuilder {
+	b.u.Kind = Ptr(kind)
+	return b
+}
+
+// Status sets the execution status.
+func (b *ToolCallBuilder) Status(status ToolCallStatus) *ToolCallBuilder {
+	b.u.Status = Ptr(status)
+	return b
+}
+
+// AddContent appends a content block to the tool call's content.
+func (b *ToolCallBuilder) AddContent(block ContentBlock) *ToolCallBuilder {
+	b.u.Content = append(b.u.Content, ToolContent(block))
+	return b
+}
+
+// AddDiff appends a diff of the file at path. An empty oldText denotes a new file.
+func (b *ToolCallBuilder) AddDiff(path string, oldText string, newText string) *ToolCallBuilder {
+	if oldText == "" {
+		b.u.Content = append(b.u.Content, ToolDiffContent(path, newText))
+	} else {
+		b.u.Content = append(b.u.Content, ToolDiffContent(path, newText, oldText))
+	}
+	return b
+}
+
+// AddTerminal appends a reference to a terminal created with CreateTerminal.
+func (b *ToolCallBuilder) AddTerminal(terminalID string) *ToolCallBuilder {
+	b.u.Content = append(b.u.Content, ToolTerminalRef(terminalID))
+	return b
+}
+
+// AddLocation appends a file location affected by the tool call.
+func (b *ToolCallBuilder) AddLocation(path string) *ToolCallBuilder {
+	b.u.Locations = append(b.u.Locations, ToolCallLocation{Path: path})
+	return b
+}
+
+// RawInput sets the raw input parameters sent to the tool.
+func (b *ToolCallBuilder) RawInput(v any) *ToolCallBuilder {
+	b.u.RawInput = v
+	return b
+}
+
+// RawOutput sets the raw output returned by the tool.
+func (b *ToolCallBuilder) RawOutput(v any) *ToolCallBuilder {
+	b.u.RawOutput = v
+	return b
+}
+
+// Build returns the assembled ToolCallUpdate, e.g. for a RequestPermissionRequest.
+func (b *ToolCallBuilder) Build() ToolCallUpdate {
+	u := b.u
+	u.Content = append([]ToolCallContent(nil), b.u.Content...)
+	u.Locations = append([]ToolCallLocation(nil), b.u.Locations...)
+	return u
+}
+
+// SessionUpdate returns the assembled update as a tool_call_update session update.
+func (b *ToolCallBuilder) SessionUpdate() SessionUpdate {
+	return ToolCallUpdateNotification("", b.Build()).Update
+}
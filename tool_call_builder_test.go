@@ -0,0 +1,52 @@
+package acp
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestToolCallBuilder(t *testing.T) {
+	b := NewToolCall("call_1").
+		Title("Editing config").
+		Kind(ToolKindEdit).
+		Status(ToolCallStatusPending).
+		AddLocation("/project/config.json").
+		AddContent(TextBlock("Updating timeout")).
+		AddDiff("/project/config.json", `{"timeout":5}`, `{"timeout":30}`).
+		AddDiff("/project/new.json", "", "{}")
+
+	u := b.Build()
+	if err := u.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if Deref(u.Title, "") != "Editing config" || *u.Kind != ToolKindEdit || *u.Status != ToolCallStatusPending {
+		t.Fatalf("unexpected fields: %+v", u)
+	}
+	if len(u.Content) != 3 || u.Content[0].Content == nil || u.Content[1].Diff == nil {
+		t.Fatalf("unexpected content: %+v", u.Content)
+	}
+	if d := u.Content[1].Diff; d.OldText == nil || *d.OldText != `{"timeout":5}` || d.NewText != `{"timeout":30}` {
+		t.Fatalf("unexpected diff: %+v", d)
+	}
+	if d := u.Content[2].Diff; d.OldText != nil {
+		t.Fatalf("expected nil oldText for new file, got %q", *d.OldText)
+	}
+
+	// Further building must not alter an already built update.
+	b.AddContent(TextBlock("more"))
+	if len(u.Content) != 3 {
+		t.Fatalf("built update was mutated: %d content items", len(u.Content))
+	}
+
+	raw, err := json.Marshal(b.SessionUpdate())
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var back SessionUpdate
+	if err := json.Unmarshal(raw, &back); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if back.ToolCallUpdate == nil || back.ToolCallUpdate.ToolCallId != "call_1" || len(back.ToolCallUpdate.Content) != 4 {
+		t.Fatalf("unexpected round-trip: %s", raw)
+	}
+}
@@ -0,0 +1,118 @@
+package acp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+)
+
+// Propagator injects and extracts trace context through the "_meta" object of
+// JSON-RPC params. ACP reserves the W3C trace context keys ("traceparent",
+// "tracestate", "baggage") at the root of "_meta" for this purpose.
+//
+// OpenTelemetry propagators can be adapted by wrapping the carrier, e.g.
+// propagation.MapCarrier(carrier).
+type Propagator interface {
+	// Inject writes the trace context carried by ctx into carrier.
+	Inject(ctx context.Context, carrier map[string]string)
+	// Extract returns a copy of ctx with the trace context found in carrier.
+	Extract(ctx context.Context, carrier map[string]string) context.Context
+}
+
+// SpanKind describes the role of a span started by a Tracer.
+type SpanKind int
+
+const (
+	// SpanKindClient marks a span around an outbound request.
+	SpanKindClient SpanKind = iota
+	// SpanKindServer marks a span around an inbound request handler.
+	SpanKindServer
+)
+
+// Tracer starts spans around outbound requests and inbound request handlers.
+// The returned function ends the span and receives the request outcome.
+type Tracer interface {
+	Start(ctx context.Context, method string, kind SpanKind) (context.Context, func(err *RequestError))
+}
+
+// WithPropagator installs a Propagator used to carry trace context in "_meta".
+// Without one, params are sent and received unchanged.
+func WithPropagator(p Propagator) ConnectionOption {
+	return func(c *Connection) {
+		c.propagator = p
+	}
+}
+
+// WithTracer installs a Tracer that starts a client span for each outbound
+// request and a server span for each inbound request.
+func WithTracer(t Tracer) ConnectionOption {
+	return func(c *Connection) {
+		c.tracer = t
+	}
+}
+
+func (c *Connection) startSpan(ctx context.Context, method string, kind SpanKind) (context.Context, func(err *RequestError)) {
+	if c.tracer == nil {
+		return ctx, func(*RequestError) {}
+	}
+	return c.tracer.Start(ctx, method, kind)
+}
+
+// injectTraceContext merges the trace context from ctx into the "_meta" object of
+// params. Params that are not a JSON object are returned unchanged.
+func (c *Connection) injectTraceContext(ctx context.Context, params json.RawMessage) json.RawMessage {
+	if c.propagator == nil {
+		return params
+	}
+	carrier := make(map[string]string)
+	c.propagator.Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return params
+	}
+
+	obj := make(map[string]json.RawMessage)
+	if trimmed := bytes.TrimSpace(params); len(trimmed) > 0 && !bytes.Equal(trimmed, []byte("null")) {
+		if err := json.Unmarshal(trimmed, &obj); err != nil {
+			return params
+		}
+	}
+	meta := make(map[string]json.RawMessage)
+	if raw, ok := obj["_meta"]; ok {
+		if err := json.Unmarshal(raw, &meta); err != nil || meta == nil {
+			return params
+		}
+	}
+	for k, v := range carrier {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return params
+		}
+		meta[k] = b
+	}
+	metaRaw, err := json.Marshal(meta)
+	if err != nil {
+		return params
+	}
+	obj["_meta"] = metaRaw
+	out, err := json.Marshal(obj)
+	if err != nil {
+		return params
+	}
+	return out
+}
+
+// extractTraceContext returns ctx enriched with any trace context found in the
+// "_meta" object of an inbound message.
+func (c *Connection) extractTraceContext(ctx context.Context, meta map[string]json.RawMessage) context.Context {
+	if c.propagator == nil || len(meta) == 0 {
+		return ctx
+	}
+	carrier := make(map[string]string, len(meta))
+	for k, raw := range meta {
+		var v string
+		if err := json.Unmarshal(raw, &v); err == nil {
+			carrier[k] = v
+		}
+	}
+	return c.propagator.Extract(ctx, carrier)
+}
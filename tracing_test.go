@@ -0,0 +1,123 @@
+package acp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+type traceIDKey struct{}
+
+type testPropagator struct{}
+
+func (testPropagator) Inject(ctx context.Context, carrier map[string]string) {
+	if id, ok := ctx.Value(traceIDKey{}).(string); ok {
+		carrier["traceparent"] = id
+	}
+}
+
+func (testPropagator) Extract(ctx context.Context, carrier map[string]string) context.Context {
+	if id, ok := carrier["traceparent"]; ok {
+		return context.WithValue(ctx, traceIDKey{}, id)
+	}
+	return ctx
+}
+
+type recordingTracer struct {
+	mu    sync.Mutex
+	spans []string
+}
+
+func (r *recordingTracer) Start(ctx context.Context, method string, kind SpanKind) (context.Context, func(*RequestError)) {
+	parent, _ := ctx.Value(traceIDKey{}).(string)
+	return ctx, func(err *RequestError) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.spans = append(r.spans, fmt.Sprintf("%s kind=%d parent=%s err=%t", method, kind, parent, err != nil))
+	}
+}
+
+func (r *recordingTracer) snapshot() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.spans...)
+}
+
+func TestPropagator_CarriesTraceContextInMeta(t *testing.T) {
+	c2aR, c2aW := io.Pipe()
+	a2cR, a2cW := io.Pipe()
+	defer func() {
+		_ = c2aR.Close()
+		_ = c2aW.Close()
+		_ = a2cR.Close()
+		_ = a2cW.Close()
+	}()
+
+	type received struct {
+		traceID string
+		params  map[string]any
+	}
+	got := make(chan received, 1)
+	serverTracer := &recordingTracer{}
+	_ = NewConnection(func(ctx context.Context, method string, params json.RawMessage) (any, *RequestError) {
+		var p map[string]any
+		_ = json.Unmarshal(params, &p)
+		id, _ := ctx.Value(traceIDKey{}).(string)
+		got <- received{traceID: id, params: p}
+		return map[string]any{}, nil
+	}, a2cW, c2aR, WithPropagator(testPropagator{}), WithTracer(serverTracer))
+
+	clientTracer := &recordingTracer{}
+	c := NewConnection(nil, c2aW, a2cR, WithPropagator(testPropagator{}), WithTracer(clientTracer))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	ctx = context.WithValue(ctx, traceIDKey{}, "00-abc-def-01")
+
+	params := map[string]any{"x": 1, "_meta": map[string]any{"tenant": "t1"}}
+	if err := c.SendRequestNoResult(ctx, "traced", params); err != nil {
+		t.Fatalf("SendRequestNoResult: %v", err)
+	}
+
+	r := <-got
+	if r.traceID != "00-abc-def-01" {
+		t.Fatalf("handler trace id = %q", r.traceID)
+	}
+	meta, _ := r.params["_meta"].(map[string]any)
+	if meta["tenant"] != "t1" || meta["traceparent"] != "00-abc-def-01" {
+		t.Fatalf("unexpected _meta: %v", r.params["_meta"])
+	}
+	if r.params["x"] != float64(1) {
+		t.Fatalf("params not preserved: %v", r.params)
+	}
+
+	if spans := clientTracer.snapshot(); len(spans) != 1 || spans[0] != "traced kind=0 parent=00-abc-def-01 err=false" {
+		t.Fatalf("unexpected client spans: %v", spans)
+	}
+	// The server span ends after the response is written, so allow it to catch up.
+	deadline := time.Now().Add(2 * time.Second)
+	for len(serverTracer.snapshot()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if spans := serverTracer.snapshot(); len(spans) != 1 || spans[0] != "traced kind=1 parent=00-abc-def-01 err=false" {
+		t.Fatalf("unexpected server spans: %v", spans)
+	}
+}
+
+func TestPropagator_NoopWithoutTraceContext(t *testing.T) {
+	c := &Connection{propagator: testPropagator{}}
+	params := json.RawMessage(`{"x":1}`)
+	if out := c.injectTraceContext(context.Background(), params); string(out) != string(params) {
+		t.Fatalf("params changed without trace context: %s", out)
+	}
+
+	c = &Connection{}
+	ctx := context.WithValue(context.Background(), traceIDKey{}, "id")
+	if out := c.injectTraceContext(ctx, params); string(out) != string(params) {
+		t.Fatalf("params changed without propagator: %s", out)
+	}
+}
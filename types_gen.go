@@ -76,13 +76,6 @@ type AgentCapabilities struct {
 	SessionCapabilities SessionCapabilities `json:"sessionCapabilities,omitempty"`
 }
 
-func (v AgentCapabilities) MarshalJSON() ([]byte, error) {
-	type Alias AgentCapabilities
-	var a Alias
-	a = Alias(v)
-	return json.Marshal(a)
-}
-
 func (v *AgentCapabilities) UnmarshalJSON(b []byte) error {
 	var m map[string]json.RawMessage
 	if err := json.Unmarshal(b, &m); err != nil {
@@ -134,7 +127,7 @@ type AgentNotification struct {
 
 func (v *AgentNotification) Validate() error {
 	if v.Method == "" {
-		return fmt.Errorf("method is required")
+		return newValidationError("method", "required", "method is required")
 	}
 	return nil
 }
@@ -147,7 +140,7 @@ type AgentRequest struct {
 
 func (v *AgentRequest) Validate() error {
 	if v.Method == "" {
-		return fmt.Errorf("method is required")
+		return newValidationError("method", "required", "method is required")
 	}
 	return nil
 }
@@ -257,7 +250,7 @@ func (u AgentResponse) MarshalJSON() ([]byte, error) {
 		}
 		return json.Marshal(m)
 	}
-	return []byte{}, nil
+	return nil, errors.New("AgentResponse has no variant set")
 }
 
 // Optional annotations for the client. The client can use annotations to inform how objects are used or displayed
@@ -310,13 +303,6 @@ type AuthCapabilities struct {
 	Terminal bool `json:"terminal,omitempty"`
 }
 
-func (v AuthCapabilities) MarshalJSON() ([]byte, error) {
-	type Alias AuthCapabilities
-	var a Alias
-	a = Alias(v)
-	return json.Marshal(a)
-}
-
 func (v *AuthCapabilities) UnmarshalJSON(b []byte) error {
 	var m map[string]json.RawMessage
 	if err := json.Unmarshal(b, &m); err != nil {
@@ -368,13 +354,6 @@ type AuthEnvVar struct {
 	Secret bool `json:"secret,omitempty"`
 }
 
-func (v AuthEnvVar) MarshalJSON() ([]byte, error) {
-	type Alias AuthEnvVar
-	var a Alias
-	a = Alias(v)
-	return json.Marshal(a)
-}
-
 func (v *AuthEnvVar) UnmarshalJSON(b []byte) error {
 	var m map[string]json.RawMessage
 	if err := json.Unmarshal(b, &m); err != nil {
@@ -447,7 +426,7 @@ type AuthMethodTerminalInline struct {
 	// Optional description providing more details about this authentication method.
 	Description *string `json:"description,omitempty"`
 	// Additional environment variables to set when running the agent binary for terminal auth.
-	Env map[string]any `json:"env,omitempty"`
+	Env map[string]string `json:"env,omitempty"`
 	// Unique identifier for this authentication method.
 	Id string `json:"id"`
 	// Human-readable name of the authentication method.
@@ -626,7 +605,49 @@ func (u AuthMethod) MarshalJSON() ([]byte, error) {
 		}
 		return json.Marshal(m)
 	}
-	return []byte{}, nil
+	return nil, errors.New("AuthMethod has no variant set")
+}
+
+// validateVariant checks that exactly one variant is set and that the set
+// variant's required string fields are non-empty.
+func (u *AuthMethod) validateVariant() error {
+	var count int
+	if u.EnvVar != nil {
+		count++
+	}
+	if u.Terminal != nil {
+		count++
+	}
+	if u.Agent != nil {
+		count++
+	}
+	if count != 1 {
+		return fmt.Errorf("AuthMethod must have exactly one variant set, got %d", count)
+	}
+	switch {
+	case u.EnvVar != nil:
+		if u.EnvVar.Name == "" {
+			return errors.New("env_var: name is required")
+		}
+		if u.EnvVar.Id == "" {
+			return fmt.Errorf("env_var %q: id is required", u.EnvVar.Name)
+		}
+	case u.Terminal != nil:
+		if u.Terminal.Name == "" {
+			return errors.New("terminal: name is required")
+		}
+		if u.Terminal.Id == "" {
+			return fmt.Errorf("terminal %q: id is required", u.Terminal.Name)
+		}
+	case u.Agent != nil:
+		if u.Agent.Name == "" {
+			return errors.New("agent: name is required")
+		}
+		if u.Agent.Id == "" {
+			return fmt.Errorf("agent %q: id is required", u.Agent.Name)
+		}
+	}
+	return nil
 }
 
 // Agent handles authentication itself.
@@ -692,7 +713,7 @@ type AuthMethodTerminal struct {
 	// Optional description providing more details about this authentication method.
 	Description *string `json:"description,omitempty"`
 	// Additional environment variables to set when running the agent binary for terminal auth.
-	Env map[string]any `json:"env,omitempty"`
+	Env map[string]string `json:"env,omitempty"`
 	// Unique identifier for this authentication method.
 	Id string `json:"id"`
 	// Human-readable name of the authentication method.
@@ -716,7 +737,7 @@ type AuthenticateRequest struct {
 
 func (v *AuthenticateRequest) Validate() error {
 	if v.MethodId == "" {
-		return fmt.Errorf("methodId is required")
+		return newValidationError("methodId", "required", "methodId is required")
 	}
 	return nil
 }
@@ -803,7 +824,26 @@ func (u AvailableCommandInput) MarshalJSON() ([]byte, error) {
 		}
 		return json.Marshal(m)
 	}
-	return []byte{}, nil
+	return nil, errors.New("AvailableCommandInput has no variant set")
+}
+
+// validateVariant checks that exactly one variant is set and that the set
+// variant's required string fields are non-empty.
+func (u *AvailableCommandInput) validateVariant() error {
+	var count int
+	if u.Unstructured != nil {
+		count++
+	}
+	if count != 1 {
+		return fmt.Errorf("AvailableCommandInput must have exactly one variant set, got %d", count)
+	}
+	switch {
+	case u.Unstructured != nil:
+		if u.Unstructured.Hint == "" {
+			return fmt.Errorf("unstructured: hint is required")
+		}
+	}
+	return nil
 }
 
 // Available commands are ready or have changed
@@ -911,13 +951,6 @@ type ClientCapabilities struct {
 	Terminal bool `json:"terminal,omitempty"`
 }
 
-func (v ClientCapabilities) MarshalJSON() ([]byte, error) {
-	type Alias ClientCapabilities
-	var a Alias
-	a = Alias(v)
-	return json.Marshal(a)
-}
-
 func (v *ClientCapabilities) UnmarshalJSON(b []byte) error {
 	var m map[string]json.RawMessage
 	if err := json.Unmarshal(b, &m); err != nil {
@@ -973,7 +1006,7 @@ type ClientNotification struct {
 
 func (v *ClientNotification) Validate() error {
 	if v.Method == "" {
-		return fmt.Errorf("method is required")
+		return newValidationError("method", "required", "method is required")
 	}
 	return nil
 }
@@ -986,7 +1019,7 @@ type ClientRequest struct {
 
 func (v *ClientRequest) Validate() error {
 	if v.Method == "" {
-		return fmt.Errorf("method is required")
+		return newValidationError("method", "required", "method is required")
 	}
 	return nil
 }
@@ -1096,7 +1129,7 @@ func (u ClientResponse) MarshalJSON() ([]byte, error) {
 		}
 		return json.Marshal(m)
 	}
-	return []byte{}, nil
+	return nil, errors.New("ClientResponse has no variant set")
 }
 
 // Request parameters for closing an active session.
@@ -1330,100 +1363,10 @@ func (u *ContentBlock) UnmarshalJSON(b []byte) error {
 				u.Resource = &v
 				return nil
 			}
-		}
-		{
-			var v ContentBlockText
-			var match bool = true
-			if _, ok := m["type"]; !ok {
-				match = false
-			}
-			if _, ok := m["text"]; !ok {
-				match = false
-			}
-			if match {
-				if json.Unmarshal(b, &v) != nil {
-					return errors.New("invalid variant payload")
-				}
-				u.Text = &v
-				return nil
-			}
-		}
-		{
-			var v ContentBlockImage
-			var match bool = true
-			if _, ok := m["type"]; !ok {
-				match = false
-			}
-			if _, ok := m["data"]; !ok {
-				match = false
-			}
-			if _, ok := m["mimeType"]; !ok {
-				match = false
-			}
-			if match {
-				if json.Unmarshal(b, &v) != nil {
-					return errors.New("invalid variant payload")
-				}
-				u.Image = &v
-				return nil
-			}
-		}
-		{
-			var v ContentBlockAudio
-			var match bool = true
-			if _, ok := m["type"]; !ok {
-				match = false
-			}
-			if _, ok := m["data"]; !ok {
-				match = false
-			}
-			if _, ok := m["mimeType"]; !ok {
-				match = false
-			}
-			if match {
-				if json.Unmarshal(b, &v) != nil {
-					return errors.New("invalid variant payload")
-				}
-				u.Audio = &v
-				return nil
-			}
-		}
-		{
-			var v ContentBlockResourceLink
-			var match bool = true
-			if _, ok := m["type"]; !ok {
-				match = false
-			}
-			if _, ok := m["name"]; !ok {
-				match = false
-			}
-			if _, ok := m["uri"]; !ok {
-				match = false
-			}
-			if match {
-				if json.Unmarshal(b, &v) != nil {
-					return errors.New("invalid variant payload")
-				}
-				u.ResourceLink = &v
-				return nil
-			}
-		}
-		{
-			var v ContentBlockResource
-			var match bool = true
-			if _, ok := m["type"]; !ok {
-				match = false
-			}
-			if _, ok := m["resource"]; !ok {
-				match = false
-			}
-			if match {
-				if json.Unmarshal(b, &v) != nil {
-					return errors.New("invalid variant payload")
-				}
-				u.Resource = &v
-				return nil
+			if disc == "" {
+				return fmt.Errorf("ContentBlock: missing \"type\" discriminator")
 			}
+			return fmt.Errorf("ContentBlock: unknown type %q", disc)
 		}
 	} else {
 		if _, ok := err.(*json.UnmarshalTypeError); !ok {
@@ -1471,6 +1414,9 @@ func (u *ContentBlock) UnmarshalJSON(b []byte) error {
 	return errors.New("no matching variant for union")
 }
 func (u ContentBlock) MarshalJSON() ([]byte, error) {
+	if err := u.Validate(); err != nil {
+		return nil, err
+	}
 	if u.Text != nil {
 		_b, _e := json.Marshal(*u.Text)
 		if _e != nil {
@@ -1579,7 +1525,7 @@ func (u ContentBlock) MarshalJSON() ([]byte, error) {
 			return json.Marshal(nm)
 		}
 	}
-	return []byte{}, nil
+	return nil, errors.New("ContentBlock has no variant set")
 }
 
 func (u *ContentBlock) Validate() error {
@@ -1600,7 +1546,7 @@ func (u *ContentBlock) Validate() error {
 		count++
 	}
 	if count != 1 {
-		return errors.New("ContentBlock must have exactly one variant set")
+		return fmt.Errorf("ContentBlock must have exactly one variant set, got %d", count)
 	}
 	return nil
 }
@@ -1670,7 +1616,10 @@ type CreateTerminalRequest struct {
 
 func (v *CreateTerminalRequest) Validate() error {
 	if v.Command == "" {
-		return fmt.Errorf("command is required")
+		return newValidationError("command", "required", "command is required")
+	}
+	if v.OutputByteLimit != nil && *v.OutputByteLimit < 0 {
+		return newValidationError("outputByteLimit", "below minimum", "outputByteLimit must be >= 0, got %v", *v.OutputByteLimit)
 	}
 	return nil
 }
@@ -1689,7 +1638,7 @@ type CreateTerminalResponse struct {
 
 func (v *CreateTerminalResponse) Validate() error {
 	if v.TerminalId == "" {
-		return fmt.Errorf("terminalId is required")
+		return newValidationError("terminalId", "required", "terminalId is required")
 	}
 	return nil
 }
@@ -1876,7 +1825,39 @@ func (u EmbeddedResourceResource) MarshalJSON() ([]byte, error) {
 		}
 		return json.Marshal(m)
 	}
-	return []byte{}, nil
+	return nil, errors.New("EmbeddedResourceResource has no variant set")
+}
+
+// validateVariant checks that exactly one variant is set and that the set
+// variant's required string fields are non-empty.
+func (u *EmbeddedResourceResource) validateVariant() error {
+	var count int
+	if u.TextResourceContents != nil {
+		count++
+	}
+	if u.BlobResourceContents != nil {
+		count++
+	}
+	if count != 1 {
+		return fmt.Errorf("EmbeddedResourceResource must have exactly one variant set, got %d", count)
+	}
+	switch {
+	case u.TextResourceContents != nil:
+		if u.TextResourceContents.Text == "" {
+			return fmt.Errorf("textresourcecontents: text is required")
+		}
+		if u.TextResourceContents.Uri == "" {
+			return fmt.Errorf("textresourcecontents: uri is required")
+		}
+	case u.BlobResourceContents != nil:
+		if u.BlobResourceContents.Blob == "" {
+			return fmt.Errorf("blobresourcecontents: blob is required")
+		}
+		if u.BlobResourceContents.Uri == "" {
+			return fmt.Errorf("blobresourcecontents: uri is required")
+		}
+	}
+	return nil
 }
 
 // An environment variable to set when launching an MCP server.
@@ -2128,7 +2109,7 @@ func (u ErrorCode) MarshalJSON() ([]byte, error) {
 		}
 		return json.Marshal(m)
 	}
-	return []byte{}, nil
+	return nil, errors.New("ErrorCode has no variant set")
 }
 
 // Allows the Agent to send an arbitrary notification that is not part of the ACP spec.
@@ -2175,13 +2156,6 @@ type FileSystemCapabilities struct {
 	WriteTextFile bool `json:"writeTextFile,omitempty"`
 }
 
-func (v FileSystemCapabilities) MarshalJSON() ([]byte, error) {
-	type Alias FileSystemCapabilities
-	var a Alias
-	a = Alias(v)
-	return json.Marshal(a)
-}
-
 func (v *FileSystemCapabilities) UnmarshalJSON(b []byte) error {
 	var m map[string]json.RawMessage
 	if err := json.Unmarshal(b, &m); err != nil {
@@ -2283,13 +2257,6 @@ type InitializeRequest struct {
 	ProtocolVersion ProtocolVersion `json:"protocolVersion"`
 }
 
-func (v InitializeRequest) MarshalJSON() ([]byte, error) {
-	type Alias InitializeRequest
-	var a Alias
-	a = Alias(v)
-	return json.Marshal(a)
-}
-
 func (v *InitializeRequest) UnmarshalJSON(b []byte) error {
 	var m map[string]json.RawMessage
 	if err := json.Unmarshal(b, &m); err != nil {
@@ -2311,6 +2278,12 @@ func (v *InitializeRequest) UnmarshalJSON(b []byte) error {
 }
 
 func (v *InitializeRequest) Validate() error {
+	if v.ProtocolVersion < 0 {
+		return newValidationError("protocolVersion", "below minimum", "protocolVersion must be >= 0, got %v", v.ProtocolVersion)
+	}
+	if v.ProtocolVersion > 65535 {
+		return newValidationError("protocolVersion", "above maximum", "protocolVersion must be <= 65535, got %v", v.ProtocolVersion)
+	}
 	return nil
 }
 
@@ -2337,7 +2310,7 @@ type InitializeResponse struct {
 	// Authentication methods supported by the agent.
 	//
 	// Defaults to [] if unset.
-	AuthMethods []AuthMethod `json:"authMethods"`
+	AuthMethods []AuthMethod `json:"authMethods,omitempty"`
 	// The protocol version the client specified if supported by the agent,
 	// or the latest protocol version supported by the agent.
 	//
@@ -2345,16 +2318,6 @@ type InitializeResponse struct {
 	ProtocolVersion ProtocolVersion `json:"protocolVersion"`
 }
 
-func (v InitializeResponse) MarshalJSON() ([]byte, error) {
-	type Alias InitializeResponse
-	var a Alias
-	a = Alias(v)
-	if a.AuthMethods == nil {
-		json.Unmarshal([]byte("[]"), &a.AuthMethods)
-	}
-	return json.Marshal(a)
-}
-
 func (v *InitializeResponse) UnmarshalJSON(b []byte) error {
 	var m map[string]json.RawMessage
 	if err := json.Unmarshal(b, &m); err != nil {
@@ -2382,6 +2345,12 @@ func (v *InitializeResponse) UnmarshalJSON(b []byte) error {
 }
 
 func (v *InitializeResponse) Validate() error {
+	if v.ProtocolVersion < 0 {
+		return newValidationError("protocolVersion", "below minimum", "protocolVersion must be >= 0, got %v", v.ProtocolVersion)
+	}
+	if v.ProtocolVersion > 65535 {
+		return newValidationError("protocolVersion", "above maximum", "protocolVersion must be <= 65535, got %v", v.ProtocolVersion)
+	}
 	return nil
 }
 
@@ -2401,7 +2370,7 @@ type KillTerminalRequest struct {
 
 func (v *KillTerminalRequest) Validate() error {
 	if v.TerminalId == "" {
-		return fmt.Errorf("terminalId is required")
+		return newValidationError("terminalId", "required", "terminalId is required")
 	}
 	return nil
 }
@@ -2457,7 +2426,7 @@ type ListSessionsResponse struct {
 
 func (v *ListSessionsResponse) Validate() error {
 	if v.Sessions == nil {
-		return fmt.Errorf("sessions is required")
+		return newValidationError("sessions", "required", "sessions is required")
 	}
 	return nil
 }
@@ -2491,10 +2460,10 @@ type LoadSessionRequest struct {
 
 func (v *LoadSessionRequest) Validate() error {
 	if v.Cwd == "" {
-		return fmt.Errorf("cwd is required")
+		return newValidationError("cwd", "required", "cwd is required")
 	}
 	if v.McpServers == nil {
-		return fmt.Errorf("mcpServers is required")
+		return newValidationError("mcpServers", "required", "mcpServers is required")
 	}
 	return nil
 }
@@ -2587,13 +2556,6 @@ type McpCapabilities struct {
 	Sse bool `json:"sse,omitempty"`
 }
 
-func (v McpCapabilities) MarshalJSON() ([]byte, error) {
-	type Alias McpCapabilities
-	var a Alias
-	a = Alias(v)
-	return json.Marshal(a)
-}
-
 func (v *McpCapabilities) UnmarshalJSON(b []byte) error {
 	var m map[string]json.RawMessage
 	if err := json.Unmarshal(b, &m); err != nil {
@@ -2926,7 +2888,59 @@ func (u McpServer) MarshalJSON() ([]byte, error) {
 		}
 		return json.Marshal(m)
 	}
-	return []byte{}, nil
+	return nil, errors.New("McpServer has no variant set")
+}
+
+// validateVariant checks that exactly one variant is set and that the set
+// variant's required string fields are non-empty.
+func (u *McpServer) validateVariant() error {
+	var count int
+	if u.Http != nil {
+		count++
+	}
+	if u.Sse != nil {
+		count++
+	}
+	if u.Acp != nil {
+		count++
+	}
+	if u.Stdio != nil {
+		count++
+	}
+	if count != 1 {
+		return fmt.Errorf("McpServer must have exactly one variant set, got %d", count)
+	}
+	switch {
+	case u.Http != nil:
+		if u.Http.Name == "" {
+			return errors.New("http: name is required")
+		}
+		if u.Http.Url == "" {
+			return fmt.Errorf("http %q: url is required", u.Http.Name)
+		}
+	case u.Sse != nil:
+		if u.Sse.Name == "" {
+			return errors.New("sse: name is required")
+		}
+		if u.Sse.Url == "" {
+			return fmt.Errorf("sse %q: url is required", u.Sse.Name)
+		}
+	case u.Acp != nil:
+		if u.Acp.Name == "" {
+			return errors.New("acp: name is required")
+		}
+		if u.Acp.Id == "" {
+			return fmt.Errorf("acp %q: id is required", u.Acp.Name)
+		}
+	case u.Stdio != nil:
+		if u.Stdio.Name == "" {
+			return errors.New("stdio: name is required")
+		}
+		if u.Stdio.Command == "" {
+			return fmt.Errorf("stdio %q: command is required", u.Stdio.Name)
+		}
+	}
+	return nil
 }
 
 // **UNSTABLE**
@@ -3254,10 +3268,10 @@ type NewSessionRequest struct {
 
 func (v *NewSessionRequest) Validate() error {
 	if v.Cwd == "" {
-		return fmt.Errorf("cwd is required")
+		return newValidationError("cwd", "required", "cwd is required")
 	}
 	if v.McpServers == nil {
-		return fmt.Errorf("mcpServers is required")
+		return newValidationError("mcpServers", "required", "mcpServers is required")
 	}
 	return nil
 }
@@ -3319,6 +3333,25 @@ const (
 	PermissionOptionKindRejectAlways PermissionOptionKind = "reject_always"
 )
 
+// String returns the wire value of v.
+func (v PermissionOptionKind) String() string {
+	return string(v)
+}
+
+// IsValid reports whether v is one of the known PermissionOptionKind values.
+func (v PermissionOptionKind) IsValid() bool {
+	switch v {
+	case PermissionOptionKindAllowOnce, PermissionOptionKindAllowAlways, PermissionOptionKindRejectOnce, PermissionOptionKindRejectAlways:
+		return true
+	}
+	return false
+}
+
+// AllPermissionOptionKind returns the known PermissionOptionKind values in schema order.
+func AllPermissionOptionKind() []PermissionOptionKind {
+	return []PermissionOptionKind{PermissionOptionKindAllowOnce, PermissionOptionKindAllowAlways, PermissionOptionKindRejectOnce, PermissionOptionKindRejectAlways}
+}
+
 // An execution plan for accomplishing complex tasks.
 //
 // Plans consist of multiple entries representing individual tasks or goals.
@@ -3388,6 +3421,25 @@ const (
 	PlanEntryPriorityLow    PlanEntryPriority = "low"
 )
 
+// String returns the wire value of v.
+func (v PlanEntryPriority) String() string {
+	return string(v)
+}
+
+// IsValid reports whether v is one of the known PlanEntryPriority values.
+func (v PlanEntryPriority) IsValid() bool {
+	switch v {
+	case PlanEntryPriorityHigh, PlanEntryPriorityMedium, PlanEntryPriorityLow:
+		return true
+	}
+	return false
+}
+
+// AllPlanEntryPriority returns the known PlanEntryPriority values in schema order.
+func AllPlanEntryPriority() []PlanEntryPriority {
+	return []PlanEntryPriority{PlanEntryPriorityHigh, PlanEntryPriorityMedium, PlanEntryPriorityLow}
+}
+
 // Status of a plan entry in the execution flow.
 //
 // Tracks the lifecycle of each task from planning through completion.
@@ -3400,6 +3452,25 @@ const (
 	PlanEntryStatusCompleted  PlanEntryStatus = "completed"
 )
 
+// String returns the wire value of v.
+func (v PlanEntryStatus) String() string {
+	return string(v)
+}
+
+// IsValid reports whether v is one of the known PlanEntryStatus values.
+func (v PlanEntryStatus) IsValid() bool {
+	switch v {
+	case PlanEntryStatusPending, PlanEntryStatusInProgress, PlanEntryStatusCompleted:
+		return true
+	}
+	return false
+}
+
+// AllPlanEntryStatus returns the known PlanEntryStatus values in schema order.
+func AllPlanEntryStatus() []PlanEntryStatus {
+	return []PlanEntryStatus{PlanEntryStatusPending, PlanEntryStatusInProgress, PlanEntryStatusCompleted}
+}
+
 // **UNSTABLE**
 //
 // This capability is not part of the spec yet, and may be removed or changed at any point.
@@ -3589,66 +3660,10 @@ func (u *PlanUpdateContent) UnmarshalJSON(b []byte) error {
 				u.Markdown = &v
 				return nil
 			}
-		}
-		{
-			var v PlanUpdateContentItems
-			var match bool = true
-			if _, ok := m["type"]; !ok {
-				match = false
-			}
-			if _, ok := m["id"]; !ok {
-				match = false
-			}
-			if _, ok := m["entries"]; !ok {
-				match = false
-			}
-			if match {
-				if json.Unmarshal(b, &v) != nil {
-					return errors.New("invalid variant payload")
-				}
-				u.Items = &v
-				return nil
-			}
-		}
-		{
-			var v PlanUpdateContentFile
-			var match bool = true
-			if _, ok := m["type"]; !ok {
-				match = false
-			}
-			if _, ok := m["id"]; !ok {
-				match = false
-			}
-			if _, ok := m["uri"]; !ok {
-				match = false
-			}
-			if match {
-				if json.Unmarshal(b, &v) != nil {
-					return errors.New("invalid variant payload")
-				}
-				u.File = &v
-				return nil
-			}
-		}
-		{
-			var v PlanUpdateContentMarkdown
-			var match bool = true
-			if _, ok := m["type"]; !ok {
-				match = false
-			}
-			if _, ok := m["id"]; !ok {
-				match = false
-			}
-			if _, ok := m["content"]; !ok {
-				match = false
-			}
-			if match {
-				if json.Unmarshal(b, &v) != nil {
-					return errors.New("invalid variant payload")
-				}
-				u.Markdown = &v
-				return nil
+			if disc == "" {
+				return fmt.Errorf("PlanUpdateContent: missing \"type\" discriminator")
 			}
+			return fmt.Errorf("PlanUpdateContent: unknown type %q", disc)
 		}
 	} else {
 		if _, ok := err.(*json.UnmarshalTypeError); !ok {
@@ -3682,6 +3697,9 @@ func (u *PlanUpdateContent) UnmarshalJSON(b []byte) error {
 	return errors.New("no matching variant for union")
 }
 func (u PlanUpdateContent) MarshalJSON() ([]byte, error) {
+	if err := u.Validate(); err != nil {
+		return nil, err
+	}
 	if u.Items != nil {
 		_b, _e := json.Marshal(*u.Items)
 		if _e != nil {
@@ -3718,7 +3736,7 @@ func (u PlanUpdateContent) MarshalJSON() ([]byte, error) {
 		m["type"] = "markdown"
 		return json.Marshal(m)
 	}
-	return []byte{}, nil
+	return nil, errors.New("PlanUpdateContent has no variant set")
 }
 
 func (u *PlanUpdateContent) Validate() error {
@@ -3733,7 +3751,7 @@ func (u *PlanUpdateContent) Validate() error {
 		count++
 	}
 	if count != 1 {
-		return errors.New("PlanUpdateContent must have exactly one variant set")
+		return fmt.Errorf("PlanUpdateContent must have exactly one variant set, got %d", count)
 	}
 	return nil
 }
@@ -3749,6 +3767,25 @@ const (
 	PositionEncodingKindUtf8  PositionEncodingKind = "utf-8"
 )
 
+// String returns the wire value of v.
+func (v PositionEncodingKind) String() string {
+	return string(v)
+}
+
+// IsValid reports whether v is one of the known PositionEncodingKind values.
+func (v PositionEncodingKind) IsValid() bool {
+	switch v {
+	case PositionEncodingKindUtf16, PositionEncodingKindUtf32, PositionEncodingKindUtf8:
+		return true
+	}
+	return false
+}
+
+// AllPositionEncodingKind returns the known PositionEncodingKind values in schema order.
+func AllPositionEncodingKind() []PositionEncodingKind {
+	return []PositionEncodingKind{PositionEncodingKindUtf16, PositionEncodingKindUtf32, PositionEncodingKindUtf8}
+}
+
 // Prompt capabilities supported by the agent in 'session/prompt' requests.
 //
 // Baseline agent functionality requires support for ['ContentBlock::Text']
@@ -3785,13 +3822,6 @@ type PromptCapabilities struct {
 	Image bool `json:"image,omitempty"`
 }
 
-func (v PromptCapabilities) MarshalJSON() ([]byte, error) {
-	type Alias PromptCapabilities
-	var a Alias
-	a = Alias(v)
-	return json.Marshal(a)
-}
-
 func (v *PromptCapabilities) UnmarshalJSON(b []byte) error {
 	var m map[string]json.RawMessage
 	if err := json.Unmarshal(b, &m); err != nil {
@@ -3866,7 +3896,7 @@ type PromptRequest struct {
 
 func (v *PromptRequest) Validate() error {
 	if v.Prompt == nil {
-		return fmt.Errorf("prompt is required")
+		return newValidationError("prompt", "required", "prompt is required")
 	}
 	return nil
 }
@@ -3902,6 +3932,9 @@ type PromptResponse struct {
 }
 
 func (v *PromptResponse) Validate() error {
+	if !v.StopReason.IsValid() {
+		return newValidationError("stopReason", "unknown enum", "invalid stopReason: %q", v.StopReason)
+	}
 	return nil
 }
 
@@ -3948,8 +3981,14 @@ type ReadTextFileRequest struct {
 }
 
 func (v *ReadTextFileRequest) Validate() error {
+	if v.Limit != nil && *v.Limit < 0 {
+		return newValidationError("limit", "below minimum", "limit must be >= 0, got %v", *v.Limit)
+	}
+	if v.Line != nil && *v.Line < 0 {
+		return newValidationError("line", "below minimum", "line must be >= 0, got %v", *v.Line)
+	}
 	if v.Path == "" {
-		return fmt.Errorf("path is required")
+		return newValidationError("path", "required", "path is required")
 	}
 	return nil
 }
@@ -3967,7 +4006,7 @@ type ReadTextFileResponse struct {
 
 func (v *ReadTextFileResponse) Validate() error {
 	if v.Content == "" {
-		return fmt.Errorf("content is required")
+		return newValidationError("content", "required", "content is required")
 	}
 	return nil
 }
@@ -3988,7 +4027,7 @@ type ReleaseTerminalRequest struct {
 
 func (v *ReleaseTerminalRequest) Validate() error {
 	if v.TerminalId == "" {
-		return fmt.Errorf("terminalId is required")
+		return newValidationError("terminalId", "required", "terminalId is required")
 	}
 	return nil
 }
@@ -4095,7 +4134,7 @@ func (u RequestId) MarshalJSON() ([]byte, error) {
 		}
 		return json.Marshal(m)
 	}
-	return []byte{}, nil
+	return nil, errors.New("RequestId has no variant set")
 }
 
 // The outcome of a permission request.
@@ -4160,37 +4199,10 @@ func (u *RequestPermissionOutcome) UnmarshalJSON(b []byte) error {
 				u.Selected = &v
 				return nil
 			}
-		}
-		{
-			var v RequestPermissionOutcomeCancelled
-			var match bool = true
-			if _, ok := m["outcome"]; !ok {
-				match = false
-			}
-			if match {
-				if json.Unmarshal(b, &v) != nil {
-					return errors.New("invalid variant payload")
-				}
-				u.Cancelled = &v
-				return nil
-			}
-		}
-		{
-			var v RequestPermissionOutcomeSelected
-			var match bool = true
-			if _, ok := m["outcome"]; !ok {
-				match = false
-			}
-			if _, ok := m["optionId"]; !ok {
-				match = false
-			}
-			if match {
-				if json.Unmarshal(b, &v) != nil {
-					return errors.New("invalid variant payload")
-				}
-				u.Selected = &v
-				return nil
+			if disc == "" {
+				return fmt.Errorf("RequestPermissionOutcome: missing \"outcome\" discriminator")
 			}
+			return fmt.Errorf("RequestPermissionOutcome: unknown outcome %q", disc)
 		}
 	} else {
 		if _, ok := err.(*json.UnmarshalTypeError); !ok {
@@ -4217,6 +4229,9 @@ func (u *RequestPermissionOutcome) UnmarshalJSON(b []byte) error {
 	return errors.New("no matching variant for union")
 }
 func (u RequestPermissionOutcome) MarshalJSON() ([]byte, error) {
+	if err := u.Validate(); err != nil {
+		return nil, err
+	}
 	if u.Cancelled != nil {
 		_b, _e := json.Marshal(*u.Cancelled)
 		if _e != nil {
@@ -4241,7 +4256,7 @@ func (u RequestPermissionOutcome) MarshalJSON() ([]byte, error) {
 		m["outcome"] = "selected"
 		return json.Marshal(m)
 	}
-	return []byte{}, nil
+	return nil, errors.New("RequestPermissionOutcome has no variant set")
 }
 
 func (u *RequestPermissionOutcome) Validate() error {
@@ -4253,7 +4268,7 @@ func (u *RequestPermissionOutcome) Validate() error {
 		count++
 	}
 	if count != 1 {
-		return errors.New("RequestPermissionOutcome must have exactly one variant set")
+		return fmt.Errorf("RequestPermissionOutcome must have exactly one variant set, got %d", count)
 	}
 	return nil
 }
@@ -4280,7 +4295,7 @@ type RequestPermissionRequest struct {
 
 func (v *RequestPermissionRequest) Validate() error {
 	if v.Options == nil {
-		return fmt.Errorf("options is required")
+		return newValidationError("options", "required", "options is required")
 	}
 	return nil
 }
@@ -4348,7 +4363,7 @@ type ResumeSessionRequest struct {
 
 func (v *ResumeSessionRequest) Validate() error {
 	if v.Cwd == "" {
-		return fmt.Errorf("cwd is required")
+		return newValidationError("cwd", "required", "cwd is required")
 	}
 	return nil
 }
@@ -4381,6 +4396,25 @@ const (
 	RoleUser      Role = "user"
 )
 
+// String returns the wire value of v.
+func (v Role) String() string {
+	return string(v)
+}
+
+// IsValid reports whether v is one of the known Role values.
+func (v Role) IsValid() bool {
+	switch v {
+	case RoleAssistant, RoleUser:
+		return true
+	}
+	return false
+}
+
+// AllRole returns the known Role values in schema order.
+func AllRole() []Role {
+	return []Role{RoleAssistant, RoleUser}
+}
+
 // The user selected one of the provided options.
 type SelectedPermissionOutcome struct {
 	// The _meta property is reserved by ACP to allow clients and agents to attach additional
@@ -4565,43 +4599,10 @@ func (u *SessionConfigOption) UnmarshalJSON(b []byte) error {
 				u.Boolean = &v
 				return nil
 			}
-		}
-		{
-			var v SessionConfigOptionSelect
-			var match bool = true
-			if _, ok := m["type"]; !ok {
-				match = false
-			}
-			if _, ok := m["currentValue"]; !ok {
-				match = false
-			}
-			if _, ok := m["options"]; !ok {
-				match = false
-			}
-			if match {
-				if json.Unmarshal(b, &v) != nil {
-					return errors.New("invalid variant payload")
-				}
-				u.Select = &v
-				return nil
-			}
-		}
-		{
-			var v SessionConfigOptionBoolean
-			var match bool = true
-			if _, ok := m["type"]; !ok {
-				match = false
-			}
-			if _, ok := m["currentValue"]; !ok {
-				match = false
-			}
-			if match {
-				if json.Unmarshal(b, &v) != nil {
-					return errors.New("invalid variant payload")
-				}
-				u.Boolean = &v
-				return nil
+			if disc == "" {
+				return fmt.Errorf("SessionConfigOption: missing \"type\" discriminator")
 			}
+			return fmt.Errorf("SessionConfigOption: unknown type %q", disc)
 		}
 	} else {
 		if _, ok := err.(*json.UnmarshalTypeError); !ok {
@@ -4628,6 +4629,9 @@ func (u *SessionConfigOption) UnmarshalJSON(b []byte) error {
 	return errors.New("no matching variant for union")
 }
 func (u SessionConfigOption) MarshalJSON() ([]byte, error) {
+	if err := u.Validate(); err != nil {
+		return nil, err
+	}
 	if u.Select != nil {
 		_b, _e := json.Marshal(*u.Select)
 		if _e != nil {
@@ -4652,7 +4656,7 @@ func (u SessionConfigOption) MarshalJSON() ([]byte, error) {
 		m["type"] = "boolean"
 		return json.Marshal(m)
 	}
-	return []byte{}, nil
+	return nil, errors.New("SessionConfigOption has no variant set")
 }
 
 func (u *SessionConfigOption) Validate() error {
@@ -4664,7 +4668,7 @@ func (u *SessionConfigOption) Validate() error {
 		count++
 	}
 	if count != 1 {
-		return errors.New("SessionConfigOption must have exactly one variant set")
+		return fmt.Errorf("SessionConfigOption must have exactly one variant set, got %d", count)
 	}
 	return nil
 }
@@ -4686,6 +4690,26 @@ const (
 	SessionConfigOptionCategoryThoughtLevel SessionConfigOptionCategory = "thought_level"
 )
 
+// String returns the wire value of v.
+func (v SessionConfigOptionCategory) String() string {
+	return string(v)
+}
+
+// IsValid reports whether v is one of the known SessionConfigOptionCategory values. The schema also
+// permits other strings, so an invalid value is not necessarily an error.
+func (v SessionConfigOptionCategory) IsValid() bool {
+	switch v {
+	case SessionConfigOptionCategoryMode, SessionConfigOptionCategoryModel, SessionConfigOptionCategoryThoughtLevel:
+		return true
+	}
+	return false
+}
+
+// AllSessionConfigOptionCategory returns the known SessionConfigOptionCategory values in schema order.
+func AllSessionConfigOptionCategory() []SessionConfigOptionCategory {
+	return []SessionConfigOptionCategory{SessionConfigOptionCategoryMode, SessionConfigOptionCategoryModel, SessionConfigOptionCategoryThoughtLevel}
+}
+
 // A single-value selector (dropdown) session configuration option payload.
 type SessionConfigSelect struct {
 	// The currently selected value.
@@ -4833,7 +4857,7 @@ func (u SessionConfigSelectOptions) MarshalJSON() ([]byte, error) {
 		}
 		return json.Marshal(m)
 	}
-	return []byte{}, nil
+	return nil, errors.New("SessionConfigSelectOptions has no variant set")
 }
 
 // Unique identifier for a session configuration option value.
@@ -5401,279 +5425,59 @@ func (u *SessionUpdate) UnmarshalJSON(b []byte) error {
 				u.UsageUpdate = &v
 				return nil
 			}
-		}
-		{
-			var v SessionUpdateUserMessageChunk
-			var match bool = true
-			if _, ok := m["sessionUpdate"]; !ok {
-				match = false
-			}
-			if _, ok := m["content"]; !ok {
-				match = false
-			}
-			if match {
-				if json.Unmarshal(b, &v) != nil {
-					return errors.New("invalid variant payload")
-				}
-				u.UserMessageChunk = &v
-				return nil
+			if disc == "" {
+				return fmt.Errorf("SessionUpdate: missing \"sessionUpdate\" discriminator")
 			}
+			return fmt.Errorf("SessionUpdate: unknown sessionUpdate %q", disc)
 		}
-		{
-			var v SessionUpdateAgentMessageChunk
-			var match bool = true
-			if _, ok := m["sessionUpdate"]; !ok {
-				match = false
-			}
-			if _, ok := m["content"]; !ok {
-				match = false
-			}
-			if match {
-				if json.Unmarshal(b, &v) != nil {
-					return errors.New("invalid variant payload")
-				}
-				u.AgentMessageChunk = &v
-				return nil
-			}
+	} else {
+		if _, ok := err.(*json.UnmarshalTypeError); !ok {
+			return err
 		}
-		{
-			var v SessionUpdateAgentThoughtChunk
-			var match bool = true
-			if _, ok := m["sessionUpdate"]; !ok {
-				match = false
-			}
-			if _, ok := m["content"]; !ok {
-				match = false
-			}
-			if match {
-				if json.Unmarshal(b, &v) != nil {
-					return errors.New("invalid variant payload")
-				}
-				u.AgentThoughtChunk = &v
-				return nil
-			}
+	}
+	var arr []map[string]json.RawMessage
+	if json.Unmarshal(b, &arr) == nil {
+	}
+	{
+		var v SessionUpdateUserMessageChunk
+		if json.Unmarshal(b, &v) == nil {
+			u.UserMessageChunk = &v
+			return nil
 		}
-		{
-			var v SessionUpdateToolCall
-			var match bool = true
-			if _, ok := m["sessionUpdate"]; !ok {
-				match = false
-			}
-			if _, ok := m["toolCallId"]; !ok {
-				match = false
-			}
-			if _, ok := m["title"]; !ok {
-				match = false
-			}
-			if match {
-				if json.Unmarshal(b, &v) != nil {
-					return errors.New("invalid variant payload")
-				}
-				u.ToolCall = &v
-				return nil
-			}
+	}
+	{
+		var v SessionUpdateAgentMessageChunk
+		if json.Unmarshal(b, &v) == nil {
+			u.AgentMessageChunk = &v
+			return nil
 		}
-		{
-			var v SessionToolCallUpdate
-			var match bool = true
-			if _, ok := m["sessionUpdate"]; !ok {
-				match = false
-			}
-			if _, ok := m["toolCallId"]; !ok {
-				match = false
-			}
-			if match {
-				if json.Unmarshal(b, &v) != nil {
-					return errors.New("invalid variant payload")
-				}
-				u.ToolCallUpdate = &v
-				return nil
-			}
+	}
+	{
+		var v SessionUpdateAgentThoughtChunk
+		if json.Unmarshal(b, &v) == nil {
+			u.AgentThoughtChunk = &v
+			return nil
 		}
-		{
-			var v SessionUpdatePlan
-			var match bool = true
-			if _, ok := m["sessionUpdate"]; !ok {
-				match = false
-			}
-			if _, ok := m["entries"]; !ok {
-				match = false
-			}
-			if match {
-				if json.Unmarshal(b, &v) != nil {
-					return errors.New("invalid variant payload")
-				}
-				u.Plan = &v
-				return nil
-			}
+	}
+	{
+		var v SessionUpdateToolCall
+		if json.Unmarshal(b, &v) == nil {
+			u.ToolCall = &v
+			return nil
 		}
-		{
-			var v SessionPlanUpdate
-			var match bool = true
-			if _, ok := m["sessionUpdate"]; !ok {
-				match = false
-			}
-			if _, ok := m["plan"]; !ok {
-				match = false
-			}
-			if match {
-				if json.Unmarshal(b, &v) != nil {
-					return errors.New("invalid variant payload")
-				}
-				u.PlanUpdate = &v
-				return nil
-			}
+	}
+	{
+		var v SessionToolCallUpdate
+		if json.Unmarshal(b, &v) == nil {
+			u.ToolCallUpdate = &v
+			return nil
 		}
-		{
-			var v SessionUpdatePlanRemoved
-			var match bool = true
-			if _, ok := m["sessionUpdate"]; !ok {
-				match = false
-			}
-			if _, ok := m["id"]; !ok {
-				match = false
-			}
-			if match {
-				if json.Unmarshal(b, &v) != nil {
-					return errors.New("invalid variant payload")
-				}
-				u.PlanRemoved = &v
-				return nil
-			}
-		}
-		{
-			var v SessionAvailableCommandsUpdate
-			var match bool = true
-			if _, ok := m["sessionUpdate"]; !ok {
-				match = false
-			}
-			if _, ok := m["availableCommands"]; !ok {
-				match = false
-			}
-			if match {
-				if json.Unmarshal(b, &v) != nil {
-					return errors.New("invalid variant payload")
-				}
-				u.AvailableCommandsUpdate = &v
-				return nil
-			}
-		}
-		{
-			var v SessionCurrentModeUpdate
-			var match bool = true
-			if _, ok := m["sessionUpdate"]; !ok {
-				match = false
-			}
-			if _, ok := m["currentModeId"]; !ok {
-				match = false
-			}
-			if match {
-				if json.Unmarshal(b, &v) != nil {
-					return errors.New("invalid variant payload")
-				}
-				u.CurrentModeUpdate = &v
-				return nil
-			}
-		}
-		{
-			var v SessionConfigOptionUpdate
-			var match bool = true
-			if _, ok := m["sessionUpdate"]; !ok {
-				match = false
-			}
-			if _, ok := m["configOptions"]; !ok {
-				match = false
-			}
-			if match {
-				if json.Unmarshal(b, &v) != nil {
-					return errors.New("invalid variant payload")
-				}
-				u.ConfigOptionUpdate = &v
-				return nil
-			}
-		}
-		{
-			var v SessionSessionInfoUpdate
-			var match bool = true
-			if _, ok := m["sessionUpdate"]; !ok {
-				match = false
-			}
-			if match {
-				if json.Unmarshal(b, &v) != nil {
-					return errors.New("invalid variant payload")
-				}
-				u.SessionInfoUpdate = &v
-				return nil
-			}
-		}
-		{
-			var v SessionUsageUpdate
-			var match bool = true
-			if _, ok := m["sessionUpdate"]; !ok {
-				match = false
-			}
-			if _, ok := m["used"]; !ok {
-				match = false
-			}
-			if _, ok := m["size"]; !ok {
-				match = false
-			}
-			if match {
-				if json.Unmarshal(b, &v) != nil {
-					return errors.New("invalid variant payload")
-				}
-				u.UsageUpdate = &v
-				return nil
-			}
-		}
-	} else {
-		if _, ok := err.(*json.UnmarshalTypeError); !ok {
-			return err
-		}
-	}
-	var arr []map[string]json.RawMessage
-	if json.Unmarshal(b, &arr) == nil {
-	}
-	{
-		var v SessionUpdateUserMessageChunk
-		if json.Unmarshal(b, &v) == nil {
-			u.UserMessageChunk = &v
-			return nil
-		}
-	}
-	{
-		var v SessionUpdateAgentMessageChunk
-		if json.Unmarshal(b, &v) == nil {
-			u.AgentMessageChunk = &v
-			return nil
-		}
-	}
-	{
-		var v SessionUpdateAgentThoughtChunk
-		if json.Unmarshal(b, &v) == nil {
-			u.AgentThoughtChunk = &v
-			return nil
-		}
-	}
-	{
-		var v SessionUpdateToolCall
-		if json.Unmarshal(b, &v) == nil {
-			u.ToolCall = &v
-			return nil
-		}
-	}
-	{
-		var v SessionToolCallUpdate
-		if json.Unmarshal(b, &v) == nil {
-			u.ToolCallUpdate = &v
-			return nil
-		}
-	}
-	{
-		var v SessionUpdatePlan
-		if json.Unmarshal(b, &v) == nil {
-			u.Plan = &v
-			return nil
+	}
+	{
+		var v SessionUpdatePlan
+		if json.Unmarshal(b, &v) == nil {
+			u.Plan = &v
+			return nil
 		}
 	}
 	{
@@ -5728,6 +5532,9 @@ func (u *SessionUpdate) UnmarshalJSON(b []byte) error {
 	return errors.New("no matching variant for union")
 }
 func (u SessionUpdate) MarshalJSON() ([]byte, error) {
+	if err := u.Validate(); err != nil {
+		return nil, err
+	}
 	if u.UserMessageChunk != nil {
 		_b, _e := json.Marshal(*u.UserMessageChunk)
 		if _e != nil {
@@ -5884,7 +5691,7 @@ func (u SessionUpdate) MarshalJSON() ([]byte, error) {
 		m["sessionUpdate"] = "usage_update"
 		return json.Marshal(m)
 	}
-	return []byte{}, nil
+	return nil, errors.New("SessionUpdate has no variant set")
 }
 
 func (u *SessionUpdate) Validate() error {
@@ -5929,7 +5736,248 @@ func (u *SessionUpdate) Validate() error {
 		count++
 	}
 	if count != 1 {
-		return errors.New("SessionUpdate must have exactly one variant set")
+		return fmt.Errorf("SessionUpdate must have exactly one variant set, got %d", count)
+	}
+	return nil
+}
+
+// SessionUpdateVisitor handles each variant of a SessionUpdate. See SessionUpdate.Accept.
+type SessionUpdateVisitor interface {
+	VisitUserMessageChunk(*SessionUpdateUserMessageChunk) error
+	VisitAgentMessageChunk(*SessionUpdateAgentMessageChunk) error
+	VisitAgentThoughtChunk(*SessionUpdateAgentThoughtChunk) error
+	VisitToolCall(*SessionUpdateToolCall) error
+	VisitToolCallUpdate(*SessionToolCallUpdate) error
+	VisitPlan(*SessionUpdatePlan) error
+	VisitPlanUpdate(*SessionPlanUpdate) error
+	VisitPlanRemoved(*SessionUpdatePlanRemoved) error
+	VisitAvailableCommandsUpdate(*SessionAvailableCommandsUpdate) error
+	VisitCurrentModeUpdate(*SessionCurrentModeUpdate) error
+	VisitConfigOptionUpdate(*SessionConfigOptionUpdate) error
+	VisitSessionInfoUpdate(*SessionSessionInfoUpdate) error
+	VisitUsageUpdate(*SessionUsageUpdate) error
+}
+
+// Accept calls the SessionUpdateVisitor method for the variant that is set and returns its
+// error. It returns an error if no variant is set.
+func (u SessionUpdate) Accept(v SessionUpdateVisitor) error {
+	switch {
+	case u.UserMessageChunk != nil:
+		return v.VisitUserMessageChunk(u.UserMessageChunk)
+	case u.AgentMessageChunk != nil:
+		return v.VisitAgentMessageChunk(u.AgentMessageChunk)
+	case u.AgentThoughtChunk != nil:
+		return v.VisitAgentThoughtChunk(u.AgentThoughtChunk)
+	case u.ToolCall != nil:
+		return v.VisitToolCall(u.ToolCall)
+	case u.ToolCallUpdate != nil:
+		return v.VisitToolCallUpdate(u.ToolCallUpdate)
+	case u.Plan != nil:
+		return v.VisitPlan(u.Plan)
+	case u.PlanUpdate != nil:
+		return v.VisitPlanUpdate(u.PlanUpdate)
+	case u.PlanRemoved != nil:
+		return v.VisitPlanRemoved(u.PlanRemoved)
+	case u.AvailableCommandsUpdate != nil:
+		return v.VisitAvailableCommandsUpdate(u.AvailableCommandsUpdate)
+	case u.CurrentModeUpdate != nil:
+		return v.VisitCurrentModeUpdate(u.CurrentModeUpdate)
+	case u.ConfigOptionUpdate != nil:
+		return v.VisitConfigOptionUpdate(u.ConfigOptionUpdate)
+	case u.SessionInfoUpdate != nil:
+		return v.VisitSessionInfoUpdate(u.SessionInfoUpdate)
+	case u.UsageUpdate != nil:
+		return v.VisitUsageUpdate(u.UsageUpdate)
+	}
+	return errors.New("SessionUpdate has no variant set")
+}
+
+// SessionUpdateKind identifies which variant of a SessionUpdate is set.
+type SessionUpdateKind string
+
+const (
+	SessionUpdateKindUserMessageChunk        SessionUpdateKind = "user_message_chunk"
+	SessionUpdateKindAgentMessageChunk       SessionUpdateKind = "agent_message_chunk"
+	SessionUpdateKindAgentThoughtChunk       SessionUpdateKind = "agent_thought_chunk"
+	SessionUpdateKindToolCall                SessionUpdateKind = "tool_call"
+	SessionUpdateKindToolCallUpdate          SessionUpdateKind = "tool_call_update"
+	SessionUpdateKindPlan                    SessionUpdateKind = "plan"
+	SessionUpdateKindPlanUpdate              SessionUpdateKind = "plan_update"
+	SessionUpdateKindPlanRemoved             SessionUpdateKind = "plan_removed"
+	SessionUpdateKindAvailableCommandsUpdate SessionUpdateKind = "available_commands_update"
+	SessionUpdateKindCurrentModeUpdate       SessionUpdateKind = "current_mode_update"
+	SessionUpdateKindConfigOptionUpdate      SessionUpdateKind = "config_option_update"
+	SessionUpdateKindSessionInfoUpdate       SessionUpdateKind = "session_info_update"
+	SessionUpdateKindUsageUpdate             SessionUpdateKind = "usage_update"
+)
+
+// String returns the wire value of v.
+func (v SessionUpdateKind) String() string {
+	return string(v)
+}
+
+// IsValid reports whether v is one of the known SessionUpdateKind values.
+func (v SessionUpdateKind) IsValid() bool {
+	switch v {
+	case SessionUpdateKindUserMessageChunk, SessionUpdateKindAgentMessageChunk, SessionUpdateKindAgentThoughtChunk, SessionUpdateKindToolCall, SessionUpdateKindToolCallUpdate, SessionUpdateKindPlan, SessionUpdateKindPlanUpdate, SessionUpdateKindPlanRemoved, SessionUpdateKindAvailableCommandsUpdate, SessionUpdateKindCurrentModeUpdate, SessionUpdateKindConfigOptionUpdate, SessionUpdateKindSessionInfoUpdate, SessionUpdateKindUsageUpdate:
+		return true
+	}
+	return false
+}
+
+// AllSessionUpdateKind returns the known SessionUpdateKind values in schema order.
+func AllSessionUpdateKind() []SessionUpdateKind {
+	return []SessionUpdateKind{SessionUpdateKindUserMessageChunk, SessionUpdateKindAgentMessageChunk, SessionUpdateKindAgentThoughtChunk, SessionUpdateKindToolCall, SessionUpdateKindToolCallUpdate, SessionUpdateKindPlan, SessionUpdateKindPlanUpdate, SessionUpdateKindPlanRemoved, SessionUpdateKindAvailableCommandsUpdate, SessionUpdateKindCurrentModeUpdate, SessionUpdateKindConfigOptionUpdate, SessionUpdateKindSessionInfoUpdate, SessionUpdateKindUsageUpdate}
+}
+
+// Kind returns the discriminator of the variant that is set, or the empty SessionUpdateKind
+// if none is.
+func (u SessionUpdate) Kind() SessionUpdateKind {
+	switch {
+	case u.UserMessageChunk != nil:
+		return SessionUpdateKindUserMessageChunk
+	case u.AgentMessageChunk != nil:
+		return SessionUpdateKindAgentMessageChunk
+	case u.AgentThoughtChunk != nil:
+		return SessionUpdateKindAgentThoughtChunk
+	case u.ToolCall != nil:
+		return SessionUpdateKindToolCall
+	case u.ToolCallUpdate != nil:
+		return SessionUpdateKindToolCallUpdate
+	case u.Plan != nil:
+		return SessionUpdateKindPlan
+	case u.PlanUpdate != nil:
+		return SessionUpdateKindPlanUpdate
+	case u.PlanRemoved != nil:
+		return SessionUpdateKindPlanRemoved
+	case u.AvailableCommandsUpdate != nil:
+		return SessionUpdateKindAvailableCommandsUpdate
+	case u.CurrentModeUpdate != nil:
+		return SessionUpdateKindCurrentModeUpdate
+	case u.ConfigOptionUpdate != nil:
+		return SessionUpdateKindConfigOptionUpdate
+	case u.SessionInfoUpdate != nil:
+		return SessionUpdateKindSessionInfoUpdate
+	case u.UsageUpdate != nil:
+		return SessionUpdateKindUsageUpdate
+	}
+	return ""
+}
+
+// SessionUpdateVariant is implemented by every SessionUpdate variant type.
+// It is sealed, so a type switch over Variant() can cover every case.
+type SessionUpdateVariant interface {
+	SessionUpdateKind() SessionUpdateKind
+	isSessionUpdateVariant()
+}
+
+// SessionUpdateKind returns SessionUpdateKindUserMessageChunk.
+func (*SessionUpdateUserMessageChunk) SessionUpdateKind() SessionUpdateKind {
+	return SessionUpdateKindUserMessageChunk
+}
+func (*SessionUpdateUserMessageChunk) isSessionUpdateVariant() {}
+
+// SessionUpdateKind returns SessionUpdateKindAgentMessageChunk.
+func (*SessionUpdateAgentMessageChunk) SessionUpdateKind() SessionUpdateKind {
+	return SessionUpdateKindAgentMessageChunk
+}
+func (*SessionUpdateAgentMessageChunk) isSessionUpdateVariant() {}
+
+// SessionUpdateKind returns SessionUpdateKindAgentThoughtChunk.
+func (*SessionUpdateAgentThoughtChunk) SessionUpdateKind() SessionUpdateKind {
+	return SessionUpdateKindAgentThoughtChunk
+}
+func (*SessionUpdateAgentThoughtChunk) isSessionUpdateVariant() {}
+
+// SessionUpdateKind returns SessionUpdateKindToolCall.
+func (*SessionUpdateToolCall) SessionUpdateKind() SessionUpdateKind {
+	return SessionUpdateKindToolCall
+}
+func (*SessionUpdateToolCall) isSessionUpdateVariant() {}
+
+// SessionUpdateKind returns SessionUpdateKindToolCallUpdate.
+func (*SessionToolCallUpdate) SessionUpdateKind() SessionUpdateKind {
+	return SessionUpdateKindToolCallUpdate
+}
+func (*SessionToolCallUpdate) isSessionUpdateVariant() {}
+
+// SessionUpdateKind returns SessionUpdateKindPlan.
+func (*SessionUpdatePlan) SessionUpdateKind() SessionUpdateKind {
+	return SessionUpdateKindPlan
+}
+func (*SessionUpdatePlan) isSessionUpdateVariant() {}
+
+// SessionUpdateKind returns SessionUpdateKindPlanUpdate.
+func (*SessionPlanUpdate) SessionUpdateKind() SessionUpdateKind {
+	return SessionUpdateKindPlanUpdate
+}
+func (*SessionPlanUpdate) isSessionUpdateVariant() {}
+
+// SessionUpdateKind returns SessionUpdateKindPlanRemoved.
+func (*SessionUpdatePlanRemoved) SessionUpdateKind() SessionUpdateKind {
+	return SessionUpdateKindPlanRemoved
+}
+func (*SessionUpdatePlanRemoved) isSessionUpdateVariant() {}
+
+// SessionUpdateKind returns SessionUpdateKindAvailableCommandsUpdate.
+func (*SessionAvailableCommandsUpdate) SessionUpdateKind() SessionUpdateKind {
+	return SessionUpdateKindAvailableCommandsUpdate
+}
+func (*SessionAvailableCommandsUpdate) isSessionUpdateVariant() {}
+
+// SessionUpdateKind returns SessionUpdateKindCurrentModeUpdate.
+func (*SessionCurrentModeUpdate) SessionUpdateKind() SessionUpdateKind {
+	return SessionUpdateKindCurrentModeUpdate
+}
+func (*SessionCurrentModeUpdate) isSessionUpdateVariant() {}
+
+// SessionUpdateKind returns SessionUpdateKindConfigOptionUpdate.
+func (*SessionConfigOptionUpdate) SessionUpdateKind() SessionUpdateKind {
+	return SessionUpdateKindConfigOptionUpdate
+}
+func (*SessionConfigOptionUpdate) isSessionUpdateVariant() {}
+
+// SessionUpdateKind returns SessionUpdateKindSessionInfoUpdate.
+func (*SessionSessionInfoUpdate) SessionUpdateKind() SessionUpdateKind {
+	return SessionUpdateKindSessionInfoUpdate
+}
+func (*SessionSessionInfoUpdate) isSessionUpdateVariant() {}
+
+// SessionUpdateKind returns SessionUpdateKindUsageUpdate.
+func (*SessionUsageUpdate) SessionUpdateKind() SessionUpdateKind {
+	return SessionUpdateKindUsageUpdate
+}
+func (*SessionUsageUpdate) isSessionUpdateVariant() {}
+
+// Variant returns the variant that is set, or nil if none is.
+func (u SessionUpdate) Variant() SessionUpdateVariant {
+	switch {
+	case u.UserMessageChunk != nil:
+		return u.UserMessageChunk
+	case u.AgentMessageChunk != nil:
+		return u.AgentMessageChunk
+	case u.AgentThoughtChunk != nil:
+		return u.AgentThoughtChunk
+	case u.ToolCall != nil:
+		return u.ToolCall
+	case u.ToolCallUpdate != nil:
+		return u.ToolCallUpdate
+	case u.Plan != nil:
+		return u.Plan
+	case u.PlanUpdate != nil:
+		return u.PlanUpdate
+	case u.PlanRemoved != nil:
+		return u.PlanRemoved
+	case u.AvailableCommandsUpdate != nil:
+		return u.AvailableCommandsUpdate
+	case u.CurrentModeUpdate != nil:
+		return u.CurrentModeUpdate
+	case u.ConfigOptionUpdate != nil:
+		return u.ConfigOptionUpdate
+	case u.SessionInfoUpdate != nil:
+		return u.SessionInfoUpdate
+	case u.UsageUpdate != nil:
+		return u.UsageUpdate
 	}
 	return nil
 }
@@ -6080,7 +6128,29 @@ func (u SetSessionConfigOptionRequest) MarshalJSON() ([]byte, error) {
 		}
 		return json.Marshal(m)
 	}
-	return []byte{}, nil
+	return nil, errors.New("SetSessionConfigOptionRequest has no variant set")
+}
+
+// validateVariant checks that exactly one variant is set and that the set
+// variant's required string fields are non-empty.
+func (u *SetSessionConfigOptionRequest) validateVariant() error {
+	var count int
+	if u.Boolean != nil {
+		count++
+	}
+	if u.ValueId != nil {
+		count++
+	}
+	if count != 1 {
+		return fmt.Errorf("SetSessionConfigOptionRequest must have exactly one variant set, got %d", count)
+	}
+	switch {
+	case u.ValueId != nil:
+		if u.ValueId.Value == "" {
+			return fmt.Errorf("valueid: value is required")
+		}
+	}
+	return nil
 }
 
 func (v *SetSessionConfigOptionRequest) Validate() error {
@@ -6101,7 +6171,7 @@ type SetSessionConfigOptionResponse struct {
 
 func (v *SetSessionConfigOptionResponse) Validate() error {
 	if v.ConfigOptions == nil {
-		return fmt.Errorf("configOptions is required")
+		return newValidationError("configOptions", "required", "configOptions is required")
 	}
 	return nil
 }
@@ -6151,6 +6221,25 @@ const (
 	StopReasonCancelled       StopReason = "cancelled"
 )
 
+// String returns the wire value of v.
+func (v StopReason) String() string {
+	return string(v)
+}
+
+// IsValid reports whether v is one of the known StopReason values.
+func (v StopReason) IsValid() bool {
+	switch v {
+	case StopReasonEndTurn, StopReasonMaxTokens, StopReasonMaxTurnRequests, StopReasonRefusal, StopReasonCancelled:
+		return true
+	}
+	return false
+}
+
+// AllStopReason returns the known StopReason values in schema order.
+func AllStopReason() []StopReason {
+	return []StopReason{StopReasonEndTurn, StopReasonMaxTokens, StopReasonMaxTurnRequests, StopReasonRefusal, StopReasonCancelled}
+}
+
 // Embed a terminal created with 'terminal/create' by its id.
 //
 // The terminal must be added before calling 'terminal/release'.
@@ -6196,7 +6285,7 @@ type TerminalOutputRequest struct {
 
 func (v *TerminalOutputRequest) Validate() error {
 	if v.TerminalId == "" {
-		return fmt.Errorf("terminalId is required")
+		return newValidationError("terminalId", "required", "terminalId is required")
 	}
 	return nil
 }
@@ -6219,7 +6308,7 @@ type TerminalOutputResponse struct {
 
 func (v *TerminalOutputResponse) Validate() error {
 	if v.Output == "" {
-		return fmt.Errorf("output is required")
+		return newValidationError("output", "required", "output is required")
 	}
 	return nil
 }
@@ -6244,6 +6333,25 @@ const (
 	TextDocumentSyncKindIncremental TextDocumentSyncKind = "incremental"
 )
 
+// String returns the wire value of v.
+func (v TextDocumentSyncKind) String() string {
+	return string(v)
+}
+
+// IsValid reports whether v is one of the known TextDocumentSyncKind values.
+func (v TextDocumentSyncKind) IsValid() bool {
+	switch v {
+	case TextDocumentSyncKindFull, TextDocumentSyncKindIncremental:
+		return true
+	}
+	return false
+}
+
+// AllTextDocumentSyncKind returns the known TextDocumentSyncKind values in schema order.
+func AllTextDocumentSyncKind() []TextDocumentSyncKind {
+	return []TextDocumentSyncKind{TextDocumentSyncKindFull, TextDocumentSyncKindIncremental}
+}
+
 // Text-based resource contents.
 type TextResourceContents struct {
 	// The _meta property is reserved by ACP to allow clients and agents to attach additional
@@ -6386,60 +6494,10 @@ func (u *ToolCallContent) UnmarshalJSON(b []byte) error {
 				u.Terminal = &v
 				return nil
 			}
-		}
-		{
-			var v ToolCallContentContent
-			var match bool = true
-			if _, ok := m["type"]; !ok {
-				match = false
-			}
-			if _, ok := m["content"]; !ok {
-				match = false
-			}
-			if match {
-				if json.Unmarshal(b, &v) != nil {
-					return errors.New("invalid variant payload")
-				}
-				u.Content = &v
-				return nil
-			}
-		}
-		{
-			var v ToolCallContentDiff
-			var match bool = true
-			if _, ok := m["type"]; !ok {
-				match = false
-			}
-			if _, ok := m["path"]; !ok {
-				match = false
-			}
-			if _, ok := m["newText"]; !ok {
-				match = false
-			}
-			if match {
-				if json.Unmarshal(b, &v) != nil {
-					return errors.New("invalid variant payload")
-				}
-				u.Diff = &v
-				return nil
-			}
-		}
-		{
-			var v ToolCallContentTerminal
-			var match bool = true
-			if _, ok := m["type"]; !ok {
-				match = false
-			}
-			if _, ok := m["terminalId"]; !ok {
-				match = false
-			}
-			if match {
-				if json.Unmarshal(b, &v) != nil {
-					return errors.New("invalid variant payload")
-				}
-				u.Terminal = &v
-				return nil
+			if disc == "" {
+				return fmt.Errorf("ToolCallContent: missing \"type\" discriminator")
 			}
+			return fmt.Errorf("ToolCallContent: unknown type %q", disc)
 		}
 	} else {
 		if _, ok := err.(*json.UnmarshalTypeError); !ok {
@@ -6473,6 +6531,9 @@ func (u *ToolCallContent) UnmarshalJSON(b []byte) error {
 	return errors.New("no matching variant for union")
 }
 func (u ToolCallContent) MarshalJSON() ([]byte, error) {
+	if err := u.Validate(); err != nil {
+		return nil, err
+	}
 	if u.Content != nil {
 		_b, _e := json.Marshal(*u.Content)
 		if _e != nil {
@@ -6509,7 +6570,7 @@ func (u ToolCallContent) MarshalJSON() ([]byte, error) {
 		m["type"] = "terminal"
 		return json.Marshal(m)
 	}
-	return []byte{}, nil
+	return nil, errors.New("ToolCallContent has no variant set")
 }
 
 func (u *ToolCallContent) Validate() error {
@@ -6524,7 +6585,7 @@ func (u *ToolCallContent) Validate() error {
 		count++
 	}
 	if count != 1 {
-		return errors.New("ToolCallContent must have exactly one variant set")
+		return fmt.Errorf("ToolCallContent must have exactly one variant set, got %d", count)
 	}
 	return nil
 }
@@ -6565,6 +6626,25 @@ const (
 	ToolCallStatusFailed     ToolCallStatus = "failed"
 )
 
+// String returns the wire value of v.
+func (v ToolCallStatus) String() string {
+	return string(v)
+}
+
+// IsValid reports whether v is one of the known ToolCallStatus values.
+func (v ToolCallStatus) IsValid() bool {
+	switch v {
+	case ToolCallStatusPending, ToolCallStatusInProgress, ToolCallStatusCompleted, ToolCallStatusFailed:
+		return true
+	}
+	return false
+}
+
+// AllToolCallStatus returns the known ToolCallStatus values in schema order.
+func AllToolCallStatus() []ToolCallStatus {
+	return []ToolCallStatus{ToolCallStatusPending, ToolCallStatusInProgress, ToolCallStatusCompleted, ToolCallStatusFailed}
+}
+
 // An update to an existing tool call.
 //
 // Used to report progress and results as tools execute. All fields except
@@ -6598,7 +6678,7 @@ type ToolCallUpdate struct {
 
 func (t *ToolCallUpdate) Validate() error {
 	if t.ToolCallId == "" {
-		return fmt.Errorf("toolCallId is required")
+		return newValidationError("toolCallId", "required", "toolCallId is required")
 	}
 	return nil
 }
@@ -6624,6 +6704,25 @@ const (
 	ToolKindOther      ToolKind = "other"
 )
 
+// String returns the wire value of v.
+func (v ToolKind) String() string {
+	return string(v)
+}
+
+// IsValid reports whether v is one of the known ToolKind values.
+func (v ToolKind) IsValid() bool {
+	switch v {
+	case ToolKindRead, ToolKindEdit, ToolKindDelete, ToolKindMove, ToolKindSearch, ToolKindExecute, ToolKindThink, ToolKindFetch, ToolKindSwitchMode, ToolKindOther:
+		return true
+	}
+	return false
+}
+
+// AllToolKind returns the known ToolKind values in schema order.
+func AllToolKind() []ToolKind {
+	return []ToolKind{ToolKindRead, ToolKindEdit, ToolKindDelete, ToolKindMove, ToolKindSearch, ToolKindExecute, ToolKindThink, ToolKindFetch, ToolKindSwitchMode, ToolKindOther}
+}
+
 // Notification sent when a suggestion is accepted.
 type UnstableAcceptNesNotification struct {
 	// The _meta property is reserved by ACP to allow clients and agents to attach additional
@@ -6640,11 +6739,21 @@ type UnstableAcceptNesNotification struct {
 
 func (v *UnstableAcceptNesNotification) Validate() error {
 	if v.Id == "" {
-		return fmt.Errorf("id is required")
+		return newValidationError("id", "required", "id is required")
 	}
 	return nil
 }
 
+// Schema for boolean properties in an elicitation form.
+type UnstableBooleanPropertySchema struct {
+	// Default value.
+	Default *bool `json:"default,omitempty"`
+	// Human-readable description.
+	Description *string `json:"description,omitempty"`
+	// Optional title for the property.
+	Title *string `json:"title,omitempty"`
+}
+
 // **UNSTABLE**
 //
 // This capability is not part of the spec yet, and may be removed or changed at any point.
@@ -6832,43 +6941,10 @@ func (u *UnstableCreateElicitationRequest) UnmarshalJSON(b []byte) error {
 				u.Url = &v
 				return nil
 			}
-		}
-		{
-			var v UnstableCreateElicitationForm
-			var match bool = true
-			if _, ok := m["mode"]; !ok {
-				match = false
-			}
-			if _, ok := m["requestedSchema"]; !ok {
-				match = false
-			}
-			if match {
-				if json.Unmarshal(b, &v) != nil {
-					return errors.New("invalid variant payload")
-				}
-				u.Form = &v
-				return nil
-			}
-		}
-		{
-			var v UnstableCreateElicitationUrl
-			var match bool = true
-			if _, ok := m["mode"]; !ok {
-				match = false
-			}
-			if _, ok := m["elicitationId"]; !ok {
-				match = false
-			}
-			if _, ok := m["url"]; !ok {
-				match = false
-			}
-			if match {
-				if json.Unmarshal(b, &v) != nil {
-					return errors.New("invalid variant payload")
-				}
-				u.Url = &v
-				return nil
+			if disc == "" {
+				return fmt.Errorf("UnstableCreateElicitationRequest: missing \"mode\" discriminator")
 			}
+			return fmt.Errorf("UnstableCreateElicitationRequest: unknown mode %q", disc)
 		}
 	} else {
 		if _, ok := err.(*json.UnmarshalTypeError); !ok {
@@ -6895,6 +6971,9 @@ func (u *UnstableCreateElicitationRequest) UnmarshalJSON(b []byte) error {
 	return errors.New("no matching variant for union")
 }
 func (u UnstableCreateElicitationRequest) MarshalJSON() ([]byte, error) {
+	if err := u.Validate(); err != nil {
+		return nil, err
+	}
 	if u.Form != nil {
 		_b, _e := json.Marshal(*u.Form)
 		if _e != nil {
@@ -6919,7 +6998,7 @@ func (u UnstableCreateElicitationRequest) MarshalJSON() ([]byte, error) {
 		m["mode"] = "url"
 		return json.Marshal(m)
 	}
-	return []byte{}, nil
+	return nil, errors.New("UnstableCreateElicitationRequest has no variant set")
 }
 
 func (u *UnstableCreateElicitationRequest) Validate() error {
@@ -6931,7 +7010,7 @@ func (u *UnstableCreateElicitationRequest) Validate() error {
 		count++
 	}
 	if count != 1 {
-		return errors.New("UnstableCreateElicitationRequest must have exactly one variant set")
+		return fmt.Errorf("UnstableCreateElicitationRequest must have exactly one variant set, got %d", count)
 	}
 	return nil
 }
@@ -6951,7 +7030,7 @@ type UnstableCreateElicitationAccept struct {
 	Meta   map[string]any `json:"_meta,omitempty"`
 	Action string         `json:"action"`
 	// The user-provided content, if any, as an object matching the requested schema.
-	Content map[string]any `json:"content,omitempty"`
+	Content map[string]UnstableElicitationContentValue `json:"content,omitempty"`
 }
 
 // The user declined the elicitation.
@@ -7016,48 +7095,10 @@ func (u *UnstableCreateElicitationResponse) UnmarshalJSON(b []byte) error {
 				u.Cancel = &v
 				return nil
 			}
-		}
-		{
-			var v UnstableCreateElicitationAccept
-			var match bool = true
-			if _, ok := m["action"]; !ok {
-				match = false
-			}
-			if match {
-				if json.Unmarshal(b, &v) != nil {
-					return errors.New("invalid variant payload")
-				}
-				u.Accept = &v
-				return nil
-			}
-		}
-		{
-			var v UnstableCreateElicitationDecline
-			var match bool = true
-			if _, ok := m["action"]; !ok {
-				match = false
-			}
-			if match {
-				if json.Unmarshal(b, &v) != nil {
-					return errors.New("invalid variant payload")
-				}
-				u.Decline = &v
-				return nil
-			}
-		}
-		{
-			var v UnstableCreateElicitationCancel
-			var match bool = true
-			if _, ok := m["action"]; !ok {
-				match = false
-			}
-			if match {
-				if json.Unmarshal(b, &v) != nil {
-					return errors.New("invalid variant payload")
-				}
-				u.Cancel = &v
-				return nil
+			if disc == "" {
+				return fmt.Errorf("UnstableCreateElicitationResponse: missing \"action\" discriminator")
 			}
+			return fmt.Errorf("UnstableCreateElicitationResponse: unknown action %q", disc)
 		}
 	} else {
 		if _, ok := err.(*json.UnmarshalTypeError); !ok {
@@ -7091,6 +7132,9 @@ func (u *UnstableCreateElicitationResponse) UnmarshalJSON(b []byte) error {
 	return errors.New("no matching variant for union")
 }
 func (u UnstableCreateElicitationResponse) MarshalJSON() ([]byte, error) {
+	if err := u.Validate(); err != nil {
+		return nil, err
+	}
 	if u.Accept != nil {
 		_b, _e := json.Marshal(*u.Accept)
 		if _e != nil {
@@ -7127,7 +7171,7 @@ func (u UnstableCreateElicitationResponse) MarshalJSON() ([]byte, error) {
 		m["action"] = "cancel"
 		return json.Marshal(m)
 	}
-	return []byte{}, nil
+	return nil, errors.New("UnstableCreateElicitationResponse has no variant set")
 }
 
 func (u *UnstableCreateElicitationResponse) Validate() error {
@@ -7142,7 +7186,7 @@ func (u *UnstableCreateElicitationResponse) Validate() error {
 		count++
 	}
 	if count != 1 {
-		return errors.New("UnstableCreateElicitationResponse must have exactly one variant set")
+		return fmt.Errorf("UnstableCreateElicitationResponse must have exactly one variant set, got %d", count)
 	}
 	return nil
 }
@@ -7207,10 +7251,10 @@ type UnstableDidChangeDocumentNotification struct {
 
 func (v *UnstableDidChangeDocumentNotification) Validate() error {
 	if v.ContentChanges == nil {
-		return fmt.Errorf("contentChanges is required")
+		return newValidationError("contentChanges", "required", "contentChanges is required")
 	}
 	if v.Uri == "" {
-		return fmt.Errorf("uri is required")
+		return newValidationError("uri", "required", "uri is required")
 	}
 	return nil
 }
@@ -7231,7 +7275,7 @@ type UnstableDidCloseDocumentNotification struct {
 
 func (v *UnstableDidCloseDocumentNotification) Validate() error {
 	if v.Uri == "" {
-		return fmt.Errorf("uri is required")
+		return newValidationError("uri", "required", "uri is required")
 	}
 	return nil
 }
@@ -7258,7 +7302,7 @@ type UnstableDidFocusDocumentNotification struct {
 
 func (v *UnstableDidFocusDocumentNotification) Validate() error {
 	if v.Uri == "" {
-		return fmt.Errorf("uri is required")
+		return newValidationError("uri", "required", "uri is required")
 	}
 	return nil
 }
@@ -7285,13 +7329,13 @@ type UnstableDidOpenDocumentNotification struct {
 
 func (v *UnstableDidOpenDocumentNotification) Validate() error {
 	if v.LanguageId == "" {
-		return fmt.Errorf("languageId is required")
+		return newValidationError("languageId", "required", "languageId is required")
 	}
 	if v.Text == "" {
-		return fmt.Errorf("text is required")
+		return newValidationError("text", "required", "text is required")
 	}
 	if v.Uri == "" {
-		return fmt.Errorf("uri is required")
+		return newValidationError("uri", "required", "uri is required")
 	}
 	return nil
 }
@@ -7312,7 +7356,7 @@ type UnstableDidSaveDocumentNotification struct {
 
 func (v *UnstableDidSaveDocumentNotification) Validate() error {
 	if v.Uri == "" {
-		return fmt.Errorf("uri is required")
+		return newValidationError("uri", "required", "uri is required")
 	}
 	return nil
 }
@@ -7335,7 +7379,7 @@ type UnstableDisableProviderRequest struct {
 
 func (v *UnstableDisableProviderRequest) Validate() error {
 	if v.Id == "" {
-		return fmt.Errorf("id is required")
+		return newValidationError("id", "required", "id is required")
 	}
 	return nil
 }
@@ -7403,7 +7447,137 @@ func (v *UnstableDisconnectMcpResponse) Validate() error {
 // The user accepted the elicitation and provided content.
 type UnstableElicitationAcceptAction struct {
 	// The user-provided content, if any, as an object matching the requested schema.
-	Content map[string]any `json:"content,omitempty"`
+	Content map[string]UnstableElicitationContentValue `json:"content,omitempty"`
+}
+
+type UnstableElicitationContentValueString string
+
+type UnstableElicitationContentValueInteger int
+
+type UnstableElicitationContentValueNumber float64
+
+type UnstableElicitationContentValueBoolean bool
+
+type UnstableElicitationContentValueStringArray []string
+
+type UnstableElicitationContentValue struct {
+	String      *UnstableElicitationContentValueString      `json:"-"`
+	Integer     *UnstableElicitationContentValueInteger     `json:"-"`
+	Number      *UnstableElicitationContentValueNumber      `json:"-"`
+	Boolean     *UnstableElicitationContentValueBoolean     `json:"-"`
+	StringArray *UnstableElicitationContentValueStringArray `json:"-"`
+}
+
+func (u *UnstableElicitationContentValue) UnmarshalJSON(b []byte) error {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(b, &m); err == nil {
+	} else {
+		if _, ok := err.(*json.UnmarshalTypeError); !ok {
+			return err
+		}
+	}
+	var arr []map[string]json.RawMessage
+	if json.Unmarshal(b, &arr) == nil {
+	}
+	{
+		var v UnstableElicitationContentValueString
+		if json.Unmarshal(b, &v) == nil {
+			u.String = &v
+			return nil
+		}
+	}
+	{
+		var v UnstableElicitationContentValueInteger
+		if json.Unmarshal(b, &v) == nil {
+			u.Integer = &v
+			return nil
+		}
+	}
+	{
+		var v UnstableElicitationContentValueNumber
+		if json.Unmarshal(b, &v) == nil {
+			u.Number = &v
+			return nil
+		}
+	}
+	{
+		var v UnstableElicitationContentValueBoolean
+		if json.Unmarshal(b, &v) == nil {
+			u.Boolean = &v
+			return nil
+		}
+	}
+	{
+		var v UnstableElicitationContentValueStringArray
+		if json.Unmarshal(b, &v) == nil {
+			u.StringArray = &v
+			return nil
+		}
+	}
+	return errors.New("no matching variant for union")
+}
+func (u UnstableElicitationContentValue) MarshalJSON() ([]byte, error) {
+	if u.String != nil {
+		_b, _e := json.Marshal(*u.String)
+		if _e != nil {
+			return []byte{}, _e
+		}
+		return _b, nil
+		var m map[string]any
+		if json.Unmarshal(_b, &m) != nil {
+			return []byte{}, errors.New("invalid variant payload")
+		}
+		return json.Marshal(m)
+	}
+	if u.Integer != nil {
+		_b, _e := json.Marshal(*u.Integer)
+		if _e != nil {
+			return []byte{}, _e
+		}
+		return _b, nil
+		var m map[string]any
+		if json.Unmarshal(_b, &m) != nil {
+			return []byte{}, errors.New("invalid variant payload")
+		}
+		return json.Marshal(m)
+	}
+	if u.Number != nil {
+		_b, _e := json.Marshal(*u.Number)
+		if _e != nil {
+			return []byte{}, _e
+		}
+		return _b, nil
+		var m map[string]any
+		if json.Unmarshal(_b, &m) != nil {
+			return []byte{}, errors.New("invalid variant payload")
+		}
+		return json.Marshal(m)
+	}
+	if u.Boolean != nil {
+		_b, _e := json.Marshal(*u.Boolean)
+		if _e != nil {
+			return []byte{}, _e
+		}
+		return _b, nil
+		var m map[string]any
+		if json.Unmarshal(_b, &m) != nil {
+			return []byte{}, errors.New("invalid variant payload")
+		}
+		return json.Marshal(m)
+	}
+	if u.StringArray != nil {
+		_b, _e := json.Marshal(*u.StringArray)
+		if _e != nil {
+			return []byte{}, _e
+		}
+		return _b, nil
+		var m map[string]any
+		if json.Unmarshal(_b, &m) != nil {
+			return []byte{}, errors.New("invalid variant payload")
+		}
+		return json.Marshal(m)
+	}
+	return nil, errors.New("UnstableElicitationContentValue has no variant set")
 }
 
 // **UNSTABLE**
@@ -7497,7 +7671,29 @@ func (u UnstableElicitationFormMode) MarshalJSON() ([]byte, error) {
 		}
 		return json.Marshal(m)
 	}
-	return []byte{}, nil
+	return nil, errors.New("UnstableElicitationFormMode has no variant set")
+}
+
+// validateVariant checks that exactly one variant is set and that the set
+// variant's required string fields are non-empty.
+func (u *UnstableElicitationFormMode) validateVariant() error {
+	var count int
+	if u.Session != nil {
+		count++
+	}
+	if u.Request != nil {
+		count++
+	}
+	if count != 1 {
+		return fmt.Errorf("UnstableElicitationFormMode must have exactly one variant set, got %d", count)
+	}
+	switch {
+	case u.Session != nil:
+		if u.Session.SessionId == "" {
+			return fmt.Errorf("session: sessionId is required")
+		}
+	}
+	return nil
 }
 
 // **UNSTABLE**
@@ -7507,6 +7703,290 @@ func (u UnstableElicitationFormMode) MarshalJSON() ([]byte, error) {
 // Unique identifier for an elicitation.
 type UnstableElicitationId string
 
+// Property schema for elicitation form fields.
+//
+// Each variant corresponds to a JSON Schema '"type"' value.
+// Single-select enums use the 'String' variant with 'enum' or 'oneOf' set.
+// Multi-select enums use the 'Array' variant.
+// String property (or single-select enum when 'enum'/'oneOf' is set).
+type UnstableElicitationPropertySchemaString struct {
+	// Default value.
+	Default *string `json:"default,omitempty"`
+	// Human-readable description.
+	Description *string `json:"description,omitempty"`
+	// Enum values for untitled single-select enums.
+	Enum []string `json:"enum,omitempty"`
+	// String format.
+	Format *UnstableStringFormat `json:"format,omitempty"`
+	// Maximum string length.
+	MaxLength *int `json:"maxLength,omitempty"`
+	// Minimum string length.
+	MinLength *int `json:"minLength,omitempty"`
+	// Titled enum options for titled single-select enums.
+	OneOf []UnstableEnumOption `json:"oneOf,omitempty"`
+	// Pattern the string must match.
+	Pattern *string `json:"pattern,omitempty"`
+	// Optional title for the property.
+	Title *string `json:"title,omitempty"`
+	Type  string  `json:"type"`
+}
+
+// Number (floating-point) property.
+type UnstableElicitationPropertySchemaNumber struct {
+	// Default value.
+	Default *float64 `json:"default,omitempty"`
+	// Human-readable description.
+	Description *string `json:"description,omitempty"`
+	// Maximum value (inclusive).
+	Maximum *float64 `json:"maximum,omitempty"`
+	// Minimum value (inclusive).
+	Minimum *float64 `json:"minimum,omitempty"`
+	// Optional title for the property.
+	Title *string `json:"title,omitempty"`
+	Type  string  `json:"type"`
+}
+
+// Integer property.
+type UnstableElicitationPropertySchemaInteger struct {
+	// Default value.
+	Default *int `json:"default,omitempty"`
+	// Human-readable description.
+	Description *string `json:"description,omitempty"`
+	// Maximum value (inclusive).
+	Maximum *int `json:"maximum,omitempty"`
+	// Minimum value (inclusive).
+	Minimum *int `json:"minimum,omitempty"`
+	// Optional title for the property.
+	Title *string `json:"title,omitempty"`
+	Type  string  `json:"type"`
+}
+
+// Boolean property.
+type UnstableElicitationPropertySchemaBoolean struct {
+	// Default value.
+	Default *bool `json:"default,omitempty"`
+	// Human-readable description.
+	Description *string `json:"description,omitempty"`
+	// Optional title for the property.
+	Title *string `json:"title,omitempty"`
+	Type  string  `json:"type"`
+}
+
+// Multi-select array property.
+type UnstableElicitationPropertySchemaArray struct {
+	// Default selected values.
+	Default []string `json:"default,omitempty"`
+	// Human-readable description.
+	Description *string `json:"description,omitempty"`
+	// The items definition describing allowed values.
+	Items UnstableMultiSelectItems `json:"items"`
+	// Maximum number of items to select.
+	MaxItems *int `json:"maxItems,omitempty"`
+	// Minimum number of items to select.
+	MinItems *int `json:"minItems,omitempty"`
+	// Optional title for the property.
+	Title *string `json:"title,omitempty"`
+	Type  string  `json:"type"`
+}
+
+type UnstableElicitationPropertySchema struct {
+	// String property (or single-select enum when 'enum'/'oneOf' is set).
+	String *UnstableElicitationPropertySchemaString `json:"-"`
+	// Number (floating-point) property.
+	Number *UnstableElicitationPropertySchemaNumber `json:"-"`
+	// Integer property.
+	Integer *UnstableElicitationPropertySchemaInteger `json:"-"`
+	// Boolean property.
+	Boolean *UnstableElicitationPropertySchemaBoolean `json:"-"`
+	// Multi-select array property.
+	Array *UnstableElicitationPropertySchemaArray `json:"-"`
+}
+
+func (u *UnstableElicitationPropertySchema) UnmarshalJSON(b []byte) error {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(b, &m); err == nil {
+		{
+			var disc string
+			if v, ok := m["type"]; ok {
+				json.Unmarshal(v, &disc)
+			}
+			switch disc {
+			case "string":
+				var v UnstableElicitationPropertySchemaString
+				if json.Unmarshal(b, &v) != nil {
+					return errors.New("invalid variant payload")
+				}
+				u.String = &v
+				return nil
+			case "number":
+				var v UnstableElicitationPropertySchemaNumber
+				if json.Unmarshal(b, &v) != nil {
+					return errors.New("invalid variant payload")
+				}
+				u.Number = &v
+				return nil
+			case "integer":
+				var v UnstableElicitationPropertySchemaInteger
+				if json.Unmarshal(b, &v) != nil {
+					return errors.New("invalid variant payload")
+				}
+				u.Integer = &v
+				return nil
+			case "boolean":
+				var v UnstableElicitationPropertySchemaBoolean
+				if json.Unmarshal(b, &v) != nil {
+					return errors.New("invalid variant payload")
+				}
+				u.Boolean = &v
+				return nil
+			case "array":
+				var v UnstableElicitationPropertySchemaArray
+				if json.Unmarshal(b, &v) != nil {
+					return errors.New("invalid variant payload")
+				}
+				u.Array = &v
+				return nil
+			}
+			if disc == "" {
+				return fmt.Errorf("UnstableElicitationPropertySchema: missing \"type\" discriminator")
+			}
+			return fmt.Errorf("UnstableElicitationPropertySchema: unknown type %q", disc)
+		}
+	} else {
+		if _, ok := err.(*json.UnmarshalTypeError); !ok {
+			return err
+		}
+	}
+	var arr []map[string]json.RawMessage
+	if json.Unmarshal(b, &arr) == nil {
+	}
+	{
+		var v UnstableElicitationPropertySchemaString
+		if json.Unmarshal(b, &v) == nil {
+			u.String = &v
+			return nil
+		}
+	}
+	{
+		var v UnstableElicitationPropertySchemaNumber
+		if json.Unmarshal(b, &v) == nil {
+			u.Number = &v
+			return nil
+		}
+	}
+	{
+		var v UnstableElicitationPropertySchemaInteger
+		if json.Unmarshal(b, &v) == nil {
+			u.Integer = &v
+			return nil
+		}
+	}
+	{
+		var v UnstableElicitationPropertySchemaBoolean
+		if json.Unmarshal(b, &v) == nil {
+			u.Boolean = &v
+			return nil
+		}
+	}
+	{
+		var v UnstableElicitationPropertySchemaArray
+		if json.Unmarshal(b, &v) == nil {
+			u.Array = &v
+			return nil
+		}
+	}
+	return errors.New("no matching variant for union")
+}
+func (u UnstableElicitationPropertySchema) MarshalJSON() ([]byte, error) {
+	if err := u.Validate(); err != nil {
+		return nil, err
+	}
+	if u.String != nil {
+		_b, _e := json.Marshal(*u.String)
+		if _e != nil {
+			return []byte{}, _e
+		}
+		var m map[string]any
+		if json.Unmarshal(_b, &m) != nil {
+			return []byte{}, errors.New("invalid variant payload")
+		}
+		m["type"] = "string"
+		return json.Marshal(m)
+	}
+	if u.Number != nil {
+		_b, _e := json.Marshal(*u.Number)
+		if _e != nil {
+			return []byte{}, _e
+		}
+		var m map[string]any
+		if json.Unmarshal(_b, &m) != nil {
+			return []byte{}, errors.New("invalid variant payload")
+		}
+		m["type"] = "number"
+		return json.Marshal(m)
+	}
+	if u.Integer != nil {
+		_b, _e := json.Marshal(*u.Integer)
+		if _e != nil {
+			return []byte{}, _e
+		}
+		var m map[string]any
+		if json.Unmarshal(_b, &m) != nil {
+			return []byte{}, errors.New("invalid variant payload")
+		}
+		m["type"] = "integer"
+		return json.Marshal(m)
+	}
+	if u.Boolean != nil {
+		_b, _e := json.Marshal(*u.Boolean)
+		if _e != nil {
+			return []byte{}, _e
+		}
+		var m map[string]any
+		if json.Unmarshal(_b, &m) != nil {
+			return []byte{}, errors.New("invalid variant payload")
+		}
+		m["type"] = "boolean"
+		return json.Marshal(m)
+	}
+	if u.Array != nil {
+		_b, _e := json.Marshal(*u.Array)
+		if _e != nil {
+			return []byte{}, _e
+		}
+		var m map[string]any
+		if json.Unmarshal(_b, &m) != nil {
+			return []byte{}, errors.New("invalid variant payload")
+		}
+		m["type"] = "array"
+		return json.Marshal(m)
+	}
+	return nil, errors.New("UnstableElicitationPropertySchema has no variant set")
+}
+
+func (u *UnstableElicitationPropertySchema) Validate() error {
+	var count int
+	if u.String != nil {
+		count++
+	}
+	if u.Number != nil {
+		count++
+	}
+	if u.Integer != nil {
+		count++
+	}
+	if u.Boolean != nil {
+		count++
+	}
+	if u.Array != nil {
+		count++
+	}
+	if count != 1 {
+		return fmt.Errorf("UnstableElicitationPropertySchema must have exactly one variant set, got %d", count)
+	}
+	return nil
+}
+
 // **UNSTABLE**
 //
 // This capability is not part of the spec yet, and may be removed or changed at any point.
@@ -7528,7 +8008,7 @@ type UnstableElicitationSchema struct {
 	// Property definitions (must be primitive types).
 	//
 	// Defaults to {} if unset.
-	Properties map[string]any `json:"properties"`
+	Properties map[string]UnstableElicitationPropertySchema `json:"properties,omitempty"`
 	// List of required property names.
 	Required []string `json:"required,omitempty"`
 	// Optional title for the schema.
@@ -7539,16 +8019,6 @@ type UnstableElicitationSchema struct {
 	Type UnstableElicitationSchemaType `json:"type,omitempty"`
 }
 
-func (v UnstableElicitationSchema) MarshalJSON() ([]byte, error) {
-	type Alias UnstableElicitationSchema
-	var a Alias
-	a = Alias(v)
-	if a.Properties == nil {
-		json.Unmarshal([]byte("{}"), &a.Properties)
-	}
-	return json.Marshal(a)
-}
-
 func (v *UnstableElicitationSchema) UnmarshalJSON(b []byte) error {
 	var m map[string]json.RawMessage
 	if err := json.Unmarshal(b, &m); err != nil {
@@ -7582,6 +8052,25 @@ const (
 	UnstableElicitationSchemaTypeObject UnstableElicitationSchemaType = "object"
 )
 
+// String returns the wire value of v.
+func (v UnstableElicitationSchemaType) String() string {
+	return string(v)
+}
+
+// IsValid reports whether v is one of the known UnstableElicitationSchemaType values.
+func (v UnstableElicitationSchemaType) IsValid() bool {
+	switch v {
+	case UnstableElicitationSchemaTypeObject:
+		return true
+	}
+	return false
+}
+
+// AllUnstableElicitationSchemaType returns the known UnstableElicitationSchemaType values in schema order.
+func AllUnstableElicitationSchemaType() []UnstableElicitationSchemaType {
+	return []UnstableElicitationSchemaType{UnstableElicitationSchemaTypeObject}
+}
+
 // **UNSTABLE**
 //
 // This capability is not part of the spec yet, and may be removed or changed at any point.
@@ -7598,6 +8087,32 @@ type UnstableElicitationSessionScope struct {
 	ToolCallId *ToolCallId `json:"toolCallId,omitempty"`
 }
 
+// Items definition for untitled multi-select enum properties.
+type UnstableElicitationStringType string
+
+const (
+	UnstableElicitationStringTypeString UnstableElicitationStringType = "string"
+)
+
+// String returns the wire value of v.
+func (v UnstableElicitationStringType) String() string {
+	return string(v)
+}
+
+// IsValid reports whether v is one of the known UnstableElicitationStringType values.
+func (v UnstableElicitationStringType) IsValid() bool {
+	switch v {
+	case UnstableElicitationStringTypeString:
+		return true
+	}
+	return false
+}
+
+// AllUnstableElicitationStringType returns the known UnstableElicitationStringType values in schema order.
+func AllUnstableElicitationStringType() []UnstableElicitationStringType {
+	return []UnstableElicitationStringType{UnstableElicitationStringTypeString}
+}
+
 // **UNSTABLE**
 //
 // This capability is not part of the spec yet, and may be removed or changed at any point.
@@ -7689,7 +8204,37 @@ func (u UnstableElicitationUrlMode) MarshalJSON() ([]byte, error) {
 		}
 		return json.Marshal(m)
 	}
-	return []byte{}, nil
+	return nil, errors.New("UnstableElicitationUrlMode has no variant set")
+}
+
+// validateVariant checks that exactly one variant is set and that the set
+// variant's required string fields are non-empty.
+func (u *UnstableElicitationUrlMode) validateVariant() error {
+	var count int
+	if u.Session != nil {
+		count++
+	}
+	if u.Request != nil {
+		count++
+	}
+	if count != 1 {
+		return fmt.Errorf("UnstableElicitationUrlMode must have exactly one variant set, got %d", count)
+	}
+	switch {
+	case u.Session != nil:
+		if u.Session.SessionId == "" {
+			return fmt.Errorf("session: sessionId is required")
+		}
+	}
+	return nil
+}
+
+// A titled enum option with a const value and human-readable title.
+type UnstableEnumOption struct {
+	// The constant value for this option.
+	Const string `json:"const"`
+	// Human-readable title for this option.
+	Title string `json:"title"`
 }
 
 // **UNSTABLE**
@@ -7725,7 +8270,7 @@ type UnstableForkSessionRequest struct {
 
 func (v *UnstableForkSessionRequest) Validate() error {
 	if v.Cwd == "" {
-		return fmt.Errorf("cwd is required")
+		return newValidationError("cwd", "required", "cwd is required")
 	}
 	return nil
 }
@@ -7756,6 +8301,20 @@ func (v *UnstableForkSessionResponse) Validate() error {
 	return nil
 }
 
+// Schema for integer properties in an elicitation form.
+type UnstableIntegerPropertySchema struct {
+	// Default value.
+	Default *int `json:"default,omitempty"`
+	// Human-readable description.
+	Description *string `json:"description,omitempty"`
+	// Maximum value (inclusive).
+	Maximum *int `json:"maximum,omitempty"`
+	// Minimum value (inclusive).
+	Minimum *int `json:"minimum,omitempty"`
+	// Optional title for the property.
+	Title *string `json:"title,omitempty"`
+}
+
 // **UNSTABLE**
 //
 // This capability is not part of the spec yet, and may be removed or changed at any point.
@@ -7792,7 +8351,7 @@ type UnstableListProvidersResponse struct {
 
 func (v *UnstableListProvidersResponse) Validate() error {
 	if v.Providers == nil {
-		return fmt.Errorf("providers is required")
+		return newValidationError("providers", "required", "providers is required")
 	}
 	return nil
 }
@@ -7817,6 +8376,26 @@ const (
 	UnstableLlmProtocolBedrock   UnstableLlmProtocol = "bedrock"
 )
 
+// String returns the wire value of v.
+func (v UnstableLlmProtocol) String() string {
+	return string(v)
+}
+
+// IsValid reports whether v is one of the known UnstableLlmProtocol values. The schema also
+// permits other strings, so an invalid value is not necessarily an error.
+func (v UnstableLlmProtocol) IsValid() bool {
+	switch v {
+	case UnstableLlmProtocolAnthropic, UnstableLlmProtocolOpenai, UnstableLlmProtocolAzure, UnstableLlmProtocolVertex, UnstableLlmProtocolBedrock:
+		return true
+	}
+	return false
+}
+
+// AllUnstableLlmProtocol returns the known UnstableLlmProtocol values in schema order.
+func AllUnstableLlmProtocol() []UnstableLlmProtocol {
+	return []UnstableLlmProtocol{UnstableLlmProtocolAnthropic, UnstableLlmProtocolOpenai, UnstableLlmProtocolAzure, UnstableLlmProtocolVertex, UnstableLlmProtocolBedrock}
+}
+
 // **UNSTABLE**
 //
 // This capability is not part of the spec yet, and may be removed or changed at any point.
@@ -8114,17 +8693,69 @@ func (u UnstableMcpServer) MarshalJSON() ([]byte, error) {
 		return json.Marshal(m)
 	}
 	if u.Stdio != nil {
-		_b, _e := json.Marshal(*u.Stdio)
-		if _e != nil {
-			return []byte{}, _e
+		_b, _e := json.Marshal(*u.Stdio)
+		if _e != nil {
+			return []byte{}, _e
+		}
+		var m map[string]any
+		if json.Unmarshal(_b, &m) != nil {
+			return []byte{}, errors.New("invalid variant payload")
+		}
+		return json.Marshal(m)
+	}
+	return nil, errors.New("UnstableMcpServer has no variant set")
+}
+
+// validateVariant checks that exactly one variant is set and that the set
+// variant's required string fields are non-empty.
+func (u *UnstableMcpServer) validateVariant() error {
+	var count int
+	if u.Http != nil {
+		count++
+	}
+	if u.Sse != nil {
+		count++
+	}
+	if u.Acp != nil {
+		count++
+	}
+	if u.Stdio != nil {
+		count++
+	}
+	if count != 1 {
+		return fmt.Errorf("UnstableMcpServer must have exactly one variant set, got %d", count)
+	}
+	switch {
+	case u.Http != nil:
+		if u.Http.Name == "" {
+			return errors.New("http: name is required")
 		}
-		var m map[string]any
-		if json.Unmarshal(_b, &m) != nil {
-			return []byte{}, errors.New("invalid variant payload")
+		if u.Http.Url == "" {
+			return fmt.Errorf("http %q: url is required", u.Http.Name)
+		}
+	case u.Sse != nil:
+		if u.Sse.Name == "" {
+			return errors.New("sse: name is required")
+		}
+		if u.Sse.Url == "" {
+			return fmt.Errorf("sse %q: url is required", u.Sse.Name)
+		}
+	case u.Acp != nil:
+		if u.Acp.Name == "" {
+			return errors.New("acp: name is required")
+		}
+		if u.Acp.Id == "" {
+			return fmt.Errorf("acp %q: id is required", u.Acp.Name)
+		}
+	case u.Stdio != nil:
+		if u.Stdio.Name == "" {
+			return errors.New("stdio: name is required")
+		}
+		if u.Stdio.Command == "" {
+			return fmt.Errorf("stdio %q: command is required", u.Stdio.Name)
 		}
-		return json.Marshal(m)
 	}
-	return []byte{}, nil
+	return nil
 }
 
 // **UNSTABLE**
@@ -8189,7 +8820,7 @@ type UnstableMessageMcpNotification struct {
 
 func (v *UnstableMessageMcpNotification) Validate() error {
 	if v.Method == "" {
-		return fmt.Errorf("method is required")
+		return newValidationError("method", "required", "method is required")
 	}
 	return nil
 }
@@ -8218,7 +8849,7 @@ type UnstableMessageMcpRequest struct {
 
 func (v *UnstableMessageMcpRequest) Validate() error {
 	if v.Method == "" {
-		return fmt.Errorf("method is required")
+		return newValidationError("method", "required", "method is required")
 	}
 	return nil
 }
@@ -8233,6 +8864,114 @@ func (v *UnstableMessageMcpRequest) Validate() error {
 // UnstableMessageMcpResponse is a union or complex schema; represented generically.
 type UnstableMessageMcpResponse any
 
+// Items for a multi-select (array) property schema.
+type UnstableMultiSelectItems struct {
+	// Untitled multi-select items with plain string values.
+	Untitled *UnstableUntitledMultiSelectItems `json:"-"`
+	// Titled multi-select items with human-readable labels.
+	Titled *UnstableTitledMultiSelectItems `json:"-"`
+}
+
+func (u *UnstableMultiSelectItems) UnmarshalJSON(b []byte) error {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(b, &m); err == nil {
+		{
+			var v UnstableUntitledMultiSelectItems
+			var match bool = true
+			if _, ok := m["type"]; !ok {
+				match = false
+			}
+			if _, ok := m["enum"]; !ok {
+				match = false
+			}
+			if match {
+				if json.Unmarshal(b, &v) != nil {
+					return errors.New("invalid variant payload")
+				}
+				u.Untitled = &v
+				return nil
+			}
+		}
+		{
+			var v UnstableTitledMultiSelectItems
+			var match bool = true
+			if _, ok := m["anyOf"]; !ok {
+				match = false
+			}
+			if match {
+				if json.Unmarshal(b, &v) != nil {
+					return errors.New("invalid variant payload")
+				}
+				u.Titled = &v
+				return nil
+			}
+		}
+	} else {
+		if _, ok := err.(*json.UnmarshalTypeError); !ok {
+			return err
+		}
+	}
+	var arr []map[string]json.RawMessage
+	if json.Unmarshal(b, &arr) == nil {
+	}
+	{
+		var v UnstableUntitledMultiSelectItems
+		if json.Unmarshal(b, &v) == nil {
+			u.Untitled = &v
+			return nil
+		}
+	}
+	{
+		var v UnstableTitledMultiSelectItems
+		if json.Unmarshal(b, &v) == nil {
+			u.Titled = &v
+			return nil
+		}
+	}
+	return errors.New("no matching variant for union")
+}
+func (u UnstableMultiSelectItems) MarshalJSON() ([]byte, error) {
+	if u.Untitled != nil {
+		_b, _e := json.Marshal(*u.Untitled)
+		if _e != nil {
+			return []byte{}, _e
+		}
+		var m map[string]any
+		if json.Unmarshal(_b, &m) != nil {
+			return []byte{}, errors.New("invalid variant payload")
+		}
+		return json.Marshal(m)
+	}
+	if u.Titled != nil {
+		_b, _e := json.Marshal(*u.Titled)
+		if _e != nil {
+			return []byte{}, _e
+		}
+		var m map[string]any
+		if json.Unmarshal(_b, &m) != nil {
+			return []byte{}, errors.New("invalid variant payload")
+		}
+		return json.Marshal(m)
+	}
+	return nil, errors.New("UnstableMultiSelectItems has no variant set")
+}
+
+// Schema for multi-select (array) properties in an elicitation form.
+type UnstableMultiSelectPropertySchema struct {
+	// Default selected values.
+	Default []string `json:"default,omitempty"`
+	// Human-readable description.
+	Description *string `json:"description,omitempty"`
+	// The items definition describing allowed values.
+	Items UnstableMultiSelectItems `json:"items"`
+	// Maximum number of items to select.
+	MaxItems *int `json:"maxItems,omitempty"`
+	// Minimum number of items to select.
+	MinItems *int `json:"minItems,omitempty"`
+	// Optional title for the property.
+	Title *string `json:"title,omitempty"`
+}
+
 // A diagnostic (error, warning, etc.).
 type UnstableNesDiagnostic struct {
 	// The diagnostic message.
@@ -8255,6 +8994,25 @@ const (
 	UnstableNesDiagnosticSeverityHint        UnstableNesDiagnosticSeverity = "hint"
 )
 
+// String returns the wire value of v.
+func (v UnstableNesDiagnosticSeverity) String() string {
+	return string(v)
+}
+
+// IsValid reports whether v is one of the known UnstableNesDiagnosticSeverity values.
+func (v UnstableNesDiagnosticSeverity) IsValid() bool {
+	switch v {
+	case UnstableNesDiagnosticSeverityError, UnstableNesDiagnosticSeverityWarning, UnstableNesDiagnosticSeverityInformation, UnstableNesDiagnosticSeverityHint:
+		return true
+	}
+	return false
+}
+
+// AllUnstableNesDiagnosticSeverity returns the known UnstableNesDiagnosticSeverity values in schema order.
+func AllUnstableNesDiagnosticSeverity() []UnstableNesDiagnosticSeverity {
+	return []UnstableNesDiagnosticSeverity{UnstableNesDiagnosticSeverityError, UnstableNesDiagnosticSeverityWarning, UnstableNesDiagnosticSeverityInformation, UnstableNesDiagnosticSeverityHint}
+}
+
 // An entry in the edit history.
 type UnstableNesEditHistoryEntry struct {
 	// A diff representing the edit.
@@ -8327,6 +9085,25 @@ const (
 	UnstableNesRejectReasonCancelled UnstableNesRejectReason = "cancelled"
 )
 
+// String returns the wire value of v.
+func (v UnstableNesRejectReason) String() string {
+	return string(v)
+}
+
+// IsValid reports whether v is one of the known UnstableNesRejectReason values.
+func (v UnstableNesRejectReason) IsValid() bool {
+	switch v {
+	case UnstableNesRejectReasonRejected, UnstableNesRejectReasonIgnored, UnstableNesRejectReasonReplaced, UnstableNesRejectReasonCancelled:
+		return true
+	}
+	return false
+}
+
+// AllUnstableNesRejectReason returns the known UnstableNesRejectReason values in schema order.
+func AllUnstableNesRejectReason() []UnstableNesRejectReason {
+	return []UnstableNesRejectReason{UnstableNesRejectReasonRejected, UnstableNesRejectReasonIgnored, UnstableNesRejectReasonReplaced, UnstableNesRejectReasonCancelled}
+}
+
 // A related code snippet from a file.
 type UnstableNesRelatedSnippet struct {
 	// The code excerpts.
@@ -8495,104 +9272,10 @@ func (u *UnstableNesSuggestion) UnmarshalJSON(b []byte) error {
 				u.SearchAndReplace = &v
 				return nil
 			}
-		}
-		{
-			var v UnstableNesSuggestionEdit
-			var match bool = true
-			if _, ok := m["kind"]; !ok {
-				match = false
-			}
-			if _, ok := m["id"]; !ok {
-				match = false
-			}
-			if _, ok := m["uri"]; !ok {
-				match = false
-			}
-			if _, ok := m["edits"]; !ok {
-				match = false
-			}
-			if match {
-				if json.Unmarshal(b, &v) != nil {
-					return errors.New("invalid variant payload")
-				}
-				u.Edit = &v
-				return nil
-			}
-		}
-		{
-			var v UnstableNesSuggestionJump
-			var match bool = true
-			if _, ok := m["kind"]; !ok {
-				match = false
-			}
-			if _, ok := m["id"]; !ok {
-				match = false
-			}
-			if _, ok := m["uri"]; !ok {
-				match = false
-			}
-			if _, ok := m["position"]; !ok {
-				match = false
-			}
-			if match {
-				if json.Unmarshal(b, &v) != nil {
-					return errors.New("invalid variant payload")
-				}
-				u.Jump = &v
-				return nil
-			}
-		}
-		{
-			var v UnstableNesSuggestionRename
-			var match bool = true
-			if _, ok := m["kind"]; !ok {
-				match = false
-			}
-			if _, ok := m["id"]; !ok {
-				match = false
-			}
-			if _, ok := m["uri"]; !ok {
-				match = false
-			}
-			if _, ok := m["position"]; !ok {
-				match = false
-			}
-			if _, ok := m["newName"]; !ok {
-				match = false
-			}
-			if match {
-				if json.Unmarshal(b, &v) != nil {
-					return errors.New("invalid variant payload")
-				}
-				u.Rename = &v
-				return nil
-			}
-		}
-		{
-			var v UnstableNesSuggestionSearchAndReplace
-			var match bool = true
-			if _, ok := m["kind"]; !ok {
-				match = false
-			}
-			if _, ok := m["id"]; !ok {
-				match = false
-			}
-			if _, ok := m["uri"]; !ok {
-				match = false
-			}
-			if _, ok := m["search"]; !ok {
-				match = false
-			}
-			if _, ok := m["replace"]; !ok {
-				match = false
-			}
-			if match {
-				if json.Unmarshal(b, &v) != nil {
-					return errors.New("invalid variant payload")
-				}
-				u.SearchAndReplace = &v
-				return nil
+			if disc == "" {
+				return fmt.Errorf("UnstableNesSuggestion: missing \"kind\" discriminator")
 			}
+			return fmt.Errorf("UnstableNesSuggestion: unknown kind %q", disc)
 		}
 	} else {
 		if _, ok := err.(*json.UnmarshalTypeError); !ok {
@@ -8633,6 +9316,9 @@ func (u *UnstableNesSuggestion) UnmarshalJSON(b []byte) error {
 	return errors.New("no matching variant for union")
 }
 func (u UnstableNesSuggestion) MarshalJSON() ([]byte, error) {
+	if err := u.Validate(); err != nil {
+		return nil, err
+	}
 	if u.Edit != nil {
 		_b, _e := json.Marshal(*u.Edit)
 		if _e != nil {
@@ -8681,7 +9367,7 @@ func (u UnstableNesSuggestion) MarshalJSON() ([]byte, error) {
 		m["kind"] = "searchAndReplace"
 		return json.Marshal(m)
 	}
-	return []byte{}, nil
+	return nil, errors.New("UnstableNesSuggestion has no variant set")
 }
 
 func (u *UnstableNesSuggestion) Validate() error {
@@ -8699,7 +9385,7 @@ func (u *UnstableNesSuggestion) Validate() error {
 		count++
 	}
 	if count != 1 {
-		return errors.New("UnstableNesSuggestion must have exactly one variant set")
+		return fmt.Errorf("UnstableNesSuggestion must have exactly one variant set, got %d", count)
 	}
 	return nil
 }
@@ -8721,6 +9407,25 @@ const (
 	UnstableNesTriggerKindManual     UnstableNesTriggerKind = "manual"
 )
 
+// String returns the wire value of v.
+func (v UnstableNesTriggerKind) String() string {
+	return string(v)
+}
+
+// IsValid reports whether v is one of the known UnstableNesTriggerKind values.
+func (v UnstableNesTriggerKind) IsValid() bool {
+	switch v {
+	case UnstableNesTriggerKindAutomatic, UnstableNesTriggerKindDiagnostic, UnstableNesTriggerKindManual:
+		return true
+	}
+	return false
+}
+
+// AllUnstableNesTriggerKind returns the known UnstableNesTriggerKind values in schema order.
+func AllUnstableNesTriggerKind() []UnstableNesTriggerKind {
+	return []UnstableNesTriggerKind{UnstableNesTriggerKindAutomatic, UnstableNesTriggerKindDiagnostic, UnstableNesTriggerKindManual}
+}
+
 // A user action (typing, cursor movement, etc.).
 type UnstableNesUserAction struct {
 	// The kind of action (e.g., "insertChar", "cursorMovement").
@@ -8733,6 +9438,20 @@ type UnstableNesUserAction struct {
 	Uri string `json:"uri"`
 }
 
+// Schema for number (floating-point) properties in an elicitation form.
+type UnstableNumberPropertySchema struct {
+	// Default value.
+	Default *float64 `json:"default,omitempty"`
+	// Human-readable description.
+	Description *string `json:"description,omitempty"`
+	// Maximum value (inclusive).
+	Maximum *float64 `json:"maximum,omitempty"`
+	// Minimum value (inclusive).
+	Minimum *float64 `json:"minimum,omitempty"`
+	// Optional title for the property.
+	Title *string `json:"title,omitempty"`
+}
+
 // A zero-based position in a text document.
 //
 // The meaning of 'character' depends on the negotiated position encoding.
@@ -8805,7 +9524,10 @@ type UnstableRejectNesNotification struct {
 
 func (v *UnstableRejectNesNotification) Validate() error {
 	if v.Id == "" {
-		return fmt.Errorf("id is required")
+		return newValidationError("id", "required", "id is required")
+	}
+	if v.Reason != nil && !v.Reason.IsValid() {
+		return newValidationError("reason", "unknown enum", "invalid reason: %q", *v.Reason)
 	}
 	return nil
 }
@@ -8904,43 +9626,10 @@ func (u *UnstableSessionConfigOption) UnmarshalJSON(b []byte) error {
 				u.Boolean = &v
 				return nil
 			}
-		}
-		{
-			var v UnstableSessionConfigOptionSelect
-			var match bool = true
-			if _, ok := m["type"]; !ok {
-				match = false
-			}
-			if _, ok := m["currentValue"]; !ok {
-				match = false
-			}
-			if _, ok := m["options"]; !ok {
-				match = false
-			}
-			if match {
-				if json.Unmarshal(b, &v) != nil {
-					return errors.New("invalid variant payload")
-				}
-				u.Select = &v
-				return nil
-			}
-		}
-		{
-			var v UnstableSessionConfigOptionBoolean
-			var match bool = true
-			if _, ok := m["type"]; !ok {
-				match = false
-			}
-			if _, ok := m["currentValue"]; !ok {
-				match = false
-			}
-			if match {
-				if json.Unmarshal(b, &v) != nil {
-					return errors.New("invalid variant payload")
-				}
-				u.Boolean = &v
-				return nil
+			if disc == "" {
+				return fmt.Errorf("UnstableSessionConfigOption: missing \"type\" discriminator")
 			}
+			return fmt.Errorf("UnstableSessionConfigOption: unknown type %q", disc)
 		}
 	} else {
 		if _, ok := err.(*json.UnmarshalTypeError); !ok {
@@ -8967,6 +9656,9 @@ func (u *UnstableSessionConfigOption) UnmarshalJSON(b []byte) error {
 	return errors.New("no matching variant for union")
 }
 func (u UnstableSessionConfigOption) MarshalJSON() ([]byte, error) {
+	if err := u.Validate(); err != nil {
+		return nil, err
+	}
 	if u.Select != nil {
 		_b, _e := json.Marshal(*u.Select)
 		if _e != nil {
@@ -8991,7 +9683,7 @@ func (u UnstableSessionConfigOption) MarshalJSON() ([]byte, error) {
 		m["type"] = "boolean"
 		return json.Marshal(m)
 	}
-	return []byte{}, nil
+	return nil, errors.New("UnstableSessionConfigOption has no variant set")
 }
 
 func (u *UnstableSessionConfigOption) Validate() error {
@@ -9003,7 +9695,7 @@ func (u *UnstableSessionConfigOption) Validate() error {
 		count++
 	}
 	if count != 1 {
-		return errors.New("UnstableSessionConfigOption must have exactly one variant set")
+		return fmt.Errorf("UnstableSessionConfigOption must have exactly one variant set, got %d", count)
 	}
 	return nil
 }
@@ -9028,17 +9720,17 @@ type UnstableSetProviderRequest struct {
 	BaseUrl string `json:"baseUrl"`
 	// Full headers map for this provider.
 	// May include authorization, routing, or other integration-specific headers.
-	Headers map[string]any `json:"headers,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
 	// Provider id to configure.
 	Id string `json:"id"`
 }
 
 func (v *UnstableSetProviderRequest) Validate() error {
 	if v.BaseUrl == "" {
-		return fmt.Errorf("baseUrl is required")
+		return newValidationError("baseUrl", "required", "baseUrl is required")
 	}
 	if v.Id == "" {
-		return fmt.Errorf("id is required")
+		return newValidationError("id", "required", "id is required")
 	}
 	return nil
 }
@@ -9097,6 +9789,60 @@ func (v *UnstableStartNesResponse) Validate() error {
 	return nil
 }
 
+// String format types for string properties in elicitation schemas.
+type UnstableStringFormat string
+
+const (
+	UnstableStringFormatEmail    UnstableStringFormat = "email"
+	UnstableStringFormatUri      UnstableStringFormat = "uri"
+	UnstableStringFormatDate     UnstableStringFormat = "date"
+	UnstableStringFormatDateTime UnstableStringFormat = "date-time"
+)
+
+// String returns the wire value of v.
+func (v UnstableStringFormat) String() string {
+	return string(v)
+}
+
+// IsValid reports whether v is one of the known UnstableStringFormat values.
+func (v UnstableStringFormat) IsValid() bool {
+	switch v {
+	case UnstableStringFormatEmail, UnstableStringFormatUri, UnstableStringFormatDate, UnstableStringFormatDateTime:
+		return true
+	}
+	return false
+}
+
+// AllUnstableStringFormat returns the known UnstableStringFormat values in schema order.
+func AllUnstableStringFormat() []UnstableStringFormat {
+	return []UnstableStringFormat{UnstableStringFormatEmail, UnstableStringFormatUri, UnstableStringFormatDate, UnstableStringFormatDateTime}
+}
+
+// Schema for string properties in an elicitation form.
+//
+// When 'enum' or 'oneOf' is set, this represents a single-select enum
+// with '"type": "string"'.
+type UnstableStringPropertySchema struct {
+	// Default value.
+	Default *string `json:"default,omitempty"`
+	// Human-readable description.
+	Description *string `json:"description,omitempty"`
+	// Enum values for untitled single-select enums.
+	Enum []string `json:"enum,omitempty"`
+	// String format.
+	Format *UnstableStringFormat `json:"format,omitempty"`
+	// Maximum string length.
+	MaxLength *int `json:"maxLength,omitempty"`
+	// Minimum string length.
+	MinLength *int `json:"minLength,omitempty"`
+	// Titled enum options for titled single-select enums.
+	OneOf []UnstableEnumOption `json:"oneOf,omitempty"`
+	// Pattern the string must match.
+	Pattern *string `json:"pattern,omitempty"`
+	// Optional title for the property.
+	Title *string `json:"title,omitempty"`
+}
+
 // Request for a code suggestion.
 type UnstableSuggestNesRequest struct {
 	// The _meta property is reserved by ACP to allow clients and agents to attach additional
@@ -9122,8 +9868,11 @@ type UnstableSuggestNesRequest struct {
 }
 
 func (v *UnstableSuggestNesRequest) Validate() error {
+	if !v.TriggerKind.IsValid() {
+		return newValidationError("triggerKind", "unknown enum", "invalid triggerKind: %q", v.TriggerKind)
+	}
 	if v.Uri == "" {
-		return fmt.Errorf("uri is required")
+		return newValidationError("uri", "required", "uri is required")
 	}
 	return nil
 }
@@ -9142,7 +9891,7 @@ type UnstableSuggestNesResponse struct {
 
 func (v *UnstableSuggestNesResponse) Validate() error {
 	if v.Suggestions == nil {
-		return fmt.Errorf("suggestions is required")
+		return newValidationError("suggestions", "required", "suggestions is required")
 	}
 	return nil
 }
@@ -9158,6 +9907,20 @@ type UnstableTextDocumentContentChangeEvent struct {
 	Text string `json:"text"`
 }
 
+// Items definition for titled multi-select enum properties.
+type UnstableTitledMultiSelectItems struct {
+	// Titled enum options.
+	AnyOf []UnstableEnumOption `json:"anyOf"`
+}
+
+// Items definition for untitled multi-select enum properties.
+type UnstableUntitledMultiSelectItems struct {
+	// Allowed enum values.
+	Enum []string `json:"enum"`
+	// Item type discriminator. Must be '"string"'.
+	Type UnstableElicitationStringType `json:"type"`
+}
+
 // A workspace folder.
 type UnstableWorkspaceFolder struct {
 	// The display name of the folder.
@@ -9234,7 +9997,7 @@ type WaitForTerminalExitRequest struct {
 
 func (v *WaitForTerminalExitRequest) Validate() error {
 	if v.TerminalId == "" {
-		return fmt.Errorf("terminalId is required")
+		return newValidationError("terminalId", "required", "terminalId is required")
 	}
 	return nil
 }
@@ -9254,6 +10017,9 @@ type WaitForTerminalExitResponse struct {
 }
 
 func (v *WaitForTerminalExitResponse) Validate() error {
+	if v.ExitCode != nil && *v.ExitCode < 0 {
+		return newValidationError("exitCode", "below minimum", "exitCode must be >= 0, got %v", *v.ExitCode)
+	}
 	return nil
 }
 
@@ -9277,10 +10043,10 @@ type WriteTextFileRequest struct {
 
 func (v *WriteTextFileRequest) Validate() error {
 	if v.Content == "" {
-		return fmt.Errorf("content is required")
+		return newValidationError("content", "required", "content is required")
 	}
 	if v.Path == "" {
-		return fmt.Errorf("path is required")
+		return newValidationError("path", "required", "path is required")
 	}
 	return nil
 }
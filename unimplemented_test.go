@@ -0,0 +1,56 @@
+package acp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+var (
+	_ Agent              = UnimplementedAgent{}
+	_ AgentLoader        = UnimplementedAgent{}
+	_ AgentExperimental  = UnimplementedAgent{}
+	_ Client             = UnimplementedClient{}
+	_ ClientExperimental = UnimplementedClient{}
+)
+
+type promptOnlyAgent struct {
+	UnimplementedAgent
+}
+
+func (promptOnlyAgent) Prompt(context.Context, PromptRequest) (PromptResponse, error) {
+	return PromptResponse{StopReason: StopReasonEndTurn}, nil
+}
+
+func TestUnimplementedAgent_EmbeddedDispatch(t *testing.T) {
+	c2aR, c2aW := io.Pipe()
+	a2cR, a2cW := io.Pipe()
+	defer func() {
+		_ = c2aR.Close()
+		_ = c2aW.Close()
+		_ = a2cR.Close()
+		_ = a2cW.Close()
+	}()
+
+	_ = NewAgentSideConnection(promptOnlyAgent{}, a2cW, c2aR)
+	cs := NewClientSideConnection(UnimplementedClient{}, c2aW, a2cR)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	res, err := cs.Prompt(ctx, PromptRequest{SessionId: "s", Prompt: []ContentBlock{TextBlock("hi")}})
+	if err != nil {
+		t.Fatalf("Prompt: %v", err)
+	}
+	if res.StopReason != StopReasonEndTurn {
+		t.Fatalf("unexpected stop reason: %v", res.StopReason)
+	}
+
+	_, err = cs.NewSession(ctx, NewSessionRequest{Cwd: "/", McpServers: []McpServer{}})
+	var re *RequestError
+	if !errors.As(err, &re) || re.Code != -32601 {
+		t.Fatalf("expected method not found, got %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package acp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestValidateNumericBounds(t *testing.T) {
+	ok := InitializeRequest{ProtocolVersion: ProtocolVersionNumber}
+	if err := ok.Validate(); err != nil {
+		t.Fatalf("valid request rejected: %v", err)
+	}
+	for _, v := range []ProtocolVersion{-1, 65536} {
+		req := InitializeRequest{ProtocolVersion: v}
+		if err := req.Validate(); err == nil {
+			t.Fatalf("protocolVersion %d accepted", v)
+		}
+	}
+
+	read := ReadTextFileRequest{SessionId: "s1", Path: "/a", Line: Ptr(-1)}
+	err := read.Validate()
+	if err == nil || err.Error() != "line must be >= 0, got -1" {
+		t.Fatalf("Validate() = %v", err)
+	}
+	read.Line = nil
+	if err := read.Validate(); err != nil {
+		t.Fatalf("unset optional bound rejected: %v", err)
+	}
+}
+
+func TestValidateBoundsAtDispatch(t *testing.T) {
+	called := false
+	as := newTerminalTestAgent(t, &clientFuncs{
+		ReadTextFileFunc: func(context.Context, ReadTextFileRequest) (ReadTextFileResponse, error) {
+			called = true
+			return ReadTextFileResponse{}, nil
+		},
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	_, err := as.ReadTextFile(ctx, ReadTextFileRequest{SessionId: "s1", Path: "/a", Limit: Ptr(-5)})
+	if !errors.Is(err, ErrInvalidParams) {
+		t.Fatalf("expected invalid params, got %v", err)
+	}
+	if called {
+		t.Fatalf("handler ran despite invalid params")
+	}
+}
+
+func TestDiscriminatedUnionRejectsMalformedPayloads(t *testing.T) {
+	for _, raw := range []string{
+		`{"text":"hi"}`,
+		`{"type":"frobnicate","text":"hi"}`,
+	} {
+		var cb ContentBlock
+		if err := json.Unmarshal([]byte(raw), &cb); err == nil {
+			t.Fatalf("%s decoded as %+v", raw, cb)
+		}
+	}
+	var cb ContentBlock
+	if err := json.Unmarshal([]byte(`{"type":"text","text":"hi"}`), &cb); err != nil || cb.Text == nil {
+		t.Fatalf("valid block: %+v, %v", cb, err)
+	}
+
+	if _, err := json.Marshal(ContentBlock{}); err == nil {
+		t.Fatalf("marshaled union with no variant set")
+	}
+	two := ContentBlock{Text: &ContentBlockText{Text: "a"}, Image: &ContentBlockImage{Data: "b", MimeType: "image/png"}}
+	if err := two.Validate(); err == nil {
+		t.Fatalf("Validate accepted two variants")
+	}
+	if _, err := json.Marshal(two); err == nil {
+		t.Fatalf("marshaled union with two variants set")
+	}
+}
+
+func TestDispatch_ValidationFailureCarriesField(t *testing.T) {
+	conn := &ClientSideConnection{client: &clientFuncs{}}
+
+	_, reqErr := conn.handle(context.Background(), ClientMethodFsReadTextFile, json.RawMessage(`{"sessionId":"s1","path":"/a","line":-1}`))
+	if reqErr == nil || reqErr.Code != CodeInvalidParams {
+		t.Fatalf("expected invalid params, got %+v", reqErr)
+	}
+	want := map[string]any{"error": "line must be >= 0, got -1", "field": "line", "reason": "below minimum"}
+	if !reflect.DeepEqual(reqErr.Data, want) {
+		t.Fatalf("data = %#v, want %#v", reqErr.Data, want)
+	}
+	var ve *ValidationError
+	if !errors.As(reqErr, &ve) || ve.Field != "line" {
+		t.Fatalf("RequestError does not unwrap to the ValidationError: %v", reqErr)
+	}
+
+	// Malformed JSON is still reported without a field.
+	_, reqErr = conn.handle(context.Background(), ClientMethodFsReadTextFile, json.RawMessage(`{"sessionId":1}`))
+	if reqErr == nil || reqErr.Code != CodeInvalidParams {
+		t.Fatalf("expected invalid params, got %+v", reqErr)
+	}
+	if data, _ := reqErr.Data.(map[string]any); data["field"] != nil {
+		t.Fatalf("decode error reported a field: %+v", reqErr)
+	}
+}
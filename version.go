@@ -0,0 +1,34 @@
+package acp
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Range of protocol versions this SDK can speak.
+const (
+	MinSupportedProtocolVersion = 1
+	MaxSupportedProtocolVersion = ProtocolVersionNumber
+)
+
+// ErrUnsupportedProtocolVersion indicates that the two sides share no protocol
+// version.
+var ErrUnsupportedProtocolVersion = errors.New("unsupported protocol version")
+
+// NegotiateProtocolVersion picks the protocol version an agent should answer
+// initialize with. Per ACP, the client sends the latest version it supports;
+// the agent answers with that version if it supports it and otherwise with the
+// latest version it does support, so the result is the lower of clientWants and
+// agentSupports. It returns an error wrapping ErrUnsupportedProtocolVersion if
+// that version is below MinSupportedProtocolVersion.
+//
+// Agents typically call it as
+//
+//	v, err := acp.NegotiateProtocolVersion(int(params.ProtocolVersion), acp.MaxSupportedProtocolVersion)
+func NegotiateProtocolVersion(clientWants, agentSupports int) (int, error) {
+	v := min(clientWants, agentSupports)
+	if v < MinSupportedProtocolVersion {
+		return 0, fmt.Errorf("%w: client wants %d, agent supports up to %d, minimum is %d", ErrUnsupportedProtocolVersion, clientWants, agentSupports, MinSupportedProtocolVersion)
+	}
+	return v, nil
+}
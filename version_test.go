@@ -0,0 +1,38 @@
+package acp
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNegotiateProtocolVersion(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name                       string
+		clientWants, agentSupports int
+		want                       int
+		wantErr                    bool
+	}{
+		{name: "same", clientWants: 1, agentSupports: 1, want: 1},
+		{name: "client newer", clientWants: 3, agentSupports: 2, want: 2},
+		{name: "agent newer", clientWants: 1, agentSupports: 2, want: 1},
+		{name: "client too old", clientWants: 0, agentSupports: 1, wantErr: true},
+		{name: "agent too old", clientWants: 1, agentSupports: 0, wantErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := NegotiateProtocolVersion(tc.clientWants, tc.agentSupports)
+			if tc.wantErr {
+				if !errors.Is(err, ErrUnsupportedProtocolVersion) {
+					t.Fatalf("expected ErrUnsupportedProtocolVersion, got %d, %v", got, err)
+				}
+				return
+			}
+			if err != nil || got != tc.want {
+				t.Fatalf("NegotiateProtocolVersion(%d, %d) = %d, %v; want %d", tc.clientWants, tc.agentSupports, got, err, tc.want)
+			}
+		})
+	}
+	if v, err := NegotiateProtocolVersion(ProtocolVersionNumber, MaxSupportedProtocolVersion); err != nil || v != ProtocolVersionNumber {
+		t.Fatalf("current version does not negotiate to itself: %d, %v", v, err)
+	}
+}
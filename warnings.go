@@ -0,0 +1,95 @@
+package acp
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+)
+
+// ResponseWarningsMetaKey is the _meta key under which warnings added with
+// AddResponseWarning are sent, as an array of strings.
+const ResponseWarningsMetaKey = "warnings"
+
+type responseWarningsContextKey struct{}
+
+type responseWarnings struct {
+	mu   sync.Mutex
+	msgs []string
+}
+
+// AddResponseWarning attaches a non-fatal warning to the response of the
+// inbound request being handled, e.g. for a file read that succeeded but had
+// to replace undecodable bytes. Warnings are sent in the result's _meta under
+// ResponseWarningsMetaKey and are dropped if the handler returns an error or a
+// result that does not encode as a JSON object. AddResponseWarning does nothing
+// outside a request handler, including in notification handlers.
+func AddResponseWarning(ctx context.Context, msg string) {
+	w, ok := ctx.Value(responseWarningsContextKey{}).(*responseWarnings)
+	if !ok {
+		return
+	}
+	w.mu.Lock()
+	w.msgs = append(w.msgs, msg)
+	w.mu.Unlock()
+}
+
+// ResponseWarnings returns the warnings a handler attached to a response with
+// AddResponseWarning, given the response's Meta field.
+func ResponseWarnings(meta map[string]any) []string {
+	raw, _ := meta[ResponseWarningsMetaKey].([]any)
+	var out []string
+	for _, v := range raw {
+		if s, ok := v.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func (w *responseWarnings) snapshot() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]string(nil), w.msgs...)
+}
+
+// injectResponseWarnings adds msgs to the _meta of the encoded result, after
+// any warnings already there. A null result is treated as an empty object. It
+// reports false, leaving result untouched, if result is not a JSON object.
+func injectResponseWarnings(result json.RawMessage, msgs []string) (json.RawMessage, bool) {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(result, &obj); err != nil {
+		return result, false
+	}
+	if obj == nil {
+		obj = make(map[string]json.RawMessage)
+	}
+	var meta map[string]json.RawMessage
+	if raw, ok := obj["_meta"]; ok {
+		if err := json.Unmarshal(raw, &meta); err != nil {
+			return result, false
+		}
+	}
+	if meta == nil {
+		meta = make(map[string]json.RawMessage)
+	}
+	var all []string
+	if raw, ok := meta[ResponseWarningsMetaKey]; ok {
+		if err := json.Unmarshal(raw, &all); err != nil {
+			return result, false
+		}
+	}
+	all = append(all, msgs...)
+
+	var err error
+	if meta[ResponseWarningsMetaKey], err = json.Marshal(all); err != nil {
+		return result, false
+	}
+	if obj["_meta"], err = json.Marshal(meta); err != nil {
+		return result, false
+	}
+	out, err := json.Marshal(obj)
+	if err != nil {
+		return result, false
+	}
+	return out, true
+}
@@ -0,0 +1,78 @@
+package acp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestAddResponseWarning_RidesAlongInMeta(t *testing.T) {
+	client := &clientFuncs{
+		ReadTextFileFunc: func(ctx context.Context, req ReadTextFileRequest) (ReadTextFileResponse, error) {
+			AddResponseWarning(ctx, "replaced invalid UTF-8")
+			AddResponseWarning(ctx, "file truncated")
+			return ReadTextFileResponse{Content: "hello", Meta: map[string]any{"other": true}}, nil
+		},
+	}
+	_, agentConn := newNotificationBarrierTestPair(t, client, agentFuncs{})
+
+	resp, err := agentConn.ReadTextFile(context.Background(), ReadTextFileRequest{SessionId: "s", Path: "/f"})
+	if err != nil {
+		t.Fatalf("ReadTextFile: %v", err)
+	}
+	if resp.Content != "hello" || resp.Meta["other"] != true {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+	if got, want := ResponseWarnings(resp.Meta), []string{"replaced invalid UTF-8", "file truncated"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("warnings = %q, want %q", got, want)
+	}
+}
+
+func TestAddResponseWarning_NonObjectResultAndNotifications(t *testing.T) {
+	c2aR, c2aW := io.Pipe()
+	a2cR, a2cW := io.Pipe()
+	defer func() {
+		_ = c2aR.Close()
+		_ = c2aW.Close()
+		_ = a2cR.Close()
+		_ = a2cW.Close()
+	}()
+
+	notified := make(chan struct{})
+	NewConnection(func(ctx context.Context, method string, params json.RawMessage) (any, *RequestError) {
+		AddResponseWarning(ctx, "ignored")
+		switch method {
+		case "note":
+			close(notified)
+			return nil, nil
+		case "null":
+			return nil, nil
+		default:
+			return []int{1}, nil
+		}
+	}, a2cW, c2aR)
+	client := NewConnection(nil, c2aW, a2cR)
+
+	raw, err := SendRequest[json.RawMessage](client, context.Background(), "list", nil)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if string(raw) != "[1]" {
+		t.Fatalf("list result = %s, want [1]", raw)
+	}
+
+	raw, err = SendRequest[json.RawMessage](client, context.Background(), "null", nil)
+	if err != nil {
+		t.Fatalf("null: %v", err)
+	}
+	if want := `{"_meta":{"warnings":["ignored"]}}`; string(raw) != want {
+		t.Fatalf("null result = %s, want %s", raw, want)
+	}
+
+	if err := client.SendNotification(context.Background(), "note", nil); err != nil {
+		t.Fatalf("note: %v", err)
+	}
+	<-notified
+}
@@ -0,0 +1,66 @@
+package acp
+
+import "fmt"
+
+// Direction identifies which way a message travels relative to the local peer.
+type Direction int
+
+const (
+	// DirectionInbound marks messages received from the peer.
+	DirectionInbound Direction = iota
+	// DirectionOutbound marks messages sent to the peer.
+	DirectionOutbound
+)
+
+func (d Direction) String() string {
+	switch d {
+	case DirectionInbound:
+		return "inbound"
+	case DirectionOutbound:
+		return "outbound"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText encodes d as "inbound" or "outbound".
+func (d Direction) MarshalText() ([]byte, error) {
+	switch d {
+	case DirectionInbound, DirectionOutbound:
+		return []byte(d.String()), nil
+	default:
+		return nil, fmt.Errorf("invalid direction %d", int(d))
+	}
+}
+
+// UnmarshalText decodes "inbound" or "outbound".
+func (d *Direction) UnmarshalText(b []byte) error {
+	switch string(b) {
+	case "inbound":
+		*d = DirectionInbound
+	case "outbound":
+		*d = DirectionOutbound
+	default:
+		return fmt.Errorf("invalid direction %q", b)
+	}
+	return nil
+}
+
+// WireTap receives the raw JSON of every message on the wire, without framing.
+type WireTap func(direction Direction, raw []byte)
+
+// WithWireTap installs a callback that observes every well-formed or malformed
+// message exchanged with the peer, e.g. for protocol debugging. The callback gets
+// its own copy of the bytes. Outbound messages are tapped in the order they are
+// written, while the write lock is held, so the callback must return quickly.
+func WithWireTap(tap WireTap) ConnectionOption {
+	return func(c *Connection) {
+		c.wireTap = tap
+	}
+}
+
+func (c *Connection) tap(direction Direction, raw []byte) {
+	if c.wireTap != nil {
+		c.wireTap(direction, append([]byte(nil), raw...))
+	}
+}
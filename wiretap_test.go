@@ -0,0 +1,71 @@
+package acp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingTap struct {
+	mu     sync.Mutex
+	frames []string
+}
+
+func (r *recordingTap) tap(direction Direction, raw []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.frames = append(r.frames, direction.String()+" "+string(raw))
+	// Scribble over the buffer to make sure the connection handed us a copy.
+	for i := range raw {
+		raw[i] = 'X'
+	}
+}
+
+func (r *recordingTap) snapshot() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.frames...)
+}
+
+func TestWireTap_CapturesBothDirections(t *testing.T) {
+	c2aR, c2aW := io.Pipe()
+	a2cR, a2cW := io.Pipe()
+	defer func() {
+		_ = c2aR.Close()
+		_ = c2aW.Close()
+		_ = a2cR.Close()
+		_ = a2cW.Close()
+	}()
+
+	_ = NewConnection(func(ctx context.Context, method string, params json.RawMessage) (any, *RequestError) {
+		return map[string]string{"echo": method}, nil
+	}, a2cW, c2aR)
+
+	tap := &recordingTap{}
+	c := NewConnection(nil, c2aW, a2cR, WithWireTap(tap.tap))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	got, err := SendRequest[map[string]string](c, ctx, "hello", map[string]int{"n": 1})
+	if err != nil {
+		t.Fatalf("SendRequest: %v", err)
+	}
+	if got["echo"] != "hello" {
+		t.Fatalf("unexpected result: %v", got)
+	}
+
+	frames := tap.snapshot()
+	if len(frames) != 2 {
+		t.Fatalf("expected 2 tapped frames, got %d: %v", len(frames), frames)
+	}
+	if !strings.HasPrefix(frames[0], "outbound ") || !strings.Contains(frames[0], `"method":"hello"`) || strings.HasSuffix(frames[0], "\n") {
+		t.Fatalf("unexpected outbound frame: %q", frames[0])
+	}
+	if !strings.HasPrefix(frames[1], "inbound ") || !strings.Contains(frames[1], `"result":{"echo":"hello"}`) {
+		t.Fatalf("unexpected inbound frame: %q", frames[1])
+	}
+}
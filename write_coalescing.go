@@ -0,0 +1,76 @@
+package acp
+
+import (
+	"fmt"
+	"time"
+)
+
+// WithWriteCoalescing buffers outbound messages so that bursts, such as streams of
+// session/update notifications, reach the peer in fewer writes. Buffered messages
+// are written once maxBytes accumulate or maxDelay has passed since the first of
+// them was buffered, whichever comes first. Outbound requests are always flushed
+// before waiting for their response, so coalescing never stalls a request.
+// By default, every message is written as soon as it is sent.
+func WithWriteCoalescing(maxDelay time.Duration, maxBytes int) ConnectionOption {
+	return func(c *Connection) {
+		if maxDelay <= 0 || maxBytes <= 0 {
+			return
+		}
+		c.coalesceDelay = maxDelay
+		c.coalesceBytes = maxBytes
+	}
+}
+
+// writerFunc adapts Connection.write to io.Writer for use with bufio.Writer.
+type writerFunc func(b []byte) error
+
+func (f writerFunc) Write(b []byte) (int, error) {
+	if err := f(b); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// Flush writes any messages buffered by WithWriteCoalescing to the peer. It is a
+// no-op when write coalescing is disabled.
+func (c *Connection) Flush() error {
+	if c.bw == nil {
+		return nil
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.checkWriteErrorLocked(c.bw.Flush())
+}
+
+// bufferLocked appends a framed message to the coalescing buffer, flushing it when
+// full and otherwise scheduling a delayed flush. Callers must hold writeMu.
+func (c *Connection) bufferLocked(framed []byte) error {
+	if _, err := c.bw.Write(framed); err != nil {
+		return err
+	}
+	if c.bw.Buffered() == 0 {
+		return nil
+	}
+	if c.bw.Buffered() >= c.coalesceBytes {
+		return c.bw.Flush()
+	}
+	if !c.flushScheduled {
+		c.flushScheduled = true
+		time.AfterFunc(c.coalesceDelay, c.flushDelayed)
+	}
+	return nil
+}
+
+func (c *Connection) flushDelayed() {
+	c.writeMu.Lock()
+	c.flushScheduled = false
+	err := c.checkWriteErrorLocked(c.bw.Flush())
+	c.writeMu.Unlock()
+
+	// Nobody is waiting on a delayed flush, so a failure must close the connection
+	// rather than silently dropping the buffered messages.
+	if err != nil && c.ctx.Err() == nil {
+		c.loggerOrDefault().Error("failed to flush buffered messages; closing connection", "err", err)
+		c.cancel(fmt.Errorf("flush buffered messages: %w", err))
+	}
+}
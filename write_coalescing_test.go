@@ -0,0 +1,141 @@
+package acp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingWriter counts Write calls made to the underlying writer.
+type countingWriter struct {
+	w      io.Writer
+	writes atomic.Int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	cw.writes.Add(1)
+	return cw.w.Write(p)
+}
+
+func TestWriteCoalescing_FlushesBeforeWaitingForResponse(t *testing.T) {
+	c2aR, c2aW := io.Pipe()
+	a2cR, a2cW := io.Pipe()
+	defer func() {
+		_ = c2aR.Close()
+		_ = c2aW.Close()
+		_ = a2cR.Close()
+		_ = a2cW.Close()
+	}()
+
+	var mu sync.Mutex
+	var methods []string
+	_ = NewConnection(func(ctx context.Context, method string, params json.RawMessage) (any, *RequestError) {
+		mu.Lock()
+		methods = append(methods, method)
+		mu.Unlock()
+		return map[string]any{}, nil
+	}, a2cW, c2aR)
+
+	w := &countingWriter{w: c2aW}
+	c := NewConnection(nil, w, a2cR, WithWriteCoalescing(time.Hour, 64*1024))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	for i := 0; i < 5; i++ {
+		if err := c.SendNotification(ctx, "note", nil); err != nil {
+			t.Fatalf("SendNotification: %v", err)
+		}
+	}
+	if got := w.writes.Load(); got != 0 {
+		t.Fatalf("notifications were not buffered: %d writes", got)
+	}
+
+	if err := c.SendRequestNoResult(ctx, "req", nil); err != nil {
+		t.Fatalf("SendRequestNoResult: %v", err)
+	}
+	if got := w.writes.Load(); got != 1 {
+		t.Fatalf("expected a single coalesced write, got %d", got)
+	}
+
+	// Notifications are handled on their own queue, so they may still be in flight.
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		n := len(methods)
+		mu.Unlock()
+		if n == 6 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(methods) != 6 {
+		t.Fatalf("expected all buffered messages to be delivered, got %v", methods)
+	}
+}
+
+func TestWriteCoalescing_FlushesAfterMaxDelay(t *testing.T) {
+	c2aR, c2aW := io.Pipe()
+	a2cR, a2cW := io.Pipe()
+	defer func() {
+		_ = c2aR.Close()
+		_ = c2aW.Close()
+		_ = a2cR.Close()
+		_ = a2cW.Close()
+	}()
+
+	notified := make(chan struct{}, 1)
+	_ = NewConnection(func(ctx context.Context, method string, params json.RawMessage) (any, *RequestError) {
+		notified <- struct{}{}
+		return nil, nil
+	}, a2cW, c2aR)
+	c := NewConnection(nil, c2aW, a2cR, WithWriteCoalescing(10*time.Millisecond, 64*1024))
+
+	if err := c.SendNotification(context.Background(), "note", nil); err != nil {
+		t.Fatalf("SendNotification: %v", err)
+	}
+	select {
+	case <-notified:
+	case <-time.After(2 * time.Second):
+		t.Fatal("buffered notification was never flushed")
+	}
+}
+
+func TestWriteCoalescing_ShutdownFlushesResponses(t *testing.T) {
+	c2aR, c2aW := io.Pipe()
+	a2cR, a2cW := io.Pipe()
+	defer func() {
+		_ = c2aR.Close()
+		_ = c2aW.Close()
+		_ = a2cR.Close()
+		_ = a2cW.Close()
+	}()
+
+	started := make(chan struct{})
+	server := NewConnection(func(ctx context.Context, method string, params json.RawMessage) (any, *RequestError) {
+		close(started)
+		time.Sleep(50 * time.Millisecond)
+		return map[string]any{"ok": true}, nil
+	}, a2cW, c2aR, WithWriteCoalescing(time.Hour, 64*1024))
+	client := NewConnection(nil, c2aW, a2cR)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	errc := make(chan error, 1)
+	go func() {
+		_, err := SendRequest[json.RawMessage](client, ctx, "slow", nil)
+		errc <- err
+	}()
+	<-started
+	if err := server.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	if err := <-errc; err != nil {
+		t.Fatalf("response buffered before Shutdown was lost: %v", err)
+	}
+}